@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bills/db"
+	"bills/internal/archive"
+	"bills/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const usage = `Usage: backup [-db URL] [-dir D] [-keep N] [command] [arguments]
+
+Commands:
+  create             write a snapshot of the running SQLite database to the
+                     backup directory and delete all but the newest -keep
+  list               list the snapshots of the backup directory
+  restore FILE       replace the database with a snapshot, after checking it
+                     was taken by this build or an older one. Stop the server
+                     first
+  export [-o FILE]   write every workspace to a JSON archive, stdout by
+                     default. Works on SQLite and PostgreSQL alike
+  import FILE        add the workspaces of a JSON archive to the database
+
+Without a command backup creates a snapshot.
+
+Flags:
+`
+
+func main() {
+	// Parse command line flags
+	dbPath := flag.String("db", os.Getenv("DATABASE_URL"), "SQLite database path or PostgreSQL URL, DATABASE_URL or bills.db by default")
+	dir := flag.String("dir", envOr("BACKUP_DIR", "backups"), "Directory of the snapshots, BACKUP_DIR or backups by default")
+	keep := flag.Int("keep", envInt("BACKUP_KEEP", 7), "Number of snapshots to keep, 0 keeps them all, BACKUP_KEEP or 7 by default")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command, args := "create", flag.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	ctx := context.Background()
+
+	// Restoring replaces the database file, it is opened once checked
+	if command == "restore" {
+		if len(args) != 1 {
+			log.Fatal("expected the snapshot to restore")
+		}
+		if err := db.Restore(ctx, args[0], *dbPath); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Restored %s", args[0])
+		return
+	}
+
+	// Listing only reads the directory
+	if command == "list" {
+		if err := list(db.NewBackups(nil, *dir, *keep)); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Open database
+	sqlDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	// Enable foreign keys
+	if err := db.EnableForeignKeys(sqlDB); err != nil {
+		log.Fatal(err)
+	}
+
+	switch command {
+	case "create":
+		var file db.BackupFile
+		if file, err = db.NewBackups(sqlDB, *dir, *keep).Create(ctx); err == nil {
+			log.Printf("Backed up to %s/%s", *dir, file.Name)
+		}
+	case "export":
+		err = export(ctx, sqlDB, args)
+	case "import":
+		err = importArchive(ctx, sqlDB, *dbPath, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// envOr returns the environment variable key, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envInt returns the environment variable key as a number, or fallback when
+// it is not set
+func envInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("invalid %s %q", key, value)
+	}
+	return n
+}
+
+// list prints the snapshots, newest first
+func list(backups *db.Backups) error {
+	files, err := backups.List()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("No backups")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTAKEN\tSIZE")
+	for _, file := range files {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", file.Name, file.CreatedAt.Local().Format("2006-01-02 15:04:05"), file.Size)
+	}
+	return w.Flush()
+}
+
+// export writes the archive of every workspace to -o or stdout
+func export(ctx context.Context, sqlDB *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "File to write the archive to, stdout by default")
+	flags.Parse(args)
+
+	if err := db.CheckSchema(sqlDB); err != nil {
+		return fmt.Errorf("export reads the schema of the latest migration: %w", err)
+	}
+	repos := repository.NewRepositories(sqlDB)
+	exported, err := archive.Export(ctx, repository.NewUnitOfWork(sqlDB), repos.Workspaces)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return archive.Write(os.Stdout, exported)
+	}
+	file, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	err = archive.Write(file, exported)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Exported %d workspaces to %s", len(exported.Workspaces), *output)
+	return nil
+}
+
+// importArchive migrates the database and adds the workspaces of the archive
+// to it
+func importArchive(ctx context.Context, sqlDB *sql.DB, dbPath string, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected the archive to import")
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	imported, err := archive.Read(file)
+	if err != nil {
+		return err
+	}
+
+	if err := db.MigrateDB(sqlDB, dbPath); err != nil {
+		return err
+	}
+	if err := db.CheckSchema(sqlDB); err != nil {
+		return err
+	}
+	repos := repository.NewRepositories(sqlDB)
+	summary, err := archive.Import(ctx, imported, repository.NewUnitOfWork(sqlDB), repos.Workspaces)
+	if err != nil {
+		return err
+	}
+	log.Printf("Imported %s", summary)
+	return nil
+}
@@ -3,116 +3,354 @@ package main
 import (
 	"bills/db"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
+const usage = `Usage: migrate [-db URL] [command] [arguments]
+
+Commands:
+  up [N]             apply every pending migration, or the next N
+  down N|all         roll back the last N migrations, or all of them
+  status             list the applied and pending migrations with checksums,
+                     flagging applied ones whose files changed since
+  goto V             migrate up or down to version V
+  force V            record version V and clear the dirty flag of a failed
+                     migration without running anything, -1 for none
+  create [-dir D] NAME
+                     scaffold the up and down files of a new migration for
+                     SQLite and PostgreSQL, versioned with the current UTC
+                     time, in db/migrations by default
+  inspect            print every bill with its lines
+
+Without a command migrate applies every pending migration.
+
+Flags:
+`
+
 func main() {
 	// Parse command line flags
-	down := flag.Bool("down", false, "Drop all tables")
-	dbPath := flag.String("db", "bills.db", "Database path")
-	check := flag.Bool("check", false, "Check database content")
+	dbPath := flag.String("db", os.Getenv("DATABASE_URL"), "SQLite database path or PostgreSQL URL, DATABASE_URL or bills.db by default")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	command, args := "up", flag.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	// Creating a migration only writes files
+	if command == "create" {
+		if err := create(args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Open database
-	sqlDB, err := sql.Open("sqlite3", *dbPath)
+	sqlDB, err := db.Open(*dbPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer sqlDB.Close()
 
 	// Enable foreign keys
-	_, err = sqlDB.Exec("PRAGMA foreign_keys = ON")
+	if err := db.EnableForeignKeys(sqlDB); err != nil {
+		log.Fatal(err)
+	}
+
+	switch command {
+	case "up":
+		if len(args) == 0 {
+			err = db.MigrateDB(sqlDB, *dbPath)
+			break
+		}
+		var n int
+		if n, err = steps(args); err == nil {
+			err = db.MigrateSteps(sqlDB, n)
+		}
+	case "down":
+		if len(args) == 1 && args[0] == "all" {
+			err = db.DropDB(sqlDB, *dbPath)
+			break
+		}
+		var n int
+		if n, err = steps(args); err == nil {
+			err = db.MigrateSteps(sqlDB, -n)
+		}
+	case "goto":
+		var version int
+		if version, err = versionArg(args); err == nil && version < 0 {
+			err = fmt.Errorf("invalid version %d", version)
+		}
+		if err == nil {
+			err = db.MigrateTo(sqlDB, uint(version))
+		}
+	case "force":
+		var version int
+		if version, err = versionArg(args); err == nil {
+			err = db.ForceVersion(sqlDB, version)
+		}
+	case "status":
+		err = status(sqlDB)
+	case "inspect":
+		err = inspect(sqlDB)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if *check {
-		// Query bills
-		rows, err := sqlDB.Query(`
-			SELECT b.id, b.amount, b.due_date, b.paid, b.issuer_id, b.receiver_id
-			FROM bills b
-			ORDER BY b.id DESC
-		`)
+	if command != "status" && command != "inspect" {
+		version, dirty, err := db.MigrationVersion(sqlDB)
 		if err != nil {
 			log.Fatal(err)
 		}
-		defer rows.Close()
+		log.Printf("Database at version %d, dirty: %v", version, dirty)
+	}
+}
 
-		fmt.Println("\nBills:")
-		fmt.Println("----------------------------------------")
-		for rows.Next() {
-			var (
-				id, issuerID, receiverID int64
-				amount                   float64
-				dueDate                  time.Time
-				paid                     bool
-			)
-			if err := rows.Scan(&id, &amount, &dueDate, &paid, &issuerID, &receiverID); err != nil {
-				log.Fatal(err)
+// steps parses the number of migrations of up and down
+func steps(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected the number of migrations")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid number of migrations %q", args[0])
+	}
+	return n, nil
+}
+
+// versionArg parses the version of goto and force
+func versionArg(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected a version")
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil || version < -1 {
+		return 0, fmt.Errorf("invalid version %q", args[0])
+	}
+	return version, nil
+}
+
+// status prints every migration, whether it was applied and its checksum,
+// then whether the schema matches the models. Applied migrations whose
+// checksum differs from the recorded one are marked changed
+func status(sqlDB *sql.DB) error {
+	migrations, err := db.Migrations(sqlDB)
+	if err != nil {
+		return err
+	}
+	applied, err := db.AppliedChecksums(sqlDB)
+	if err != nil {
+		return err
+	}
+	version, dirty, err := db.MigrationVersion(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Database at version %d, dirty: %v\n\n", version, dirty)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tCHECKSUM")
+	known := version == 0
+	changed := false
+	for _, migration := range migrations {
+		state := "pending"
+		switch {
+		case migration.Version == version && dirty:
+			state = "dirty"
+		case migration.Version <= version:
+			state = "applied"
+			if checksum, ok := applied[migration.Version]; ok && checksum != migration.Checksum {
+				state = "changed"
+				changed = true
 			}
-			fmt.Printf("Bill ID: %d\n", id)
-			fmt.Printf("Amount: %.2f\n", amount)
-			fmt.Printf("Due Date: %s\n", dueDate.Format("2006-01-02"))
-			fmt.Printf("Paid: %v\n", paid)
-			fmt.Printf("Issuer ID: %d\n", issuerID)
-			fmt.Printf("Receiver ID: %d\n", receiverID)
-
-			// Query bill items for this bill
-			itemRows, err := sqlDB.Query(`
-				SELECT a.id, a.item_id, a.quantity, a.unit_price, a.subtotal,
-					   i.description
-				FROM bill_item_assignments a
-				LEFT JOIN bill_items i ON a.item_id = i.id
-				WHERE a.bill_id = ?
-			`, id)
+		}
+		known = known || migration.Version == version
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", migration.Version, migration.Name, state, migration.Checksum[:16])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !known {
+		fmt.Printf("\nVersion %d is not one of the migrations of this build\n", version)
+	}
+	if changed {
+		fmt.Println("\nChanged migrations differ from the files they were applied from, the database does not have their new version")
+	}
+
+	if len(migrations) > 0 && version == migrations[len(migrations)-1].Version && !dirty {
+		if err := db.CheckSchema(sqlDB); err != nil {
+			fmt.Printf("\n%v", err)
+			return nil
+		}
+		fmt.Println("\nThe schema matches the models")
+	}
+	return nil
+}
+
+// migrationName is what create accepts as the name of a migration
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// create writes empty up and down files of a migration named after the
+// current time, for SQLite in dir and for PostgreSQL in dir/postgres
+func create(args []string) error {
+	flags := flag.NewFlagSet("create", flag.ExitOnError)
+	dir := flags.String("dir", filepath.Join("db", "migrations"), "Directory of the SQLite migrations, the PostgreSQL ones are in its postgres directory")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("expected the name of the migration")
+	}
+	name := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(flags.Arg(0)), " ", "_"))
+	if !migrationName.MatchString(name) {
+		return fmt.Errorf("invalid migration name %q, use letters, digits and underscores", flags.Arg(0))
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+	for _, dialect := range []string{*dir, filepath.Join(*dir, "postgres")} {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dialect, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
 			if err != nil {
-				log.Fatal(err)
+				return err
+			}
+			_, err = fmt.Fprintf(file, "-- %s %s\n", strings.ReplaceAll(name, "_", " "), direction)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
 			}
-			defer itemRows.Close()
-
-			fmt.Println("\nBill Items:")
-			fmt.Println("----------------------------------------")
-			for itemRows.Next() {
-				var (
-					assignID, itemID    int64
-					quantity            int
-					unitPrice, subtotal float64
-					description         string
-				)
-				if err := itemRows.Scan(&assignID, &itemID, &quantity, &unitPrice, &subtotal, &description); err != nil {
-					log.Fatal(err)
-				}
-				fmt.Printf("Assignment ID: %d\n", assignID)
-				fmt.Printf("Item ID: %d\n", itemID)
-				fmt.Printf("Description: %s\n", description)
-				fmt.Printf("Quantity: %d\n", quantity)
-				fmt.Printf("Unit Price: %.2f\n", unitPrice)
-				fmt.Printf("Subtotal: %.2f\n", subtotal)
+			if err != nil {
+				return err
 			}
-			fmt.Println("----------------------------------------\n")
+			fmt.Println("Created", path)
 		}
-		os.Exit(0)
 	}
+	return nil
+}
 
-	if *down {
-		// Drop all tables
-		if err := db.DropDB(sqlDB, *dbPath); err != nil {
-			log.Fatal(err)
+// inspectLine is a line of a bill as inspect prints it
+type inspectLine struct {
+	id, itemID                                 int64
+	itemName, currency                         string
+	quantity                                   int
+	price, exchangeRate, originalAmount, total float64
+}
+
+// inspect prints every bill of every workspace with its lines, trashed ones
+// included
+func inspect(sqlDB *sql.DB) error {
+	if err := db.CheckSchema(sqlDB); err != nil {
+		return fmt.Errorf("inspect reads the schema of the latest migration: %w", err)
+	}
+
+	lines := make(map[int64][]inspectLine)
+	rows, err := sqlDB.Query(`
+		SELECT a.bill_id, a.id, a.item_id, COALESCE(i.name, ''), a.quantity, a.price,
+			a.currency, a.exchange_rate, a.original_amount, a.eur_amount
+		FROM bill_item_assignments a
+		LEFT JOIN bill_items i ON i.id = a.item_id
+		ORDER BY a.bill_id, a.id
+	`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var billID int64
+		var line inspectLine
+		if err := rows.Scan(&billID, &line.id, &line.itemID, &line.itemName, &line.quantity, &line.price,
+			&line.currency, &line.exchangeRate, &line.originalAmount, &line.total); err != nil {
+			rows.Close()
+			return err
 		}
-		log.Println("Successfully dropped all tables")
-	} else {
-		// Apply migrations
-		if err := db.MigrateDB(sqlDB, *dbPath); err != nil {
-			log.Fatal(err)
+		lines[billID] = append(lines[billID], line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = sqlDB.Query(`
+		SELECT b.id, b.workspace_id, b.issue_date, b.due_date, COALESCE(b.paid, FALSE),
+			b.issuer_id, COALESCE(i.name, ''), b.receiver_id, COALESCE(r.name, ''),
+			b.currency, b.original_total, b.eur_total, b.deleted_at
+		FROM bills b
+		LEFT JOIN issuers i ON i.id = b.issuer_id
+		LEFT JOIN receivers r ON r.id = b.receiver_id
+		ORDER BY b.id DESC
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var (
+			id, workspaceID, issuerID, receiverID int64
+			issuerName, receiverName, currency    string
+			issueDate, deletedAt                  sql.NullTime
+			dueDate                               time.Time
+			paid                                  bool
+			originalTotal, eurTotal               float64
+		)
+		if err := rows.Scan(&id, &workspaceID, &issueDate, &dueDate, &paid,
+			&issuerID, &issuerName, &receiverID, &receiverName,
+			&currency, &originalTotal, &eurTotal, &deletedAt); err != nil {
+			return err
+		}
+		count++
+
+		fmt.Println("----------------------------------------")
+		fmt.Printf("Bill #%d in workspace %d\n", id, workspaceID)
+		if issueDate.Valid {
+			fmt.Printf("Issued:   %s\n", issueDate.Time.Format("2006-01-02"))
+		}
+		fmt.Printf("Due:      %s\n", dueDate.Format("2006-01-02"))
+		fmt.Printf("Paid:     %v\n", paid)
+		fmt.Printf("Issuer:   %s (#%d)\n", issuerName, issuerID)
+		fmt.Printf("Receiver: %s (#%d)\n", receiverName, receiverID)
+		fmt.Printf("Total:    %.2f %s, %.2f EUR\n", originalTotal, currency, eurTotal)
+		if deletedAt.Valid {
+			fmt.Printf("Trashed:  %s\n", deletedAt.Time.Format("2006-01-02 15:04"))
 		}
-		log.Println("Successfully applied all migrations")
+
+		if len(lines[id]) == 0 {
+			fmt.Println("No lines")
+			continue
+		}
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "LINE\tITEM\tNAME\tQUANTITY\tPRICE\tCURRENCY\tRATE\tAMOUNT\tEUR\t")
+		for _, line := range lines[id] {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%.2f\t%s\t%.4f\t%.2f\t%.2f\t\n",
+				line.id, line.itemID, line.itemName, line.quantity, line.price,
+				line.currency, line.exchangeRate, line.originalAmount, line.total)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	os.Exit(0)
+	fmt.Println("----------------------------------------")
+	fmt.Printf("%d bills\n", count)
+	return nil
 }
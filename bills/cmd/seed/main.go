@@ -1,32 +1,36 @@
 package main
 
 import (
+	"bills/db"
 	"bills/internal/models"
 	"bills/internal/repository"
-	"database/sql"
+	"bills/internal/tenant"
+	"context"
 	"log"
-
-	_ "github.com/mattn/go-sqlite3"
+	"os"
 )
 
 func main() {
-	// Open database
-	db, err := sql.Open("sqlite3", "bills.db")
+	// Open the database named by DATABASE_URL, bills.db by default
+	sqlDB, err := db.Open(os.Getenv("DATABASE_URL"))
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer db.Close()
+	defer sqlDB.Close()
 
 	// Enable foreign keys
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
-	if err != nil {
+	if err := db.EnableForeignKeys(sqlDB); err != nil {
 		log.Fatal(err)
 	}
 
+	// Seed data goes into the default workspace
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
 	// Initialize repositories
-	issuerRepo := repository.NewSQLiteIssuerRepository(db)
-	receiverRepo := repository.NewSQLiteReceiverRepository(db)
-	billItemRepo := repository.NewSQLiteBillItemRepository(db)
+	repos := repository.NewRepositories(sqlDB)
+	issuerRepo := repos.Issuers
+	receiverRepo := repos.Receivers
+	billItemRepo := repos.BillItems
 
 	// Seed issuers
 	issuers := []*models.Issuer{
@@ -61,7 +65,7 @@ func main() {
 
 	log.Println("Seeding issuers...")
 	for _, issuer := range issuers {
-		if err := issuerRepo.Create(issuer); err != nil {
+		if err := issuerRepo.Create(ctx, issuer); err != nil {
 			log.Printf("Error creating issuer %s: %v\n", issuer.Name, err)
 		} else {
 			log.Printf("Created issuer: %s\n", issuer.Name)
@@ -101,7 +105,7 @@ func main() {
 
 	log.Println("Seeding receivers...")
 	for _, receiver := range receivers {
-		if err := receiverRepo.Create(receiver); err != nil {
+		if err := receiverRepo.Create(ctx, receiver); err != nil {
 			log.Printf("Error creating receiver %s: %v\n", receiver.Name, err)
 		} else {
 			log.Printf("Created receiver: %s\n", receiver.Name)
@@ -144,7 +148,7 @@ func main() {
 
 	log.Println("Seeding bill items...")
 	for _, item := range billItems {
-		if err := billItemRepo.Create(item); err != nil {
+		if err := billItemRepo.Create(ctx, item); err != nil {
 			log.Printf("Error creating bill item %s: %v\n", item.Name, err)
 		} else {
 			log.Printf("Created bill item: %s\n", item.Name)
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bills/db"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Creates a user that can sign in, or resets the password of an existing one,
+// and adds them to a workspace with a role. Instance admins may take and
+// download snapshots of the whole database
+func main() {
+	// Parse command line flags
+	username := flag.String("username", "", "Username to create or update")
+	password := flag.String("password", "", "Password to set, required for new users")
+	roleName := flag.String("role", "", "Role in the workspace (admin, accountant, viewer). New users default to admin when they are the first user, viewer otherwise")
+	instanceAdmin := flag.Bool("instance-admin", false, "Let the user take and download snapshots of the whole database, -instance-admin=false takes it away. New users default to it when they are the first user")
+	workspaceName := flag.String("workspace", "", "Workspace to add the user to, created when it does not exist. Defaults to the Default workspace")
+	dbURL := flag.String("db", os.Getenv("DATABASE_URL"), "SQLite database path or PostgreSQL URL, DATABASE_URL or bills.db by default")
+	flag.Parse()
+
+	if *username == "" {
+		flag.Usage()
+		log.Fatal("-username is required")
+	}
+
+	instanceAdminSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "instance-admin" {
+			instanceAdminSet = true
+		}
+	})
+
+	// Open database
+	sqlDB, err := db.Open(*dbURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	// Enable foreign keys
+	if err := db.EnableForeignKeys(sqlDB); err != nil {
+		log.Fatal(err)
+	}
+
+	// Make sure the users table exists
+	if err := db.MigrateDB(sqlDB, *dbURL); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repos := repository.NewRepositories(sqlDB)
+	userRepo := repos.Users
+	sessionRepo := repos.Sessions
+	workspaceRepo := repos.Workspaces
+
+	user, err := userRepo.GetByUsername(ctx, *username)
+	found := err == nil
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		log.Fatal(err)
+	}
+
+	var role models.Role
+	if *roleName != "" {
+		if role, err = models.ParseRole(*roleName); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if found {
+		if *password != "" {
+			if err := user.SetPassword(*password); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if instanceAdminSet {
+			user.InstanceAdmin = *instanceAdmin
+		}
+		if *password != "" || instanceAdminSet {
+			if err := userRepo.Update(ctx, user); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *password != "" {
+			// Sign the user out everywhere after a password reset
+			if err := sessionRepo.DeleteByUserID(ctx, user.ID); err != nil {
+				log.Fatal(err)
+			}
+		}
+		fmt.Printf("Updated user %s\n", user.Username)
+		if role != "" || *workspaceName != "" {
+			addToWorkspace(ctx, workspaceRepo, user, *workspaceName, role)
+		}
+		return
+	}
+
+	if *password == "" {
+		flag.Usage()
+		log.Fatal("-password is required for new users")
+	}
+
+	users, err := userRepo.GetAll(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	first := len(users) == 0
+	if role == "" {
+		role = models.RoleViewer
+		if first {
+			role = models.RoleAdmin
+		}
+	}
+
+	user, err = models.NewUser(*username, *password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	user.InstanceAdmin = first
+	if instanceAdminSet {
+		user.InstanceAdmin = *instanceAdmin
+	}
+	if err := user.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Created user %s\n", user.Username)
+	if user.InstanceAdmin {
+		fmt.Printf("%s is an instance admin\n", user.Username)
+	}
+
+	addToWorkspace(ctx, workspaceRepo, user, *workspaceName, role)
+}
+
+// addToWorkspace makes the user a member of the named workspace, or of the
+// Default one when name is empty, creating it first when needed. Members get
+// the given role, new members are viewers when it is empty
+func addToWorkspace(ctx context.Context, repo repository.WorkspaceRepository, user *models.User, name string, role models.Role) {
+	var workspace *models.Workspace
+	var err error
+	if name == "" {
+		workspace, err = repo.GetByID(ctx, models.DefaultWorkspaceID)
+	} else {
+		workspace, err = repo.GetByName(ctx, name)
+	}
+	if errors.Is(err, models.ErrNotFound) {
+		workspace = models.NewWorkspace(name)
+		if err := workspace.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		if err := repo.Create(ctx, workspace); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Created workspace %s\n", workspace.Name)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
+	member, err := repo.IsMember(ctx, workspace.ID, user.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch {
+	case member && role == "":
+		return
+	case member:
+		err = repo.SetRole(ctx, workspace.ID, user.ID, role)
+	case role == "":
+		role = models.RoleViewer
+		fallthrough
+	default:
+		err = repo.AddMember(ctx, workspace.ID, user.ID, role)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s is %s in workspace %s\n", user.Username, role, workspace.Name)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bills/db"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+)
+
+const usage = `Usage: verify [-db URL] [-fix]
+
+Recomputes the amounts of every line and the totals of every bill, and looks
+for lines without a bill, bills and lines that refer to rows missing from
+their workspace and currencies that are not supported. Trashed rows and every
+workspace are checked.
+
+With -fix the problems are repaired in one transaction, nothing is changed
+when one of them fails. Missing issuers, receivers and bill items are
+replaced by stand-ins that go to the trash. Currencies that are not only
+spelled differently from a supported one need a hand.
+
+Exits with status 1 when problems are left.
+
+Flags:
+`
+
+func main() {
+	// Parse command line flags
+	dbPath := flag.String("db", os.Getenv("DATABASE_URL"), "SQLite database path or PostgreSQL URL, DATABASE_URL or bills.db by default")
+	fix := flag.Bool("fix", false, "Repair the problems found")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Open database
+	sqlDB, err := db.Open(*dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	// Enable foreign keys
+	if err := db.EnableForeignKeys(sqlDB); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.CheckSchema(sqlDB); err != nil {
+		log.Fatalf("verify reads the schema of the latest migration: %v", err)
+	}
+
+	ctx := context.Background()
+	var problems []*models.IntegrityProblem
+	fixed := 0
+	err = repository.NewUnitOfWork(sqlDB).Do(ctx, func(tx *repository.Tx) error {
+		var err error
+		if problems, err = tx.Integrity.Check(ctx); err != nil {
+			return err
+		}
+		if !*fix {
+			return nil
+		}
+		for _, problem := range problems {
+			if !problem.Fixable {
+				continue
+			}
+			if err := tx.Integrity.Fix(ctx, problem); err != nil {
+				return fmt.Errorf("failed to fix %s: %w", problem.Detail, err)
+			}
+			fixed++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+		return
+	}
+	if err := report(problems, *fix); err != nil {
+		log.Fatal(err)
+	}
+
+	left := len(problems) - fixed
+	switch {
+	case *fix:
+		fmt.Printf("\nFixed %d problems, %d need a hand\n", fixed, left)
+	case fixable(problems) > 0:
+		fmt.Printf("\n%d problems, %d of them can be fixed with -fix\n", len(problems), fixable(problems))
+	default:
+		fmt.Printf("\n%d problems\n", len(problems))
+	}
+	if left > 0 {
+		os.Exit(1)
+	}
+}
+
+// report lists the problems, with whether they were fixed or can be
+func report(problems []*models.IntegrityProblem, fixed bool) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROBLEM\tWORKSPACE\tSTATE\tDETAIL")
+	for _, problem := range problems {
+		state := "needs a hand"
+		switch {
+		case problem.Fixable && fixed:
+			state = "fixed"
+		case problem.Fixable:
+			state = "fixable"
+		}
+		workspace := "-"
+		if problem.WorkspaceID != 0 {
+			workspace = fmt.Sprint(problem.WorkspaceID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", problem.Kind, workspace, state, problem.Detail)
+	}
+	return w.Flush()
+}
+
+// fixable counts the problems -fix repairs
+func fixable(problems []*models.IntegrityProblem) int {
+	n := 0
+	for _, problem := range problems {
+		if problem.Fixable {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrBackupUnsupported is returned when backing up a database other than
+// SQLite, PostgreSQL has pg_dump for that
+var ErrBackupUnsupported = errors.New("backups need SQLite, use pg_dump for PostgreSQL")
+
+// Backup writes a consistent snapshot of the SQLite database db to path with
+// the online backup API, so the server keeps running while it is taken. The
+// snapshot is written next to path and renamed once complete
+func Backup(ctx context.Context, db *sql.DB, path string) error {
+	if IsPostgres(db) {
+		return ErrBackupUnsupported
+	}
+
+	tmp := path + ".tmp"
+	if err := copyDatabase(ctx, db, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Restore replaces the SQLite database at dbPath with the backup at
+// backupPath. Backups taken before the latest migration are migrated after
+// the restore, backups of a newer build or of a failed migration are refused.
+// The server must not be running on dbPath
+func Restore(ctx context.Context, backupPath, dbPath string) error {
+	if _, err := os.Stat(backupPath); err != nil {
+		return err
+	}
+	backup, err := sql.Open("sqlite3", "file:"+backupPath+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	if err := checkBackup(ctx, backup); err != nil {
+		return fmt.Errorf("cannot restore %s: %w", backupPath, err)
+	}
+
+	target, err := Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+	if IsPostgres(target) {
+		return ErrBackupUnsupported
+	}
+
+	if err := copyInto(ctx, backup, target); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", backupPath, err)
+	}
+	if err := MigrateDB(target, dbPath); err != nil {
+		return err
+	}
+	return CheckSchema(target)
+}
+
+// checkBackup makes sure backup is an intact database this build can migrate
+func checkBackup(ctx context.Context, backup *sql.DB) error {
+	var check string
+	if err := backup.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&check); err != nil {
+		return err
+	}
+	if check != "ok" {
+		return fmt.Errorf("the backup is damaged: %s", check)
+	}
+
+	var version int64
+	var dirty bool
+	err := backup.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("the backup has no migration version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("the backup was taken while migration %d failed", version)
+	}
+
+	migrations, err := Migrations(backup)
+	if err != nil {
+		return err
+	}
+	latest := migrations[len(migrations)-1].Version
+	if uint(version) > latest {
+		return fmt.Errorf("the backup is at version %d, newer than version %d of this build", version, latest)
+	}
+	if !slices.ContainsFunc(migrations, func(m Migration) bool { return m.Version == uint(version) }) {
+		return fmt.Errorf("the backup is at version %d, which this build does not know", version)
+	}
+	return nil
+}
+
+// copyDatabase copies db into a new database file at path
+func copyDatabase(ctx context.Context, db *sql.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	dest, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	return copyInto(ctx, db, dest)
+}
+
+// copyInto overwrites dest with every page of src
+func copyInto(ctx context.Context, src, dest *sql.DB) error {
+	return withSQLiteConn(ctx, dest, func(destConn *sqlite3.SQLiteConn) error {
+		return withSQLiteConn(ctx, src, func(srcConn *sqlite3.SQLiteConn) error {
+			backup, err := destConn.Backup("main", srcConn, "main")
+			if err != nil {
+				return err
+			}
+			// All pages in one step, the snapshot is taken under a single
+			// read lock
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// withSQLiteConn runs fn on a connection of db
+func withSQLiteConn(ctx context.Context, db *sql.DB, fn func(conn *sqlite3.SQLiteConn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return ErrBackupUnsupported
+		}
+		return fn(sqliteConn)
+	})
+}
+
+// BackupFile is a snapshot in a backup directory
+type BackupFile struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// backupName matches the files Backups.Create writes, named after the time
+// they were taken so they sort from oldest to newest
+var backupName = regexp.MustCompile(`^bills-\d{8}-\d{6}\.\d{3}\.db$`)
+
+// Backups takes timestamped snapshots of a database into a directory and
+// keeps the newest of them
+type Backups struct {
+	db   *sql.DB
+	dir  string
+	keep int
+}
+
+// NewBackups returns the backups of db in dir. Creating one deletes all but
+// the keep newest, 0 keeps them all
+func NewBackups(db *sql.DB, dir string, keep int) *Backups {
+	return &Backups{db: db, dir: dir, keep: keep}
+}
+
+// Create takes a snapshot and rotates the older ones out
+func (b *Backups) Create(ctx context.Context) (BackupFile, error) {
+	if IsPostgres(b.db) {
+		return BackupFile{}, ErrBackupUnsupported
+	}
+	if err := os.MkdirAll(b.dir, 0o750); err != nil {
+		return BackupFile{}, err
+	}
+
+	now := time.Now().UTC()
+	name := "bills-" + now.Format("20060102-150405.000") + ".db"
+	path := filepath.Join(b.dir, name)
+	if err := Backup(ctx, b.db, path); err != nil {
+		return BackupFile{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return BackupFile{}, err
+	}
+
+	if err := b.rotate(); err != nil {
+		return BackupFile{}, fmt.Errorf("backed up to %s but failed to remove old backups: %w", path, err)
+	}
+	return BackupFile{Name: name, Size: info.Size(), CreatedAt: now}, nil
+}
+
+// List returns the snapshots, newest first
+func (b *Backups) List() ([]BackupFile, error) {
+	entries, err := os.ReadDir(b.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []BackupFile
+	for _, entry := range entries {
+		if entry.IsDir() || !backupName.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		createdAt, err := time.Parse("20060102-150405.000", strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "bills-"), ".db"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, BackupFile{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+	slices.Reverse(files)
+	return files, nil
+}
+
+// Path returns the path of the snapshot called name, which must be one
+// Create wrote
+func (b *Backups) Path(name string) (string, error) {
+	if !backupName.MatchString(name) {
+		return "", fmt.Errorf("%q is not a backup", name)
+	}
+	path := filepath.Join(b.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// rotate deletes all but the newest snapshots
+func (b *Backups) rotate() error {
+	if b.keep <= 0 {
+		return nil
+	}
+	files, err := b.List()
+	if err != nil {
+		return err
+	}
+	for len(files) > b.keep {
+		if err := os.Remove(filepath.Join(b.dir, files[len(files)-1].Name)); err != nil {
+			return err
+		}
+		files = files[:len(files)-1]
+	}
+	return nil
+}
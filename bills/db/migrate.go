@@ -1,33 +1,62 @@
 package db
 
 import (
+	"crypto/sha256"
 	"database/sql"
 	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-//go:embed migrations/*.sql
+// The PostgreSQL migrations mirror the SQLite ones version for version, so
+// both databases report the same schema version
+//
+//go:embed migrations/*.sql migrations/postgres/*.sql
 var migrationsFS embed.FS
 
-// MigrateDB applies all pending migrations to the database
-func MigrateDB(db *sql.DB, dbName string) error {
-	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+// newMigrate returns the migrations of the database db was opened with
+func newMigrate(db *sql.DB) (*migrate.Migrate, error) {
+	var driver database.Driver
+	var err error
+	name, dir := "sqlite3", "migrations"
+	if IsPostgres(db) {
+		name, dir = "postgres", "migrations/postgres"
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	} else {
+		driver, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create sqlite driver: %w", err)
+		return nil, fmt.Errorf("failed to create %s driver: %w", name, err)
 	}
 
-	d, err := iofs.New(migrationsFS, "migrations")
+	d, err := iofs.New(migrationsFS, dir)
 	if err != nil {
-		return fmt.Errorf("failed to create source driver: %w", err)
+		return nil, fmt.Errorf("failed to create source driver: %w", err)
 	}
 
-	m, err := migrate.NewWithInstance("iofs", d, "sqlite3", driver)
+	m, err := migrate.NewWithInstance("iofs", d, name, driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// MigrateDB applies all pending migrations to the database
+func MigrateDB(db *sql.DB, dbName string) error {
+	m, err := newMigrate(db)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
 
 	// Get current version before migration
@@ -38,7 +67,8 @@ func MigrateDB(db *sql.DB, dbName string) error {
 	fmt.Printf("Current migration version: %d, dirty: %v\n", version, dirty)
 
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to apply migrations: %w", err)
+		// The migrations applied before the one that failed keep their checksums
+		return errors.Join(fmt.Errorf("failed to apply migrations: %w", err), recordChecksums(db))
 	}
 
 	// Get version after migration
@@ -48,29 +78,260 @@ func MigrateDB(db *sql.DB, dbName string) error {
 	}
 	fmt.Printf("Migration version after Up(): %d, dirty: %v\n", version, dirty)
 
-	return nil
+	if err := recordChecksums(db); err != nil {
+		return err
+	}
+	if IsPostgres(db) {
+		return nil
+	}
+	return SyncSearchIndex(db)
 }
 
 // DropDB drops all tables in the database
 func DropDB(db *sql.DB, dbName string) error {
-	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	return move(db, func(m *migrate.Migrate) error {
+		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to drop database: %w", err)
+		}
+		return nil
+	})
+}
+
+// Migration is one migration of the set embedded for a database
+type Migration struct {
+	Version uint
+	Name    string
+	// Checksum is the SHA-256 of the up and down files, it tells whether two
+	// builds apply the same migration. The one of every applied migration is
+	// recorded, see AppliedChecksums
+	Checksum string
+}
+
+// migrationFile matches the files of a migration, e.g.
+// 000003_create_bills_table.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migrations returns the migrations embedded for the database db was opened
+// with, oldest first
+func Migrations(db *sql.DB) ([]Migration, error) {
+	dir := "migrations"
+	if IsPostgres(db) {
+		dir = "migrations/postgres"
+	}
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The entries are sorted by name, so the up file follows the down file
+	// of the same migration
+	var migrations []Migration
+	hash := sha256.New()
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+		content, err := migrationsFS.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		hash.Write(content)
+		if match[3] == "up" {
+			migrations = append(migrations, Migration{
+				Version:  uint(version),
+				Name:     match[2],
+				Checksum: hex.EncodeToString(hash.Sum(nil)),
+			})
+			hash.Reset()
+		}
+	}
+	return migrations, nil
+}
+
+// checksumTable holds the checksum of every migration applied to the
+// database, as it was when it was applied
+const checksumTable = "schema_migration_checksums"
+
+// createChecksumTable creates the checksum table of databases migrated before
+// it existed
+func createChecksumTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + checksumTable + ` (
+		version BIGINT PRIMARY KEY,
+		checksum TEXT NOT NULL
+	)`)
+	return err
+}
+
+// recordChecksums brings the checksum table in line with the version of the
+// database: migrations up to the version that have no checksum yet get the
+// one of this build, the ones rolled back lose theirs. A dirty version was
+// not applied in full and gets none
+func recordChecksums(db *sql.DB) error {
+	version, dirty, err := MigrationVersion(db)
+	if err != nil {
+		return err
+	}
+	migrations, err := Migrations(db)
+	if err != nil {
+		return err
+	}
+	if err := createChecksumTable(db); err != nil {
+		return fmt.Errorf("failed to create the checksum table: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Versions need not be consecutive, the migration before a dirty one is
+	// the last that was applied in full
+	applied := version
+	if dirty {
+		applied = 0
+		for _, migration := range migrations {
+			if migration.Version >= version {
+				break
+			}
+			applied = migration.Version
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM `+checksumTable+` WHERE version > $1`, applied); err != nil {
+		return fmt.Errorf("failed to clear checksums: %w", err)
+	}
+	for _, migration := range migrations {
+		if migration.Version > applied {
+			break
+		}
+		_, err := tx.Exec(`INSERT INTO `+checksumTable+` (version, checksum) VALUES ($1, $2)
+			ON CONFLICT (version) DO NOTHING`, migration.Version, migration.Checksum)
+		if err != nil {
+			return fmt.Errorf("failed to record the checksum of migration %d: %w", migration.Version, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// AppliedChecksums returns the checksums the applied migrations had when they
+// were applied, by version. Migrations of this build with another checksum
+// were changed since
+func AppliedChecksums(db *sql.DB) (map[uint]string, error) {
+	if err := createChecksumTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create the checksum table: %w", err)
+	}
+	rows, err := db.Query(`SELECT version, checksum FROM ` + checksumTable)
 	if err != nil {
-		return fmt.Errorf("failed to create sqlite driver: %w", err)
+		return nil, err
 	}
+	defer rows.Close()
 
-	d, err := iofs.New(migrationsFS, "migrations")
+	checksums := make(map[uint]string)
+	for rows.Next() {
+		var version int64
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		checksums[uint(version)] = checksum
+	}
+	return checksums, rows.Err()
+}
+
+// MigrationVersion returns the version the database is at, 0 before the
+// first migration. A dirty version failed halfway and has to be fixed by hand
+// and then forced
+func MigrationVersion(db *sql.DB) (version uint, dirty bool, err error) {
+	m, err := newMigrate(db)
 	if err != nil {
-		return fmt.Errorf("failed to create source driver: %w", err)
+		return 0, false, err
+	}
+	version, dirty, err = m.Version()
+	if err == migrate.ErrNilVersion {
+		return 0, false, nil
 	}
+	return version, dirty, err
+}
+
+// MigrateSteps applies the next n migrations, or rolls back the last -n ones
+// when n is negative
+func MigrateSteps(db *sql.DB, n int) error {
+	return move(db, func(m *migrate.Migrate) error {
+		if err := m.Steps(n); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to migrate %d steps: %w", n, err)
+		}
+		return nil
+	})
+}
 
-	m, err := migrate.NewWithInstance("iofs", d, "sqlite3", driver)
+// MigrateTo migrates the database up or down to version
+func MigrateTo(db *sql.DB, version uint) error {
+	return move(db, func(m *migrate.Migrate) error {
+		if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+		}
+		return nil
+	})
+}
+
+// ForceVersion records the database at version and clears the dirty flag
+// without running any migration, once a failed one was fixed by hand. -1
+// records the database as never migrated
+func ForceVersion(db *sql.DB, version int) error {
+	m, err := newMigrate(db)
+	if err != nil {
+		return err
+	}
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return recordChecksums(db)
+}
+
+// move runs migrations in either direction. The triggers of the search index
+// refer to columns older versions do not have, so on SQLite the index is
+// dropped first and only built again at the latest version
+func move(db *sql.DB, run func(m *migrate.Migrate) error) error {
+	m, err := newMigrate(db)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
+	}
+	if IsPostgres(db) {
+		if err := run(m); err != nil {
+			return err
+		}
+		return recordChecksums(db)
 	}
 
-	if err := m.Down(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to drop database: %w", err)
+	// A dirty database is left alone until it was fixed and forced
+	if version, dirty, err := m.Version(); err == nil && dirty {
+		return migrate.ErrDirty{Version: int(version)}
+	}
+	if err := DropSearchIndex(db); err != nil {
+		return err
+	}
+	if err := run(m); err != nil {
+		return err
+	}
+	if err := recordChecksums(db); err != nil {
+		return err
 	}
 
-	return nil
+	migrations, err := Migrations(db)
+	if err != nil {
+		return err
+	}
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return err
+	}
+	if dirty || len(migrations) == 0 || version != migrations[len(migrations)-1].Version {
+		return nil
+	}
+	return SyncSearchIndex(db)
 }
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer';
+
+-- Accounts created before roles existed could do everything, keep it that way
+UPDATE users SET role = 'admin';
@@ -0,0 +1,26 @@
+ALTER TABLE sessions DROP COLUMN workspace_id;
+
+DROP INDEX IF EXISTS idx_bill_item_assignments_workspace_id;
+DROP INDEX IF EXISTS idx_bill_items_workspace_id;
+DROP INDEX IF EXISTS idx_bills_workspace_id;
+DROP INDEX IF EXISTS idx_issuers_workspace_id;
+DROP INDEX IF EXISTS idx_receivers_workspace_id;
+
+ALTER TABLE bill_item_assignments DROP COLUMN workspace_id;
+ALTER TABLE bill_items DROP COLUMN workspace_id;
+ALTER TABLE bills DROP COLUMN workspace_id;
+ALTER TABLE issuers DROP COLUMN workspace_id;
+ALTER TABLE receivers DROP COLUMN workspace_id;
+
+-- Users get back the most privileged role of their memberships
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer';
+
+UPDATE users SET role = 'accountant'
+WHERE id IN (SELECT user_id FROM workspace_members WHERE role = 'accountant');
+
+UPDATE users SET role = 'admin'
+WHERE id IN (SELECT user_id FROM workspace_members WHERE role = 'admin');
+
+DROP INDEX IF EXISTS idx_workspace_members_user_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
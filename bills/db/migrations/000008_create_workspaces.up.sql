@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- A role is what a user may do in one workspace, so it lives on the
+-- membership
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role TEXT NOT NULL DEFAULT 'viewer',
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (workspace_id, user_id),
+    FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- Everything that existed before workspaces belongs to the default one, and
+-- so does every existing user with the role they had everywhere before
+INSERT INTO workspaces (id, name, created_at, updated_at)
+VALUES (1, 'Default', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+SELECT 1, id, role, CURRENT_TIMESTAMP FROM users;
+
+ALTER TABLE users DROP COLUMN role;
+
+-- Every table holding bills data belongs to a workspace. Exchange rates are
+-- public market data and stay shared. SQLite refuses ADD COLUMN with a
+-- REFERENCES clause and a non-NULL default while foreign keys are on, so the
+-- workspace is only indexed here
+ALTER TABLE receivers ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE issuers ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bills ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bill_items ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bill_item_assignments ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_receivers_workspace_id ON receivers(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_issuers_workspace_id ON issuers(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_bills_workspace_id ON bills(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_bill_items_workspace_id ON bill_items(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_bill_item_assignments_workspace_id ON bill_item_assignments(workspace_id);
+
+-- The workspace picked in the layout is remembered per session
+ALTER TABLE sessions ADD COLUMN workspace_id INTEGER;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS audit_events_no_delete;
+DROP TRIGGER IF EXISTS audit_events_no_update;
+DROP INDEX IF EXISTS idx_audit_events_actor_id;
+DROP INDEX IF EXISTS idx_audit_events_entity;
+DROP INDEX IF EXISTS idx_audit_events_workspace_id;
+DROP TABLE IF EXISTS audit_events;
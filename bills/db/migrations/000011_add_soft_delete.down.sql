@@ -0,0 +1,16 @@
+-- Trashed rows would reappear as live data, purge them first
+DELETE FROM bill_item_assignments WHERE bill_id IN (SELECT id FROM bills WHERE deleted_at IS NOT NULL);
+DELETE FROM bills WHERE deleted_at IS NOT NULL;
+DELETE FROM bill_items WHERE deleted_at IS NOT NULL;
+DELETE FROM issuers WHERE deleted_at IS NOT NULL;
+DELETE FROM receivers WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_receivers_deleted_at;
+DROP INDEX IF EXISTS idx_issuers_deleted_at;
+DROP INDEX IF EXISTS idx_bill_items_deleted_at;
+DROP INDEX IF EXISTS idx_bills_deleted_at;
+
+ALTER TABLE receivers DROP COLUMN deleted_at;
+ALTER TABLE issuers DROP COLUMN deleted_at;
+ALTER TABLE bill_items DROP COLUMN deleted_at;
+ALTER TABLE bills DROP COLUMN deleted_at;
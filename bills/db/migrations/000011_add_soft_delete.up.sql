@@ -0,0 +1,12 @@
+-- Deleted bills, parties and catalog items go to the trash first. Rows with a
+-- deleted_at are hidden from the app until restored, or purged for good from
+-- the trash page or by the retention job
+ALTER TABLE bills ADD COLUMN deleted_at DATETIME;
+ALTER TABLE bill_items ADD COLUMN deleted_at DATETIME;
+ALTER TABLE issuers ADD COLUMN deleted_at DATETIME;
+ALTER TABLE receivers ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_bills_deleted_at ON bills(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_bill_items_deleted_at ON bill_items(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_issuers_deleted_at ON issuers(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_receivers_deleted_at ON receivers(deleted_at);
@@ -0,0 +1,9 @@
+-- Bills record the day they were issued, their due date may not come before
+-- it. Existing bills were issued when they were created, or on their due date
+-- when they were recorded after it
+ALTER TABLE bills ADD COLUMN issue_date DATETIME;
+
+UPDATE bills SET issue_date = CASE
+    WHEN due_date < created_at THEN due_date
+    ELSE created_at
+END;
@@ -0,0 +1,10 @@
+-- Bills and bill items are listed a page at a time in the order of a sortable
+-- column, with the id breaking ties. These indexes let a page start right
+-- after the last row of the one before instead of scanning the table
+CREATE INDEX IF NOT EXISTS idx_bills_due_date ON bills(workspace_id, due_date, id);
+CREATE INDEX IF NOT EXISTS idx_bills_issue_date ON bills(workspace_id, issue_date, id);
+CREATE INDEX IF NOT EXISTS idx_bills_eur_total ON bills(workspace_id, eur_total, id);
+CREATE INDEX IF NOT EXISTS idx_bills_issuer_id ON bills(issuer_id);
+CREATE INDEX IF NOT EXISTS idx_bills_receiver_id ON bills(receiver_id);
+CREATE INDEX IF NOT EXISTS idx_bill_items_name ON bill_items(workspace_id, name, id);
+CREATE INDEX IF NOT EXISTS idx_bill_items_price ON bill_items(workspace_id, price, id);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS bills (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    due_date TIMESTAMPTZ NOT NULL,
+    paid BOOLEAN DEFAULT FALSE,
+    issuer_id BIGINT NOT NULL REFERENCES issuers(id),
+    receiver_id BIGINT NOT NULL REFERENCES receivers(id),
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bill_item_assignments;
+DROP TABLE IF EXISTS bill_items;
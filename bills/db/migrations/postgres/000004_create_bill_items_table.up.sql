@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS bill_items (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS bill_item_assignments (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    bill_id BIGINT NOT NULL REFERENCES bills(id) ON DELETE CASCADE,
+    item_id BIGINT NOT NULL REFERENCES bill_items(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,22 @@
+-- Create exchange rates table
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    currency_from TEXT NOT NULL,
+    currency_to TEXT NOT NULL,
+    rate DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Add currency to bill_items
+ALTER TABLE bill_items ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+
+-- Add currency info to bill_item_assignments
+ALTER TABLE bill_item_assignments ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+ALTER TABLE bill_item_assignments ADD COLUMN exchange_rate DOUBLE PRECISION NOT NULL DEFAULT 1.0;
+ALTER TABLE bill_item_assignments ADD COLUMN original_amount DOUBLE PRECISION NOT NULL DEFAULT 0.0;
+ALTER TABLE bill_item_assignments ADD COLUMN eur_amount DOUBLE PRECISION NOT NULL DEFAULT 0.0;
+
+-- Add currency info to bills
+ALTER TABLE bills ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+ALTER TABLE bills ADD COLUMN original_total DOUBLE PRECISION NOT NULL DEFAULT 0.0;
+ALTER TABLE bills ADD COLUMN eur_total DOUBLE PRECISION NOT NULL DEFAULT 0.0;
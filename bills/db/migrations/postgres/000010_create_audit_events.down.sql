@@ -0,0 +1,8 @@
+DROP FUNCTION IF EXISTS json_extract(TEXT, TEXT);
+DROP TRIGGER IF EXISTS audit_events_no_delete ON audit_events;
+DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP INDEX IF EXISTS idx_audit_events_actor_id;
+DROP INDEX IF EXISTS idx_audit_events_entity;
+DROP INDEX IF EXISTS idx_audit_events_workspace_id;
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,41 @@
+-- Every create, update and delete of workspace data, written in the same
+-- transaction as the change. before_data and after_data hold the row as JSON,
+-- before_data is NULL for creates and after_data once the row is gone.
+-- actor_id is NULL for changes made outside a signed in request, such as the
+-- seed command
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    workspace_id BIGINT NOT NULL,
+    actor_id BIGINT,
+    entity TEXT NOT NULL,
+    entity_id BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    before_data TEXT,
+    after_data TEXT,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_workspace_id ON audit_events(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_entity ON audit_events(entity, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events(actor_id);
+
+-- The log is append-only
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update
+BEFORE UPDATE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_delete
+BEFORE DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+-- The snapshots are read with json_extract, as SQLite has it, for the
+-- history of a bill
+CREATE OR REPLACE FUNCTION json_extract(data TEXT, path TEXT) RETURNS TEXT AS $$
+    SELECT data::jsonb #>> string_to_array(substring(path FROM 3), '.')
+$$ LANGUAGE SQL IMMUTABLE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_bill_items_price;
+DROP INDEX IF EXISTS idx_bill_items_name;
+DROP INDEX IF EXISTS idx_bills_receiver_id;
+DROP INDEX IF EXISTS idx_bills_issuer_id;
+DROP INDEX IF EXISTS idx_bills_eur_total;
+DROP INDEX IF EXISTS idx_bills_issue_date;
+DROP INDEX IF EXISTS idx_bills_due_date;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_bill_item_assignments_bill_id;
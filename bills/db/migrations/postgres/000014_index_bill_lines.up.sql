@@ -0,0 +1,2 @@
+-- The lines of bills are loaded by the bills they belong to
+CREATE INDEX IF NOT EXISTS idx_bill_item_assignments_bill_id ON bill_item_assignments(bill_id);
@@ -0,0 +1,6 @@
+-- Instance admins look after the whole server, such as its database snapshots
+ALTER TABLE users ADD COLUMN instance_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Admins of the Default workspace could take snapshots before, keep it that way
+UPDATE users SET instance_admin = TRUE
+WHERE id IN (SELECT user_id FROM workspace_members WHERE workspace_id = 1 AND role = 'admin');
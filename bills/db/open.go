@@ -0,0 +1,58 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DefaultPath is the SQLite database used when no database is configured
+const DefaultPath = "bills.db"
+
+// PostgresOptIn is the environment variable that has to be 1 to open
+// PostgreSQL. Its support is experimental: the test suite is not run against
+// it by default and search only matches substrings, without ranking
+const PostgresOptIn = "BILLS_EXPERIMENTAL_POSTGRES"
+
+// ErrPostgresExperimental is returned when opening PostgreSQL without
+// PostgresOptIn
+var ErrPostgresExperimental = errors.New("PostgreSQL support is experimental, set " + PostgresOptIn + "=1 to use it anyway")
+
+// Open opens the database named by url, the DATABASE_URL setting.
+// postgres:// and postgresql:// URLs open PostgreSQL, once PostgresOptIn is
+// set, anything else is the path of a SQLite database, optionally prefixed
+// with sqlite://. An empty url opens DefaultPath
+func Open(url string) (*sql.DB, error) {
+	if strings.HasPrefix(url, "postgres://") || strings.HasPrefix(url, "postgresql://") {
+		if os.Getenv(PostgresOptIn) != "1" {
+			return nil, ErrPostgresExperimental
+		}
+		return sql.Open("postgres", url)
+	}
+
+	path := strings.TrimPrefix(url, "sqlite://")
+	if path == "" {
+		path = DefaultPath
+	}
+	return sql.Open("sqlite3", path)
+}
+
+// IsPostgres reports whether db was opened on PostgreSQL
+func IsPostgres(db *sql.DB) bool {
+	_, ok := db.Driver().(*pq.Driver)
+	return ok
+}
+
+// EnableForeignKeys makes SQLite enforce foreign keys, which PostgreSQL
+// always does
+func EnableForeignKeys(db *sql.DB) error {
+	if IsPostgres(db) {
+		return nil
+	}
+	_, err := db.Exec("PRAGMA foreign_keys = ON")
+	return err
+}
@@ -0,0 +1,297 @@
+package db
+
+import (
+	"bills/internal/currency"
+	"bills/internal/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// searchIndex is the table of the search index, which SyncSearchIndex creates
+// outside the migrations when SQLite has FTS5
+const searchIndex = "search_index"
+
+// column is a column of a table with its declared type
+type column struct {
+	name string
+	typ  string
+}
+
+// schemaTable is a table the repositories read or write. Its columns are the
+// fields of model tagged with db, followed by the ones the repositories keep
+// next to them
+type schemaTable struct {
+	name  string
+	model interface{}
+	extra []column
+}
+
+var (
+	// tenantColumns are kept on the rows of every workspace, see tenant
+	tenantColumns = []column{{"workspace_id", "INTEGER"}}
+
+	// trashColumns are kept on the rows that go to the trash when deleted
+	trashColumns = []column{{"deleted_at", "DATETIME"}}
+)
+
+// schema is what the models expect of the database once the migrations ran,
+// on SQLite and PostgreSQL alike
+var schema = []schemaTable{
+	{"receivers", models.Receiver{}, slices.Concat(tenantColumns, trashColumns)},
+	{"issuers", models.Issuer{}, slices.Concat(tenantColumns, trashColumns)},
+	{"bills", models.Bill{}, slices.Concat(tenantColumns, trashColumns)},
+	{"bill_items", models.BillItem{}, slices.Concat(tenantColumns, trashColumns)},
+	{"bill_item_assignments", models.BillItemAssignment{}, tenantColumns},
+	{"exchange_rates", currency.ExchangeRate{}, nil},
+	{"users", models.User{}, nil},
+	{"sessions", models.Session{}, nil},
+	{"workspaces", models.Workspace{}, nil},
+	{"workspace_members", models.Membership{}, nil},
+	{"api_tokens", models.APIToken{}, nil},
+	{"audit_events", models.AuditEvent{}, nil},
+}
+
+// searchSchema is the search index, which SyncSearchIndex creates outside the
+// migrations when SQLite has FTS5. FTS5 columns have no type
+var searchSchema = schemaTable{searchIndex, nil, []column{
+	{"entity", ""}, {"entity_id", ""}, {"workspace_id", ""}, {"title", ""}, {"detail", ""},
+}}
+
+// columns returns the columns of the table
+func (t schemaTable) columns() []column {
+	var columns []column
+	if t.model != nil {
+		model := reflect.TypeOf(t.model)
+		for i := 0; i < model.NumField(); i++ {
+			field := model.Field(i)
+			if name, ok := field.Tag.Lookup("db"); ok {
+				columns = append(columns, column{name, columnType(field.Type)})
+			}
+		}
+	}
+	return append(columns, t.extra...)
+}
+
+// columnType returns the SQLite type of the column a field of type typ is
+// stored in, pointers are NULL when unset
+func columnType(typ reflect.Type) string {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		return "DATETIME"
+	case typ == reflect.TypeOf(json.RawMessage{}):
+		return "TEXT"
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int64:
+		return "INTEGER"
+	case reflect.Float64:
+		return "REAL"
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.String:
+		return "TEXT"
+	}
+	return typ.String()
+}
+
+// expectedSchema returns the tables and columns the models expect, search
+// index included when it is there to compare
+func expectedSchema(search bool) map[string][]column {
+	tables := schema
+	if search {
+		tables = append(slices.Clip(tables), searchSchema)
+	}
+	expected := make(map[string][]column, len(tables))
+	for _, table := range tables {
+		expected[table.name] = table.columns()
+	}
+	return expected
+}
+
+// SchemaError is returned by CheckSchema when the database drifted from what
+// the models expect. Diff lists what is missing from the database with a -,
+// what it has on top with a + and columns of another type with a ~
+type SchemaError struct {
+	Diff string
+}
+
+func (e *SchemaError) Error() string {
+	return "database schema does not match the models, run the migrations or fix the database:\n" + e.Diff
+}
+
+// CheckSchema compares the tables and columns of db and their types with the
+// ones the models expect, see schema, and returns a SchemaError when they
+// differ, so migrations that do not match the models are caught once they ran.
+// Columns are compared by the kind of value their type holds, PostgreSQL has
+// types of its own. Tables of the migration tool, of SQLite itself and the
+// internal tables of the search index are not compared
+func CheckSchema(db *sql.DB) error {
+	var expected, live map[string][]column
+	var err error
+	if IsPostgres(db) {
+		// The search index only exists on SQLite
+		expected = expectedSchema(false)
+		live, err = postgresColumns(db)
+	} else {
+		var fts5 bool
+		if fts5, err = HasFTS5(db); err != nil {
+			return fmt.Errorf("failed to check for FTS5: %w", err)
+		}
+		expected = expectedSchema(fts5)
+		live, err = sqliteColumns(db, fts5)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the database schema: %w", err)
+	}
+
+	var diff strings.Builder
+	for _, table := range slices.Sorted(maps.Keys(expected)) {
+		columns, ok := live[table]
+		if !ok {
+			fmt.Fprintf(&diff, "- table %s\n", table)
+			continue
+		}
+		delete(live, table)
+
+		var lines []string
+		for _, want := range expected[table] {
+			i := slices.IndexFunc(columns, func(c column) bool { return c.name == want.name })
+			switch {
+			case i < 0:
+				lines = append(lines, "    - "+want.name)
+			case typeKind(columns[i].typ) != typeKind(want.typ):
+				lines = append(lines, fmt.Sprintf("    ~ %s %s, expected %s", want.name, columns[i].typ, want.typ))
+			}
+		}
+		for _, got := range columns {
+			if !slices.ContainsFunc(expected[table], func(c column) bool { return c.name == got.name }) {
+				lines = append(lines, "    + "+got.name)
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&diff, "  table %s\n%s\n", table, strings.Join(lines, "\n"))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(live)) {
+		fmt.Fprintf(&diff, "+ table %s\n", name)
+	}
+
+	if diff.Len() > 0 {
+		return &SchemaError{Diff: diff.String()}
+	}
+	return nil
+}
+
+// typeKinds maps the column types of both databases to the kind of value
+// they hold
+var typeKinds = map[string]string{
+	"INTEGER":                  "integer",
+	"BIGINT":                   "integer",
+	"REAL":                     "real",
+	"DOUBLE PRECISION":         "real",
+	"TEXT":                     "text",
+	"BOOLEAN":                  "boolean",
+	"DATETIME":                 "timestamp",
+	"TIMESTAMP WITH TIME ZONE": "timestamp",
+}
+
+// typeKind returns the kind of value of a column type, types it does not know
+// are only equal to themselves
+func typeKind(typ string) string {
+	typ = strings.ToUpper(typ)
+	if kind, ok := typeKinds[typ]; ok {
+		return kind
+	}
+	return typ
+}
+
+// ignoredTable reports whether CheckSchema leaves the table out
+func ignoredTable(name string) bool {
+	return name == "schema_migrations" || name == checksumTable ||
+		strings.HasPrefix(name, "sqlite_") ||
+		strings.HasPrefix(name, searchIndex+"_")
+}
+
+// sqliteColumns returns the columns of every table in the SQLite database.
+// The search index can only be read with FTS5, without it the index a build
+// with FTS5 left behind is not compared
+func sqliteColumns(db *sql.DB, fts5 bool) (map[string][]column, error) {
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table'`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if ignoredTable(name) || (name == searchIndex && !fts5) {
+			continue
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string][]column, len(tables))
+	for _, table := range tables {
+		rows, err := db.Query(`SELECT name, type FROM pragma_table_info(?) ORDER BY cid`, table)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var c column
+			if err := rows.Scan(&c.name, &c.typ); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			columns[table] = append(columns[table], c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return columns, nil
+}
+
+// postgresColumns returns the columns of every table in the schema of the
+// search path
+func postgresColumns(db *sql.DB) (map[string][]column, error) {
+	rows, err := db.Query(`
+		SELECT c.table_name, c.column_name, UPPER(c.data_type)
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name, c.ordinal_position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]column)
+	for rows.Next() {
+		var table string
+		var c column
+		if err := rows.Scan(&table, &c.name, &c.typ); err != nil {
+			return nil, err
+		}
+		if !ignoredTable(table) {
+			columns[table] = append(columns[table], c)
+		}
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// The search index is an FTS5 table over bills, parties and catalog items.
+// FTS5 is only compiled into SQLite with the sqlite_fts5 build tag, which is
+// why the index is kept out of the migrations: builds without it skip the
+// index and search falls back to LIKE. The rowid of an entry encodes its
+// entity and id so triggers update it without scanning the index
+const searchTable = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		entity UNINDEXED,
+		entity_id UNINDEXED,
+		workspace_id UNINDEXED,
+		title,
+		detail,
+		tokenize = 'unicode61 remove_diacritics 2',
+		prefix = '2 3'
+	)`
+
+// searchEntries selects the index entries of each entity. Trashed rows are
+// left out
+var searchEntries = map[string]string{
+	"bills": `
+		SELECT b.id * 4, 'bills', b.id, b.workspace_id, '#' || b.id,
+			COALESCE(i.name, '') || ' → ' || COALESCE(r.name, '') || ', ' || printf('%.2f', b.original_total) || ' ' || b.currency
+		FROM bills b
+		LEFT JOIN issuers i ON i.id = b.issuer_id
+		LEFT JOIN receivers r ON r.id = b.receiver_id
+		WHERE b.deleted_at IS NULL`,
+	"issuers":    partyEntries("issuers"),
+	"receivers":  partyEntries("receivers"),
+	"bill_items": `SELECT id * 4 + 3, 'bill_items', id, workspace_id, name, currency FROM bill_items WHERE deleted_at IS NULL`,
+}
+
+// searchKinds numbers the entities for the rowids of their entries
+var searchKinds = map[string]int{"bills": 0, "issuers": 1, "receivers": 2, "bill_items": 3}
+
+func partyEntries(table string) string {
+	return fmt.Sprintf(`
+		SELECT id * 4 + %d, '%s', id, workspace_id, name,
+			vat_number || ', ' || street || ', ' || zip_code || ' ' || city || ', ' || state || ', ' || country
+		FROM %s WHERE deleted_at IS NULL`, searchKinds[table], table, table)
+}
+
+// searchTriggers returns the triggers that keep the entries of an entity in
+// sync with its table. Renaming a party also refreshes the entries of its
+// bills
+func searchTriggers(entity string) string {
+	entries := "INSERT INTO search_index (rowid, entity, entity_id, workspace_id, title, detail) " + searchEntries[entity]
+	var b strings.Builder
+	fmt.Fprintf(&b, `
+		CREATE TRIGGER search_%[1]s_insert AFTER INSERT ON %[1]s BEGIN
+			%[3]s AND %[4]s.id = NEW.id;
+		END;
+		CREATE TRIGGER search_%[1]s_update AFTER UPDATE ON %[1]s BEGIN
+			DELETE FROM search_index WHERE rowid = OLD.id * 4 + %[2]d;
+			%[3]s AND %[4]s.id = NEW.id;
+		END;
+		CREATE TRIGGER search_%[1]s_delete AFTER DELETE ON %[1]s BEGIN
+			DELETE FROM search_index WHERE rowid = OLD.id * 4 + %[2]d;
+		END;`, entity, searchKinds[entity], entries, alias(entity))
+	if entity == "issuers" || entity == "receivers" {
+		bills := "INSERT INTO search_index (rowid, entity, entity_id, workspace_id, title, detail) " + searchEntries["bills"]
+		column := strings.TrimSuffix(entity, "s") + "_id"
+		fmt.Fprintf(&b, `
+		CREATE TRIGGER search_%[1]s_bills AFTER UPDATE OF name ON %[1]s BEGIN
+			DELETE FROM search_index WHERE rowid IN (SELECT id * 4 FROM bills WHERE %[2]s = NEW.id);
+			%[3]s AND b.%[2]s = NEW.id;
+		END;`, entity, column, bills)
+	}
+	return b.String()
+}
+
+// searchTriggerCount returns how many triggers searchTriggers creates
+func searchTriggerCount() int {
+	count := 0
+	for entity := range searchEntries {
+		count += strings.Count(searchTriggers(entity), "CREATE TRIGGER")
+	}
+	return count
+}
+
+// alias returns how the table of entity is named in its searchEntries query
+func alias(entity string) string {
+	if entity == "bills" {
+		return "b"
+	}
+	return entity
+}
+
+// HasFTS5 reports whether the SQLite library was built with FTS5
+func HasFTS5(db *sql.DB) (bool, error) {
+	if IsPostgres(db) {
+		return false, nil
+	}
+	var used bool
+	err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used)
+	return used, err
+}
+
+// SyncSearchIndex creates and fills the search index along with its triggers
+// when SQLite has FTS5. Without FTS5 the triggers are dropped so the tables
+// stay writable, the index is then rebuilt by the next build that has it
+func SyncSearchIndex(db *sql.DB) error {
+	fts5, err := HasFTS5(db)
+	if err != nil {
+		return fmt.Errorf("failed to check for FTS5: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	triggers, err := searchTriggerNames(tx)
+	if err != nil {
+		return err
+	}
+
+	if !fts5 {
+		for _, name := range triggers {
+			if _, err := tx.Exec("DROP TRIGGER " + name); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	}
+
+	// The triggers only exist while the index is in sync
+	if len(triggers) == searchTriggerCount() {
+		return nil
+	}
+	for _, name := range triggers {
+		if _, err := tx.Exec("DROP TRIGGER " + name); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(searchTable); err != nil {
+		return fmt.Errorf("failed to create the search index: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM search_index"); err != nil {
+		return err
+	}
+	for entity, entries := range searchEntries {
+		if _, err := tx.Exec("INSERT INTO search_index (rowid, entity, entity_id, workspace_id, title, detail) " + entries); err != nil {
+			return fmt.Errorf("failed to index %s: %w", entity, err)
+		}
+		if _, err := tx.Exec(searchTriggers(entity)); err != nil {
+			return fmt.Errorf("failed to create the search triggers of %s: %w", entity, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DropSearchIndex drops the search index and its triggers, SyncSearchIndex
+// builds them again
+func DropSearchIndex(db *sql.DB) error {
+	fts5, err := HasFTS5(db)
+	if err != nil {
+		return fmt.Errorf("failed to check for FTS5: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	triggers, err := searchTriggerNames(tx)
+	if err != nil {
+		return err
+	}
+	for _, name := range triggers {
+		if _, err := tx.Exec("DROP TRIGGER " + name); err != nil {
+			return err
+		}
+	}
+
+	// Without FTS5 the index can't be dropped, it is left for a build with it
+	if fts5 {
+		if _, err := tx.Exec("DROP TABLE IF EXISTS search_index"); err != nil {
+			return fmt.Errorf("failed to drop the search index: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// searchTriggerNames returns the names of the triggers of the search index
+func searchTriggerNames(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query(`SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'search\_%' ESCAPE '\'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BillHandler serves the bill endpoints of the JSON API
+type BillHandler struct {
+	repo         repository.BillRepository
+	receiverRepo repository.ReceiverRepository
+	issuerRepo   repository.IssuerRepository
+	billItemRepo repository.BillItemRepository
+}
+
+// NewBillHandler creates a new BillHandler instance
+func NewBillHandler(
+	repo repository.BillRepository,
+	receiverRepo repository.ReceiverRepository,
+	issuerRepo repository.IssuerRepository,
+	billItemRepo repository.BillItemRepository,
+) *BillHandler {
+	return &BillHandler{
+		repo:         repo,
+		receiverRepo: receiverRepo,
+		issuerRepo:   issuerRepo,
+		billItemRepo: billItemRepo,
+	}
+}
+
+// List returns all bills with their items
+func (h *BillHandler) List(c echo.Context) error {
+	bills, err := h.repo.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	if bills == nil {
+		bills = []*models.Bill{}
+	}
+
+	return c.JSON(http.StatusOK, bills)
+}
+
+// Get returns a single bill with its items, issuer and receiver
+func (h *BillHandler) Get(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	bill, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if bill.Issuer, err = h.issuerRepo.GetByID(c.Request().Context(), bill.IssuerID); err != nil {
+		return err
+	}
+	if bill.Receiver, err = h.receiverRepo.GetByID(c.Request().Context(), bill.ReceiverID); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, bill)
+}
+
+// Create stores a new bill together with its items
+func (h *BillHandler) Create(c echo.Context) error {
+	var in models.Bill
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	bill := models.NewBill(in.DueDate, in.IssuerID, in.ReceiverID)
+	if !in.IssueDate.IsZero() {
+		bill.IssueDate = in.IssueDate
+	}
+	bill.Paid = in.Paid
+	for i, item := range in.Items {
+		assignment, err := newAssignment(0, item)
+		if err != nil {
+			return fmt.Errorf("items[%d]: %w", i, err)
+		}
+		bill.Items = append(bill.Items, assignment)
+	}
+
+	bill.ResolveCurrency()
+	bill.CalculateTotals()
+
+	if err := h.validate(c.Request().Context(), bill); err != nil {
+		return err
+	}
+
+	if err := h.repo.Create(c.Request().Context(), bill); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, bill)
+}
+
+// BillUpdate is the body of a bill update. Paid is a pointer so that a body
+// without it keeps the stored status
+type BillUpdate struct {
+	IssueDate  time.Time `json:"issue_date"`
+	DueDate    time.Time `json:"due_date"`
+	IssuerID   int64     `json:"issuer_id"`
+	ReceiverID int64     `json:"receiver_id"`
+	Paid       *bool     `json:"paid,omitempty"`
+}
+
+// Update changes the dates, parties and paid status of a bill. The issue date
+// and the paid status are kept when left out. Items are managed through the
+// assignment endpoints
+func (h *BillHandler) Update(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	bill, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	var in BillUpdate
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	if !in.IssueDate.IsZero() {
+		bill.IssueDate = in.IssueDate
+	}
+	bill.DueDate = in.DueDate
+	bill.IssuerID = in.IssuerID
+	bill.ReceiverID = in.ReceiverID
+	if in.Paid != nil {
+		bill.Paid = *in.Paid
+	}
+
+	if err := h.validate(c.Request().Context(), bill); err != nil {
+		return err
+	}
+
+	if err := h.repo.Update(c.Request().Context(), bill); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, bill)
+}
+
+// Delete moves a bill and its items to the trash
+func (h *BillHandler) Delete(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// validate checks the bill itself and that everything it references exists
+func (h *BillHandler) validate(ctx context.Context, bill *models.Bill) error {
+	if err := bill.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := h.issuerRepo.GetByID(ctx, bill.IssuerID); err != nil {
+		return reference(err, "issuer_id", bill.IssuerID)
+	}
+
+	if _, err := h.receiverRepo.GetByID(ctx, bill.ReceiverID); err != nil {
+		return reference(err, "receiver_id", bill.ReceiverID)
+	}
+
+	for _, item := range bill.Items {
+		if err := checkBillItem(ctx, h.billItemRepo, item.ItemID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newAssignment builds an assignment from API input, defaulting the currency
+// and exchange rate the same way the bill form does
+func newAssignment(billID int64, in *models.BillItemAssignment) (*models.BillItemAssignment, error) {
+	currency := in.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency()
+	}
+	if !models.IsSupportedCurrency(currency) {
+		return nil, models.Invalid("currency", fmt.Sprintf("%q is not supported", currency))
+	}
+
+	assignment := models.NewBillItemAssignment(billID, in.ItemID, in.Quantity, in.Price, currency, in.ExchangeRate)
+	if err := assignment.Validate(); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// checkBillItem makes sure the catalog item referenced by an assignment exists
+func checkBillItem(ctx context.Context, repo repository.BillItemRepository, itemID int64) error {
+	if _, err := repo.GetByID(ctx, itemID); err != nil {
+		return reference(err, "item_id", itemID)
+	}
+	return nil
+}
+
+// reference reports a referenced record that does not exist as invalid input
+func reference(err error, field string, id int64) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return models.Invalid(field, fmt.Sprintf("%d does not exist", id))
+	}
+	return err
+}
+
+// recalculateBill refreshes the stored currency and totals of a bill after its
+// items changed
+func recalculateBill(ctx context.Context, repo repository.BillRepository, id int64) error {
+	bill, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	bill.ResolveCurrency()
+	bill.CalculateTotals()
+	return repo.Update(ctx, bill)
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BillItemAssignmentHandler serves the bill line endpoints of the JSON API.
+// Every change to a line also refreshes the totals of its bill, in the same
+// transaction
+type BillItemAssignmentHandler struct {
+	uow          repository.UnitOfWork
+	repo         repository.BillItemAssignmentRepository
+	billRepo     repository.BillRepository
+	billItemRepo repository.BillItemRepository
+}
+
+// NewBillItemAssignmentHandler creates a new BillItemAssignmentHandler instance
+func NewBillItemAssignmentHandler(
+	uow repository.UnitOfWork,
+	repo repository.BillItemAssignmentRepository,
+	billRepo repository.BillRepository,
+	billItemRepo repository.BillItemRepository,
+) *BillItemAssignmentHandler {
+	return &BillItemAssignmentHandler{
+		uow:          uow,
+		repo:         repo,
+		billRepo:     billRepo,
+		billItemRepo: billItemRepo,
+	}
+}
+
+// List returns the lines of a bill
+func (h *BillItemAssignmentHandler) List(c echo.Context) error {
+	billID, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.billRepo.GetByID(c.Request().Context(), billID); err != nil {
+		return err
+	}
+
+	assignments, err := h.repo.GetByBillID(c.Request().Context(), billID)
+	if err != nil {
+		return err
+	}
+	if assignments == nil {
+		assignments = []*models.BillItemAssignment{}
+	}
+
+	return c.JSON(http.StatusOK, assignments)
+}
+
+// Get returns a single bill line
+func (h *BillItemAssignmentHandler) Get(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	assignment, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, assignment)
+}
+
+// Create adds a line to a bill
+func (h *BillItemAssignmentHandler) Create(c echo.Context) error {
+	billID, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.billRepo.GetByID(c.Request().Context(), billID); err != nil {
+		return err
+	}
+
+	var in models.BillItemAssignment
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	assignment, err := newAssignment(billID, &in)
+	if err != nil {
+		return err
+	}
+	if err := checkBillItem(c.Request().Context(), h.billItemRepo, assignment.ItemID); err != nil {
+		return err
+	}
+
+	err = h.uow.Do(c.Request().Context(), func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.Create(c.Request().Context(), assignment); err != nil {
+			return err
+		}
+		return recalculateBill(c.Request().Context(), tx.Bills, billID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, assignment)
+}
+
+// Update changes the quantity, price and currency of a bill line
+func (h *BillItemAssignmentHandler) Update(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	assignment, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	var in models.BillItemAssignment
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	// The catalog item of a line is fixed, only its pricing can change
+	in.ItemID = assignment.ItemID
+	updated, err := newAssignment(assignment.BillID, &in)
+	if err != nil {
+		return err
+	}
+
+	assignment.Quantity = updated.Quantity
+	assignment.Price = updated.Price
+	assignment.Currency = updated.Currency
+	assignment.ExchangeRate = updated.ExchangeRate
+	assignment.CalculateAmounts()
+
+	err = h.uow.Do(c.Request().Context(), func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.Update(c.Request().Context(), assignment); err != nil {
+			return err
+		}
+		return recalculateBill(c.Request().Context(), tx.Bills, assignment.BillID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, assignment)
+}
+
+// Delete removes a line from its bill
+func (h *BillItemAssignmentHandler) Delete(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	assignment, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	err = h.uow.Do(c.Request().Context(), func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.Delete(c.Request().Context(), id); err != nil {
+			return err
+		}
+		return recalculateBill(c.Request().Context(), tx.Bills, assignment.BillID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BillItemHandler serves the bill item catalog endpoints of the JSON API
+type BillItemHandler struct {
+	repo repository.BillItemRepository
+}
+
+// NewBillItemHandler creates a new BillItemHandler instance
+func NewBillItemHandler(repo repository.BillItemRepository) *BillItemHandler {
+	return &BillItemHandler{repo: repo}
+}
+
+// List returns all bill items
+func (h *BillItemHandler) List(c echo.Context) error {
+	items, err := h.repo.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	if items == nil {
+		items = []*models.BillItem{}
+	}
+
+	return c.JSON(http.StatusOK, items)
+}
+
+// Get returns a single bill item
+func (h *BillItemHandler) Get(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	item, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// Create stores a new bill item
+func (h *BillItemHandler) Create(c echo.Context) error {
+	var in models.BillItem
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	if in.Currency == "" {
+		in.Currency = models.DefaultCurrency()
+	}
+
+	if err := in.Validate(); err != nil {
+		return err
+	}
+
+	item := models.NewBillItem(in.Name, in.Price, in.Currency)
+	if err := h.repo.Create(c.Request().Context(), item); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, item)
+}
+
+// Update replaces the fields of an existing bill item
+func (h *BillItemHandler) Update(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	item, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	var in models.BillItem
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	if in.Currency == "" {
+		in.Currency = models.DefaultCurrency()
+	}
+
+	item.Name = in.Name
+	item.Price = in.Price
+	item.Currency = in.Currency
+
+	if err := item.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.repo.Update(c.Request().Context(), item); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// Delete moves a bill item to the trash
+func (h *BillItemHandler) Delete(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
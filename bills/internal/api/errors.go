@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorResponse is the body returned by every failed API request
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes what went wrong with an API request
+type ErrorBody struct {
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// ErrorMiddleware renders errors returned by API handlers as an ErrorResponse.
+// Errors that HTTPError does not know are logged and reported as 500s without
+// leaking their details to the client
+func ErrorMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		if err == nil || c.Response().Committed {
+			return err
+		}
+
+		he := HTTPError(err)
+		if he == nil {
+			c.Logger().Error(err)
+			he = echo.NewHTTPError(http.StatusInternalServerError)
+		}
+
+		return c.JSON(he.Code, NewErrorResponse(he, err))
+	}
+}
+
+// NewErrorResponse describes he, the status err is reported with, listing
+// what is wrong with each field of invalid input
+func NewErrorResponse(he *echo.HTTPError, err error) ErrorResponse {
+	body := ErrorBody{
+		Status:  he.Code,
+		Message: fmt.Sprint(he.Message),
+	}
+	if errs := models.FieldErrors(err); errs != nil {
+		body.Fields = errs.Fields()
+	}
+	return ErrorResponse{Error: body}
+}
+
+// HTTPError returns the echo.HTTPError to report err with. Missing records
+// give a 404, conflicts a 409, invalid input, references to records of other
+// workspaces included, a 422 and requests that ran out of time waiting for the
+// database a 503. Nil is returned for internal errors
+func HTTPError(err error) *echo.HTTPError {
+	var he *echo.HTTPError
+	var ve *models.ValidationError
+	switch {
+	case errors.As(err, &he):
+		return he
+	case errors.As(err, &ve), errors.Is(err, repository.ErrNotInWorkspace):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrConflict):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "the database took too long to answer")
+	}
+	return nil
+}
+
+// WantsJSON reports whether the client expects JSON rather than a page
+func WantsJSON(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, "/api/") || strings.Contains(req.Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON)
+}
+
+// parseID reads a numeric path parameter
+func parseID(c echo.Context, name string) (int64, error) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name)
+	}
+	return id, nil
+}
+
+// bind decodes the JSON request body into v
+func bind(c echo.Context, v interface{}) error {
+	if err := (&echo.DefaultBinder{}).BindBody(c, v); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IssuerHandler serves the issuer endpoints of the JSON API
+type IssuerHandler struct {
+	repo repository.IssuerRepository
+}
+
+// NewIssuerHandler creates a new IssuerHandler instance
+func NewIssuerHandler(repo repository.IssuerRepository) *IssuerHandler {
+	return &IssuerHandler{repo: repo}
+}
+
+// List returns all issuers
+func (h *IssuerHandler) List(c echo.Context) error {
+	issuers, err := h.repo.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	if issuers == nil {
+		issuers = []*models.Issuer{}
+	}
+
+	return c.JSON(http.StatusOK, issuers)
+}
+
+// Get returns a single issuer
+func (h *IssuerHandler) Get(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	issuer, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, issuer)
+}
+
+// Create stores a new issuer
+func (h *IssuerHandler) Create(c echo.Context) error {
+	var in models.Issuer
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	issuer := models.NewIssuer(in.Name, in.VATNumber, in.Street, in.City, in.State, in.ZipCode, in.Country)
+	if err := issuer.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.repo.Create(c.Request().Context(), issuer); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, issuer)
+}
+
+// Update replaces the fields of an existing issuer
+func (h *IssuerHandler) Update(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	issuer, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	var in models.Issuer
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	issuer.Name = in.Name
+	issuer.VATNumber = in.VATNumber
+	issuer.Street = in.Street
+	issuer.City = in.City
+	issuer.State = in.State
+	issuer.ZipCode = in.ZipCode
+	issuer.Country = in.Country
+
+	if err := issuer.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.repo.Update(c.Request().Context(), issuer); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, issuer)
+}
+
+// Delete moves an issuer to the trash
+func (h *IssuerHandler) Delete(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReceiverHandler serves the receiver endpoints of the JSON API
+type ReceiverHandler struct {
+	repo repository.ReceiverRepository
+}
+
+// NewReceiverHandler creates a new ReceiverHandler instance
+func NewReceiverHandler(repo repository.ReceiverRepository) *ReceiverHandler {
+	return &ReceiverHandler{repo: repo}
+}
+
+// List returns all receivers
+func (h *ReceiverHandler) List(c echo.Context) error {
+	receivers, err := h.repo.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	if receivers == nil {
+		receivers = []*models.Receiver{}
+	}
+
+	return c.JSON(http.StatusOK, receivers)
+}
+
+// Get returns a single receiver
+func (h *ReceiverHandler) Get(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	receiver, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, receiver)
+}
+
+// Create stores a new receiver
+func (h *ReceiverHandler) Create(c echo.Context) error {
+	var in models.Receiver
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	receiver := models.NewReceiver(in.Name, in.VATNumber, in.Street, in.City, in.State, in.ZipCode, in.Country)
+	if err := receiver.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.repo.Create(c.Request().Context(), receiver); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, receiver)
+}
+
+// Update replaces the fields of an existing receiver
+func (h *ReceiverHandler) Update(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	receiver, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	var in models.Receiver
+	if err := bind(c, &in); err != nil {
+		return err
+	}
+
+	receiver.Name = in.Name
+	receiver.VATNumber = in.VATNumber
+	receiver.Street = in.Street
+	receiver.City = in.City
+	receiver.State = in.State
+	receiver.ZipCode = in.ZipCode
+	receiver.Country = in.Country
+
+	if err := receiver.Validate(); err != nil {
+		return err
+	}
+
+	if err := h.repo.Update(c.Request().Context(), receiver); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, receiver)
+}
+
+// Delete moves a receiver to the trash
+func (h *ReceiverHandler) Delete(c echo.Context) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
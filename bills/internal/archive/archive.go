@@ -0,0 +1,345 @@
+// Package archive exports the bills of one or every workspace, with the
+// issuers, receivers and bill items they refer to, to a JSON document and
+// imports it again. It only goes through the repositories, so an archive
+// moves data between SQLite, PostgreSQL and the in-memory store alike. Users,
+// API tokens, the audit log and the trash are not archived
+package archive
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Version is the format of the archives Export writes. Import refuses any
+// other
+const Version = 1
+
+// Archive is the JSON document of an export. IDs only link the records of
+// one archive, an import gives them new ones
+type Archive struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// Workspace holds the records of one workspace, matched by name on import
+type Workspace struct {
+	Name      string             `json:"name"`
+	Issuers   []*models.Issuer   `json:"issuers"`
+	Receivers []*models.Receiver `json:"receivers"`
+	BillItems []*models.BillItem `json:"bill_items"`
+	Bills     []Bill             `json:"bills"`
+}
+
+// Bill is a bill with its lines
+type Bill struct {
+	ID            int64     `json:"id"`
+	IssuerID      int64     `json:"issuer_id"`
+	ReceiverID    int64     `json:"receiver_id"`
+	IssueDate     time.Time `json:"issue_date"`
+	DueDate       time.Time `json:"due_date"`
+	Currency      string    `json:"currency"`
+	OriginalTotal float64   `json:"original_total"`
+	EURTotal      float64   `json:"eur_total"`
+	Paid          bool      `json:"paid"`
+	Lines         []Line    `json:"lines"`
+	// Names of the parties, in case they are in the trash
+	IssuerName   string `json:"issuer_name"`
+	ReceiverName string `json:"receiver_name"`
+}
+
+// Line is a line of a bill
+type Line struct {
+	ItemID         int64   `json:"item_id"`
+	ItemName       string  `json:"item_name"`
+	Quantity       int     `json:"quantity"`
+	Price          float64 `json:"price"`
+	Currency       string  `json:"currency"`
+	ExchangeRate   float64 `json:"exchange_rate"`
+	OriginalAmount float64 `json:"original_amount"`
+	EURAmount      float64 `json:"eur_amount"`
+}
+
+// Summary counts what an import created
+type Summary struct {
+	Workspaces int
+	Issuers    int
+	Receivers  int
+	BillItems  int
+	Bills      int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d workspaces, %d issuers, %d receivers, %d bill items and %d bills",
+		s.Workspaces, s.Issuers, s.Receivers, s.BillItems, s.Bills)
+}
+
+// Export reads the live records of every workspace, each workspace in one
+// unit of work so its bills match its parties and items
+func Export(ctx context.Context, uow repository.UnitOfWork, workspaces repository.WorkspaceRepository) (*Archive, error) {
+	all, err := workspaces.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{Version: Version, ExportedAt: time.Now().UTC()}
+	for _, workspace := range all {
+		exported, err := exportWorkspace(ctx, uow, workspace)
+		if err != nil {
+			return nil, err
+		}
+		archive.Workspaces = append(archive.Workspaces, exported)
+	}
+	return archive, nil
+}
+
+// ExportWorkspace reads the live records of one workspace, the way Export
+// does for all of them
+func ExportWorkspace(ctx context.Context, uow repository.UnitOfWork, workspace *models.Workspace) (*Archive, error) {
+	exported, err := exportWorkspace(ctx, uow, workspace)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{Version: Version, ExportedAt: time.Now().UTC(), Workspaces: []Workspace{exported}}, nil
+}
+
+func exportWorkspace(ctx context.Context, uow repository.UnitOfWork, workspace *models.Workspace) (Workspace, error) {
+	ctx = tenant.WithWorkspace(ctx, workspace.ID)
+	exported := Workspace{Name: workspace.Name}
+	err := uow.Do(ctx, func(tx *repository.Tx) error {
+		var err error
+		if exported.Issuers, err = tx.Issuers.GetAll(ctx); err != nil {
+			return err
+		}
+		if exported.Receivers, err = tx.Receivers.GetAll(ctx); err != nil {
+			return err
+		}
+		if exported.BillItems, err = tx.BillItems.GetAll(ctx); err != nil {
+			return err
+		}
+		bills, err := tx.Bills.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, bill := range bills {
+			exported.Bills = append(exported.Bills, exportBill(bill))
+		}
+		return nil
+	})
+	if err != nil {
+		return Workspace{}, fmt.Errorf("failed to export workspace %s: %w", workspace.Name, err)
+	}
+	return exported, nil
+}
+
+func exportBill(bill *models.Bill) Bill {
+	exported := Bill{
+		ID:            bill.ID,
+		IssuerID:      bill.IssuerID,
+		ReceiverID:    bill.ReceiverID,
+		IssueDate:     bill.IssueDate,
+		DueDate:       bill.DueDate,
+		Currency:      bill.Currency,
+		OriginalTotal: bill.OriginalTotal,
+		EURTotal:      bill.EURTotal,
+		Paid:          bill.Paid,
+		Lines:         make([]Line, 0, len(bill.Items)),
+		IssuerName:    bill.IssuerName,
+		ReceiverName:  bill.ReceiverName,
+	}
+	for _, item := range bill.Items {
+		var name string
+		if item.BillItem != nil {
+			name = item.BillItem.Name
+		}
+		exported.Lines = append(exported.Lines, Line{
+			ItemID:         item.ItemID,
+			ItemName:       name,
+			Quantity:       item.Quantity,
+			Price:          item.Price,
+			Currency:       item.Currency,
+			ExchangeRate:   item.ExchangeRate,
+			OriginalAmount: item.OriginalAmount,
+			EURAmount:      item.EURAmount,
+		})
+	}
+	return exported
+}
+
+// Import adds the records of archive to the workspaces of the same name,
+// creating the ones that do not exist. Each workspace is imported in one unit
+// of work, nothing of it is kept when one of its records is refused
+func Import(ctx context.Context, archive *Archive, uow repository.UnitOfWork, workspaces repository.WorkspaceRepository) (Summary, error) {
+	var summary Summary
+	if archive.Version != Version {
+		return summary, fmt.Errorf("unsupported archive version %d, expected %d", archive.Version, Version)
+	}
+
+	for _, imported := range archive.Workspaces {
+		workspace, err := workspaces.GetByName(ctx, imported.Name)
+		if errors.Is(err, models.ErrNotFound) {
+			workspace = models.NewWorkspace(imported.Name)
+			if err := workspace.Validate(); err != nil {
+				return summary, err
+			}
+			if err := workspaces.Create(ctx, workspace); err != nil {
+				return summary, err
+			}
+		} else if err != nil {
+			return summary, err
+		}
+
+		ctx := tenant.WithWorkspace(ctx, workspace.ID)
+		var counts Summary
+		err = uow.Do(ctx, func(tx *repository.Tx) error {
+			counts = Summary{Workspaces: 1}
+			return importWorkspace(ctx, tx, imported, &counts)
+		})
+		if err != nil {
+			return summary, fmt.Errorf("failed to import workspace %s: %w", imported.Name, err)
+		}
+		summary.Workspaces += counts.Workspaces
+		summary.Issuers += counts.Issuers
+		summary.Receivers += counts.Receivers
+		summary.BillItems += counts.BillItems
+		summary.Bills += counts.Bills
+	}
+	return summary, nil
+}
+
+// importWorkspace creates the records of one workspace and links the bills
+// to the new IDs of their parties and items. Records are stored as they were
+// exported, rules added to the models since are not applied to them
+func importWorkspace(ctx context.Context, tx *repository.Tx, imported Workspace, counts *Summary) error {
+	issuers := make(map[int64]int64, len(imported.Issuers))
+	for _, issuer := range imported.Issuers {
+		id := issuer.ID
+		if err := tx.Issuers.Create(ctx, issuer); err != nil {
+			return err
+		}
+		issuers[id] = issuer.ID
+		counts.Issuers++
+	}
+
+	receivers := make(map[int64]int64, len(imported.Receivers))
+	for _, receiver := range imported.Receivers {
+		id := receiver.ID
+		if err := tx.Receivers.Create(ctx, receiver); err != nil {
+			return err
+		}
+		receivers[id] = receiver.ID
+		counts.Receivers++
+	}
+
+	items := make(map[int64]int64, len(imported.BillItems))
+	for _, item := range imported.BillItems {
+		id := item.ID
+		if err := tx.BillItems.Create(ctx, item); err != nil {
+			return err
+		}
+		items[id] = item.ID
+		counts.BillItems++
+	}
+
+	// Bills can refer to parties and items in the trash, which are not
+	// exported. They are recreated from the names the bills show and go back
+	// to the trash once the bills are in
+	var trashed []func() error
+	standIn := func(ids map[int64]int64, id int64, create func() (int64, error), trash func(id int64) error) (int64, error) {
+		if newID, ok := ids[id]; ok {
+			return newID, nil
+		}
+		newID, err := create()
+		if err != nil {
+			return 0, err
+		}
+		ids[id] = newID
+		trashed = append(trashed, func() error { return trash(newID) })
+		return newID, nil
+	}
+
+	for _, imported := range imported.Bills {
+		issuerID, err := standIn(issuers, imported.IssuerID, func() (int64, error) {
+			issuer := &models.Issuer{Name: imported.IssuerName}
+			err := tx.Issuers.Create(ctx, issuer)
+			return issuer.ID, err
+		}, func(id int64) error { return tx.Issuers.Delete(ctx, id) })
+		if err != nil {
+			return err
+		}
+		receiverID, err := standIn(receivers, imported.ReceiverID, func() (int64, error) {
+			receiver := &models.Receiver{Name: imported.ReceiverName}
+			err := tx.Receivers.Create(ctx, receiver)
+			return receiver.ID, err
+		}, func(id int64) error { return tx.Receivers.Delete(ctx, id) })
+		if err != nil {
+			return err
+		}
+
+		bill := &models.Bill{
+			IssuerID:      issuerID,
+			ReceiverID:    receiverID,
+			IssueDate:     imported.IssueDate,
+			DueDate:       imported.DueDate,
+			Currency:      imported.Currency,
+			OriginalTotal: imported.OriginalTotal,
+			EURTotal:      imported.EURTotal,
+			Paid:          imported.Paid,
+		}
+		for _, line := range imported.Lines {
+			itemID, err := standIn(items, line.ItemID, func() (int64, error) {
+				item := &models.BillItem{Name: line.ItemName, Price: line.Price, Currency: line.Currency}
+				err := tx.BillItems.Create(ctx, item)
+				return item.ID, err
+			}, func(id int64) error { return tx.BillItems.Delete(ctx, id) })
+			if err != nil {
+				return err
+			}
+			bill.Items = append(bill.Items, &models.BillItemAssignment{
+				ItemID:         itemID,
+				Quantity:       line.Quantity,
+				Price:          line.Price,
+				Currency:       line.Currency,
+				ExchangeRate:   line.ExchangeRate,
+				OriginalAmount: line.OriginalAmount,
+				EURAmount:      line.EURAmount,
+			})
+		}
+		if err := tx.Bills.Create(ctx, bill); err != nil {
+			return err
+		}
+		counts.Bills++
+	}
+
+	for _, trash := range trashed {
+		if err := trash(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write encodes archive as indented JSON
+func Write(w io.Writer, archive *Archive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(archive)
+}
+
+// Read decodes an archive Write encoded
+func Read(r io.Reader) (*Archive, error) {
+	var archive Archive
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&archive); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	return &archive, nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"bills/internal/api"
+	"bills/internal/models"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Permissions holds the permission needed by every route registered in
+// main.go, keyed by method and echo path. The server refuses to start when a
+// route is missing, see Unguarded
+var Permissions = map[string]models.Permission{
+	// Pages and HTMX partials
+	"GET /":                    models.PermissionView,
+	"GET /bills":               models.PermissionView,
+	"POST /bills":              models.PermissionCreateBills,
+	"GET /bills/list":          models.PermissionView,
+	"GET /bills/:id":           models.PermissionView,
+	"GET /bills/:id/lines":     models.PermissionView,
+	"GET /bills/:id/edit":      models.PermissionEditBills,
+	"PUT /bills/:id":           models.PermissionEditBills,
+	"POST /bills/:id/toggle":   models.PermissionTogglePaid,
+	"DELETE /bills/:id":        models.PermissionDeleteBills,
+	"GET /receivers":           models.PermissionView,
+	"POST /receivers":          models.PermissionManageParties,
+	"GET /receivers/list":      models.PermissionView,
+	"GET /receivers/select":    models.PermissionView,
+	"GET /receivers/:id":       models.PermissionView,
+	"GET /receivers/:id/edit":  models.PermissionManageParties,
+	"PUT /receivers/:id":       models.PermissionManageParties,
+	"DELETE /receivers/:id":    models.PermissionDeleteParties,
+	"GET /issuers":             models.PermissionView,
+	"POST /issuers":            models.PermissionManageParties,
+	"GET /issuers/list":        models.PermissionView,
+	"GET /issuers/select":      models.PermissionView,
+	"GET /issuers/:id":         models.PermissionView,
+	"GET /issuers/:id/edit":    models.PermissionManageParties,
+	"PUT /issuers/:id":         models.PermissionManageParties,
+	"DELETE /issuers/:id":      models.PermissionDeleteParties,
+	"GET /bill-items":          models.PermissionView,
+	"POST /bill-items":         models.PermissionManageCatalog,
+	"GET /bill-items/list":     models.PermissionView,
+	"GET /bill-items/select":   models.PermissionView,
+	"GET /bill-items/:id/edit": models.PermissionManageCatalog,
+	"PUT /bill-items/:id":      models.PermissionManageCatalog,
+	"DELETE /bill-items/:id":   models.PermissionDeleteCatalog,
+	"GET /search":              models.PermissionView,
+	"GET /search/results":      models.PermissionView,
+	"POST /logout":             models.PermissionView,
+	"POST /workspaces/switch":  models.PermissionView,
+	"GET /openapi.json":        models.PermissionView,
+	"GET /api/docs":            models.PermissionView,
+
+	// Administration
+	"GET /admin/users":                models.PermissionManageUsers,
+	"POST /admin/users/:id/role":      models.PermissionManageUsers,
+	"GET /audit":                      models.PermissionViewAudit,
+	"GET /audit/list":                 models.PermissionViewAudit,
+	"GET /trash":                      models.PermissionManageTrash,
+	"POST /trash/:entity/:id/restore": models.PermissionManageTrash,
+	"DELETE /trash/:entity/:id":       models.PermissionManageTrash,
+	"GET /admin/backups":              models.PermissionManageBackups,
+	"POST /admin/backups":             models.PermissionManageSnapshots,
+	"GET /admin/backups/:name":        models.PermissionManageSnapshots,
+	"GET /admin/archive":              models.PermissionManageBackups,
+
+	// API tokens of the signed in user
+	"GET /account/tokens":        models.PermissionManageTokens,
+	"POST /account/tokens":       models.PermissionManageTokens,
+	"DELETE /account/tokens/:id": models.PermissionManageTokens,
+
+	// Bills
+	"GET /api/v1/bills":              models.PermissionView,
+	"POST /api/v1/bills":             models.PermissionCreateBills,
+	"GET /api/v1/bills/:id":          models.PermissionView,
+	"PUT /api/v1/bills/:id":          models.PermissionEditBills,
+	"DELETE /api/v1/bills/:id":       models.PermissionDeleteBills,
+	"GET /api/v1/bills/:id/items":    models.PermissionView,
+	"POST /api/v1/bills/:id/items":   models.PermissionEditBills,
+	"GET /api/v1/assignments/:id":    models.PermissionView,
+	"PUT /api/v1/assignments/:id":    models.PermissionEditBills,
+	"DELETE /api/v1/assignments/:id": models.PermissionEditBills,
+
+	// Issuers
+	"GET /api/v1/issuers":        models.PermissionView,
+	"POST /api/v1/issuers":       models.PermissionManageParties,
+	"GET /api/v1/issuers/:id":    models.PermissionView,
+	"PUT /api/v1/issuers/:id":    models.PermissionManageParties,
+	"DELETE /api/v1/issuers/:id": models.PermissionDeleteParties,
+
+	// Receivers
+	"GET /api/v1/receivers":        models.PermissionView,
+	"POST /api/v1/receivers":       models.PermissionManageParties,
+	"GET /api/v1/receivers/:id":    models.PermissionView,
+	"PUT /api/v1/receivers/:id":    models.PermissionManageParties,
+	"DELETE /api/v1/receivers/:id": models.PermissionDeleteParties,
+
+	// Bill items
+	"GET /api/v1/bill-items":        models.PermissionView,
+	"POST /api/v1/bill-items":       models.PermissionManageCatalog,
+	"GET /api/v1/bill-items/:id":    models.PermissionView,
+	"PUT /api/v1/bill-items/:id":    models.PermissionManageCatalog,
+	"DELETE /api/v1/bill-items/:id": models.PermissionDeleteCatalog,
+}
+
+// Authorize rejects requests whose user lacks the permission of the route,
+// or whose API token is not scoped for it. It runs after Sessions.Middleware,
+// requests for unknown routes are passed on so they end in a 404
+func Authorize(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Path() == LoginPath {
+			return next(c)
+		}
+
+		permission, ok := Permissions[c.Request().Method+" "+c.Path()]
+		if !ok {
+			return next(c)
+		}
+		if token := CurrentToken(c); token != nil && !token.Allows(permission) {
+			return forbidden(c)
+		}
+		if CurrentUser(c).Can(permission) {
+			return next(c)
+		}
+		return forbidden(c)
+	}
+}
+
+// Unguarded returns the keys of the given routes that have no entry in
+// Permissions, sorted
+func Unguarded(routes []*echo.Route) []string {
+	var missing []string
+	for _, route := range routes {
+		switch route.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			continue
+		}
+		if route.Path == LoginPath {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		if _, ok := Permissions[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func forbidden(c echo.Context) error {
+	return refuse(c, http.StatusForbidden, "you do not have permission to do this")
+}
+
+// refuse answers with an error status in the form the client expects
+func refuse(c echo.Context, status int, message string) error {
+	req := c.Request()
+
+	switch {
+	case req.Header.Get("HX-Request") == "true":
+		return c.String(status, message)
+	case api.WantsJSON(req):
+		return c.JSON(status, api.ErrorResponse{
+			Error: api.ErrorBody{
+				Status:  status,
+				Message: message,
+			},
+		})
+	}
+	return echo.NewHTTPError(status, message)
+}
@@ -0,0 +1,336 @@
+// Package auth signs users in with cookie sessions stored in the database and
+// protects the routes of the echo server
+package auth
+
+import (
+	"bills/internal/api"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// CookieName is the name of the session cookie
+	CookieName = "bills_session"
+
+	// DefaultTTL is how long a session lasts after signing in
+	DefaultTTL = 7 * 24 * time.Hour
+
+	// LoginPath is the page unauthenticated browsers are sent to
+	LoginPath = "/login"
+
+	userKey       = "user"
+	workspaceKey  = "workspace"
+	workspacesKey = "workspaces"
+)
+
+var (
+	// ErrInvalidCredentials is returned when the username or password is wrong
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrNotMember is returned when switching to a workspace the user does not
+	// belong to
+	ErrNotMember = errors.New("you are not a member of this workspace")
+)
+
+// dummyHash is compared against when the username does not exist so unknown
+// and known usernames take the same time to reject
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+// Sessions signs users in and out, authenticates requests and remembers the
+// workspace each session works in
+type Sessions struct {
+	users      repository.UserRepository
+	sessions   repository.SessionRepository
+	workspaces repository.WorkspaceRepository
+	TTL        time.Duration
+}
+
+// NewSessions creates a new Sessions instance
+func NewSessions(users repository.UserRepository, sessions repository.SessionRepository, workspaces repository.WorkspaceRepository) *Sessions {
+	return &Sessions{
+		users:      users,
+		sessions:   sessions,
+		workspaces: workspaces,
+		TTL:        DefaultTTL,
+	}
+}
+
+// Login checks the credentials, stores a new session and sets its cookie
+func (s *Sessions) Login(c echo.Context, username, password string) (*models.User, error) {
+	user, err := s.Verify(c.Request().Context(), username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := models.NewSession(hashToken(token), user.ID, s.TTL)
+	if err := s.sessions.Create(c.Request().Context(), session); err != nil {
+		return nil, err
+	}
+
+	c.SetCookie(s.cookie(c, token, session.ExpiresAt))
+	return user, nil
+}
+
+// Verify returns the user with the given credentials, or
+// ErrInvalidCredentials when the username or password is wrong
+func (s *Sessions) Verify(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := s.users.GetByUsername(ctx, strings.TrimSpace(username))
+	if errors.Is(err, models.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.CheckPassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Logout deletes the current session and clears its cookie
+func (s *Sessions) Logout(c echo.Context) error {
+	if cookie, err := c.Cookie(CookieName); err == nil {
+		if err := s.sessions.Delete(c.Request().Context(), hashToken(cookie.Value)); err != nil {
+			return err
+		}
+	}
+
+	cookie := s.cookie(c, "", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	c.SetCookie(cookie)
+	return nil
+}
+
+// Authenticate returns the user of the session cookie, or nil when there is
+// no valid session
+func (s *Sessions) Authenticate(c echo.Context) (*models.User, error) {
+	user, _, err := s.authenticate(c)
+	return user, err
+}
+
+// authenticate returns the user and session of the session cookie, or nils
+// when there is no valid session
+func (s *Sessions) authenticate(c echo.Context) (*models.User, *models.Session, error) {
+	cookie, err := c.Cookie(CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, nil, nil
+	}
+
+	ctx := c.Request().Context()
+	session, err := s.sessions.GetByTokenHash(ctx, hashToken(cookie.Value))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.Expired() {
+		return nil, nil, s.sessions.Delete(ctx, session.TokenHash)
+	}
+
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, session, nil
+}
+
+// Middleware rejects requests without a valid session, except for the login
+// page. Browsers are redirected to the login page, HTMX requests are told to
+// redirect and API clients get a JSON error. Signed in requests work in the
+// workspace of their session with the role the user has there, users that
+// belong to none are refused. Requests already signed in by Tokens.Middleware
+// are passed on
+func (s *Sessions) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Path() == LoginPath || CurrentUser(c) != nil {
+			return next(c)
+		}
+
+		user, session, err := s.authenticate(c)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return unauthorized(c)
+		}
+		SetUser(c, user)
+
+		workspace, err := s.resolveWorkspace(c, user, session)
+		if err != nil {
+			return err
+		}
+		if workspace == nil {
+			return refuse(c, http.StatusForbidden, "you are not a member of any workspace")
+		}
+		if user.Role, err = s.workspaces.GetRole(c.Request().Context(), workspace.ID, user.ID); err != nil {
+			return err
+		}
+		SetWorkspace(c, workspace)
+
+		return next(c)
+	}
+}
+
+// SwitchWorkspace makes the current session work in another workspace of its
+// user. ErrNotMember is returned for workspaces the user does not belong to
+func (s *Sessions) SwitchWorkspace(c echo.Context, workspaceID int64) error {
+	user, session, err := s.authenticate(c)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrNotMember
+	}
+
+	ctx := c.Request().Context()
+	member, err := s.workspaces.IsMember(ctx, workspaceID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return ErrNotMember
+	}
+	return s.sessions.SetWorkspace(ctx, session.TokenHash, workspaceID)
+}
+
+// resolveWorkspace returns the workspace the session works in, falling back
+// to the first workspace of the user when none was picked yet or the user
+// was removed from it. The list of workspaces is kept for the layout
+func (s *Sessions) resolveWorkspace(c echo.Context, user *models.User, session *models.Session) (*models.Workspace, error) {
+	ctx := c.Request().Context()
+	workspaces, err := s.workspaces.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(workspacesKey, workspaces)
+	if len(workspaces) == 0 {
+		return nil, nil
+	}
+
+	for _, workspace := range workspaces {
+		if workspace.ID == session.WorkspaceID {
+			return workspace, nil
+		}
+	}
+
+	workspace := workspaces[0]
+	if err := s.sessions.SetWorkspace(ctx, session.TokenHash, workspace.ID); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// SetUser stores the signed in user on the echo context, and on the request
+// context so repositories record them as the author of changes
+func SetUser(c echo.Context, user *models.User) {
+	c.Set(userKey, user)
+	req := c.Request()
+	c.SetRequest(req.WithContext(tenant.WithActor(req.Context(), user.ID)))
+}
+
+// CurrentUser returns the signed in user, or nil on public routes
+func CurrentUser(c echo.Context) *models.User {
+	user, _ := c.Get(userKey).(*models.User)
+	return user
+}
+
+// SetWorkspace makes the request work in the given workspace. The workspace
+// is stored on the echo context for templates and on the request context for
+// the repositories
+func SetWorkspace(c echo.Context, workspace *models.Workspace) {
+	c.Set(workspaceKey, workspace)
+	req := c.Request()
+	c.SetRequest(req.WithContext(tenant.WithWorkspace(req.Context(), workspace.ID)))
+}
+
+// CurrentWorkspace returns the workspace of the request, or nil on public
+// routes
+func CurrentWorkspace(c echo.Context) *models.Workspace {
+	workspace, _ := c.Get(workspaceKey).(*models.Workspace)
+	return workspace
+}
+
+// Workspaces returns the workspaces the signed in user can switch between
+func Workspaces(c echo.Context) []*models.Workspace {
+	workspaces, _ := c.Get(workspacesKey).([]*models.Workspace)
+	return workspaces
+}
+
+// SafeRedirect returns next when it points inside this site, the bills page
+// otherwise
+func SafeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+func unauthorized(c echo.Context) error {
+	req := c.Request()
+	login := LoginPath + "?next=" + url.QueryEscape(req.URL.RequestURI())
+
+	switch {
+	case req.Header.Get("HX-Request") == "true":
+		c.Response().Header().Set("HX-Redirect", login)
+		return c.NoContent(http.StatusUnauthorized)
+	case api.WantsJSON(req):
+		return c.JSON(http.StatusUnauthorized, api.ErrorResponse{
+			Error: api.ErrorBody{
+				Status:  http.StatusUnauthorized,
+				Message: "authentication required",
+			},
+		})
+	}
+	return c.Redirect(http.StatusSeeOther, login)
+}
+
+func (s *Sessions) cookie(c echo.Context, value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// newToken returns a random session token for the cookie
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the value stored in the database for a cookie token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
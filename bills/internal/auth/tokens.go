@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"bills/internal/api"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// TokenPrefix starts every API token so leaked tokens are easy to spot
+	TokenPrefix = "bills_"
+
+	// touchInterval is how stale the last used time of a token may get before
+	// it is written again, so busy scripts do not write on every request
+	touchInterval = time.Minute
+
+	tokenKey = "api_token"
+)
+
+// ErrInvalidToken is returned for unknown, revoked or expired API tokens
+var ErrInvalidToken = errors.New("invalid or expired API token")
+
+// Tokens issues personal API tokens and authenticates requests that send one
+// as Bearer auth
+type Tokens struct {
+	tokens     repository.APITokenRepository
+	users      repository.UserRepository
+	workspaces repository.WorkspaceRepository
+}
+
+// NewTokens creates a new Tokens instance
+func NewTokens(tokens repository.APITokenRepository, users repository.UserRepository, workspaces repository.WorkspaceRepository) *Tokens {
+	return &Tokens{
+		tokens:     tokens,
+		users:      users,
+		workspaces: workspaces,
+	}
+}
+
+// Issue creates a token for the user in the given workspace. The plain token
+// is returned once and cannot be recovered afterwards. A zero ttl never
+// expires
+func (t *Tokens) Issue(ctx context.Context, userID, workspaceID int64, name string, scope models.TokenScope, ttl time.Duration) (*models.APIToken, string, error) {
+	secret, err := newToken()
+	if err != nil {
+		return nil, "", err
+	}
+	plain := TokenPrefix + secret
+
+	token := models.NewAPIToken(userID, workspaceID, name, scope, hashToken(plain), ttl)
+	if err := token.Validate(); err != nil {
+		return nil, "", err
+	}
+	if err := t.tokens.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+// Authenticate returns the token and its user, or ErrInvalidToken
+func (t *Tokens) Authenticate(ctx context.Context, plain string) (*models.APIToken, *models.User, error) {
+	token, err := t.tokens.GetByTokenHash(ctx, hashToken(plain))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if token.Expired() {
+		return nil, nil, ErrInvalidToken
+	}
+
+	user, err := t.users.GetByID(ctx, token.UserID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > touchInterval {
+		now := time.Now()
+		if err := t.tokens.Touch(ctx, token.ID, now); err != nil {
+			return nil, nil, err
+		}
+		token.LastUsedAt = &now
+	}
+	return token, user, nil
+}
+
+// Middleware signs in requests carrying an Authorization: Bearer header as the
+// user of the token, in the workspace the token was created in and with the
+// role the user has there. Requests without the header are left to
+// Sessions.Middleware
+func (t *Tokens) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		plain, ok := bearerToken(c.Request())
+		if !ok {
+			return next(c)
+		}
+
+		ctx := c.Request().Context()
+		token, user, err := t.Authenticate(ctx, plain)
+		if errors.Is(err, ErrInvalidToken) {
+			return invalidToken(c)
+		}
+		if err != nil {
+			return err
+		}
+
+		role, err := t.workspaces.GetRole(ctx, token.WorkspaceID, user.ID)
+		if errors.Is(err, models.ErrNotFound) {
+			return refuse(c, http.StatusForbidden, "you are not a member of the workspace of this token")
+		}
+		if err != nil {
+			return err
+		}
+		workspace, err := t.workspaces.GetByID(ctx, token.WorkspaceID)
+		if errors.Is(err, models.ErrNotFound) {
+			return refuse(c, http.StatusForbidden, "you are not a member of the workspace of this token")
+		}
+		if err != nil {
+			return err
+		}
+		user.Role = role
+
+		SetUser(c, user)
+		c.Set(tokenKey, token)
+		SetWorkspace(c, workspace)
+		return next(c)
+	}
+}
+
+// CurrentToken returns the API token the request was signed in with, or nil
+// for browser sessions
+func CurrentToken(c echo.Context) *models.APIToken {
+	token, _ := c.Get(tokenKey).(*models.APIToken)
+	return token
+}
+
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(req *http.Request) (string, bool) {
+	header := req.Header.Get(echo.HeaderAuthorization)
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// invalidToken rejects a Bearer request. Scripts always get JSON
+func invalidToken(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+	return c.JSON(http.StatusUnauthorized, api.ErrorResponse{
+		Error: api.ErrorBody{
+			Status:  http.StatusUnauthorized,
+			Message: ErrInvalidToken.Error(),
+		},
+	})
+}
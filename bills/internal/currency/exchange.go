@@ -1,6 +1,7 @@
 package currency
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -11,11 +12,11 @@ import (
 
 // ExchangeRate represents a currency exchange rate
 type ExchangeRate struct {
-	ID        int64     `json:"id"`
-	From      string    `json:"currency_from"`
-	To        string    `json:"currency_to"`
-	Rate      float64   `json:"rate"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id" db:"id"`
+	From      string    `json:"currency_from" db:"currency_from"`
+	To        string    `json:"currency_to" db:"currency_to"`
+	Rate      float64   `json:"rate" db:"rate"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 // ExchangeService handles currency exchange operations
@@ -33,7 +34,7 @@ func NewExchangeService(db *sql.DB) *ExchangeService {
 }
 
 // GetRate gets the exchange rate from the API and stores it
-func (s *ExchangeService) GetRate(from, to string) (*ExchangeRate, error) {
+func (s *ExchangeService) GetRate(ctx context.Context, from, to string) (*ExchangeRate, error) {
 	if from == to {
 		return &ExchangeRate{
 			From:      from,
@@ -46,7 +47,11 @@ func (s *ExchangeService) GetRate(from, to string) (*ExchangeRate, error) {
 	url := fmt.Sprintf("https://api.freecurrencyapi.com/v1/latest?apikey=%s&base_currency=%s&currencies=%s",
 		s.apiKey, from, to)
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
 	}
@@ -73,7 +78,7 @@ func (s *ExchangeService) GetRate(from, to string) (*ExchangeRate, error) {
 	}
 
 	// Store the rate
-	err = s.storeRate(exchangeRate)
+	err = s.storeRate(ctx, exchangeRate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store rate: %w", err)
 	}
@@ -82,8 +87,8 @@ func (s *ExchangeService) GetRate(from, to string) (*ExchangeRate, error) {
 }
 
 // storeRate stores the exchange rate in the database
-func (s *ExchangeService) storeRate(rate *ExchangeRate) error {
-	result, err := s.db.Exec(`
+func (s *ExchangeService) storeRate(ctx context.Context, rate *ExchangeRate) error {
+	result, err := s.db.ExecContext(ctx, `
         INSERT INTO exchange_rates (currency_from, currency_to, rate, created_at)
         VALUES (?, ?, ?, ?)
     `,
@@ -106,8 +111,8 @@ func (s *ExchangeService) storeRate(rate *ExchangeRate) error {
 }
 
 // Convert converts an amount from one currency to another
-func (s *ExchangeService) Convert(amount float64, from, to string) (float64, float64, error) {
-	rate, err := s.GetRate(from, to)
+func (s *ExchangeService) Convert(ctx context.Context, amount float64, from, to string) (float64, float64, error) {
+	rate, err := s.GetRate(ctx, from, to)
 	if err != nil {
 		return 0, 0, err
 	}
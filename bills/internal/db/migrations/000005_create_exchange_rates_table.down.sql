@@ -1 +0,0 @@
-DROP TABLE IF EXISTS exchange_rates; 
\ No newline at end of file
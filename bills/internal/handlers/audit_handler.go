@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// auditPageSize caps how many events the audit page shows at once
+const auditPageSize = 200
+
+// AuditHandler handles HTTP requests for the audit log page
+type AuditHandler struct {
+	repo repository.AuditRepository
+}
+
+// NewAuditHandler creates a new AuditHandler instance
+func NewAuditHandler(repo repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{repo: repo}
+}
+
+// RenderAudit renders the audit log page, filtered by the entity and user
+// query parameters
+func (h *AuditHandler) RenderAudit(c echo.Context) error {
+	data, err := h.listData(c)
+	if err != nil {
+		return err
+	}
+
+	actors, err := h.repo.GetActors(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	data["Actors"] = actors
+	data["Entities"] = models.AuditEntities()
+
+	return c.Render(http.StatusOK, "audit.html", data)
+}
+
+// GetAuditList returns the audit events partial for HTMX updates
+func (h *AuditHandler) GetAuditList(c echo.Context) error {
+	data, err := h.listData(c)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, "audit-list", data)
+}
+
+// listData loads the events matching the query parameters
+func (h *AuditHandler) listData(c echo.Context) (map[string]interface{}, error) {
+	filter := models.AuditFilter{
+		Entity: c.QueryParam("entity"),
+		Limit:  auditPageSize,
+	}
+	if filter.Entity != "" && !isAuditEntity(filter.Entity) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid entity")
+	}
+	for param, dest := range map[string]*int64{"entity_id": &filter.EntityID, "user": &filter.ActorID} {
+		if value := c.QueryParam(param); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid "+param)
+			}
+			*dest = id
+		}
+	}
+
+	events, err := h.repo.List(c.Request().Context(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"Events": events,
+		"Filter": filter,
+	}, nil
+}
+
+func isAuditEntity(entity string) bool {
+	for _, e := range models.AuditEntities() {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
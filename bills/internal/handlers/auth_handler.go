@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bills/internal/auth"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuthHandler handles HTTP requests for signing in and out
+type AuthHandler struct {
+	sessions *auth.Sessions
+}
+
+// NewAuthHandler creates a new AuthHandler instance
+func NewAuthHandler(sessions *auth.Sessions) *AuthHandler {
+	return &AuthHandler{sessions: sessions}
+}
+
+// RenderLogin renders the login page, or sends signed in users on their way
+func (h *AuthHandler) RenderLogin(c echo.Context) error {
+	next := auth.SafeRedirect(c.QueryParam("next"))
+
+	user, err := h.sessions.Authenticate(c)
+	if err != nil {
+		return err
+	}
+	if user != nil {
+		return c.Redirect(http.StatusSeeOther, next)
+	}
+
+	return c.Render(http.StatusOK, "login.html", map[string]interface{}{
+		"Next": next,
+	})
+}
+
+// Login signs the user in and redirects to the page they asked for
+func (h *AuthHandler) Login(c echo.Context) error {
+	username := c.FormValue("username")
+	next := auth.SafeRedirect(c.FormValue("next"))
+
+	_, err := h.sessions.Login(c, username, c.FormValue("password"))
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		return c.Render(http.StatusUnauthorized, "login.html", map[string]interface{}{
+			"Next":     next,
+			"Username": username,
+			"Error":    err.Error(),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.Redirect(http.StatusSeeOther, next)
+}
+
+// Logout ends the session and returns to the login page
+func (h *AuthHandler) Logout(c echo.Context) error {
+	if err := h.sessions.Logout(c); err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusSeeOther, auth.LoginPath)
+}
+
+// SwitchWorkspace makes the session work in another workspace of the user and
+// returns to the bills page, as the page shown may not exist in the new one
+func (h *AuthHandler) SwitchWorkspace(c echo.Context) error {
+	id, err := strconv.ParseInt(c.FormValue("workspace_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid workspace_id")
+	}
+
+	err = h.sessions.SwitchWorkspace(c, id)
+	if errors.Is(err, auth.ErrNotMember) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/")
+}
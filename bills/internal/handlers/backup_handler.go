@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bills/db"
+	"bills/internal/archive"
+	"bills/internal/auth"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BackupHandler handles HTTP requests for the backups page. Admins download
+// the archive of their own workspace. Snapshots of the whole database hold
+// every workspace and the credentials of every user, so only instance admins
+// take and download them
+type BackupHandler struct {
+	backups *db.Backups
+	uow     repository.UnitOfWork
+}
+
+// NewBackupHandler creates a new BackupHandler instance
+func NewBackupHandler(backups *db.Backups, uow repository.UnitOfWork) *BackupHandler {
+	return &BackupHandler{backups: backups, uow: uow}
+}
+
+// RenderBackups renders the backups page, with the snapshots for instance
+// admins
+func (h *BackupHandler) RenderBackups(c echo.Context) error {
+	var files []db.BackupFile
+	if auth.CurrentUser(c).Can(models.PermissionManageSnapshots) {
+		var err error
+		if files, err = h.backups.List(); err != nil {
+			return err
+		}
+	}
+
+	return c.Render(http.StatusOK, "backups.html", map[string]interface{}{
+		"Backups": files,
+	})
+}
+
+// CreateBackup takes a snapshot of the database and returns the updated list
+func (h *BackupHandler) CreateBackup(c echo.Context) error {
+	_, err := h.backups.Create(c.Request().Context())
+	if errors.Is(err, db.ErrBackupUnsupported) {
+		// Rendered as a success so HTMX swaps the message in
+		return h.renderList(c, "Snapshots need SQLite, back up PostgreSQL with pg_dump or download an archive.")
+	}
+	if err != nil {
+		return err
+	}
+	return h.renderList(c, "")
+}
+
+// DownloadBackup sends a snapshot
+func (h *BackupHandler) DownloadBackup(c echo.Context) error {
+	path, err := h.backups.Path(c.Param("name"))
+	if errors.Is(err, os.ErrNotExist) {
+		return echo.NewHTTPError(http.StatusNotFound, "backup not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.Attachment(path, c.Param("name"))
+}
+
+// DownloadArchive sends the JSON archive of the workspace of the request
+func (h *BackupHandler) DownloadArchive(c echo.Context) error {
+	exported, err := archive.ExportWorkspace(c.Request().Context(), h.uow, auth.CurrentWorkspace(c))
+	if err != nil {
+		return err
+	}
+
+	name := "bills-" + exported.ExportedAt.Format("20060102-150405") + ".json"
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`"`)
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	c.Response().WriteHeader(http.StatusOK)
+	return archive.Write(c.Response(), exported)
+}
+
+// renderList returns the backups list partial, with an error message when the
+// snapshot was refused
+func (h *BackupHandler) renderList(c echo.Context, message string) error {
+	files, err := h.backups.List()
+	if err != nil {
+		return err
+	}
+
+	return c.Render(http.StatusOK, "backups-list", map[string]interface{}{
+		"Backups": files,
+		"Error":   message,
+	})
+}
@@ -3,186 +3,264 @@ package handlers
 import (
 	"bills/internal/models"
 	"bills/internal/repository"
+	"errors"
+	"fmt"
 	"html/template"
+	"maps"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
-// BillHandler handles HTTP requests for bills
+// billsPageSize is how many bills the bills list shows at once
+const billsPageSize = 50
+
+// defaultBillSort is the order of the bills list, latest due first
+const defaultBillSort = "-" + models.BillSortDueDate
+
+// BillHandler handles HTTP requests for bills. Changes that read a bill
+// before writing it run in a unit of work
 type BillHandler struct {
-	repo               repository.BillRepository
-	receiverRepo       repository.ReceiverRepository
-	issuerRepo         repository.IssuerRepository
-	billItemRepo       repository.BillItemRepository
-	billItemAssignRepo repository.BillItemAssignmentRepository
-	tmpl               *template.Template
+	uow          repository.UnitOfWork
+	repo         repository.BillRepository
+	receiverRepo repository.ReceiverRepository
+	issuerRepo   repository.IssuerRepository
+	billItemRepo repository.BillItemRepository
+	auditRepo    repository.AuditRepository
+	tmpl         *template.Template
 }
 
 // NewBillHandler creates a new BillHandler instance
 func NewBillHandler(
+	uow repository.UnitOfWork,
 	repo repository.BillRepository,
 	receiverRepo repository.ReceiverRepository,
 	issuerRepo repository.IssuerRepository,
 	billItemRepo repository.BillItemRepository,
-	billItemAssignRepo repository.BillItemAssignmentRepository,
+	auditRepo repository.AuditRepository,
 	tmpl *template.Template,
 ) *BillHandler {
 	return &BillHandler{
-		repo:               repo,
-		receiverRepo:       receiverRepo,
-		issuerRepo:         issuerRepo,
-		billItemRepo:       billItemRepo,
-		billItemAssignRepo: billItemAssignRepo,
-		tmpl:               tmpl,
+		uow:          uow,
+		repo:         repo,
+		receiverRepo: receiverRepo,
+		issuerRepo:   issuerRepo,
+		billItemRepo: billItemRepo,
+		auditRepo:    auditRepo,
+		tmpl:         tmpl,
 	}
 }
 
 // RenderBills renders the bills list template
 func (h *BillHandler) RenderBills(c echo.Context) error {
-	bills, err := h.repo.GetAll()
+	data, err := h.pageData(c)
 	if err != nil {
 		return err
 	}
 
-	receivers, err := h.receiverRepo.GetAll()
+	return c.Render(http.StatusOK, "bills.html", data)
+}
+
+// pageData loads what the bills page and its bill form show
+func (h *BillHandler) pageData(c echo.Context) (map[string]interface{}, error) {
+	list, err := h.listData(c, listParams(c))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	issuers, err := h.issuerRepo.GetAll()
+	data, err := h.formData(c)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	maps.Copy(data, list)
+	data["Today"] = time.Now().Format("2006-01-02")
+	return data, nil
+}
 
-	billItems, err := h.billItemRepo.GetAll()
+// listData loads the page of the bills list that params asks for, with what
+// its filters and column headers show
+func (h *BillHandler) listData(c echo.Context, params url.Values) (map[string]interface{}, error) {
+	filter, err := billFilter(params)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	page, err := h.repo.List(c.Request().Context(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := filter.Sort
+	if sort == "" {
+		sort = defaultBillSort
+	}
+	return map[string]interface{}{
+		"Bills":    page.Bills,
+		"Next":     page.Next,
+		"Query":    params,
+		"Sort":     sort,
+		"Sorts":    sortToggles(sort, models.BillSorts()),
+		"Statuses": models.BillStatuses(),
+	}, nil
+}
+
+// billFilter reads the filters, sort and page of the bills list from params
+func billFilter(params url.Values) (models.BillFilter, error) {
+	var p formParser
+	filter := models.BillFilter{
+		Status:     params.Get("status"),
+		IssuerID:   p.id("issuer_id", params.Get("issuer_id")),
+		ReceiverID: p.id("receiver_id", params.Get("receiver_id")),
+		Currency:   params.Get("currency"),
+		DueFrom:    p.date("due_from", params.Get("due_from")),
+		DueTo:      p.date("due_to", params.Get("due_to")),
+		MinAmount:  p.float("min_amount", params.Get("min_amount")),
+		MaxAmount:  p.float("max_amount", params.Get("max_amount")),
+		Text:       params.Get("q"),
+		Sort:       params.Get("sort"),
+		After:      p.id("after", params.Get("after")),
+		Limit:      billsPageSize,
+		// The rows load their lines when they are expanded
+		SkipItems: true,
+	}
+	return filter, p.validate(&filter)
+}
+
+// formData loads the choices of the bill forms
+func (h *BillHandler) formData(c echo.Context) (map[string]interface{}, error) {
+	receivers, err := h.receiverRepo.GetAll(c.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+
+	issuers, err := h.issuerRepo.GetAll(c.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+
+	billItems, err := h.billItemRepo.GetAll(c.Request().Context())
+	if err != nil {
+		return nil, err
 	}
 
-	return c.Render(http.StatusOK, "bills.html", map[string]interface{}{
-		"Bills":               bills,
+	return map[string]interface{}{
 		"Receivers":           receivers,
 		"Issuers":             issuers,
 		"Items":               billItems,
-		"Today":               time.Now().Format("2006-01-02"),
 		"SupportedCurrencies": models.SupportedCurrencies(),
 		"DefaultCurrency":     models.DefaultCurrency(),
-	})
+	}, nil
 }
 
-// GetBillsList returns the bills list partial for HTMX updates
-func (h *BillHandler) GetBillsList(c echo.Context) error {
-	bills, err := h.repo.GetAll()
+// RenderBill renders the detail page of a bill with its parties, lines and
+// the history of changes to it and its lines
+func (h *BillHandler) RenderBill(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	return c.Render(http.StatusOK, "bills-list.html", map[string]interface{}{
-		"Bills": bills,
-	})
-}
-
-// CreateBill handles the creation of a new bill
-func (h *BillHandler) CreateBill(c echo.Context) error {
-	// Parse form data
-	dueDate, err := time.Parse("2006-01-02", c.FormValue("due_date"))
+	ctx := c.Request().Context()
+	bill, err := h.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	issuerID, err := strconv.ParseInt(c.FormValue("issuer_id"), 10, 64)
-	if err != nil {
+	// Bills keep parties that were trashed since, only their names are known
+	data := map[string]interface{}{"Bill": bill}
+	bill.Issuer, err = h.issuerRepo.GetByID(ctx, bill.IssuerID)
+	if errors.Is(err, models.ErrNotFound) {
+		bill.Issuer = &models.Issuer{ID: bill.IssuerID, Name: bill.IssuerName}
+		data["IssuerTrashed"] = true
+	} else if err != nil {
+		return err
+	}
+	bill.Receiver, err = h.receiverRepo.GetByID(ctx, bill.ReceiverID)
+	if errors.Is(err, models.ErrNotFound) {
+		bill.Receiver = &models.Receiver{ID: bill.ReceiverID, Name: bill.ReceiverName}
+		data["ReceiverTrashed"] = true
+	} else if err != nil {
 		return err
 	}
 
-	receiverID, err := strconv.ParseInt(c.FormValue("receiver_id"), 10, 64)
+	history, err := h.auditRepo.List(ctx, models.AuditFilter{BillID: bill.ID, Limit: auditPageSize})
 	if err != nil {
 		return err
 	}
+	data["History"] = history
 
-	// Create the bill
-	bill := models.NewBill(dueDate, issuerID, receiverID)
+	return c.Render(http.StatusOK, "bill.html", data)
+}
 
-	// Parse bill item assignments
-	itemIDs := c.Request().Form["item_ids[]"]
-	quantities := c.Request().Form["quantities[]"]
-	prices := c.Request().Form["prices[]"]
-	currencies := c.Request().Form["currencies[]"]
-	exchangeRates := c.Request().Form["exchange_rates[]"]
+// GetBillsList returns the bills list partial for HTMX updates, filtered,
+// sorted and paged by the query parameters, and shows its URL in the address
+// bar. Further pages come as rows to append to the list
+func (h *BillHandler) GetBillsList(c echo.Context) error {
+	params := listParams(c)
+	data, err := h.listData(c, params)
+	if err != nil {
+		return err
+	}
 
-	// Track unique currencies
-	uniqueCurrencies := make(map[string]bool)
+	if params.Get("after") != "" {
+		return c.Render(http.StatusOK, "bills-rows", data)
+	}
+	if c.Request().Method == http.MethodGet {
+		pushList(c, "/bills", params)
+	}
+	return c.Render(http.StatusOK, "bills-list", data)
+}
 
-	// Create bill item assignments
-	for i := range itemIDs {
-		itemID, err := strconv.ParseInt(itemIDs[i], 10, 64)
-		if err != nil {
-			continue
-		}
+// GetBillLines returns the lines of a bill for its row in the bills list,
+// which loads them the first time it is expanded
+func (h *BillHandler) GetBillLines(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
 
-		quantity, err := strconv.Atoi(quantities[i])
-		if err != nil {
-			continue
-		}
+	bill, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
 
-		price, err := strconv.ParseFloat(prices[i], 64)
-		if err != nil {
-			continue
-		}
+	return c.Render(http.StatusOK, "bill-lines", map[string]interface{}{
+		"Items": bill.Items,
+	})
+}
 
-		currency := currencies[i]
-		if currency == "" || !models.IsSupportedCurrency(currency) {
-			currency = models.DefaultCurrency()
-		}
-		uniqueCurrencies[currency] = true
-
-		exchangeRate, err := strconv.ParseFloat(exchangeRates[i], 64)
-		if err != nil || exchangeRate <= 0 {
-			if models.IsDefaultCurrency(currency) {
-				exchangeRate = 1.0
-			} else {
-				// TODO: Get exchange rate from service
-				exchangeRate = 1.0
-			}
-		}
+// CreateBill handles the creation of a new bill. Nothing is saved unless
+// the bill and every line are valid
+func (h *BillHandler) CreateBill(c echo.Context) error {
+	var p formParser
+	issueDate := p.date("issue_date", c.FormValue("issue_date"))
+	dueDate := p.date("due_date", c.FormValue("due_date"))
+	issuerID := p.id("issuer_id", c.FormValue("issuer_id"))
+	receiverID := p.id("receiver_id", c.FormValue("receiver_id"))
 
-		assignment := models.NewBillItemAssignment(0, itemID, quantity, price, currency, exchangeRate)
-		bill.Items = append(bill.Items, assignment)
+	bill := models.NewBill(dueDate, issuerID, receiverID)
+	if !issueDate.IsZero() {
+		bill.IssueDate = issueDate
 	}
 
-	// Set bill currency based on items
-	if len(uniqueCurrencies) == 1 {
-		// If all items have the same currency, use that
-		for currency := range uniqueCurrencies {
-			bill.Currency = currency
-		}
-	} else {
-		// If items have different currencies, use EUR
-		bill.Currency = models.DefaultCurrency()
-	}
+	bill.Items = parseLines(&p, c.Request().Form)
 
-	// Calculate totals
+	// Set bill currency based on items and calculate totals
+	bill.ResolveCurrency()
 	bill.CalculateTotals()
 
-	// Save the bill
-	if err := h.repo.Create(bill); err != nil {
-		return err
+	if err := p.validate(bill); err != nil {
+		return h.renderInvalid(c, err, "#bill-form", "bill-form", nil)
 	}
 
-	// Get issuer and receiver names
-	issuer, err := h.issuerRepo.GetByID(issuerID)
-	if err != nil {
-		return err
-	}
-	receiver, err := h.receiverRepo.GetByID(receiverID)
-	if err != nil {
+	// Save the bill
+	if err := h.repo.Create(c.Request().Context(), bill); err != nil {
 		return err
 	}
-	bill.IssuerName = issuer.Name
-	bill.ReceiverName = receiver.Name
 
 	// If it's an HTMX request, return the updated list
 	if c.Request().Header.Get("HX-Request") == "true" {
@@ -195,37 +273,118 @@ func (h *BillHandler) CreateBill(c echo.Context) error {
 // TogglePaid handles toggling the paid status of a bill
 func (h *BillHandler) TogglePaid(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	ctx := c.Request().Context()
+	err = h.uow.Do(ctx, func(tx *repository.Tx) error {
+		bill, err := tx.Bills.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		bill.Paid = !bill.Paid
+		return tx.Bills.Update(ctx, bill)
+	})
 	if err != nil {
 		return err
 	}
 
-	bill, err := h.repo.GetByID(id)
+	return h.GetBillsList(c)
+}
+
+// EditBill returns the inline form for editing a bill and its lines
+func (h *BillHandler) EditBill(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	bill, err := h.repo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
-	bill.Paid = !bill.Paid
-	if err := h.repo.Update(bill); err != nil {
+	form := url.Values{
+		"issue_date":  {bill.IssueDate.Format("2006-01-02")},
+		"due_date":    {bill.DueDate.Format("2006-01-02")},
+		"issuer_id":   {strconv.FormatInt(bill.IssuerID, 10)},
+		"receiver_id": {strconv.FormatInt(bill.ReceiverID, 10)},
+	}
+	for _, item := range bill.Items {
+		form.Add("line_ids[]", strconv.FormatInt(item.ID, 10))
+		form.Add("item_ids[]", strconv.FormatInt(item.ItemID, 10))
+		form.Add("quantities[]", strconv.Itoa(item.Quantity))
+		form.Add("prices[]", strconv.FormatFloat(item.Price, 'f', 2, 64))
+		form.Add("currencies[]", item.Currency)
+		form.Add("exchange_rates[]", strconv.FormatFloat(item.ExchangeRate, 'f', -1, 64))
+	}
+
+	data, err := h.formData(c)
+	if err != nil {
 		return err
 	}
+	h.addLines(data, form, nil, bill)
+	data["Form"] = form
 
-	return h.GetBillsList(c)
+	return c.Render(http.StatusOK, "bill-edit-form", data)
 }
 
-// DeleteBill handles the deletion of a bill
-func (h *BillHandler) DeleteBill(c echo.Context) error {
+// UpdateBill handles updating a bill together with its lines. Lines are
+// added, changed and removed to match the form and the totals recalculated,
+// all in one transaction
+func (h *BillHandler) UpdateBill(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	// Delete bill item assignments first
-	if err := h.billItemAssignRepo.DeleteByBillID(id); err != nil {
+	// The form is checked against the bill as it is in the transaction, an
+	// invalid one changes nothing and is shown again with the current bill
+	ctx := c.Request().Context()
+	var current models.Bill
+	var invalid error
+	err = h.uow.Do(ctx, func(tx *repository.Tx) error {
+		bill, err := tx.Bills.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		current = *bill
+
+		var p formParser
+		bill.IssueDate = p.date("issue_date", c.FormValue("issue_date"))
+		bill.DueDate = p.date("due_date", c.FormValue("due_date"))
+		bill.IssuerID = p.id("issuer_id", c.FormValue("issuer_id"))
+		bill.ReceiverID = p.id("receiver_id", c.FormValue("receiver_id"))
+		bill.Items = parseLines(&p, c.Request().Form)
+
+		bill.ResolveCurrency()
+		bill.CalculateTotals()
+
+		if invalid = p.validate(bill); invalid != nil {
+			return nil
+		}
+		return tx.Bills.UpdateWithItems(ctx, bill)
+	})
+	if err != nil {
 		return err
 	}
+	if invalid != nil {
+		return h.renderInvalid(c, invalid, fmt.Sprintf("#bill-%d-edit", id), "bill-edit-form", &current)
+	}
 
-	// Then delete the bill
-	if err := h.repo.Delete(id); err != nil {
+	return h.GetBillsList(c)
+}
+
+// DeleteBill handles the deletion of a bill
+func (h *BillHandler) DeleteBill(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	// The bill goes to the trash with its lines
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
 		return err
 	}
 
@@ -237,3 +396,132 @@ func (h *BillHandler) DeleteBill(c echo.Context) error {
 	// For regular requests, redirect to the root URL
 	return c.Redirect(http.StatusSeeOther, "/")
 }
+
+// parseLines reads the lines of a bill form. Lines keep the id they were
+// sent with, zero for new ones
+func parseLines(p *formParser, form url.Values) []*models.BillItemAssignment {
+	var lines []*models.BillItemAssignment
+	for i, value := range form["item_ids[]"] {
+		field := func(name string) string {
+			return fmt.Sprintf("items[%d].%s", i, name)
+		}
+
+		lineID := p.id(field("id"), formIndex(form["line_ids[]"], i))
+		itemID := p.id(field("item_id"), value)
+		quantity := p.int(field("quantity"), formIndex(form["quantities[]"], i))
+		price := p.float(field("price"), formIndex(form["prices[]"], i))
+
+		// The currency and exchange rate default when left out, but values
+		// that were sent must be usable
+		currency := formIndex(form["currencies[]"], i)
+		if currency == "" {
+			currency = models.DefaultCurrency()
+		} else if !models.IsSupportedCurrency(currency) {
+			p.fail(field("currency"), fmt.Sprintf("%q is not supported", currency))
+		}
+		exchangeRate := 1.0
+		if value := formIndex(form["exchange_rates[]"], i); value != "" {
+			if exchangeRate = p.float(field("exchange_rate"), value); exchangeRate <= 0 {
+				p.fail(field("exchange_rate"), "must be greater than zero")
+			}
+		}
+
+		line := models.NewBillItemAssignment(0, itemID, quantity, price, currency, exchangeRate)
+		line.ID = lineID
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// billLine is a line of the bill form as it was sent, shown again when the
+// form is invalid. Archived is set when its item is no longer in the catalog
+type billLine struct {
+	LineID       string
+	ItemID       string
+	Name         string
+	Quantity     string
+	Price        string
+	Currency     string
+	ExchangeRate string
+	Archived     bool
+	Error        string
+}
+
+// addLines adds the lines of form to the data of a bill form, each with the
+// first error of fields about it. bill is the bill being edited, nil for a
+// new one; its parties and items stay selectable after they were trashed
+func (h *BillHandler) addLines(data map[string]interface{}, form url.Values, fields map[string]string, bill *models.Bill) {
+	names := make(map[string]string)
+	live := make(map[string]bool)
+	for _, item := range data["Items"].([]*models.BillItem) {
+		id := strconv.FormatInt(item.ID, 10)
+		names[id] = item.Name
+		live[id] = true
+	}
+
+	if bill != nil {
+		data["ID"] = bill.ID
+		for _, item := range bill.Items {
+			id := strconv.FormatInt(item.ItemID, 10)
+			if _, ok := names[id]; !ok && item.BillItem != nil {
+				names[id] = item.BillItem.Name
+			}
+		}
+		data["ArchivedIssuer"] = &models.Issuer{ID: bill.IssuerID, Name: bill.IssuerName}
+		for _, issuer := range data["Issuers"].([]*models.Issuer) {
+			if issuer.ID == bill.IssuerID {
+				delete(data, "ArchivedIssuer")
+			}
+		}
+		data["ArchivedReceiver"] = &models.Receiver{ID: bill.ReceiverID, Name: bill.ReceiverName}
+		for _, receiver := range data["Receivers"].([]*models.Receiver) {
+			if receiver.ID == bill.ReceiverID {
+				delete(data, "ArchivedReceiver")
+			}
+		}
+	}
+
+	var lines []billLine
+	for i, itemID := range form["item_ids[]"] {
+		line := billLine{
+			LineID:       formIndex(form["line_ids[]"], i),
+			ItemID:       itemID,
+			Name:         names[itemID],
+			Quantity:     formIndex(form["quantities[]"], i),
+			Price:        formIndex(form["prices[]"], i),
+			Currency:     formIndex(form["currencies[]"], i),
+			ExchangeRate: formIndex(form["exchange_rates[]"], i),
+			Archived:     itemID != "" && !live[itemID],
+		}
+		for _, name := range []string{"id", "item_id", "quantity", "price", "currency", "exchange_rate"} {
+			if message, ok := fields[fmt.Sprintf("items[%d].%s", i, name)]; ok {
+				line.Error = strings.ReplaceAll(name, "_", " ") + " " + message
+				break
+			}
+		}
+		lines = append(lines, line)
+	}
+	data["Lines"] = lines
+}
+
+// renderInvalid sends the bill form named form back with what is wrong with
+// it, its lines included, in place of target. bill is the bill being edited,
+// nil for a new one
+func (h *BillHandler) renderInvalid(c echo.Context, err error, target, form string, bill *models.Bill) error {
+	data, lerr := h.pageData(c)
+	if lerr != nil {
+		return lerr
+	}
+	h.addLines(data, c.Request().Form, models.FieldErrors(err).Fields(), bill)
+
+	return renderInvalid(c, err, target, form, "bills.html", data)
+}
+
+// formIndex returns the i-th value of a repeated form field, blank when the
+// form sent fewer values
+func formIndex(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
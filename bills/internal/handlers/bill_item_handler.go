@@ -3,8 +3,11 @@ package handlers
 import (
 	"bills/internal/models"
 	"bills/internal/repository"
+	"fmt"
 	"html/template"
+	"maps"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -12,52 +15,93 @@ import (
 
 // BillItemHandler handles HTTP requests for bill items
 type BillItemHandler struct {
+	uow  repository.UnitOfWork
 	repo repository.BillItemRepository
 	tmpl *template.Template
 }
 
 // NewBillItemHandler creates a new BillItemHandler instance
-func NewBillItemHandler(repo repository.BillItemRepository, tmpl *template.Template) *BillItemHandler {
+func NewBillItemHandler(uow repository.UnitOfWork, repo repository.BillItemRepository, tmpl *template.Template) *BillItemHandler {
 	return &BillItemHandler{
+		uow:  uow,
 		repo: repo,
 		tmpl: tmpl,
 	}
 }
 
+// billItemsPageSize is how many bill items the bill items list shows at once
+const billItemsPageSize = 50
+
 // RenderBillItems renders the bill items list template
 func (h *BillItemHandler) RenderBillItems(c echo.Context) error {
-	items, err := h.repo.GetAll()
+	data, err := h.listData(c, listParams(c))
 	if err != nil {
 		return err
 	}
 
-	return c.Render(http.StatusOK, "bill-items.html", map[string]interface{}{
-		"Items":               items,
-		"SupportedCurrencies": models.SupportedCurrencies(),
-		"DefaultCurrency":     models.DefaultCurrency(),
-	})
+	data["SupportedCurrencies"] = models.SupportedCurrencies()
+	data["DefaultCurrency"] = models.DefaultCurrency()
+	return c.Render(http.StatusOK, "bill-items.html", data)
 }
 
-// GetBillItemsList returns the bill items list partial for HTMX updates
+// GetBillItemsList returns the bill items list partial for HTMX updates,
+// filtered, sorted and paged like the bills list
 func (h *BillItemHandler) GetBillItemsList(c echo.Context) error {
-	items, err := h.repo.GetAll()
+	params := listParams(c)
+	data, err := h.listData(c, params)
 	if err != nil {
 		return err
 	}
 
-	return c.Render(http.StatusOK, "bill-items-list.html", map[string]interface{}{
-		"Items": items,
-	})
+	if params.Get("after") != "" {
+		return c.Render(http.StatusOK, "bill-items-rows", data)
+	}
+	if c.Request().Method == http.MethodGet {
+		pushList(c, "/bill-items", params)
+	}
+	return c.Render(http.StatusOK, "bill-items-list", data)
+}
+
+// listData loads the page of the bill items list that params asks for
+func (h *BillItemHandler) listData(c echo.Context, params url.Values) (map[string]interface{}, error) {
+	var p formParser
+	filter := models.BillItemFilter{
+		Currency: params.Get("currency"),
+		Text:     params.Get("q"),
+		Sort:     params.Get("sort"),
+		After:    p.id("after", params.Get("after")),
+		Limit:    billItemsPageSize,
+	}
+	if err := p.validate(&filter); err != nil {
+		return nil, err
+	}
+
+	page, err := h.repo.List(c.Request().Context(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := filter.Sort
+	if sort == "" {
+		sort = models.BillItemSortName
+	}
+	return map[string]interface{}{
+		"Items": page.Items,
+		"Next":  page.Next,
+		"Query": params,
+		"Sort":  sort,
+		"Sorts": sortToggles(sort, models.BillItemSorts()),
+	}, nil
 }
 
 // GetBillItemsSelect returns a select dropdown with bill items for HTMX updates
 func (h *BillItemHandler) GetBillItemsSelect(c echo.Context) error {
-	items, err := h.repo.GetAll()
+	items, err := h.repo.GetAll(c.Request().Context())
 	if err != nil {
 		return err
 	}
 
-	return c.Render(http.StatusOK, "bill-items-select.html", map[string]interface{}{
+	return c.Render(http.StatusOK, "bill-items-select", map[string]interface{}{
 		"Items":               items,
 		"SupportedCurrencies": models.SupportedCurrencies(),
 		"DefaultCurrency":     models.DefaultCurrency(),
@@ -66,23 +110,23 @@ func (h *BillItemHandler) GetBillItemsSelect(c echo.Context) error {
 
 // CreateBillItem handles the creation of a new bill item
 func (h *BillItemHandler) CreateBillItem(c echo.Context) error {
-	price, err := strconv.ParseFloat(c.FormValue("price"), 64)
-	if err != nil {
-		return err
-	}
-
-	currency := c.FormValue("currency")
-	if currency == "" || !models.IsSupportedCurrency(currency) {
-		currency = models.DefaultCurrency()
-	}
+	var p formParser
+	price := p.float("price", c.FormValue("price"))
 
 	item := models.NewBillItem(
 		c.FormValue("name"),
 		price,
-		currency,
+		models.DefaultCurrency(),
 	)
+	if currency := c.FormValue("currency"); currency != "" {
+		item.Currency = currency
+	}
 
-	if err := h.repo.Create(item); err != nil {
+	if err := p.validate(item); err != nil {
+		return h.renderInvalid(c, err, "#bill-item-form", "bill-item-form", map[string]interface{}{})
+	}
+
+	if err := h.repo.Create(c.Request().Context(), item); err != nil {
 		return err
 	}
 
@@ -94,35 +138,65 @@ func (h *BillItemHandler) CreateBillItem(c echo.Context) error {
 	return c.Redirect(http.StatusSeeOther, "/bill-items")
 }
 
-// UpdateBillItem handles updating a bill item
-func (h *BillItemHandler) UpdateBillItem(c echo.Context) error {
+// EditBillItem returns the inline form for editing a bill item
+func (h *BillItemHandler) EditBillItem(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	item, err := h.repo.GetByID(id)
+	item, err := h.repo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
-	price, err := strconv.ParseFloat(c.FormValue("price"), 64)
-	if err != nil {
-		return err
-	}
+	return c.Render(http.StatusOK, "bill-item-edit-form", map[string]interface{}{
+		"ID": item.ID,
+		"Form": url.Values{
+			"name":     {item.Name},
+			"price":    {strconv.FormatFloat(item.Price, 'f', 2, 64)},
+			"currency": {item.Currency},
+		},
+		"SupportedCurrencies": models.SupportedCurrencies(),
+	})
+}
 
-	currency := c.FormValue("currency")
-	if currency == "" || !models.IsSupportedCurrency(currency) {
-		currency = models.DefaultCurrency()
+// UpdateBillItem handles updating a bill item
+func (h *BillItemHandler) UpdateBillItem(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	item.Name = c.FormValue("name")
-	item.Price = price
-	item.Currency = currency
-
-	if err := h.repo.Update(item); err != nil {
+	ctx := c.Request().Context()
+	var invalid error
+	err = h.uow.Do(ctx, func(tx *repository.Tx) error {
+		item, err := tx.BillItems.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		var p formParser
+		item.Name = c.FormValue("name")
+		item.Price = p.float("price", c.FormValue("price"))
+		if currency := c.FormValue("currency"); currency != "" {
+			item.Currency = currency
+		}
+
+		// An invalid form changes nothing
+		if invalid = p.validate(item); invalid != nil {
+			return nil
+		}
+		return tx.BillItems.Update(ctx, item)
+	})
+	if err != nil {
 		return err
 	}
+	if invalid != nil {
+		return h.renderInvalid(c, invalid, fmt.Sprintf("#bill-item-%d-edit", id), "bill-item-edit-form", map[string]interface{}{
+			"ID": id,
+		})
+	}
 
 	return h.GetBillItemsList(c)
 }
@@ -131,12 +205,26 @@ func (h *BillItemHandler) UpdateBillItem(c echo.Context) error {
 func (h *BillItemHandler) DeleteBillItem(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	if err := h.repo.Delete(id); err != nil {
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
 		return err
 	}
 
 	return h.GetBillItemsList(c)
 }
+
+// renderInvalid sends the bill item form named form back with what is wrong
+// with it, in place of target
+func (h *BillItemHandler) renderInvalid(c echo.Context, err error, target, form string, data map[string]interface{}) error {
+	list, lerr := h.listData(c, listParams(c))
+	if lerr != nil {
+		return lerr
+	}
+
+	maps.Copy(data, list)
+	data["SupportedCurrencies"] = models.SupportedCurrencies()
+	data["DefaultCurrency"] = models.DefaultCurrency()
+	return renderInvalid(c, err, target, form, "bill-items.html", data)
+}
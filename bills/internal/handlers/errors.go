@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bills/internal/api"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorHandler is the echo.HTTPErrorHandler of the server. Errors get the
+// status api.HTTPError gives them and are reported as JSON to API clients, as
+// a message HTMX swaps into the errors area of the page and as an error page
+// to browsers. Internal errors are logged and their details kept from the
+// client
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	he := api.HTTPError(err)
+	if he == nil {
+		c.Logger().Error(err)
+		he = echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	message := fmt.Sprint(he.Message)
+	data := map[string]interface{}{
+		"Status":     he.Code,
+		"StatusText": http.StatusText(he.Code),
+		"Message":    message,
+	}
+
+	req := c.Request()
+	switch {
+	case req.Method == http.MethodHead:
+		err = c.NoContent(he.Code)
+	case api.WantsJSON(req):
+		err = c.JSON(he.Code, api.NewErrorResponse(he, err))
+	case req.Header.Get("HX-Request") == "true":
+		// The pages let HTMX swap error responses aimed at #errors
+		c.Response().Header().Set("HX-Retarget", "#errors")
+		c.Response().Header().Set("HX-Reswap", "innerHTML")
+		err = c.Render(he.Code, "error-message", data)
+	default:
+		err = c.Render(he.Code, "error.html", data)
+	}
+
+	if err != nil && !c.Response().Committed {
+		// Fall back to plain text when the templates cannot be rendered
+		err = c.String(he.Code, message)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bills/internal/api"
+	"bills/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// formParser reads typed values from a form. Values that cannot be parsed
+// are collected as validation errors, so they are reported together with
+// those of the record built from the form. Blank values are left to the
+// Validate method of the record
+type formParser struct {
+	errs models.ValidationErrors
+}
+
+// fail records a field that cannot be parsed
+func (p *formParser) fail(field, message string) {
+	p.errs = append(p.errs, &models.ValidationError{Field: field, Message: message})
+}
+
+// date parses a date from a date input, zero when blank
+func (p *formParser) date(field, value string) time.Time {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		p.fail(field, "must be a date")
+	}
+	return t
+}
+
+// id parses the id of a referenced record, zero when blank
+func (p *formParser) id(field, value string) int64 {
+	if strings.TrimSpace(value) == "" {
+		return 0
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		p.fail(field, "must be a number")
+	}
+	return id
+}
+
+// int parses a whole number, zero when blank
+func (p *formParser) int(field, value string) int {
+	if strings.TrimSpace(value) == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		p.fail(field, "must be a whole number")
+	}
+	return n
+}
+
+// float parses a number, zero when blank
+func (p *formParser) float(field, value string) float64 {
+	if strings.TrimSpace(value) == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		p.fail(field, "must be a number")
+	}
+	return f
+}
+
+// validate returns the parse errors followed by those of the record, nil
+// when the form is valid. Fields that could not be parsed are only reported
+// once
+func (p *formParser) validate(record interface{ Validate() error }) error {
+	failed := p.errs.Fields()
+	errs := p.errs
+	for _, err := range models.FieldErrors(record.Validate()) {
+		if _, ok := failed[err.Field]; !ok {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// renderInvalid answers a form that failed validation with a 422 and a
+// message under every invalid field. HTMX gets the form back in place of
+// target, the element it posted from, browsers the whole page with the form
+// open. Both show the values that were sent. JSON clients and other errors
+// are left to ErrorHandler
+func renderInvalid(c echo.Context, err error, target, form, page string, data map[string]interface{}) error {
+	errs := models.FieldErrors(err)
+	if errs == nil || api.WantsJSON(c.Request()) {
+		return err
+	}
+
+	data["Errors"] = errs.Fields()
+	data["Form"] = c.Request().Form
+	if c.Request().Header.Get("HX-Request") == "true" {
+		c.Response().Header().Set("HX-Retarget", target)
+		c.Response().Header().Set("HX-Reswap", "outerHTML")
+		return c.Render(http.StatusUnprocessableEntity, form, data)
+	}
+	return c.Render(http.StatusUnprocessableEntity, page, data)
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"bills/internal/models"
 	"bills/internal/repository"
+	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -12,21 +14,25 @@ import (
 
 // IssuerHandler handles HTTP requests for issuers
 type IssuerHandler struct {
-	repo repository.IssuerRepository
-	tmpl *template.Template
+	uow      repository.UnitOfWork
+	repo     repository.IssuerRepository
+	billRepo repository.BillRepository
+	tmpl     *template.Template
 }
 
 // NewIssuerHandler creates a new IssuerHandler instance
-func NewIssuerHandler(repo repository.IssuerRepository, tmpl *template.Template) *IssuerHandler {
+func NewIssuerHandler(uow repository.UnitOfWork, repo repository.IssuerRepository, billRepo repository.BillRepository, tmpl *template.Template) *IssuerHandler {
 	return &IssuerHandler{
-		repo: repo,
-		tmpl: tmpl,
+		uow:      uow,
+		repo:     repo,
+		billRepo: billRepo,
+		tmpl:     tmpl,
 	}
 }
 
 // RenderIssuers renders the issuers list template
 func (h *IssuerHandler) RenderIssuers(c echo.Context) error {
-	issuers, err := h.repo.GetAll()
+	issuers, err := h.repo.GetAll(c.Request().Context())
 	if err != nil {
 		return err
 	}
@@ -36,21 +42,56 @@ func (h *IssuerHandler) RenderIssuers(c echo.Context) error {
 	})
 }
 
+// RenderIssuer renders the page of an issuer with its bills and what it
+// was billed, paid and still owes, filtered by the status query parameter
+func (h *IssuerHandler) RenderIssuer(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	ctx := c.Request().Context()
+	issuer, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	bills, err := h.billRepo.GetByIssuer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	totals, err := h.billRepo.SumByIssuer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	data, err := partyData(c, bills, totals)
+	if err != nil {
+		return err
+	}
+	data["Party"] = issuer
+	data["Kind"] = "Issuer"
+	data["Path"] = "/issuers"
+
+	return c.Render(http.StatusOK, "party.html", data)
+}
+
 // GetIssuersList returns the issuers list partial for HTMX updates
 func (h *IssuerHandler) GetIssuersList(c echo.Context) error {
-	issuers, err := h.repo.GetAll()
+	issuers, err := h.repo.GetAll(c.Request().Context())
 	if err != nil {
 		return err
 	}
 
-	return c.Render(http.StatusOK, "issuers-list.html", map[string]interface{}{
+	return c.Render(http.StatusOK, "issuers-list", map[string]interface{}{
 		"Issuers": issuers,
 	})
 }
 
 // GetIssuersSelect returns a select dropdown with issuers for HTMX updates
 func (h *IssuerHandler) GetIssuersSelect(c echo.Context) error {
-	issuers, err := h.repo.GetAll()
+	issuers, err := h.repo.GetAll(c.Request().Context())
 	if err != nil {
 		return err
 	}
@@ -72,7 +113,11 @@ func (h *IssuerHandler) CreateIssuer(c echo.Context) error {
 		c.FormValue("country"),
 	)
 
-	if err := h.repo.Create(issuer); err != nil {
+	if err := issuer.Validate(); err != nil {
+		return h.renderInvalid(c, err, "#issuer-form", "issuer-form", map[string]interface{}{})
+	}
+
+	if err := h.repo.Create(c.Request().Context(), issuer); err != nil {
 		return err
 	}
 
@@ -84,29 +129,69 @@ func (h *IssuerHandler) CreateIssuer(c echo.Context) error {
 	return c.Redirect(http.StatusSeeOther, "/issuers")
 }
 
-// UpdateIssuer handles updating an issuer
-func (h *IssuerHandler) UpdateIssuer(c echo.Context) error {
+// EditIssuer returns the inline form for editing an issuer
+func (h *IssuerHandler) EditIssuer(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	issuer, err := h.repo.GetByID(id)
+	issuer, err := h.repo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
-	issuer.Name = c.FormValue("name")
-	issuer.VATNumber = c.FormValue("vat_number")
-	issuer.Street = c.FormValue("street")
-	issuer.City = c.FormValue("city")
-	issuer.State = c.FormValue("state")
-	issuer.ZipCode = c.FormValue("zip_code")
-	issuer.Country = c.FormValue("country")
+	return c.Render(http.StatusOK, "issuer-edit-form", map[string]interface{}{
+		"ID": issuer.ID,
+		"Form": url.Values{
+			"name":       {issuer.Name},
+			"vat_number": {issuer.VATNumber},
+			"street":     {issuer.Street},
+			"city":       {issuer.City},
+			"state":      {issuer.State},
+			"zip_code":   {issuer.ZipCode},
+			"country":    {issuer.Country},
+		},
+	})
+}
 
-	if err := h.repo.Update(issuer); err != nil {
+// UpdateIssuer handles updating an issuer
+func (h *IssuerHandler) UpdateIssuer(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	ctx := c.Request().Context()
+	var invalid error
+	err = h.uow.Do(ctx, func(tx *repository.Tx) error {
+		issuer, err := tx.Issuers.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		issuer.Name = c.FormValue("name")
+		issuer.VATNumber = c.FormValue("vat_number")
+		issuer.Street = c.FormValue("street")
+		issuer.City = c.FormValue("city")
+		issuer.State = c.FormValue("state")
+		issuer.ZipCode = c.FormValue("zip_code")
+		issuer.Country = c.FormValue("country")
+
+		// An invalid form changes nothing
+		if invalid = issuer.Validate(); invalid != nil {
+			return nil
+		}
+		return tx.Issuers.Update(ctx, issuer)
+	})
+	if err != nil {
 		return err
 	}
+	if invalid != nil {
+		return h.renderInvalid(c, invalid, fmt.Sprintf("#issuer-%d-edit", id), "issuer-edit-form", map[string]interface{}{
+			"ID": id,
+		})
+	}
 
 	return h.GetIssuersList(c)
 }
@@ -115,12 +200,24 @@ func (h *IssuerHandler) UpdateIssuer(c echo.Context) error {
 func (h *IssuerHandler) DeleteIssuer(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	if err := h.repo.Delete(id); err != nil {
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
 		return err
 	}
 
 	return h.GetIssuersList(c)
 }
+
+// renderInvalid sends the issuer form named form back with what is wrong
+// with it, in place of target
+func (h *IssuerHandler) renderInvalid(c echo.Context, err error, target, form string, data map[string]interface{}) error {
+	issuers, lerr := h.repo.GetAll(c.Request().Context())
+	if lerr != nil {
+		return lerr
+	}
+
+	data["Issuers"] = issuers
+	return renderInvalid(c, err, target, form, "issuers.html", data)
+}
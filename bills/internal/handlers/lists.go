@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// listParams returns the query parameters a list is filtered by. Requests
+// that change a row re-render the list the page shows, its filters are in
+// the URL of the page HTMX sends along. Those start over at the first page
+func listParams(c echo.Context) url.Values {
+	if c.Request().Method == http.MethodGet {
+		return c.QueryParams()
+	}
+	params := url.Values{}
+	if current, err := url.Parse(c.Request().Header.Get("HX-Current-URL")); err == nil {
+		params = current.Query()
+	}
+	params.Del("after")
+	return params
+}
+
+// pushList has HTMX show the URL of page with the filters of params in the
+// address bar, so a filtered list can be bookmarked. Blank filters and the
+// cursor of the page are left out
+func pushList(c echo.Context, page string, params url.Values) {
+	query := url.Values{}
+	for name, values := range params {
+		if name != "after" && len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+			query.Set(name, values[0])
+		}
+	}
+	if len(query) > 0 {
+		page += "?" + query.Encode()
+	}
+	c.Response().Header().Set("HX-Push-Url", page)
+}
+
+// sortToggles returns for every column the sort a click on its header
+// applies: ascending, or descending when the list is sorted by it ascending
+func sortToggles(sort string, columns []string) map[string]string {
+	toggles := make(map[string]string, len(columns))
+	for _, column := range columns {
+		toggles[column] = column
+		if sort == column {
+			toggles[column] = "-" + column
+		}
+	}
+	return toggles
+}
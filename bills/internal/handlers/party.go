@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"bills/internal/models"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// partyData builds the data of the page of an issuer or receiver from all of
+// its bills and their totals. The list keeps the bills with the status of the
+// status query parameter, the totals always cover every bill
+func partyData(c echo.Context, bills []*models.Bill, totals *models.BillTotals) (map[string]interface{}, error) {
+	status := c.QueryParam("status")
+	if status != "" && !slices.Contains(models.BillStatuses(), status) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+	}
+
+	now := time.Now()
+	var shown []*models.Bill
+	for _, bill := range bills {
+		if bill.HasStatus(status, now) {
+			shown = append(shown, bill)
+		}
+	}
+
+	return map[string]interface{}{
+		"Bills":    shown,
+		"Totals":   totals,
+		"Status":   status,
+		"Statuses": models.BillStatuses(),
+	}, nil
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"bills/internal/models"
 	"bills/internal/repository"
+	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -12,21 +14,25 @@ import (
 
 // ReceiverHandler handles HTTP requests for receivers
 type ReceiverHandler struct {
-	repo repository.ReceiverRepository
-	tmpl *template.Template
+	uow      repository.UnitOfWork
+	repo     repository.ReceiverRepository
+	billRepo repository.BillRepository
+	tmpl     *template.Template
 }
 
 // NewReceiverHandler creates a new ReceiverHandler instance
-func NewReceiverHandler(repo repository.ReceiverRepository, tmpl *template.Template) *ReceiverHandler {
+func NewReceiverHandler(uow repository.UnitOfWork, repo repository.ReceiverRepository, billRepo repository.BillRepository, tmpl *template.Template) *ReceiverHandler {
 	return &ReceiverHandler{
-		repo: repo,
-		tmpl: tmpl,
+		uow:      uow,
+		repo:     repo,
+		billRepo: billRepo,
+		tmpl:     tmpl,
 	}
 }
 
 // RenderReceivers renders the receivers list template
 func (h *ReceiverHandler) RenderReceivers(c echo.Context) error {
-	receivers, err := h.repo.GetAll()
+	receivers, err := h.repo.GetAll(c.Request().Context())
 	if err != nil {
 		return err
 	}
@@ -36,26 +42,61 @@ func (h *ReceiverHandler) RenderReceivers(c echo.Context) error {
 	})
 }
 
+// RenderReceiver renders the page of a receiver with its bills and what it
+// was billed, paid and still owes, filtered by the status query parameter
+func (h *ReceiverHandler) RenderReceiver(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	ctx := c.Request().Context()
+	receiver, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	bills, err := h.billRepo.GetByReceiver(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	totals, err := h.billRepo.SumByReceiver(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	data, err := partyData(c, bills, totals)
+	if err != nil {
+		return err
+	}
+	data["Party"] = receiver
+	data["Kind"] = "Receiver"
+	data["Path"] = "/receivers"
+
+	return c.Render(http.StatusOK, "party.html", data)
+}
+
 // GetReceiversList returns the receivers list partial for HTMX updates
 func (h *ReceiverHandler) GetReceiversList(c echo.Context) error {
-	receivers, err := h.repo.GetAll()
+	receivers, err := h.repo.GetAll(c.Request().Context())
 	if err != nil {
 		return err
 	}
 
-	return c.Render(http.StatusOK, "receivers-list.html", map[string]interface{}{
+	return c.Render(http.StatusOK, "receivers-list", map[string]interface{}{
 		"Receivers": receivers,
 	})
 }
 
 // GetReceiversSelect returns a select dropdown with receivers for HTMX updates
 func (h *ReceiverHandler) GetReceiversSelect(c echo.Context) error {
-	receivers, err := h.repo.GetAll()
+	receivers, err := h.repo.GetAll(c.Request().Context())
 	if err != nil {
 		return err
 	}
 
-	return c.Render(http.StatusOK, "receivers-select.html", map[string]interface{}{
+	return c.Render(http.StatusOK, "receivers-select", map[string]interface{}{
 		"Receivers": receivers,
 	})
 }
@@ -72,7 +113,11 @@ func (h *ReceiverHandler) CreateReceiver(c echo.Context) error {
 		c.FormValue("country"),
 	)
 
-	if err := h.repo.Create(receiver); err != nil {
+	if err := receiver.Validate(); err != nil {
+		return h.renderInvalid(c, err, "#receiver-form", "receiver-form", map[string]interface{}{})
+	}
+
+	if err := h.repo.Create(c.Request().Context(), receiver); err != nil {
 		return err
 	}
 
@@ -84,29 +129,69 @@ func (h *ReceiverHandler) CreateReceiver(c echo.Context) error {
 	return c.Redirect(http.StatusSeeOther, "/receivers")
 }
 
-// UpdateReceiver handles updating a receiver
-func (h *ReceiverHandler) UpdateReceiver(c echo.Context) error {
+// EditReceiver returns the inline form for editing a receiver
+func (h *ReceiverHandler) EditReceiver(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	receiver, err := h.repo.GetByID(id)
+	receiver, err := h.repo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
-	receiver.Name = c.FormValue("name")
-	receiver.VATNumber = c.FormValue("vat_number")
-	receiver.Street = c.FormValue("street")
-	receiver.City = c.FormValue("city")
-	receiver.State = c.FormValue("state")
-	receiver.ZipCode = c.FormValue("zip_code")
-	receiver.Country = c.FormValue("country")
+	return c.Render(http.StatusOK, "receiver-edit-form", map[string]interface{}{
+		"ID": receiver.ID,
+		"Form": url.Values{
+			"name":       {receiver.Name},
+			"vat_number": {receiver.VATNumber},
+			"street":     {receiver.Street},
+			"city":       {receiver.City},
+			"state":      {receiver.State},
+			"zip_code":   {receiver.ZipCode},
+			"country":    {receiver.Country},
+		},
+	})
+}
 
-	if err := h.repo.Update(receiver); err != nil {
+// UpdateReceiver handles updating a receiver
+func (h *ReceiverHandler) UpdateReceiver(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	ctx := c.Request().Context()
+	var invalid error
+	err = h.uow.Do(ctx, func(tx *repository.Tx) error {
+		receiver, err := tx.Receivers.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		receiver.Name = c.FormValue("name")
+		receiver.VATNumber = c.FormValue("vat_number")
+		receiver.Street = c.FormValue("street")
+		receiver.City = c.FormValue("city")
+		receiver.State = c.FormValue("state")
+		receiver.ZipCode = c.FormValue("zip_code")
+		receiver.Country = c.FormValue("country")
+
+		// An invalid form changes nothing
+		if invalid = receiver.Validate(); invalid != nil {
+			return nil
+		}
+		return tx.Receivers.Update(ctx, receiver)
+	})
+	if err != nil {
 		return err
 	}
+	if invalid != nil {
+		return h.renderInvalid(c, invalid, fmt.Sprintf("#receiver-%d-edit", id), "receiver-edit-form", map[string]interface{}{
+			"ID": id,
+		})
+	}
 
 	return h.GetReceiversList(c)
 }
@@ -115,12 +200,24 @@ func (h *ReceiverHandler) UpdateReceiver(c echo.Context) error {
 func (h *ReceiverHandler) DeleteReceiver(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
 	}
 
-	if err := h.repo.Delete(id); err != nil {
+	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
 		return err
 	}
 
 	return h.GetReceiversList(c)
 }
+
+// renderInvalid sends the receiver form named form back with what is wrong
+// with it, in place of target
+func (h *ReceiverHandler) renderInvalid(c echo.Context, err error, target, form string, data map[string]interface{}) error {
+	receivers, lerr := h.repo.GetAll(c.Request().Context())
+	if lerr != nil {
+		return lerr
+	}
+
+	data["Receivers"] = receivers
+	return renderInvalid(c, err, target, form, "receivers.html", data)
+}
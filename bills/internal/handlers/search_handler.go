@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bills/internal/repository"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// searchPageSize caps how many results of each entity the search page
+	// shows
+	searchPageSize = 20
+
+	// searchSuggestions caps how many results of each entity the type-ahead
+	// below the search box shows
+	searchSuggestions = 5
+)
+
+// SearchHandler handles HTTP requests for the search box and page
+type SearchHandler struct {
+	repo repository.SearchRepository
+}
+
+// NewSearchHandler creates a new SearchHandler instance
+func NewSearchHandler(repo repository.SearchRepository) *SearchHandler {
+	return &SearchHandler{repo: repo}
+}
+
+// RenderSearch renders the search page with the results of the q query
+// parameter
+func (h *SearchHandler) RenderSearch(c echo.Context) error {
+	data, err := h.resultsData(c, searchPageSize)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, "search.html", data)
+}
+
+// GetSearchResults returns the results partial the search boxes show as the
+// user types. The search page gets more results and its address follows the
+// query
+func (h *SearchHandler) GetSearchResults(c echo.Context) error {
+	limit := searchSuggestions
+	if c.Request().Header.Get("HX-Target") == "search-page-results" {
+		limit = searchPageSize
+		pushList(c, "/search", c.QueryParams())
+	}
+
+	data, err := h.resultsData(c, limit)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, "search-results", data)
+}
+
+// resultsData searches for the q query parameter
+func (h *SearchHandler) resultsData(c echo.Context, limit int) (map[string]interface{}, error) {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	groups, err := h.repo.Search(c.Request().Context(), query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"Query":  query,
+		"Groups": groups,
+	}, nil
+}
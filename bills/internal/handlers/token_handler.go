@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bills/internal/auth"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tokenExpiries are the lifetimes offered when creating a token, in days.
+// Zero never expires
+var tokenExpiries = []int{30, 90, 365, 0}
+
+// TokenHandler handles HTTP requests for the API tokens of the signed in user
+type TokenHandler struct {
+	tokens *auth.Tokens
+	repo   repository.APITokenRepository
+}
+
+// NewTokenHandler creates a new TokenHandler instance
+func NewTokenHandler(tokens *auth.Tokens, repo repository.APITokenRepository) *TokenHandler {
+	return &TokenHandler{tokens: tokens, repo: repo}
+}
+
+// RenderTokens renders the API tokens page
+func (h *TokenHandler) RenderTokens(c echo.Context) error {
+	data, err := h.listData(c)
+	if err != nil {
+		return err
+	}
+	return c.Render(http.StatusOK, "tokens.html", data)
+}
+
+// CreateToken issues a token in the current workspace and returns the list
+// with the plain token, which is shown this one time only
+func (h *TokenHandler) CreateToken(c echo.Context) error {
+	scope, err := models.ParseTokenScope(c.FormValue("scope"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	days, err := strconv.Atoi(c.FormValue("expires_in_days"))
+	if err != nil || days < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid expiry")
+	}
+
+	user := auth.CurrentUser(c)
+	workspace := auth.CurrentWorkspace(c)
+	ttl := time.Duration(days) * 24 * time.Hour
+	_, plain, err := h.tokens.Issue(c.Request().Context(), user.ID, workspace.ID, c.FormValue("name"), scope, ttl)
+	if err != nil {
+		// Rendered as a success so HTMX swaps the message in
+		return h.renderList(c, map[string]interface{}{"Error": err.Error()})
+	}
+
+	return h.renderList(c, map[string]interface{}{"NewToken": plain})
+}
+
+// RevokeToken deletes a token of the signed in user
+func (h *TokenHandler) RevokeToken(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	err = h.repo.Delete(c.Request().Context(), id, auth.CurrentUser(c).ID)
+	if errors.Is(err, models.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "token not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.renderList(c, nil)
+}
+
+// renderList returns the tokens list partial with extra values, such as a
+// freshly created token or an error message
+func (h *TokenHandler) renderList(c echo.Context, extra map[string]interface{}) error {
+	data, err := h.listData(c)
+	if err != nil {
+		return err
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	return c.Render(http.StatusOK, "tokens-list", data)
+}
+
+// listData loads the tokens the signed in user made for the current workspace
+func (h *TokenHandler) listData(c echo.Context) (map[string]interface{}, error) {
+	all, err := h.repo.GetByUserID(c.Request().Context(), auth.CurrentUser(c).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := auth.CurrentWorkspace(c)
+	var tokens []*models.APIToken
+	for _, token := range all {
+		if token.WorkspaceID == workspace.ID {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return map[string]interface{}{
+		"Tokens":   tokens,
+		"Scopes":   models.TokenScopes(),
+		"Expiries": tokenExpiries,
+	}, nil
+}
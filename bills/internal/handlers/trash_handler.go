@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TrashHandler handles HTTP requests for the trash page
+type TrashHandler struct {
+	repo repository.TrashRepository
+}
+
+// NewTrashHandler creates a new TrashHandler instance
+func NewTrashHandler(repo repository.TrashRepository) *TrashHandler {
+	return &TrashHandler{repo: repo}
+}
+
+// RenderTrash renders the trash page
+func (h *TrashHandler) RenderTrash(c echo.Context) error {
+	items, err := h.repo.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.Render(http.StatusOK, "trash.html", map[string]interface{}{
+		"Items": items,
+	})
+}
+
+// RestoreItem takes a row out of the trash and returns the updated list
+func (h *TrashHandler) RestoreItem(c echo.Context) error {
+	entity, id, err := trashParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.repo.Restore(c.Request().Context(), entity, id); err != nil {
+		return err
+	}
+	return h.renderList(c, "")
+}
+
+// PurgeItem deletes a trashed row for good and returns the updated list
+func (h *TrashHandler) PurgeItem(c echo.Context) error {
+	entity, id, err := trashParams(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.repo.Purge(c.Request().Context(), entity, id)
+	if errors.Is(err, repository.ErrInUse) {
+		// Rendered as a success so HTMX swaps the message in
+		return h.renderList(c, "Bills still use this, purge them first.")
+	}
+	if err != nil {
+		return err
+	}
+	return h.renderList(c, "")
+}
+
+// renderList returns the trash list partial, with an error message when the
+// last change was refused
+func (h *TrashHandler) renderList(c echo.Context, message string) error {
+	items, err := h.repo.GetAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.Render(http.StatusOK, "trash-list", map[string]interface{}{
+		"Items": items,
+		"Error": message,
+	})
+}
+
+// trashParams reads the entity and id of a trash route
+func trashParams(c echo.Context) (string, int64, error) {
+	entity := c.Param("entity")
+	if !models.IsTrashEntity(entity) {
+		return "", 0, echo.NewHTTPError(http.StatusNotFound, "unknown entity")
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return "", 0, echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+	return entity, id, nil
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UserHandler handles HTTP requests for the user administration page. Admins
+// see and change the roles of the members of their own workspace only
+type UserHandler struct {
+	workspaces repository.WorkspaceRepository
+}
+
+// NewUserHandler creates a new UserHandler instance
+func NewUserHandler(workspaces repository.WorkspaceRepository) *UserHandler {
+	return &UserHandler{workspaces: workspaces}
+}
+
+// RenderUsers renders the users page
+func (h *UserHandler) RenderUsers(c echo.Context) error {
+	workspaceID, err := tenant.WorkspaceID(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	users, err := h.workspaces.GetMembers(c.Request().Context(), workspaceID)
+	if err != nil {
+		return err
+	}
+
+	return c.Render(http.StatusOK, "users.html", map[string]interface{}{
+		"Users": users,
+		"Roles": models.Roles(),
+	})
+}
+
+// UpdateRole assigns a new role to a member of the workspace and returns the
+// updated list
+func (h *UserHandler) UpdateRole(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+	}
+
+	ctx := c.Request().Context()
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Users of other workspaces are as good as missing
+	current, err := h.workspaces.GetRole(ctx, workspaceID, id)
+	if errors.Is(err, models.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	role, err := models.ParseRole(c.FormValue("role"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Someone has to be able to hand out roles
+	if current == models.RoleAdmin && role != models.RoleAdmin {
+		admins, err := h.workspaces.CountByRole(ctx, workspaceID, models.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			// Rendered as a success so HTMX swaps the message in
+			return h.renderList(c, workspaceID, "the last admin cannot be demoted")
+		}
+	}
+
+	if err := h.workspaces.SetRole(ctx, workspaceID, id, role); err != nil {
+		return err
+	}
+
+	return h.renderList(c, workspaceID, "")
+}
+
+// renderList returns the users list partial, with an error message when the
+// last change was refused
+func (h *UserHandler) renderList(c echo.Context, workspaceID int64, message string) error {
+	users, err := h.workspaces.GetMembers(c.Request().Context(), workspaceID)
+	if err != nil {
+		return err
+	}
+
+	return c.Render(http.StatusOK, "users-list", map[string]interface{}{
+		"Users": users,
+		"Roles": models.Roles(),
+		"Error": message,
+	})
+}
@@ -0,0 +1,32 @@
+// Package jobs holds the background work the server runs next to handling
+// requests
+package jobs
+
+import (
+	"bills/internal/repository"
+	"context"
+	"log"
+	"time"
+)
+
+// PurgeTrash purges rows that have been in the trash for longer than
+// retention, right away and then every interval until ctx is done
+func PurgeTrash(ctx context.Context, trash repository.TrashRepository, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := trash.PurgeDeletedBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Failed to purge the trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d rows from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TokenScope limits what an API token may do on behalf of its user
+type TokenScope string
+
+const (
+	ScopeRead      TokenScope = "read"
+	ScopeReadWrite TokenScope = "read_write"
+)
+
+// APIToken is a personal token scripts send as Bearer auth instead of signing
+// in. Only the hash of the token is stored, it works in the workspace it was
+// created in
+type APIToken struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	WorkspaceID int64      `json:"workspace_id" db:"workspace_id"`
+	Name        string     `json:"name" db:"name"`
+	Scope       TokenScope `json:"scope" db:"scope"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NewAPIToken creates a new APIToken instance. A zero ttl never expires
+func NewAPIToken(userID, workspaceID int64, name string, scope TokenScope, tokenHash string, ttl time.Duration) *APIToken {
+	now := time.Now()
+	token := &APIToken{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Name:        strings.TrimSpace(name),
+		Scope:       scope,
+		TokenHash:   tokenHash,
+		CreatedAt:   now,
+	}
+	if ttl != 0 {
+		expires := now.Add(ttl)
+		token.ExpiresAt = &expires
+	}
+	return token
+}
+
+// TokenScopes returns every scope, least privileged first
+func TokenScopes() []TokenScope {
+	return []TokenScope{ScopeRead, ScopeReadWrite}
+}
+
+// ParseTokenScope converts a form value into a TokenScope
+func ParseTokenScope(value string) (TokenScope, error) {
+	for _, scope := range TokenScopes() {
+		if string(scope) == value {
+			return scope, nil
+		}
+	}
+	return "", Invalid("scope", fmt.Sprintf("%q does not exist", value))
+}
+
+// Validate checks that the token can be stored
+func (t *APIToken) Validate() error {
+	return validate(
+		required("name", t.Name),
+		maxLength("name", t.Name, maxTextLength),
+		check(slices.Contains(TokenScopes(), t.Scope), "scope", fmt.Sprintf("%q does not exist", t.Scope)),
+		required("token_hash", t.TokenHash),
+	)
+}
+
+// Expired reports whether the token can no longer be used
+func (t *APIToken) Expired() bool {
+	return t.ExpiresAt != nil && !time.Now().Before(*t.ExpiresAt)
+}
+
+// Allows reports whether the scope of the token covers the permission. It
+// never grants more than the role of its user, and tokens cannot manage
+// tokens themselves nor snapshots of the server
+func (t *APIToken) Allows(permission Permission) bool {
+	switch {
+	case permission == PermissionManageTokens, permission == PermissionManageSnapshots:
+		return false
+	case t.Scope == ScopeReadWrite:
+		return true
+	}
+	return permission == PermissionView
+}
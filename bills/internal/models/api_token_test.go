@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPITokenAllows(t *testing.T) {
+	tests := []struct {
+		name       string
+		scope      TokenScope
+		permission Permission
+		want       bool
+	}{
+		{"Read token can view", ScopeRead, PermissionView, true},
+		{"Read token cannot create bills", ScopeRead, PermissionCreateBills, false},
+		{"Read write token can create bills", ScopeReadWrite, PermissionCreateBills, true},
+		{"Read write token can delete bills", ScopeReadWrite, PermissionDeleteBills, true},
+		{"Tokens cannot manage tokens", ScopeReadWrite, PermissionManageTokens, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := NewAPIToken(1, 1, "script", tt.scope, "hash", 0)
+			if got := token.Allows(tt.permission); got != tt.want {
+				t.Errorf("Allows(%s) = %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITokenExpired(t *testing.T) {
+	if NewAPIToken(1, 1, "script", ScopeRead, "hash", 0).Expired() {
+		t.Error("Expired() = true for a token without expiry")
+	}
+	if NewAPIToken(1, 1, "script", ScopeRead, "hash", time.Hour).Expired() {
+		t.Error("Expired() = true for a token that expires in an hour")
+	}
+	if !NewAPIToken(1, 1, "script", ScopeRead, "hash", -time.Hour).Expired() {
+		t.Error("Expired() = false for a token that expired an hour ago")
+	}
+}
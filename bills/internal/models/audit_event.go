@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// AuditAction is the kind of change an audit event records
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// Audited entities, named after their tables
+const (
+	EntityBills     = "bills"
+	EntityBillLines = "bill_item_assignments"
+	EntityBillItems = "bill_items"
+	EntityIssuers   = "issuers"
+	EntityReceivers = "receivers"
+)
+
+// AuditEntities returns every audited entity
+func AuditEntities() []string {
+	return []string{EntityBills, EntityBillLines, EntityBillItems, EntityIssuers, EntityReceivers}
+}
+
+// AuditEvent records a single change to a row of workspace data. Before and
+// After hold the row as a JSON object, Before is empty for creates and After
+// for purges and lines deleted for good
+type AuditEvent struct {
+	ID          int64           `json:"id" db:"id"`
+	WorkspaceID int64           `json:"workspace_id" db:"workspace_id"`
+	ActorID     *int64          `json:"actor_id,omitempty" db:"actor_id"`
+	Entity      string          `json:"entity" db:"entity"`
+	EntityID    int64           `json:"entity_id" db:"entity_id"`
+	Action      AuditAction     `json:"action" db:"action"`
+	Before      json.RawMessage `json:"before,omitempty" db:"before_data"`
+	After       json.RawMessage `json:"after,omitempty" db:"after_data"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	// Helper fields for templates
+	ActorName string `json:"-"`
+}
+
+// AuditFilter narrows the audit events that are listed. Zero values match
+// everything. BillID matches the events of a bill and of its lines, lines
+// removed since included
+type AuditFilter struct {
+	Entity   string
+	EntityID int64
+	BillID   int64
+	ActorID  int64
+	Limit    int
+}
+
+// FieldChange is a single field of an audit event with its values before and
+// after the change
+type FieldChange struct {
+	Field  string
+	Before interface{}
+	After  interface{}
+}
+
+// Changes lists the fields that differ between Before and After, sorted by
+// name. Creates list every field of After and deletes every field of Before
+func (e *AuditEvent) Changes() ([]FieldChange, error) {
+	before, err := decodeRow(e.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeRow(e.After)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	var changes []FieldChange
+	for field := range fields {
+		b, a := before[field], after[field]
+		if before != nil && after != nil && reflect.DeepEqual(b, a) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, Before: b, After: a})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+// decodeRow decodes a JSON row, an empty row decodes to nil
+func decodeRow(data json.RawMessage) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
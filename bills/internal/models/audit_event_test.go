@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAuditEventChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []FieldChange
+	}{
+		{
+			name:  "Create lists every field",
+			after: `{"name":"ACME","paid":false}`,
+			want: []FieldChange{
+				{Field: "name", After: "ACME"},
+				{Field: "paid", After: false},
+			},
+		},
+		{
+			name:   "Update lists changed fields",
+			before: `{"name":"ACME","eur_total":100,"paid":false}`,
+			after:  `{"name":"ACME","eur_total":250.5,"paid":true}`,
+			want: []FieldChange{
+				{Field: "eur_total", Before: 100.0, After: 250.5},
+				{Field: "paid", Before: false, After: true},
+			},
+		},
+		{
+			name:   "Delete lists every field",
+			before: `{"name":"ACME"}`,
+			want: []FieldChange{
+				{Field: "name", Before: "ACME"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &AuditEvent{}
+			if tt.before != "" {
+				event.Before = json.RawMessage(tt.before)
+			}
+			if tt.after != "" {
+				event.After = json.RawMessage(tt.after)
+			}
+
+			got, err := event.Changes()
+			if err != nil {
+				t.Fatalf("Changes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Changes() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,21 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
-// NewBill creates a new Bill instance with default values
+// NewBill creates a new Bill instance with default values. The bill is issued
+// today, or on its due date when it is recorded after it fell due
 func NewBill(dueDate time.Time, issuerID, receiverID int64) *Bill {
 	now := time.Now()
+	issueDate := day(now)
+	if !dueDate.IsZero() && dueDate.Before(issueDate) {
+		issueDate = dueDate
+	}
 	return &Bill{
+		IssueDate:     issueDate,
 		DueDate:       dueDate,
 		IssuerID:      issuerID,
 		ReceiverID:    receiverID,
@@ -23,6 +33,109 @@ func NewBill(dueDate time.Time, issuerID, receiverID int64) *Bill {
 	}
 }
 
+// Statuses bills can be filtered by
+const (
+	BillStatusPaid    = "paid"
+	BillStatusUnpaid  = "unpaid"
+	BillStatusOverdue = "overdue"
+)
+
+// BillStatuses returns every status bills can be filtered by
+func BillStatuses() []string {
+	return []string{BillStatusPaid, BillStatusUnpaid, BillStatusOverdue}
+}
+
+// IsOverdue reports whether the bill is still unpaid after the day it fell due
+func (b *Bill) IsOverdue(now time.Time) bool {
+	return !b.Paid && day(b.DueDate).Before(day(now))
+}
+
+// HasStatus reports whether the bill has the status on the day of now. An
+// empty status matches every bill
+func (b *Bill) HasStatus(status string, now time.Time) bool {
+	switch status {
+	case BillStatusPaid:
+		return b.Paid
+	case BillStatusUnpaid:
+		return !b.Paid
+	case BillStatusOverdue:
+		return b.IsOverdue(now)
+	}
+	return status == ""
+}
+
+// Columns bills can be sorted by
+const (
+	BillSortDueDate   = "due_date"
+	BillSortIssueDate = "issue_date"
+	BillSortAmount    = "amount"
+	BillSortIssuer    = "issuer"
+	BillSortReceiver  = "receiver"
+)
+
+// BillSorts returns every column bills can be sorted by
+func BillSorts() []string {
+	return []string{BillSortDueDate, BillSortIssueDate, BillSortAmount, BillSortIssuer, BillSortReceiver}
+}
+
+// BillFilter narrows, sorts and pages a list of bills. Zero values match
+// everything. Amounts are in the base currency and Text matches the names of
+// the parties and of the items on the lines. Sort is one of BillSorts, with a
+// leading "-" for descending order, latest due first when unset. Pages hold
+// at most Limit bills and start after the bill with the ID After, the last
+// one of the page before. SkipItems leaves the lines out, for lists that only
+// show the totals of the bills
+type BillFilter struct {
+	Status     string
+	IssuerID   int64
+	ReceiverID int64
+	Currency   string
+	DueFrom    time.Time
+	DueTo      time.Time
+	MinAmount  float64
+	MaxAmount  float64
+	Text       string
+	Sort       string
+	After      int64
+	Limit      int
+	SkipItems  bool
+}
+
+// Validate checks that the filter can be applied
+func (f *BillFilter) Validate() error {
+	checks := []*ValidationError{
+		oneOf("status", f.Status, BillStatuses()),
+		oneOf("sort", strings.TrimPrefix(f.Sort, "-"), BillSorts()),
+		notBefore("due_to", f.DueTo, "due_from", f.DueFrom),
+		notNegative("min_amount", f.MinAmount),
+		notNegative("max_amount", f.MaxAmount),
+	}
+	if f.Currency != "" {
+		checks = append(checks, supportedCurrency("currency", f.Currency))
+	}
+	if f.MaxAmount > 0 {
+		checks = append(checks, check(f.MaxAmount >= f.MinAmount, "max_amount", "must not be less than the min amount"))
+	}
+	return validate(checks...)
+}
+
+// ResolveCurrency sets the bill currency from its items. If all items share a
+// currency that one is used, otherwise the bill falls back to EUR
+func (b *Bill) ResolveCurrency() {
+	uniqueCurrencies := make(map[string]bool)
+	for _, item := range b.Items {
+		uniqueCurrencies[item.Currency] = true
+	}
+
+	if len(uniqueCurrencies) == 1 {
+		for currency := range uniqueCurrencies {
+			b.Currency = currency
+		}
+	} else {
+		b.Currency = DefaultCurrency()
+	}
+}
+
 // CalculateTotals calculates both original and EUR totals for a bill
 func (b *Bill) CalculateTotals() {
 	var originalTotal, eurTotal float64
@@ -46,3 +159,21 @@ func (b *Bill) CalculateTotals() {
 	b.OriginalTotal = originalTotal
 	b.EURTotal = eurTotal
 }
+
+// Validate checks that the bill and its lines can be stored. Errors of a line
+// name the line, like items[0].quantity
+func (b *Bill) Validate() error {
+	checks := []*ValidationError{
+		requiredDate("issue_date", b.IssueDate),
+		requiredDate("due_date", b.DueDate),
+		notBefore("due_date", b.DueDate, "issue_date", b.IssueDate),
+		requiredID("issuer_id", b.IssuerID),
+		requiredID("receiver_id", b.ReceiverID),
+		supportedCurrency("currency", b.Currency),
+		check(len(b.Items) > 0, "items", "must have at least one line"),
+	}
+	for i, item := range b.Items {
+		checks = append(checks, nested(fmt.Sprintf("items[%d]", i), item.Validate())...)
+	}
+	return validate(checks...)
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // NewBillItem creates a new BillItem instance
 func NewBillItem(name string, price float64, currency string) *BillItem {
@@ -16,3 +19,46 @@ func NewBillItem(name string, price float64, currency string) *BillItem {
 		UpdatedAt: now,
 	}
 }
+
+// Validate checks that the bill item can be stored
+func (i *BillItem) Validate() error {
+	return validate(
+		required("name", i.Name),
+		maxLength("name", i.Name, maxTextLength),
+		notNegative("price", i.Price),
+		supportedCurrency("currency", i.Currency),
+	)
+}
+
+// Columns bill items can be sorted by
+const (
+	BillItemSortName  = "name"
+	BillItemSortPrice = "price"
+)
+
+// BillItemSorts returns every column bill items can be sorted by
+func BillItemSorts() []string {
+	return []string{BillItemSortName, BillItemSortPrice}
+}
+
+// BillItemFilter narrows, sorts and pages a list of bill items like
+// BillFilter does bills. Text matches the name, Sort is one of BillItemSorts
+// and defaults to the name
+type BillItemFilter struct {
+	Currency string
+	Text     string
+	Sort     string
+	After    int64
+	Limit    int
+}
+
+// Validate checks that the filter can be applied
+func (f *BillItemFilter) Validate() error {
+	checks := []*ValidationError{
+		oneOf("sort", strings.TrimPrefix(f.Sort, "-"), BillItemSorts()),
+	}
+	if f.Currency != "" {
+		checks = append(checks, supportedCurrency("currency", f.Currency))
+	}
+	return validate(checks...)
+}
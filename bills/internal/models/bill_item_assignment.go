@@ -8,7 +8,7 @@ func NewBillItemAssignment(billID, itemID int64, quantity int, price float64, cu
 	if currency == "" || !IsSupportedCurrency(currency) {
 		currency = DefaultCurrency()
 	}
-	if IsDefaultCurrency(currency) {
+	if IsDefaultCurrency(currency) || exchangeRate <= 0 {
 		exchangeRate = 1.0
 	}
 
@@ -36,3 +36,14 @@ func (a *BillItemAssignment) CalculateAmounts() {
 		a.EURAmount = a.OriginalAmount * a.ExchangeRate
 	}
 }
+
+// Validate checks that the assignment can be stored
+func (a *BillItemAssignment) Validate() error {
+	return validate(
+		requiredID("item_id", a.ItemID),
+		positive("quantity", float64(a.Quantity)),
+		notNegative("price", a.Price),
+		supportedCurrency("currency", a.Currency),
+		positive("exchange_rate", a.ExchangeRate),
+	)
+}
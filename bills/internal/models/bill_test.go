@@ -82,3 +82,35 @@ func TestCalculateTotals(t *testing.T) {
 		})
 	}
 }
+
+func TestHasStatus(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		due    time.Time
+		paid   bool
+		status string
+		want   bool
+	}{
+		{name: "Any status", due: now, status: "", want: true},
+		{name: "Paid", due: now, paid: true, status: BillStatusPaid, want: true},
+		{name: "Unpaid is not paid", due: now, status: BillStatusPaid, want: false},
+		{name: "Unpaid", due: now, status: BillStatusUnpaid, want: true},
+		{name: "Overdue", due: now.AddDate(0, 0, -1), status: BillStatusOverdue, want: true},
+		{name: "Due today is not overdue", due: now.Add(-time.Hour), status: BillStatusOverdue, want: false},
+		{name: "Paid is never overdue", due: now.AddDate(0, 0, -1), paid: true, status: BillStatusOverdue, want: false},
+		{name: "Unknown status", due: now, status: "lost", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bill := NewBill(tt.due, 1, 1)
+			bill.Paid = tt.paid
+
+			if got := bill.HasStatus(tt.status, now); got != tt.want {
+				t.Errorf("HasStatus(%q) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
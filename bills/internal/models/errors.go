@@ -0,0 +1,55 @@
+package models
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when a record does not exist, or not in the
+	// workspace of the request
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is returned when a change clashes with other records, like
+	// purging an issuer that bills still use
+	ErrConflict = errors.New("conflict")
+)
+
+// ValidationError is returned when a field of a record holds a value that
+// cannot be stored
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error returns the field followed by what is wrong with it
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Invalid returns a ValidationError for the given field
+func Invalid(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// NotFound returns an error matching ErrNotFound that names the entity
+func NotFound(entity string) error {
+	return &domainError{kind: ErrNotFound, message: entity + " not found"}
+}
+
+// Conflict returns an error matching ErrConflict with the given message
+func Conflict(message string) error {
+	return &domainError{kind: ErrConflict, message: message}
+}
+
+// domainError is an error with its own message that matches one of the
+// sentinel errors with errors.Is
+type domainError struct {
+	kind    error
+	message string
+}
+
+func (e *domainError) Error() string {
+	return e.message
+}
+
+func (e *domainError) Unwrap() error {
+	return e.kind
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDomainErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		is      error
+		message string
+	}{
+		{"Not found", NotFound("bill"), ErrNotFound, "bill not found"},
+		{"Wrapped not found", fmt.Errorf("loading lines: %w", NotFound("bill")), ErrNotFound, "loading lines: bill not found"},
+		{"Conflict", Conflict("still used by bills"), ErrConflict, "still used by bills"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.is) {
+				t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.is)
+			}
+			if got := tt.err.Error(); got != tt.message {
+				t.Errorf("Error() = %q, want %q", got, tt.message)
+			}
+		})
+	}
+
+	if errors.Is(NotFound("bill"), ErrConflict) {
+		t.Error("Expected a not found error not to be a conflict")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := validate(
+		required("name", ""),
+		notNegative("price", -1),
+		check(false, "name", "is taken"),
+	)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "name" {
+		t.Fatalf("Expected the first field error to be found, got %v", err)
+	}
+	if got := err.Error(); got != "name is required; price must not be negative; name is taken" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	fields := FieldErrors(err).Fields()
+	if len(fields) != 2 || fields["name"] != "is required" || fields["price"] != "must not be negative" {
+		t.Errorf("Fields() = %v", fields)
+	}
+
+	if FieldErrors(Invalid("scope", "is required")).Fields()["scope"] != "is required" {
+		t.Error("Expected a single ValidationError to give its field")
+	}
+	if FieldErrors(NotFound("bill")) != nil {
+		t.Error("Expected no field errors for a not found error")
+	}
+	if err := validate(required("name", "Acme")); err != nil {
+		t.Errorf("Expected passing checks to give nil, got %v", err)
+	}
+}
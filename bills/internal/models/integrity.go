@@ -0,0 +1,43 @@
+package models
+
+import "strings"
+
+// Kinds of integrity problems. They are listed and repaired in this order:
+// lines without a bill and references to missing rows first, then
+// currencies, line amounts and finally the totals of the bills
+const (
+	// A line whose bill does not exist
+	ProblemOrphanLine = "orphan_line"
+	// Bills referring to an issuer or receiver that is not in their workspace
+	ProblemMissingIssuer   = "missing_issuer"
+	ProblemMissingReceiver = "missing_receiver"
+	// Lines referring to a bill item that is not in the workspace of their bill
+	ProblemMissingItem = "missing_item"
+	// A line or bill in a currency that is not supported
+	ProblemLineCurrency = "line_currency"
+	ProblemBillCurrency = "bill_currency"
+	// Stored amounts of a line that differ from its quantity, price and rate
+	ProblemLineAmounts = "line_amounts"
+	// Stored totals of a bill that differ from the amounts of its lines
+	ProblemBillTotals = "bill_totals"
+)
+
+// IntegrityProblem is a row that breaks a rule the repositories keep, left
+// by an older build, a failed migration or a change made by hand. ID is the
+// row of Entity the problem is about, or the missing row for the missing
+// kinds. Problems that are not Fixable need a hand
+type IntegrityProblem struct {
+	Kind        string
+	Entity      string
+	ID          int64
+	WorkspaceID int64
+	Detail      string
+	Fixable     bool
+}
+
+// NormalizeCurrency returns currency in upper case without surrounding
+// spaces, the way it is stored, and whether that is a supported currency
+func NormalizeCurrency(currency string) (string, bool) {
+	normalized := strings.ToUpper(strings.TrimSpace(currency))
+	return normalized, IsSupportedCurrency(normalized)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		currency  string
+		want      string
+		supported bool
+	}{
+		{"Stored spelling", "USD", "USD", true},
+		{"Lower case", "eur", "EUR", true},
+		{"Spaces", " chf ", "CHF", true},
+		{"Unknown", "doge", "DOGE", false},
+		{"Empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, supported := NormalizeCurrency(tt.currency)
+			if got != tt.want || supported != tt.supported {
+				t.Errorf("NormalizeCurrency(%q) = %q, %v, want %q, %v", tt.currency, got, supported, tt.want, tt.supported)
+			}
+		})
+	}
+}
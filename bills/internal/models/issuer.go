@@ -4,16 +4,16 @@ import "time"
 
 // Issuer represents a business entity that can issue bills
 type Issuer struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	VATNumber string    `json:"vat_number"`
-	Street    string    `json:"street"`
-	City      string    `json:"city"`
-	State     string    `json:"state"`
-	ZipCode   string    `json:"zip_code"`
-	Country   string    `json:"country"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	VATNumber string    `json:"vat_number" db:"vat_number"`
+	Street    string    `json:"street" db:"street"`
+	City      string    `json:"city" db:"city"`
+	State     string    `json:"state" db:"state"`
+	ZipCode   string    `json:"zip_code" db:"zip_code"`
+	Country   string    `json:"country" db:"country"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // NewIssuer creates a new Issuer instance
@@ -31,3 +31,17 @@ func NewIssuer(name, vatNumber, street, city, state, zipCode, country string) *I
 		UpdatedAt: now,
 	}
 }
+
+// Validate checks that the issuer can be stored
+func (i *Issuer) Validate() error {
+	return validate(
+		required("name", i.Name),
+		maxLength("name", i.Name, maxTextLength),
+		maxLength("vat_number", i.VATNumber, maxTextLength),
+		maxLength("street", i.Street, maxTextLength),
+		maxLength("city", i.City, maxTextLength),
+		maxLength("state", i.State, maxTextLength),
+		maxLength("zip_code", i.ZipCode, maxTextLength),
+		maxLength("country", i.Country, maxTextLength),
+	)
+}
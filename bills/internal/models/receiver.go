@@ -4,16 +4,16 @@ import "time"
 
 // Receiver represents a business entity that can receive bills
 type Receiver struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	VATNumber string    `json:"vat_number"`
-	Street    string    `json:"street"`
-	City      string    `json:"city"`
-	State     string    `json:"state"`
-	ZipCode   string    `json:"zip_code"`
-	Country   string    `json:"country"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	VATNumber string    `json:"vat_number" db:"vat_number"`
+	Street    string    `json:"street" db:"street"`
+	City      string    `json:"city" db:"city"`
+	State     string    `json:"state" db:"state"`
+	ZipCode   string    `json:"zip_code" db:"zip_code"`
+	Country   string    `json:"country" db:"country"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // NewReceiver creates a new Receiver instance
@@ -31,3 +31,17 @@ func NewReceiver(name, vatNumber, street, city, state, zipCode, country string)
 		UpdatedAt: now,
 	}
 }
+
+// Validate checks that the receiver can be stored
+func (r *Receiver) Validate() error {
+	return validate(
+		required("name", r.Name),
+		maxLength("name", r.Name, maxTextLength),
+		maxLength("vat_number", r.VATNumber, maxTextLength),
+		maxLength("street", r.Street, maxTextLength),
+		maxLength("city", r.City, maxTextLength),
+		maxLength("state", r.State, maxTextLength),
+		maxLength("zip_code", r.ZipCode, maxTextLength),
+		maxLength("country", r.Country, maxTextLength),
+	)
+}
@@ -0,0 +1,90 @@
+package models
+
+import "fmt"
+
+// Role is the set of permissions granted to a user
+type Role string
+
+// Permission allows a single kind of action
+type Permission string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleAccountant Role = "accountant"
+	RoleViewer     Role = "viewer"
+)
+
+const (
+	PermissionView          Permission = "view"
+	PermissionCreateBills   Permission = "bills.create"
+	PermissionEditBills     Permission = "bills.edit"
+	PermissionTogglePaid    Permission = "bills.toggle_paid"
+	PermissionDeleteBills   Permission = "bills.delete"
+	PermissionManageParties Permission = "parties.manage"
+	PermissionDeleteParties Permission = "parties.delete"
+	PermissionManageCatalog Permission = "catalog.manage"
+	PermissionDeleteCatalog Permission = "catalog.delete"
+	PermissionManageUsers   Permission = "users.manage"
+	PermissionManageTokens  Permission = "tokens.manage"
+	PermissionViewAudit     Permission = "audit.view"
+	PermissionManageTrash   Permission = "trash.manage"
+	PermissionManageBackups Permission = "backups.manage"
+
+	// PermissionManageSnapshots is granted to instance admins only, whatever
+	// their role, as snapshots hold every workspace of the server
+	PermissionManageSnapshots Permission = "snapshots.manage"
+)
+
+// rolePermissions lists what each role may do. Admins may do everything
+var rolePermissions = map[Role][]Permission{
+	RoleAccountant: {
+		PermissionView,
+		PermissionCreateBills,
+		PermissionEditBills,
+		PermissionTogglePaid,
+		PermissionManageParties,
+		PermissionManageCatalog,
+		PermissionManageTokens,
+	},
+	RoleViewer: {
+		PermissionView,
+		PermissionManageTokens,
+	},
+}
+
+// Roles returns every role, most privileged first
+func Roles() []Role {
+	return []Role{RoleAdmin, RoleAccountant, RoleViewer}
+}
+
+// IsValidRole checks if the role exists
+func IsValidRole(role Role) bool {
+	for _, r := range Roles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRole converts a form or flag value into a Role
+func ParseRole(value string) (Role, error) {
+	role := Role(value)
+	if !IsValidRole(role) {
+		return "", Invalid("role", fmt.Sprintf("%q does not exist", value))
+	}
+	return role, nil
+}
+
+// Can reports whether the role grants the permission
+func (r Role) Can(permission Permission) bool {
+	if r == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
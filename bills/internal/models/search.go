@@ -0,0 +1,64 @@
+package models
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SearchResult is a bill, party or catalog item matching a search
+type SearchResult struct {
+	Entity string `json:"entity"`
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// Path returns the page that shows the result. Catalog items have no page of
+// their own, their list is filtered down to them instead
+func (r *SearchResult) Path() string {
+	id := strconv.FormatInt(r.ID, 10)
+	switch r.Entity {
+	case EntityBills:
+		return "/bills/" + id
+	case EntityIssuers:
+		return "/issuers/" + id
+	case EntityReceivers:
+		return "/receivers/" + id
+	}
+	return "/bill-items?q=" + url.QueryEscape(r.Title)
+}
+
+// SearchGroup holds the results of one entity, best match first
+type SearchGroup struct {
+	Entity  string          `json:"entity"`
+	Results []*SearchResult `json:"results"`
+}
+
+// Label returns the heading of the group
+func (g *SearchGroup) Label() string {
+	switch g.Entity {
+	case EntityBills:
+		return "Bills"
+	case EntityIssuers:
+		return "Issuers"
+	case EntityReceivers:
+		return "Receivers"
+	}
+	return "Bill items"
+}
+
+// SearchEntities returns the entities that are searched, in the order their
+// groups are shown
+func SearchEntities() []string {
+	return []string{EntityBills, EntityIssuers, EntityReceivers, EntityBillItems}
+}
+
+// SearchTerms splits a search query into the words to look for. Punctuation
+// separates words, like it does in the search index
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
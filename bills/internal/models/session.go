@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Session represents a signed in browser. Only the hash of the cookie token
+// is stored
+type Session struct {
+	TokenHash string `db:"token_hash"`
+	UserID    int64  `db:"user_id"`
+	// WorkspaceID is the workspace picked in the layout, 0 until one is
+	WorkspaceID int64     `db:"workspace_id"`
+	ExpiresAt   time.Time `db:"expires_at"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// NewSession creates a new Session instance that lasts for ttl
+func NewSession(tokenHash string, userID int64, ttl time.Duration) *Session {
+	now := time.Now()
+	return &Session{
+		TokenHash: tokenHash,
+		UserID:    userID,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// Expired reports whether the session can no longer be used
+func (s *Session) Expired() bool {
+	return !time.Now().Before(s.ExpiresAt)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TrashedItem is a deleted row waiting in the trash to be restored or purged
+type TrashedItem struct {
+	Entity    string    `json:"entity"`
+	ID        int64     `json:"id"`
+	Label     string    `json:"label"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashEntities returns the entities that go to the trash when deleted. Bills
+// come first so purging them frees the parties and items they use
+func TrashEntities() []string {
+	return []string{EntityBills, EntityBillItems, EntityIssuers, EntityReceivers}
+}
+
+// IsTrashEntity reports whether rows of entity go to the trash
+func IsTrashEntity(entity string) bool {
+	for _, e := range TrashEntities() {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
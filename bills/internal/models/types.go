@@ -15,16 +15,17 @@ type Address struct {
 
 // Bill represents a bill entity in our system
 type Bill struct {
-	ID            int64                 `json:"id"`
-	IssuerID      int64                 `json:"issuer_id"`
-	ReceiverID    int64                 `json:"receiver_id"`
-	DueDate       time.Time             `json:"due_date"`
-	Currency      string                `json:"currency"`
-	OriginalTotal float64               `json:"original_total"`
-	EURTotal      float64               `json:"eur_total"`
-	Paid          bool                  `json:"paid"`
-	CreatedAt     time.Time             `json:"created_at"`
-	UpdatedAt     time.Time             `json:"updated_at"`
+	ID            int64                 `json:"id" db:"id"`
+	IssuerID      int64                 `json:"issuer_id" db:"issuer_id"`
+	ReceiverID    int64                 `json:"receiver_id" db:"receiver_id"`
+	IssueDate     time.Time             `json:"issue_date" db:"issue_date"`
+	DueDate       time.Time             `json:"due_date" db:"due_date"`
+	Currency      string                `json:"currency" db:"currency"`
+	OriginalTotal float64               `json:"original_total" db:"original_total"`
+	EURTotal      float64               `json:"eur_total" db:"eur_total"`
+	Paid          bool                  `json:"paid" db:"paid"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at" db:"updated_at"`
 	Issuer        *Issuer               `json:"issuer,omitempty"`
 	Receiver      *Receiver             `json:"receiver,omitempty"`
 	Items         []*BillItemAssignment `json:"items,omitempty"`
@@ -33,28 +34,50 @@ type Bill struct {
 	ReceiverName string `json:"-"`
 }
 
+// BillTotals adds up a set of bills in the base currency
+type BillTotals struct {
+	Count       int     `json:"count"`
+	Billed      float64 `json:"billed"`
+	Paid        float64 `json:"paid"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+// BillPage is a page of a filtered list of bills. Next is the After of the
+// page that follows, zero on the last page
+type BillPage struct {
+	Bills []*Bill `json:"bills"`
+	Next  int64   `json:"next,omitempty"`
+}
+
 // BillItem represents a service or product that can be added to bills
 type BillItem struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Price     float64   `json:"price"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Price     float64   `json:"price" db:"price"`
+	Currency  string    `json:"currency" db:"currency"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BillItemPage is a page of a filtered list of bill items. Next is the
+// After of the page that follows, zero on the last page
+type BillItemPage struct {
+	Items []*BillItem `json:"items"`
+	Next  int64       `json:"next,omitempty"`
 }
 
 // BillItemAssignment represents the assignment of a BillItem to a Bill
 type BillItemAssignment struct {
-	ID             int64     `json:"id"`
-	BillID         int64     `json:"bill_id"`
+	ID             int64     `json:"id" db:"id"`
+	BillID         int64     `json:"bill_id" db:"bill_id"`
 	BillItem       *BillItem `json:"bill_item,omitempty"`
-	ItemID         int64     `json:"item_id"`
-	Quantity       int       `json:"quantity"`
-	Price          float64   `json:"price"`
-	Currency       string    `json:"currency"`
-	ExchangeRate   float64   `json:"exchange_rate"`
-	OriginalAmount float64   `json:"original_amount"`
-	EURAmount      float64   `json:"eur_amount"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ItemID         int64     `json:"item_id" db:"item_id"`
+	Quantity       int       `json:"quantity" db:"quantity"`
+	Price          float64   `json:"price" db:"price"`
+	Currency       string    `json:"currency" db:"currency"`
+	ExchangeRate   float64   `json:"exchange_rate" db:"exchange_rate"`
+	OriginalAmount float64   `json:"original_amount" db:"original_amount"`
+	EURAmount      float64   `json:"eur_amount" db:"eur_amount"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password a user may choose
+const MinPasswordLength = 8
+
+// User represents an account that can sign in to the bills manager. Role is
+// what the user may do in the workspace a request works in, it is stored on
+// the membership and set when the request is signed in. InstanceAdmin users
+// look after the whole server, whatever workspace they work in
+type User struct {
+	ID            int64     `json:"id" db:"id"`
+	Username      string    `json:"username" db:"username"`
+	Role          Role      `json:"role,omitempty"`
+	InstanceAdmin bool      `json:"instance_admin,omitempty" db:"instance_admin"`
+	PasswordHash  string    `json:"-" db:"password_hash"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NewUser creates a new User instance with the password hashed
+func NewUser(username, password string) (*User, error) {
+	now := time.Now()
+	user := &User{
+		Username:  strings.TrimSpace(username),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetPassword replaces the password hash of the user
+func (u *User) SetPassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return Invalid("password", "must be at least 8 characters")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// CheckPassword reports whether password matches the stored hash
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// Validate checks that the user can be stored
+func (u *User) Validate() error {
+	return validate(
+		required("username", u.Username),
+		maxLength("username", u.Username, maxTextLength),
+		required("password", u.PasswordHash),
+	)
+}
+
+// Can reports whether the user may perform the action. Nobody is signed in
+// when u is nil, so nothing is allowed
+func (u *User) Can(permission Permission) bool {
+	if u == nil {
+		return false
+	}
+	if permission == PermissionManageSnapshots {
+		return u.InstanceAdmin
+	}
+	return u.Role.Can(permission)
+}
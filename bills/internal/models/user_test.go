@@ -0,0 +1,49 @@
+package models
+
+import "testing"
+
+func TestNewUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "Valid user",
+			username: " alice ",
+			password: "correct horse",
+		},
+		{
+			name:     "Short password",
+			username: "alice",
+			password: "short",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := NewUser(tt.username, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if user.Username != "alice" {
+				t.Errorf("Username = %q, want %q", user.Username, "alice")
+			}
+			if user.PasswordHash == tt.password {
+				t.Error("PasswordHash should not be the plain password")
+			}
+			if !user.CheckPassword(tt.password) {
+				t.Error("CheckPassword() = false for the right password")
+			}
+			if user.CheckPassword("wrong password") {
+				t.Error("CheckPassword() = true for a wrong password")
+			}
+		})
+	}
+}
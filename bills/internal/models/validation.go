@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxTextLength is the longest name or address line a record may hold
+const maxTextLength = 255
+
+// ValidationErrors is returned when one or more fields of a record are
+// invalid. It holds every failed check, in the order the fields were checked
+type ValidationErrors []*ValidationError
+
+// Error returns the failed checks separated by semicolons
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap lets errors.As find the ValidationError of each field
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Fields maps every invalid field to its first message, for showing the
+// messages next to the fields of a form
+func (e ValidationErrors) Fields() map[string]string {
+	fields := make(map[string]string, len(e))
+	for _, err := range e {
+		if _, set := fields[err.Field]; !set {
+			fields[err.Field] = err.Message
+		}
+	}
+	return fields
+}
+
+// FieldErrors returns the field errors in err, or nil when err is not a
+// validation error
+func FieldErrors(err error) ValidationErrors {
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ValidationErrors{ve}
+	}
+	return nil
+}
+
+// validate runs the checks of a record and returns the ones that failed as
+// ValidationErrors, or nil when every check passed
+func validate(checks ...*ValidationError) error {
+	var errs ValidationErrors
+	for _, check := range checks {
+		if check != nil {
+			errs = append(errs, check)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// nested prefixes the fields of the errors of a nested record, like the
+// lines of a bill, so they can be told apart from the fields of the parent
+func nested(prefix string, err error) []*ValidationError {
+	var checks []*ValidationError
+	for _, fe := range FieldErrors(err) {
+		checks = append(checks, &ValidationError{Field: prefix + "." + fe.Field, Message: fe.Message})
+	}
+	return checks
+}
+
+// check fails with message unless ok holds
+func check(ok bool, field, message string) *ValidationError {
+	if ok {
+		return nil
+	}
+	return &ValidationError{Field: field, Message: message}
+}
+
+// required fails for blank text
+func required(field, value string) *ValidationError {
+	return check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// maxLength fails for text longer than n characters
+func maxLength(field, value string, n int) *ValidationError {
+	return check(len([]rune(value)) <= n, field, fmt.Sprintf("must be at most %d characters", n))
+}
+
+// requiredID fails for a reference that is not set
+func requiredID(field string, id int64) *ValidationError {
+	return check(id > 0, field, "is required")
+}
+
+// requiredDate fails for a date that is not set
+func requiredDate(field string, t time.Time) *ValidationError {
+	return check(!t.IsZero(), field, "is required")
+}
+
+// notBefore fails when the day of t is before the day of other. Unset dates
+// are left to requiredDate
+func notBefore(field string, t time.Time, otherField string, other time.Time) *ValidationError {
+	if t.IsZero() || other.IsZero() {
+		return nil
+	}
+	return check(!day(t).Before(day(other)), field, "must not be before the "+strings.ReplaceAll(otherField, "_", " "))
+}
+
+// positive fails for zero and negative numbers
+func positive(field string, value float64) *ValidationError {
+	return check(value > 0, field, "must be greater than zero")
+}
+
+// notNegative fails for negative numbers
+func notNegative(field string, value float64) *ValidationError {
+	return check(value >= 0, field, "must not be negative")
+}
+
+// supportedCurrency fails for currencies the app cannot convert
+func supportedCurrency(field, value string) *ValidationError {
+	return check(IsSupportedCurrency(value), field, fmt.Sprintf("%q is not supported", value))
+}
+
+// oneOf fails for text that is set and not one of allowed
+func oneOf(field, value string, allowed []string) *ValidationError {
+	return check(value == "" || slices.Contains(allowed, value), field, fmt.Sprintf("%q is not one of %s", value, strings.Join(allowed, ", ")))
+}
+
+// day returns the calendar day of t, dropping the time of day
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBillValidate(t *testing.T) {
+	issued := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	line := func() *BillItemAssignment {
+		return NewBillItemAssignment(0, 1, 1, 10, DefaultCurrency(), 1)
+	}
+	valid := func() *Bill {
+		bill := NewBill(issued.AddDate(0, 0, 30), 1, 2)
+		bill.IssueDate = issued
+		bill.Items = []*BillItemAssignment{line()}
+		return bill
+	}
+
+	tests := []struct {
+		name   string
+		change func(b *Bill)
+		want   map[string]string
+	}{
+		{"Valid", func(b *Bill) {}, nil},
+		{"Due on the issue date", func(b *Bill) { b.DueDate = issued.Add(-9 * time.Hour) }, nil},
+		{"Due before the issue date", func(b *Bill) { b.DueDate = issued.AddDate(0, 0, -1) }, map[string]string{
+			"due_date": "must not be before the issue date",
+		}},
+		{"No lines", func(b *Bill) { b.Items = nil }, map[string]string{
+			"items": "must have at least one line",
+		}},
+		{"Missing parties and dates", func(b *Bill) {
+			b.IssuerID, b.ReceiverID = 0, 0
+			b.IssueDate, b.DueDate = time.Time{}, time.Time{}
+		}, map[string]string{
+			"issue_date":  "is required",
+			"due_date":    "is required",
+			"issuer_id":   "is required",
+			"receiver_id": "is required",
+		}},
+		{"Invalid lines", func(b *Bill) {
+			b.Items = append(b.Items, line(), line())
+			b.Items[1].Quantity = 0
+			b.Items[2].Price = -5
+			b.Items[2].ExchangeRate = 0
+		}, map[string]string{
+			"items[1].quantity":      "must be greater than zero",
+			"items[2].price":         "must not be negative",
+			"items[2].exchange_rate": "must be greater than zero",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bill := valid()
+			tt.change(bill)
+
+			got := FieldErrors(bill.Validate()).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", got, tt.want)
+			}
+			for field, message := range tt.want {
+				if got[field] != message {
+					t.Errorf("%s = %q, want %q", field, got[field], message)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBillIssueDate(t *testing.T) {
+	today := day(time.Now())
+
+	if bill := NewBill(today.AddDate(0, 1, 0), 1, 2); !bill.IssueDate.Equal(today) {
+		t.Errorf("Expected a bill due later to be issued today, got %v", bill.IssueDate)
+	}
+
+	overdue := today.AddDate(0, -1, 0)
+	if bill := NewBill(overdue, 1, 2); !bill.IssueDate.Equal(overdue) {
+		t.Errorf("Expected an overdue bill to be issued on its due date, got %v", bill.IssueDate)
+	}
+}
+
+func TestPartyValidate(t *testing.T) {
+	long := string(make([]rune, maxTextLength+1))
+	issuer := NewIssuer(" ", "", "", "", "", "", long)
+
+	got := FieldErrors(issuer.Validate()).Fields()
+	if got["name"] != "is required" || got["country"] != "must be at most 255 characters" || len(got) != 2 {
+		t.Errorf("Validate() = %v", got)
+	}
+}
+
+func TestBillFilterValidate(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	filter := BillFilter{
+		Status:    "lost",
+		Currency:  "XXX",
+		Sort:      "-color",
+		DueFrom:   from,
+		DueTo:     from.AddDate(0, 0, -1),
+		MinAmount: 50,
+		MaxAmount: 10,
+	}
+
+	got := FieldErrors(filter.Validate()).Fields()
+	for _, field := range []string{"status", "currency", "sort", "due_to", "max_amount"} {
+		if got[field] == "" {
+			t.Errorf("Expected %s to be invalid, got %v", field, got)
+		}
+	}
+
+	valid := BillFilter{Status: BillStatusOverdue, Sort: "-" + BillSortAmount, DueFrom: from, DueTo: from, MinAmount: 10}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultWorkspaceID is the workspace that holds the data created before
+// workspaces existed
+const DefaultWorkspaceID int64 = 1
+
+// Workspace represents an isolated set of bills, parties and catalog items
+// shared by its members
+type Workspace struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Membership makes a user a member of a workspace with a role. The role is
+// read into User.Role when the user works in the workspace
+type Membership struct {
+	WorkspaceID int64     `db:"workspace_id"`
+	UserID      int64     `db:"user_id"`
+	Role        Role      `db:"role"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// NewWorkspace creates a new Workspace instance
+func NewWorkspace(name string) *Workspace {
+	now := time.Now()
+	return &Workspace{
+		Name:      strings.TrimSpace(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks that the workspace can be stored
+func (w *Workspace) Validate() error {
+	return validate(
+		required("name", w.Name),
+		maxLength("name", w.Name, maxTextLength),
+	)
+}
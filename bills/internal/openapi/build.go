@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"bills/internal/api"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Version is the version of the API described by the document
+const Version = "1.0.0"
+
+// Key returns the Routes key of a method and echo path
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+// Undocumented returns the keys of the given routes that have no entry in
+// Routes, sorted
+func Undocumented(routes []*echo.Route) []string {
+	var missing []string
+	for _, route := range routes {
+		if !isOperationMethod(route.Method) {
+			continue
+		}
+		if _, ok := Routes[Key(route.Method, route.Path)]; !ok {
+			missing = append(missing, Key(route.Method, route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Build generates the document describing the given echo routes
+func Build(routes []*echo.Route) *Document {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Bills Manager",
+			Description: "JSON API and HTMX pages of the bills manager",
+			Version:     Version,
+		},
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+
+	errorSchema := schemaFor(reflect.TypeOf(api.ErrorResponse{}), doc.Components.Schemas)
+	tags := map[string]bool{}
+
+	for _, route := range routes {
+		if !isOperationMethod(route.Method) {
+			continue
+		}
+		meta, ok := Routes[Key(route.Method, route.Path)]
+		if !ok {
+			continue
+		}
+
+		path, params := convertPath(route.Path)
+		op := &Operation{
+			OperationID: operationID(route.Method, route.Path),
+			Summary:     meta.Summary,
+			Parameters:  params,
+			Responses:   map[string]*Response{},
+		}
+		if meta.Tag != "" {
+			op.Tags = []string{meta.Tag}
+			tags[meta.Tag] = true
+		}
+
+		switch {
+		case meta.Request != nil:
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					echo.MIMEApplicationJSON: {Schema: schemaFor(reflect.TypeOf(meta.Request), doc.Components.Schemas)},
+				},
+			}
+		case len(meta.Form) > 0:
+			form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			for _, field := range meta.Form {
+				form.Properties[field] = &Schema{Type: "string"}
+			}
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					echo.MIMEApplicationForm: {Schema: form},
+				},
+			}
+		}
+
+		if meta.HTML {
+			op.Responses["200"] = &Response{
+				Description: "HTML page or partial",
+				Content:     map[string]MediaType{echo.MIMETextHTML: {}},
+			}
+		} else {
+			success := &Response{Description: http.StatusText(meta.Status)}
+			if meta.Response != nil {
+				success.Content = map[string]MediaType{
+					echo.MIMEApplicationJSON: {Schema: schemaFor(reflect.TypeOf(meta.Response), doc.Components.Schemas)},
+				}
+			}
+			op.Responses[strconv.Itoa(meta.Status)] = success
+			op.Responses["default"] = &Response{
+				Description: "Error",
+				Content: map[string]MediaType{
+					echo.MIMEApplicationJSON: {Schema: errorSchema},
+				},
+			}
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		*item.item(route.Method) = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc
+}
+
+// convertPath turns an echo path such as /bills/:id into its OpenAPI form and
+// returns the path parameters it declares
+func convertPath(path string) (string, []Parameter) {
+	var params []Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimPrefix(segment, ":")
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "integer", Format: "int64"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a stable identifier such as getApiV1BillsId
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '-' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	if path == "/" {
+		b.WriteString("Root")
+	}
+	return b.String()
+}
+
+func isOperationMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
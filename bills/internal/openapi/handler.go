@@ -0,0 +1,33 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed explorer.html
+var explorerHTML []byte
+
+// Handler serves the document describing the routes registered on e. The
+// document is built on the first request, once every route is in place
+func Handler(e *echo.Echo) echo.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	return func(c echo.Context) error {
+		once.Do(func() {
+			doc = Build(e.Routes())
+		})
+		return c.JSON(http.StatusOK, doc)
+	}
+}
+
+// Explorer serves the API explorer page. It has no external dependencies so
+// it works offline
+func Explorer(c echo.Context) error {
+	return c.HTMLBlob(http.StatusOK, explorerHTML)
+}
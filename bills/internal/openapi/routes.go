@@ -0,0 +1,116 @@
+package openapi
+
+import (
+	"bills/internal/api"
+	"bills/internal/models"
+	"net/http"
+)
+
+// Route documents a single route registered on the echo server
+type Route struct {
+	Summary  string
+	Tag      string
+	Form     []string    // form fields posted by the HTMX pages
+	Request  interface{} // JSON request body, nil when there is none
+	Response interface{} // JSON response body, nil for pages and empty responses
+	Status   int         // status code of a successful response
+	HTML     bool        // the route renders a page or partial
+}
+
+// partyForm lists the fields of the issuer and receiver forms
+var partyForm = []string{"name", "vat_number", "street", "city", "state", "zip_code", "country"}
+
+// Routes holds the documentation of every route registered in main.go, keyed
+// by method and echo path. A route missing from this map is left out of the
+// generated document
+var Routes = map[string]Route{
+	// Pages and HTMX partials
+	"GET /":                           {Summary: "Bills page", Tag: "Pages", HTML: true},
+	"GET /bills":                      {Summary: "Bills page, filtered, sorted and paged by the query", Tag: "Pages", HTML: true},
+	"POST /bills":                     {Summary: "Create a bill from the bill form", Tag: "Pages", HTML: true, Form: []string{"issue_date", "due_date", "issuer_id", "receiver_id", "item_ids[]", "quantities[]", "prices[]", "currencies[]", "exchange_rates[]"}},
+	"GET /bills/list":                 {Summary: "Bills list partial, filtered, sorted and paged by the query", Tag: "Pages", HTML: true},
+	"GET /bills/:id":                  {Summary: "Bill page with its lines, parties and history", Tag: "Pages", HTML: true},
+	"GET /bills/:id/lines":            {Summary: "Lines of a bill for its row in the bills list", Tag: "Pages", HTML: true},
+	"GET /bills/:id/edit":             {Summary: "Inline form for editing a bill and its lines", Tag: "Pages", HTML: true},
+	"PUT /bills/:id":                  {Summary: "Update a bill and its lines from the edit form", Tag: "Pages", HTML: true, Form: []string{"issue_date", "due_date", "issuer_id", "receiver_id", "line_ids[]", "item_ids[]", "quantities[]", "prices[]", "currencies[]", "exchange_rates[]"}},
+	"POST /bills/:id/toggle":          {Summary: "Toggle the paid status of a bill", Tag: "Pages", HTML: true},
+	"DELETE /bills/:id":               {Summary: "Move a bill to the trash", Tag: "Pages", HTML: true},
+	"GET /receivers":                  {Summary: "Receivers page", Tag: "Pages", HTML: true},
+	"POST /receivers":                 {Summary: "Create a receiver", Tag: "Pages", HTML: true, Form: partyForm},
+	"GET /receivers/list":             {Summary: "Receivers list partial", Tag: "Pages", HTML: true},
+	"GET /receivers/select":           {Summary: "Receivers select partial", Tag: "Pages", HTML: true},
+	"GET /receivers/:id":              {Summary: "Receiver page with its bills and balances, filtered by status", Tag: "Pages", HTML: true},
+	"GET /receivers/:id/edit":         {Summary: "Inline form for editing a receiver", Tag: "Pages", HTML: true},
+	"PUT /receivers/:id":              {Summary: "Update a receiver", Tag: "Pages", HTML: true, Form: partyForm},
+	"DELETE /receivers/:id":           {Summary: "Move a receiver to the trash", Tag: "Pages", HTML: true},
+	"GET /issuers":                    {Summary: "Issuers page", Tag: "Pages", HTML: true},
+	"POST /issuers":                   {Summary: "Create an issuer", Tag: "Pages", HTML: true, Form: partyForm},
+	"GET /issuers/list":               {Summary: "Issuers list partial", Tag: "Pages", HTML: true},
+	"GET /issuers/select":             {Summary: "Issuers select partial", Tag: "Pages", HTML: true},
+	"GET /issuers/:id":                {Summary: "Issuer page with its bills and balances, filtered by status", Tag: "Pages", HTML: true},
+	"GET /issuers/:id/edit":           {Summary: "Inline form for editing an issuer", Tag: "Pages", HTML: true},
+	"PUT /issuers/:id":                {Summary: "Update an issuer", Tag: "Pages", HTML: true, Form: partyForm},
+	"DELETE /issuers/:id":             {Summary: "Move an issuer to the trash", Tag: "Pages", HTML: true},
+	"GET /bill-items":                 {Summary: "Bill items page, filtered, sorted and paged by the query", Tag: "Pages", HTML: true},
+	"POST /bill-items":                {Summary: "Create a bill item", Tag: "Pages", HTML: true, Form: []string{"name", "price", "currency"}},
+	"GET /bill-items/list":            {Summary: "Bill items list partial, filtered, sorted and paged by the query", Tag: "Pages", HTML: true},
+	"GET /bill-items/select":          {Summary: "Bill items select partial", Tag: "Pages", HTML: true},
+	"GET /bill-items/:id/edit":        {Summary: "Inline form for editing a bill item", Tag: "Pages", HTML: true},
+	"PUT /bill-items/:id":             {Summary: "Update a bill item", Tag: "Pages", HTML: true, Form: []string{"name", "price", "currency"}},
+	"DELETE /bill-items/:id":          {Summary: "Move a bill item to the trash", Tag: "Pages", HTML: true},
+	"GET /search":                     {Summary: "Search page with the bills, parties and bill items matching the q query", Tag: "Pages", HTML: true},
+	"GET /search/results":             {Summary: "Search results partial for the q query, grouped by entity", Tag: "Pages", HTML: true},
+	"GET /login":                      {Summary: "Login page", Tag: "Auth", HTML: true},
+	"POST /login":                     {Summary: "Sign in and start a session", Tag: "Auth", HTML: true, Form: []string{"username", "password", "next"}},
+	"POST /logout":                    {Summary: "End the current session", Tag: "Auth", HTML: true},
+	"POST /workspaces/switch":         {Summary: "Work in another workspace of the signed in user", Tag: "Auth", HTML: true, Form: []string{"workspace_id"}},
+	"GET /admin/users":                {Summary: "User administration page", Tag: "Admin", HTML: true},
+	"POST /admin/users/:id/role":      {Summary: "Assign a role to a user", Tag: "Admin", HTML: true, Form: []string{"role"}},
+	"GET /audit":                      {Summary: "Audit log page, filtered by entity and user", Tag: "Admin", HTML: true},
+	"GET /audit/list":                 {Summary: "Audit events partial, filtered by entity and user", Tag: "Admin", HTML: true},
+	"GET /trash":                      {Summary: "Trash page listing deleted rows", Tag: "Admin", HTML: true},
+	"POST /trash/:entity/:id/restore": {Summary: "Restore a row from the trash", Tag: "Admin", HTML: true},
+	"DELETE /trash/:entity/:id":       {Summary: "Purge a row from the trash for good", Tag: "Admin", HTML: true},
+	"GET /admin/backups":              {Summary: "Backups page offering the archive of the workspace, and the database snapshots to instance admins", Tag: "Admin", HTML: true},
+	"POST /admin/backups":             {Summary: "Take a snapshot of the SQLite database and rotate the old ones out, for instance admins", Tag: "Admin", HTML: true},
+	"GET /admin/backups/:name":        {Summary: "Download a database snapshot, for instance admins", Tag: "Admin", HTML: true},
+	"GET /admin/archive":              {Summary: "Download the workspace as a JSON archive", Tag: "Admin", HTML: true},
+	"GET /account/tokens":             {Summary: "API tokens page of the signed in user", Tag: "Auth", HTML: true},
+	"POST /account/tokens":            {Summary: "Create an API token, shown once", Tag: "Auth", HTML: true, Form: []string{"name", "scope", "expires_in_days"}},
+	"DELETE /account/tokens/:id":      {Summary: "Revoke an API token", Tag: "Auth", HTML: true},
+	"GET /openapi.json":               {Summary: "OpenAPI document describing this API", Tag: "Meta", Status: http.StatusOK},
+	"GET /api/docs":                   {Summary: "API explorer", Tag: "Meta", HTML: true},
+
+	// Bills
+	"GET /api/v1/bills":              {Summary: "List bills", Tag: "Bills", Status: http.StatusOK, Response: []models.Bill{}},
+	"POST /api/v1/bills":             {Summary: "Create a bill with its items", Tag: "Bills", Status: http.StatusCreated, Request: models.Bill{}, Response: models.Bill{}},
+	"GET /api/v1/bills/:id":          {Summary: "Get a bill", Tag: "Bills", Status: http.StatusOK, Response: models.Bill{}},
+	"PUT /api/v1/bills/:id":          {Summary: "Update the due date, parties and paid status of a bill", Tag: "Bills", Status: http.StatusOK, Request: api.BillUpdate{}, Response: models.Bill{}},
+	"DELETE /api/v1/bills/:id":       {Summary: "Move a bill to the trash", Tag: "Bills", Status: http.StatusNoContent},
+	"GET /api/v1/bills/:id/items":    {Summary: "List the lines of a bill", Tag: "Assignments", Status: http.StatusOK, Response: []models.BillItemAssignment{}},
+	"POST /api/v1/bills/:id/items":   {Summary: "Add a line to a bill", Tag: "Assignments", Status: http.StatusCreated, Request: models.BillItemAssignment{}, Response: models.BillItemAssignment{}},
+	"GET /api/v1/assignments/:id":    {Summary: "Get a bill line", Tag: "Assignments", Status: http.StatusOK, Response: models.BillItemAssignment{}},
+	"PUT /api/v1/assignments/:id":    {Summary: "Update a bill line", Tag: "Assignments", Status: http.StatusOK, Request: models.BillItemAssignment{}, Response: models.BillItemAssignment{}},
+	"DELETE /api/v1/assignments/:id": {Summary: "Remove a line from its bill", Tag: "Assignments", Status: http.StatusNoContent},
+
+	// Issuers
+	"GET /api/v1/issuers":        {Summary: "List issuers", Tag: "Issuers", Status: http.StatusOK, Response: []models.Issuer{}},
+	"POST /api/v1/issuers":       {Summary: "Create an issuer", Tag: "Issuers", Status: http.StatusCreated, Request: models.Issuer{}, Response: models.Issuer{}},
+	"GET /api/v1/issuers/:id":    {Summary: "Get an issuer", Tag: "Issuers", Status: http.StatusOK, Response: models.Issuer{}},
+	"PUT /api/v1/issuers/:id":    {Summary: "Update an issuer", Tag: "Issuers", Status: http.StatusOK, Request: models.Issuer{}, Response: models.Issuer{}},
+	"DELETE /api/v1/issuers/:id": {Summary: "Move an issuer to the trash", Tag: "Issuers", Status: http.StatusNoContent},
+
+	// Receivers
+	"GET /api/v1/receivers":        {Summary: "List receivers", Tag: "Receivers", Status: http.StatusOK, Response: []models.Receiver{}},
+	"POST /api/v1/receivers":       {Summary: "Create a receiver", Tag: "Receivers", Status: http.StatusCreated, Request: models.Receiver{}, Response: models.Receiver{}},
+	"GET /api/v1/receivers/:id":    {Summary: "Get a receiver", Tag: "Receivers", Status: http.StatusOK, Response: models.Receiver{}},
+	"PUT /api/v1/receivers/:id":    {Summary: "Update a receiver", Tag: "Receivers", Status: http.StatusOK, Request: models.Receiver{}, Response: models.Receiver{}},
+	"DELETE /api/v1/receivers/:id": {Summary: "Move a receiver to the trash", Tag: "Receivers", Status: http.StatusNoContent},
+
+	// Bill items
+	"GET /api/v1/bill-items":        {Summary: "List bill items", Tag: "Bill Items", Status: http.StatusOK, Response: []models.BillItem{}},
+	"POST /api/v1/bill-items":       {Summary: "Create a bill item", Tag: "Bill Items", Status: http.StatusCreated, Request: models.BillItem{}, Response: models.BillItem{}},
+	"GET /api/v1/bill-items/:id":    {Summary: "Get a bill item", Tag: "Bill Items", Status: http.StatusOK, Response: models.BillItem{}},
+	"PUT /api/v1/bill-items/:id":    {Summary: "Update a bill item", Tag: "Bill Items", Status: http.StatusOK, Request: models.BillItem{}, Response: models.BillItem{}},
+	"DELETE /api/v1/bill-items/:id": {Summary: "Move a bill item to the trash", Tag: "Bill Items", Status: http.StatusNoContent},
+}
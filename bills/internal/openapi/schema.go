@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema of t. Named structs are added to schemas and
+// referenced, everything else is described inline
+func schemaFor(t reflect.Type, schemas map[string]*Schema) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		return structSchema(t, schemas)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem(), schemas)}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object"}
+	case t.Kind() == reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case t.Kind() == reflect.String:
+		return &Schema{Type: "string"}
+	case t.Kind() == reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	}
+	return &Schema{}
+}
+
+// structSchema registers a struct in schemas and returns a reference to it
+func structSchema(t reflect.Type, schemas map[string]*Schema) *Schema {
+	name := t.Name()
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := schemas[name]; ok {
+		return ref
+	}
+
+	// Register before walking the fields so recursive types terminate
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	schemas[name] = schema
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonName := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			jsonName = strings.Split(tag, ",")[0]
+		}
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		schema.Properties[jsonName] = schemaFor(field.Type, schemas)
+	}
+	return ref
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"bills/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// APITokenRepository defines the interface for API token storage operations
+type APITokenRepository interface {
+	Create(ctx context.Context, token *models.APIToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*models.APIToken, error)
+	Delete(ctx context.Context, id, userID int64) error
+	Touch(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+// SQLiteAPITokenRepository implements APITokenRepository using SQLite
+type SQLiteAPITokenRepository struct {
+	db conn
+}
+
+// NewSQLiteAPITokenRepository creates a new SQLite repository instance
+func NewSQLiteAPITokenRepository(db *sql.DB) *SQLiteAPITokenRepository {
+	return &SQLiteAPITokenRepository{db: dbConn{db}}
+}
+
+func (r *SQLiteAPITokenRepository) Create(ctx context.Context, token *models.APIToken) error {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO api_tokens (user_id, workspace_id, name, token_hash, scope, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, token.UserID, token.WorkspaceID, token.Name, token.TokenHash, token.Scope, token.ExpiresAt, token.CreatedAt).Scan(&id)
+	if err != nil {
+		return err
+	}
+	token.ID = id
+	return nil
+}
+
+func (r *SQLiteAPITokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, workspace_id, name, token_hash, scope, last_used_at, expires_at, created_at
+		FROM api_tokens WHERE token_hash = ?
+	`, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("API token")
+	}
+	return token, err
+}
+
+func (r *SQLiteAPITokenRepository) GetByUserID(ctx context.Context, userID int64) ([]*models.APIToken, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, workspace_id, name, token_hash, scope, last_used_at, expires_at, created_at
+		FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC, id DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*models.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// Delete revokes a token. Only tokens of the given user are deleted, the
+// tokens of other users are not found
+func (r *SQLiteAPITokenRepository) Delete(ctx context.Context, id, userID int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM api_tokens WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return models.NotFound("API token")
+	}
+	return nil
+}
+
+// Touch records when the token was last used
+func (r *SQLiteAPITokenRepository) Touch(ctx context.Context, id int64, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE api_tokens SET last_used_at = ? WHERE id = ?", usedAt, id)
+	return err
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIToken(row scanner) (*models.APIToken, error) {
+	token := &models.APIToken{}
+	var lastUsedAt, expiresAt sql.NullTime
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.WorkspaceID,
+		&token.Name,
+		&token.TokenHash,
+		&token.Scope,
+		&lastUsedAt,
+		&expiresAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	return token, nil
+}
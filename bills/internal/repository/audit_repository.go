@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"bills/internal/models"
+	"bills/internal/tenant"
+)
+
+// AuditRepository reads the audit log. Events are only written by the other
+// repositories, inside the transaction of the change they record. Every
+// method works in the workspace of ctx
+type AuditRepository interface {
+	List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error)
+	GetActors(ctx context.Context) ([]*models.User, error)
+}
+
+// SQLiteAuditRepository implements AuditRepository using SQLite
+type SQLiteAuditRepository struct {
+	db conn
+}
+
+// NewSQLiteAuditRepository creates a new SQLite repository instance
+func NewSQLiteAuditRepository(db *sql.DB) *SQLiteAuditRepository {
+	return &SQLiteAuditRepository{db: dbConn{db}}
+}
+
+// List returns the events matching filter, newest first
+func (r *SQLiteAuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{"e.workspace_id = ?"}
+	args := []interface{}{workspaceID}
+	if filter.Entity != "" {
+		where = append(where, "e.entity = ?")
+		args = append(args, filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		where = append(where, "e.entity_id = ?")
+		args = append(args, filter.EntityID)
+	}
+	if filter.BillID != 0 {
+		// Lines name their bill in both snapshots, deleted lines in before only
+		where = append(where, `((e.entity = ? AND e.entity_id = ?) OR (e.entity = ? AND ? IN (
+			json_extract(e.before_data, '$.bill_id'), json_extract(e.after_data, '$.bill_id'))))`)
+		args = append(args, models.EntityBills, filter.BillID, models.EntityBillLines, filter.BillID)
+	}
+	if filter.ActorID != 0 {
+		where = append(where, "e.actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	query := `
+		SELECT e.id, e.workspace_id, e.actor_id, e.entity, e.entity_id, e.action,
+			   e.before_data, e.after_data, e.created_at, COALESCE(u.username, '')
+		FROM audit_events e
+		LEFT JOIN users u ON e.actor_id = u.id
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY e.id DESC`
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.AuditEvent
+	for rows.Next() {
+		event := &models.AuditEvent{}
+		var actorID sql.NullInt64
+		var before, after sql.NullString
+		err := rows.Scan(
+			&event.ID,
+			&event.WorkspaceID,
+			&actorID,
+			&event.Entity,
+			&event.EntityID,
+			&event.Action,
+			&before,
+			&after,
+			&event.CreatedAt,
+			&event.ActorName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if actorID.Valid {
+			event.ActorID = &actorID.Int64
+		}
+		if before.Valid {
+			event.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			event.After = json.RawMessage(after.String)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetActors returns the users that made changes in the workspace, for
+// filtering the log
+func (r *SQLiteAuditRepository) GetActors(ctx context.Context) ([]*models.User, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT u.id, u.username
+		FROM audit_events e
+		JOIN users u ON e.actor_id = u.id
+		WHERE e.workspace_id = ?
+		ORDER BY u.username ASC
+	`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(&user.ID, &user.Username); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// snapshotRow returns the row of table with the given id as a JSON object, or
+// nil when the workspace has no such row
+func snapshotRow(ctx context.Context, tx txn, table string, id, workspaceID int64) (json.RawMessage, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT * FROM "+table+" WHERE id = ? AND workspace_id = ?", id, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if b, ok := values[i].([]byte); ok {
+			values[i] = string(b)
+		}
+		row[column] = values[i]
+	}
+	return json.Marshal(row)
+}
+
+// recordChange appends an audit event for a change to a row of table. before
+// is the snapshot taken ahead of the change, the row is read again for the
+// state after it
+func recordChange(ctx context.Context, tx txn, table string, id, workspaceID int64, action models.AuditAction, before json.RawMessage) error {
+	after, err := snapshotRow(ctx, tx, table, id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	var actor interface{}
+	if actorID, ok := tenant.ActorID(ctx); ok {
+		actor = actorID
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_events (workspace_id, actor_id, entity, entity_id, action, before_data, after_data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, workspaceID, actor, table, id, action, nullJSON(before), nullJSON(after), time.Now())
+	return err
+}
+
+// nullJSON stores an empty snapshot as NULL
+func nullJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -1,40 +1,65 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"bills/internal/models"
+	"bills/internal/tenant"
 )
 
-// BillItemAssignmentRepository defines the interface for bill item assignment storage operations
+// BillItemAssignmentRepository defines the interface for bill item assignment storage operations.
+// Every method works in the workspace of ctx. GetByID, Update and Delete return
+// models.ErrNotFound when the workspace has no such assignment
 type BillItemAssignmentRepository interface {
-	Create(assignment *models.BillItemAssignment) error
-	GetByID(id int64) (*models.BillItemAssignment, error)
-	GetByBillID(billID int64) ([]*models.BillItemAssignment, error)
-	Update(assignment *models.BillItemAssignment) error
-	Delete(id int64) error
-	DeleteByBillID(billID int64) error
+	Create(ctx context.Context, assignment *models.BillItemAssignment) error
+	GetByID(ctx context.Context, id int64) (*models.BillItemAssignment, error)
+	GetByBillID(ctx context.Context, billID int64) ([]*models.BillItemAssignment, error)
+	Update(ctx context.Context, assignment *models.BillItemAssignment) error
+	Delete(ctx context.Context, id int64) error
+	DeleteByBillID(ctx context.Context, billID int64) error
 }
 
 // SQLiteBillItemAssignmentRepository implements BillItemAssignmentRepository using SQLite
 type SQLiteBillItemAssignmentRepository struct {
-	db *sql.DB
+	db conn
 }
 
 // NewSQLiteBillItemAssignmentRepository creates a new SQLite repository instance
 func NewSQLiteBillItemAssignmentRepository(db *sql.DB) *SQLiteBillItemAssignmentRepository {
-	return &SQLiteBillItemAssignmentRepository{db: db}
+	return &SQLiteBillItemAssignmentRepository{db: dbConn{db}}
 }
 
-func (r *SQLiteBillItemAssignmentRepository) Create(assignment *models.BillItemAssignment) error {
+func (r *SQLiteBillItemAssignmentRepository) Create(ctx context.Context, assignment *models.BillItemAssignment) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkWorkspace(ctx, tx, "bills", assignment.BillID, workspaceID); err != nil {
+		return err
+	}
+	if err := checkWorkspace(ctx, tx, "bill_items", assignment.ItemID, workspaceID); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO bill_item_assignments (
-			bill_id, item_id, quantity, price, currency, exchange_rate,
+			workspace_id, bill_id, item_id, quantity, price, currency, exchange_rate,
 			original_amount, eur_amount, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
-	result, err := r.db.Exec(query,
+	var id int64
+	err = tx.QueryRowContext(ctx, query,
+		workspaceID,
 		assignment.BillID,
 		assignment.ItemID,
 		assignment.Quantity,
@@ -45,31 +70,35 @@ func (r *SQLiteBillItemAssignmentRepository) Create(assignment *models.BillItemA
 		assignment.EURAmount,
 		time.Now(),
 		time.Now(),
-	)
+	).Scan(&id)
 	if err != nil {
 		return err
 	}
+	assignment.ID = id
 
-	id, err := result.LastInsertId()
-	if err != nil {
+	if err := recordChange(ctx, tx, models.EntityBillLines, id, workspaceID, models.AuditCreate, nil); err != nil {
 		return err
 	}
-	assignment.ID = id
-	return nil
+	return tx.Commit()
 }
 
-func (r *SQLiteBillItemAssignmentRepository) GetByID(id int64) (*models.BillItemAssignment, error) {
+func (r *SQLiteBillItemAssignmentRepository) GetByID(ctx context.Context, id int64) (*models.BillItemAssignment, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	assignment := &models.BillItemAssignment{
 		BillItem: &models.BillItem{},
 	}
-	err := r.db.QueryRow(`
+	err = r.db.QueryRowContext(ctx, `
 		SELECT a.id, a.bill_id, a.item_id, a.quantity, a.price, a.currency,
 			   a.exchange_rate, a.original_amount, a.eur_amount, a.created_at, a.updated_at,
 			   i.name, i.price, i.currency, i.created_at, i.updated_at
 		FROM bill_item_assignments a
 		LEFT JOIN bill_items i ON a.item_id = i.id
-		WHERE a.id = ?
-	`, id).Scan(
+		WHERE a.id = ? AND a.workspace_id = ?
+	`, id, workspaceID).Scan(
 		&assignment.ID,
 		&assignment.BillID,
 		&assignment.ItemID,
@@ -88,21 +117,26 @@ func (r *SQLiteBillItemAssignmentRepository) GetByID(id int64) (*models.BillItem
 		&assignment.BillItem.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, models.NotFound("assignment")
 	}
 	return assignment, err
 }
 
-func (r *SQLiteBillItemAssignmentRepository) GetByBillID(billID int64) ([]*models.BillItemAssignment, error) {
-	rows, err := r.db.Query(`
+func (r *SQLiteBillItemAssignmentRepository) GetByBillID(ctx context.Context, billID int64) ([]*models.BillItemAssignment, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT a.id, a.bill_id, a.item_id, a.quantity, a.price, a.currency,
 			   a.exchange_rate, a.original_amount, a.eur_amount, a.created_at, a.updated_at,
 			   i.id, i.name, i.price, i.currency, i.created_at, i.updated_at
 		FROM bill_item_assignments a
 		LEFT JOIN bill_items i ON a.item_id = i.id
-		WHERE a.bill_id = ?
+		WHERE a.bill_id = ? AND a.workspace_id = ?
 		ORDER BY a.id ASC
-	`, billID)
+	`, billID, workspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -140,13 +174,32 @@ func (r *SQLiteBillItemAssignmentRepository) GetByBillID(billID int64) ([]*model
 	return assignments, rows.Err()
 }
 
-func (r *SQLiteBillItemAssignmentRepository) Update(assignment *models.BillItemAssignment) error {
+func (r *SQLiteBillItemAssignmentRepository) Update(ctx context.Context, assignment *models.BillItemAssignment) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityBillLines, assignment.ID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("assignment")
+	}
+
 	assignment.UpdatedAt = time.Now()
-	_, err := r.db.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE bill_item_assignments
 		SET quantity = ?, price = ?, currency = ?, exchange_rate = ?,
 			original_amount = ?, eur_amount = ?, updated_at = ?
-		WHERE id = ?
+		WHERE id = ? AND workspace_id = ?
 	`,
 		assignment.Quantity,
 		assignment.Price,
@@ -156,16 +209,104 @@ func (r *SQLiteBillItemAssignmentRepository) Update(assignment *models.BillItemA
 		assignment.EURAmount,
 		assignment.UpdatedAt,
 		assignment.ID,
+		workspaceID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if err := recordChange(ctx, tx, models.EntityBillLines, assignment.ID, workspaceID, models.AuditUpdate, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *SQLiteBillItemAssignmentRepository) Delete(id int64) error {
-	_, err := r.db.Exec("DELETE FROM bill_item_assignments WHERE id = ?", id)
-	return err
+func (r *SQLiteBillItemAssignmentRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityBillLines, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("assignment")
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM bill_item_assignments WHERE id = ? AND workspace_id = ?", id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if err := recordChange(ctx, tx, models.EntityBillLines, id, workspaceID, models.AuditDelete, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *SQLiteBillItemAssignmentRepository) DeleteByBillID(billID int64) error {
-	_, err := r.db.Exec("DELETE FROM bill_item_assignments WHERE bill_id = ?", billID)
-	return err
+func (r *SQLiteBillItemAssignmentRepository) DeleteByBillID(ctx context.Context, billID int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteBillLines(ctx, tx, billID, workspaceID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteBillLines deletes the lines of a bill one by one, so each gets an
+// audit event
+func deleteBillLines(ctx context.Context, tx txn, billID, workspaceID int64) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM bill_item_assignments WHERE bill_id = ? AND workspace_id = ?", billID, workspaceID)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := deleteLine(ctx, tx, id, workspaceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteLine deletes a line of a bill and records its audit event
+func deleteLine(ctx context.Context, tx txn, id, workspaceID int64) error {
+	before, err := snapshotRow(ctx, tx, models.EntityBillLines, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM bill_item_assignments WHERE id = ? AND workspace_id = ?", id, workspaceID); err != nil {
+		return err
+	}
+	return recordChange(ctx, tx, models.EntityBillLines, id, workspaceID, models.AuditDelete, before)
 }
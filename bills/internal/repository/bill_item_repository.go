@@ -1,95 +1,88 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"bills/internal/models"
+	"bills/internal/tenant"
 )
 
-// BillItemRepository defines the interface for bill item storage operations
+// BillItemRepository defines the interface for bill item storage operations.
+// Every method works in the workspace of ctx. Delete moves the bill item to
+// the trash, trashed bill items are left out until restored, see
+// TrashRepository. GetByID, Update and Delete return models.ErrNotFound when
+// the workspace has no such bill item outside the trash. List returns a page
+// of the bill items matching a filter, GetAll every bill item by name
 type BillItemRepository interface {
-	Create(item *models.BillItem) error
-	GetByID(id int64) (*models.BillItem, error)
-	GetAll() ([]*models.BillItem, error)
-	Update(item *models.BillItem) error
-	Delete(id int64) error
+	Create(ctx context.Context, item *models.BillItem) error
+	GetByID(ctx context.Context, id int64) (*models.BillItem, error)
+	GetAll(ctx context.Context) ([]*models.BillItem, error)
+	List(ctx context.Context, filter models.BillItemFilter) (*models.BillItemPage, error)
+	Update(ctx context.Context, item *models.BillItem) error
+	Delete(ctx context.Context, id int64) error
 }
 
 // SQLiteBillItemRepository implements BillItemRepository using SQLite
 type SQLiteBillItemRepository struct {
-	db *sql.DB
+	db conn
 }
 
 // NewSQLiteBillItemRepository creates a new SQLite repository instance
 func NewSQLiteBillItemRepository(db *sql.DB) *SQLiteBillItemRepository {
-	return &SQLiteBillItemRepository{db: db}
+	return &SQLiteBillItemRepository{db: dbConn{db}}
 }
 
-// InitBillItemDB initializes the bill_items table
-func InitBillItemDB(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS bill_items (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			price REAL NOT NULL,
-			currency TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		)
-	`)
+func (r *SQLiteBillItemRepository) Create(ctx context.Context, item *models.BillItem) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
 	if err != nil {
 		return err
 	}
 
-	// Create bill_item_assignments table
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS bill_item_assignments (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			bill_id INTEGER NOT NULL,
-			item_id INTEGER NOT NULL,
-			quantity INTEGER NOT NULL,
-			unit_price REAL NOT NULL,
-			subtotal REAL NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
-			FOREIGN KEY (item_id) REFERENCES bill_items(id) ON DELETE RESTRICT
-		)
-	`)
-	return err
-}
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-func (r *SQLiteBillItemRepository) Create(item *models.BillItem) error {
 	query := `
-		INSERT INTO bill_items (name, price, currency, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO bill_items (workspace_id, name, price, currency, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
-	result, err := r.db.Exec(query,
+	var id int64
+	err = tx.QueryRowContext(ctx, query,
+		workspaceID,
 		item.Name,
 		item.Price,
 		item.Currency,
 		time.Now(),
 		time.Now(),
-	)
+	).Scan(&id)
 	if err != nil {
 		return err
 	}
+	item.ID = id
 
-	id, err := result.LastInsertId()
-	if err != nil {
+	if err := recordChange(ctx, tx, models.EntityBillItems, id, workspaceID, models.AuditCreate, nil); err != nil {
 		return err
 	}
-	item.ID = id
-	return nil
+	return tx.Commit()
 }
 
-func (r *SQLiteBillItemRepository) GetByID(id int64) (*models.BillItem, error) {
+func (r *SQLiteBillItemRepository) GetByID(ctx context.Context, id int64) (*models.BillItem, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	item := &models.BillItem{}
-	err := r.db.QueryRow(`
+	err = r.db.QueryRowContext(ctx, `
 		SELECT id, name, price, currency, created_at, updated_at
-		FROM bill_items WHERE id = ?
-	`, id).Scan(
+		FROM bill_items WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
+	`, id, workspaceID).Scan(
 		&item.ID,
 		&item.Name,
 		&item.Price,
@@ -98,16 +91,61 @@ func (r *SQLiteBillItemRepository) GetByID(id int64) (*models.BillItem, error) {
 		&item.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, models.NotFound("bill item")
 	}
 	return item, err
 }
 
-func (r *SQLiteBillItemRepository) GetAll() ([]*models.BillItem, error) {
-	rows, err := r.db.Query(`
+func (r *SQLiteBillItemRepository) GetAll(ctx context.Context) ([]*models.BillItem, error) {
+	page, err := r.List(ctx, models.BillItemFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// billItemSortColumns maps the columns of models.BillItemSorts to what they
+// order by
+var billItemSortColumns = map[string]string{
+	models.BillItemSortName:  "name",
+	models.BillItemSortPrice: "price",
+}
+
+func (r *SQLiteBillItemRepository) List(ctx context.Context, filter models.BillItemFilter) (*models.BillItemPage, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{"workspace_id = ?", "deleted_at IS NULL"}
+	args := []interface{}{workspaceID}
+	if filter.Currency != "" {
+		where = append(where, "currency = ?")
+		args = append(args, filter.Currency)
+	}
+	if strings.TrimSpace(filter.Text) != "" {
+		where = append(where, `name LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(filter.Text))
+	}
+
+	column, desc := sortColumn(filter.Sort, models.BillItemSortName, billItemSortColumns)
+	order, after := keyset(column, "id", "workspace_id", "bill_items", desc)
+	if filter.After != 0 {
+		where = append(where, after)
+		args = append(args, filter.After, workspaceID, filter.After)
+	}
+
+	query := `
 		SELECT id, name, price, currency, created_at, updated_at
-		FROM bill_items ORDER BY name ASC
-	`)
+		FROM bill_items WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + order
+	// One more than asked tells whether there is a next page
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -129,26 +167,100 @@ func (r *SQLiteBillItemRepository) GetAll() ([]*models.BillItem, error) {
 		}
 		items = append(items, item)
 	}
-	return items, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &models.BillItemPage{Items: items}
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		page.Items = items[:filter.Limit]
+		page.Next = page.Items[filter.Limit-1].ID
+	}
+	return page, nil
 }
 
-func (r *SQLiteBillItemRepository) Update(item *models.BillItem) error {
+func (r *SQLiteBillItemRepository) Update(ctx context.Context, item *models.BillItem) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityBillItems, item.ID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("bill item")
+	}
+
 	item.UpdatedAt = time.Now()
-	_, err := r.db.Exec(`
+	result, err := tx.ExecContext(ctx, `
 		UPDATE bill_items
 		SET name = ?, price = ?, currency = ?, updated_at = ?
-		WHERE id = ?
+		WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
 	`,
 		item.Name,
 		item.Price,
 		item.Currency,
 		item.UpdatedAt,
 		item.ID,
+		workspaceID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Trashed rows are not changed
+		return models.NotFound("bill item")
+	}
+
+	if err := recordChange(ctx, tx, models.EntityBillItems, item.ID, workspaceID, models.AuditUpdate, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *SQLiteBillItemRepository) Delete(id int64) error {
-	_, err := r.db.Exec("DELETE FROM bill_items WHERE id = ?", id)
-	return err
+func (r *SQLiteBillItemRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityBillItems, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("bill item")
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE bill_items SET deleted_at = ? WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL", time.Now(), id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Already in the trash
+		return models.NotFound("bill item")
+	}
+
+	if err := recordChange(ctx, tx, models.EntityBillItems, id, workspaceID, models.AuditDelete, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
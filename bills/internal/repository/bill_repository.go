@@ -1,73 +1,77 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"bills/internal/models"
+	"bills/internal/tenant"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// BillRepository defines the interface for bill storage operations
+// BillRepository defines the interface for bill storage operations. Every
+// method works in the workspace of ctx. Delete moves the bill to the trash,
+// trashed bills are left out until restored, see TrashRepository.
+// GetByID, Update, UpdateWithItems and Delete return models.ErrNotFound when
+// the workspace has no such bill outside the trash. Update leaves the lines
+// alone, UpdateWithItems replaces them with the Items of the bill. List
+// returns a page of the bills matching a filter, the other getters every
+// bill. The Sum methods add up the bills of a party in the base currency
 type BillRepository interface {
-	Create(bill *models.Bill) error
-	GetByID(id int64) (*models.Bill, error)
-	GetAll() ([]*models.Bill, error)
-	Update(bill *models.Bill) error
-	Delete(id int64) error
+	Create(ctx context.Context, bill *models.Bill) error
+	GetByID(ctx context.Context, id int64) (*models.Bill, error)
+	GetAll(ctx context.Context) ([]*models.Bill, error)
+	List(ctx context.Context, filter models.BillFilter) (*models.BillPage, error)
+	GetByIssuer(ctx context.Context, issuerID int64) ([]*models.Bill, error)
+	GetByReceiver(ctx context.Context, receiverID int64) ([]*models.Bill, error)
+	SumByIssuer(ctx context.Context, issuerID int64) (*models.BillTotals, error)
+	SumByReceiver(ctx context.Context, receiverID int64) (*models.BillTotals, error)
+	Update(ctx context.Context, bill *models.Bill) error
+	UpdateWithItems(ctx context.Context, bill *models.Bill) error
+	Delete(ctx context.Context, id int64) error
 }
 
 // SQLiteBillRepository implements BillRepository using SQLite
 type SQLiteBillRepository struct {
-	db *sql.DB
+	db conn
 }
 
 // NewSQLiteBillRepository creates a new SQLite repository instance
 func NewSQLiteBillRepository(db *sql.DB) *SQLiteBillRepository {
-	return &SQLiteBillRepository{db: db}
+	return &SQLiteBillRepository{db: dbConn{db}}
 }
 
-// InitDB initializes the database and creates the bills table
-func InitDB(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+func (r *SQLiteBillRepository) Create(ctx context.Context, bill *models.Bill) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Create bills table with foreign keys for issuers and receivers
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS bills (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			amount REAL NOT NULL,
-			due_date DATETIME NOT NULL,
-			paid BOOLEAN DEFAULT FALSE,
-			issuer_id INTEGER NOT NULL,
-			receiver_id INTEGER NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			FOREIGN KEY (issuer_id) REFERENCES issuers(id),
-			FOREIGN KEY (receiver_id) REFERENCES receivers(id)
-		)
-	`)
-	return db, err
-}
-
-func (r *SQLiteBillRepository) Create(bill *models.Bill) error {
-	tx, err := r.db.Begin()
+	tx, err := r.db.begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if err := checkParties(ctx, tx, bill, workspaceID); err != nil {
+		return err
+	}
+
 	// Insert bill
 	query := `
 		INSERT INTO bills (
-			due_date, currency, original_total, eur_total, paid,
-			issuer_id, receiver_id, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			workspace_id, issue_date, due_date, currency, original_total,
+			eur_total, paid, issuer_id, receiver_id, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
-	result, err := tx.Exec(query,
+	var billID int64
+	err = tx.QueryRowContext(ctx, query,
+		workspaceID,
+		bill.IssueDate,
 		bill.DueDate,
 		bill.Currency,
 		bill.OriginalTotal,
@@ -77,40 +81,20 @@ func (r *SQLiteBillRepository) Create(bill *models.Bill) error {
 		bill.ReceiverID,
 		time.Now(),
 		time.Now(),
-	)
+	).Scan(&billID)
 	if err != nil {
 		return err
 	}
+	bill.ID = billID
 
-	billID, err := result.LastInsertId()
-	if err != nil {
+	if err := recordChange(ctx, tx, models.EntityBills, billID, workspaceID, models.AuditCreate, nil); err != nil {
 		return err
 	}
-	bill.ID = billID
 
 	// Insert bill items
 	for _, item := range bill.Items {
 		item.BillID = billID
-		query = `
-			INSERT INTO bill_item_assignments (
-				bill_id, item_id, quantity, price, currency,
-				exchange_rate, original_amount, eur_amount,
-				created_at, updated_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`
-		_, err = tx.Exec(query,
-			item.BillID,
-			item.ItemID,
-			item.Quantity,
-			item.Price,
-			item.Currency,
-			item.ExchangeRate,
-			item.OriginalAmount,
-			item.EURAmount,
-			time.Now(),
-			time.Now(),
-		)
-		if err != nil {
+		if err := insertLine(ctx, tx, item, workspaceID); err != nil {
 			return err
 		}
 	}
@@ -118,19 +102,25 @@ func (r *SQLiteBillRepository) Create(bill *models.Bill) error {
 	return tx.Commit()
 }
 
-func (r *SQLiteBillRepository) GetByID(id int64) (*models.Bill, error) {
+func (r *SQLiteBillRepository) GetByID(ctx context.Context, id int64) (*models.Bill, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	bill := &models.Bill{}
-	err := r.db.QueryRow(`
-		SELECT b.id, b.due_date, b.paid, b.issuer_id, b.receiver_id,
+	err = r.db.QueryRowContext(ctx, `
+		SELECT b.id, b.issue_date, b.due_date, b.paid, b.issuer_id, b.receiver_id,
 			   b.currency, b.original_total, b.eur_total,
 			   b.created_at, b.updated_at,
 			   i.name as issuer_name, r.name as receiver_name
 		FROM bills b
 		LEFT JOIN issuers i ON b.issuer_id = i.id
 		LEFT JOIN receivers r ON b.receiver_id = r.id
-		WHERE b.id = ?
-	`, id).Scan(
+		WHERE b.id = ? AND b.workspace_id = ? AND b.deleted_at IS NULL
+	`, id, workspaceID).Scan(
 		&bill.ID,
+		&bill.IssueDate,
 		&bill.DueDate,
 		&bill.Paid,
 		&bill.IssuerID,
@@ -144,25 +134,225 @@ func (r *SQLiteBillRepository) GetByID(id int64) (*models.Bill, error) {
 		&bill.ReceiverName,
 	)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, models.NotFound("bill")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadLines(ctx, workspaceID, []*models.Bill{bill}); err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+func (r *SQLiteBillRepository) GetAll(ctx context.Context) ([]*models.Bill, error) {
+	page, err := r.List(ctx, models.BillFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Bills, nil
+}
+
+func (r *SQLiteBillRepository) GetByIssuer(ctx context.Context, issuerID int64) ([]*models.Bill, error) {
+	page, err := r.List(ctx, models.BillFilter{IssuerID: issuerID})
+	if err != nil {
+		return nil, err
 	}
+	return page.Bills, nil
+}
+
+func (r *SQLiteBillRepository) GetByReceiver(ctx context.Context, receiverID int64) ([]*models.Bill, error) {
+	page, err := r.List(ctx, models.BillFilter{ReceiverID: receiverID})
 	if err != nil {
 		return nil, err
 	}
+	return page.Bills, nil
+}
+
+func (r *SQLiteBillRepository) SumByIssuer(ctx context.Context, issuerID int64) (*models.BillTotals, error) {
+	return r.sum(ctx, "issuer_id = ?", issuerID)
+}
+
+func (r *SQLiteBillRepository) SumByReceiver(ctx context.Context, receiverID int64) (*models.BillTotals, error) {
+	return r.sum(ctx, "receiver_id = ?", receiverID)
+}
+
+// billSortColumns maps the columns of models.BillSorts to what they order by
+var billSortColumns = map[string]string{
+	models.BillSortDueDate:   "b.due_date",
+	models.BillSortIssueDate: "b.issue_date",
+	models.BillSortAmount:    "b.eur_total",
+	models.BillSortIssuer:    "i.name",
+	models.BillSortReceiver:  "r.name",
+}
+
+// billsFrom joins the bills table b with the names of their parties
+const billsFrom = `bills b
+		LEFT JOIN issuers i ON b.issuer_id = i.id
+		LEFT JOIN receivers r ON b.receiver_id = r.id`
+
+func (r *SQLiteBillRepository) List(ctx context.Context, filter models.BillFilter) (*models.BillPage, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{"b.workspace_id = ?", "b.deleted_at IS NULL"}
+	args := []interface{}{workspaceID}
+	switch filter.Status {
+	case models.BillStatusPaid:
+		where = append(where, "b.paid")
+	case models.BillStatusUnpaid:
+		where = append(where, "NOT b.paid")
+	case models.BillStatusOverdue:
+		where = append(where, "NOT b.paid AND b.due_date < ?")
+		args = append(args, startOfDay(time.Now()))
+	}
+	if filter.IssuerID != 0 {
+		where = append(where, "b.issuer_id = ?")
+		args = append(args, filter.IssuerID)
+	}
+	if filter.ReceiverID != 0 {
+		where = append(where, "b.receiver_id = ?")
+		args = append(args, filter.ReceiverID)
+	}
+	if filter.Currency != "" {
+		where = append(where, "b.currency = ?")
+		args = append(args, filter.Currency)
+	}
+	if !filter.DueFrom.IsZero() {
+		where = append(where, "b.due_date >= ?")
+		args = append(args, startOfDay(filter.DueFrom))
+	}
+	if !filter.DueTo.IsZero() {
+		where = append(where, "b.due_date < ?")
+		args = append(args, startOfDay(filter.DueTo).AddDate(0, 0, 1))
+	}
+	if filter.MinAmount > 0 {
+		where = append(where, "b.eur_total >= ?")
+		args = append(args, filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		where = append(where, "b.eur_total <= ?")
+		args = append(args, filter.MaxAmount)
+	}
+	if strings.TrimSpace(filter.Text) != "" {
+		where = append(where, `(i.name LIKE ? ESCAPE '\' OR r.name LIKE ? ESCAPE '\' OR EXISTS (
+			SELECT 1 FROM bill_item_assignments a JOIN bill_items t ON a.item_id = t.id
+			WHERE a.bill_id = b.id AND t.name LIKE ? ESCAPE '\'))`)
+		pattern := likePattern(filter.Text)
+		args = append(args, pattern, pattern, pattern)
+	}
 
-	// Load items
-	rows, err := r.db.Query(`
+	column, desc := sortColumn(filter.Sort, "-"+models.BillSortDueDate, billSortColumns)
+	order, after := keyset(column, "b.id", "b.workspace_id", billsFrom, desc)
+	if filter.After != 0 {
+		where = append(where, after)
+		args = append(args, filter.After, workspaceID, filter.After)
+	}
+
+	query := `
+		SELECT b.id, b.issue_date, b.due_date, b.paid, b.issuer_id, b.receiver_id,
+			   b.currency, b.original_total, b.eur_total,
+			   b.created_at, b.updated_at,
+			   i.name as issuer_name, r.name as receiver_name
+		FROM ` + billsFrom + `
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + order
+	// One more than asked tells whether there is a next page
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bills []*models.Bill
+	for rows.Next() {
+		bill := &models.Bill{}
+		err := rows.Scan(
+			&bill.ID,
+			&bill.IssueDate,
+			&bill.DueDate,
+			&bill.Paid,
+			&bill.IssuerID,
+			&bill.ReceiverID,
+			&bill.Currency,
+			&bill.OriginalTotal,
+			&bill.EURTotal,
+			&bill.CreatedAt,
+			&bill.UpdatedAt,
+			&bill.IssuerName,
+			&bill.ReceiverName,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		bills = append(bills, bill)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &models.BillPage{Bills: bills}
+	if filter.Limit > 0 && len(bills) > filter.Limit {
+		page.Bills = bills[:filter.Limit]
+		page.Next = page.Bills[filter.Limit-1].ID
+	}
+	if !filter.SkipItems {
+		if err := r.loadLines(ctx, workspaceID, page.Bills); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// billLinesBatch caps how many bills have their lines loaded by one query,
+// well under the number of variables SQLite allows in a statement
+const billLinesBatch = 500
+
+// loadLines sets the Items of bills to their lines with their catalog items,
+// one query per billLinesBatch bills
+func (r *SQLiteBillRepository) loadLines(ctx context.Context, workspaceID int64, bills []*models.Bill) error {
+	byID := make(map[int64]*models.Bill, len(bills))
+	for _, bill := range bills {
+		byID[bill.ID] = bill
+	}
+
+	for start := 0; start < len(bills); start += billLinesBatch {
+		batch := bills[start:min(start+billLinesBatch, len(bills))]
+		args := []interface{}{workspaceID}
+		for _, bill := range batch {
+			args = append(args, bill.ID)
+		}
+		if err := r.loadLineBatch(ctx, byID, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLineBatch appends the lines of the bills with the ids in args, after
+// the workspace id, to the bills in byID
+func (r *SQLiteBillRepository) loadLineBatch(ctx context.Context, byID map[int64]*models.Bill, args []interface{}) error {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT a.id, a.bill_id, a.item_id, a.quantity, a.price,
 			   a.currency, a.exchange_rate, a.original_amount, a.eur_amount,
 			   a.created_at, a.updated_at,
 			   i.id, i.name, i.price, i.currency, i.created_at, i.updated_at
 		FROM bill_item_assignments a
 		LEFT JOIN bill_items i ON a.item_id = i.id
-		WHERE a.bill_id = ?
-		ORDER BY a.id ASC
-	`, bill.ID)
+		WHERE a.workspace_id = ? AND a.bill_id IN (?`+strings.Repeat(", ?", len(args)-2)+`)
+		ORDER BY a.bill_id, a.id ASC
+	`, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
@@ -190,112 +380,176 @@ func (r *SQLiteBillRepository) GetByID(id int64) (*models.Bill, error) {
 			&assignment.BillItem.UpdatedAt,
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		bill := byID[assignment.BillID]
 		bill.Items = append(bill.Items, assignment)
 	}
-
-	return bill, rows.Err()
+	return rows.Err()
 }
 
-func (r *SQLiteBillRepository) GetAll() ([]*models.Bill, error) {
-	rows, err := r.db.Query(`
-		SELECT b.id, b.due_date, b.paid, b.issuer_id, b.receiver_id,
-			   b.currency, b.original_total, b.eur_total,
-			   b.created_at, b.updated_at,
-			   i.name as issuer_name, r.name as receiver_name
-		FROM bills b
-		LEFT JOIN issuers i ON b.issuer_id = i.id
-		LEFT JOIN receivers r ON b.receiver_id = r.id
-		ORDER BY b.due_date DESC
-	`)
+// sum adds up the bills of the workspace of ctx outside the trash that match
+// the condition
+func (r *SQLiteBillRepository) sum(ctx context.Context, condition string, args ...interface{}) (*models.BillTotals, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var bills []*models.Bill
+	totals := &models.BillTotals{}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			   COALESCE(SUM(eur_total), 0),
+			   COALESCE(SUM(CASE WHEN paid THEN eur_total ELSE 0 END), 0)
+		FROM bills
+		WHERE workspace_id = ? AND deleted_at IS NULL AND `+condition,
+		append([]interface{}{workspaceID}, args...)...,
+	).Scan(&totals.Count, &totals.Billed, &totals.Paid)
+	if err != nil {
+		return nil, err
+	}
+	totals.Outstanding = totals.Billed - totals.Paid
+	return totals, nil
+}
+
+func (r *SQLiteBillRepository) Update(ctx context.Context, bill *models.Bill) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateBill(ctx, tx, bill, workspaceID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateWithItems updates the bill together with its lines. Lines without an
+// id are added, lines with one are updated and lines of the bill missing from
+// Items are deleted. The amounts and totals are recalculated, so they always
+// match the lines that were saved
+func (r *SQLiteBillRepository) UpdateWithItems(ctx context.Context, bill *models.Bill) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range bill.Items {
+		item.CalculateAmounts()
+	}
+	bill.ResolveCurrency()
+	bill.CalculateTotals()
+
+	if err := updateBill(ctx, tx, bill, workspaceID); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, item_id FROM bill_item_assignments WHERE bill_id = ? AND workspace_id = ?", bill.ID, workspaceID)
+	if err != nil {
+		return err
+	}
+	current := make(map[int64]int64)
 	for rows.Next() {
-		bill := &models.Bill{}
-		err := rows.Scan(
-			&bill.ID,
-			&bill.DueDate,
-			&bill.Paid,
-			&bill.IssuerID,
-			&bill.ReceiverID,
-			&bill.Currency,
-			&bill.OriginalTotal,
-			&bill.EURTotal,
-			&bill.CreatedAt,
-			&bill.UpdatedAt,
-			&bill.IssuerName,
-			&bill.ReceiverName,
-		)
-		if err != nil {
-			return nil, err
+		var id, itemID int64
+		if err := rows.Scan(&id, &itemID); err != nil {
+			rows.Close()
+			return err
 		}
+		current[id] = itemID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-		// Load items for each bill
-		itemRows, err := r.db.Query(`
-			SELECT a.id, a.bill_id, a.item_id, a.quantity, a.price,
-				   a.currency, a.exchange_rate, a.original_amount, a.eur_amount,
-				   a.created_at, a.updated_at,
-				   i.id, i.name, i.price, i.currency, i.created_at, i.updated_at
-			FROM bill_item_assignments a
-			LEFT JOIN bill_items i ON a.item_id = i.id
-			WHERE a.bill_id = ?
-			ORDER BY a.id ASC
-		`, bill.ID)
-		if err != nil {
-			return nil, err
+	for _, item := range bill.Items {
+		item.BillID = bill.ID
+		if item.ID == 0 {
+			if err := insertLine(ctx, tx, item, workspaceID); err != nil {
+				return err
+			}
+			continue
 		}
-		defer itemRows.Close()
 
-		for itemRows.Next() {
-			assignment := &models.BillItemAssignment{
-				BillItem: &models.BillItem{},
-			}
-			err := itemRows.Scan(
-				&assignment.ID,
-				&assignment.BillID,
-				&assignment.ItemID,
-				&assignment.Quantity,
-				&assignment.Price,
-				&assignment.Currency,
-				&assignment.ExchangeRate,
-				&assignment.OriginalAmount,
-				&assignment.EURAmount,
-				&assignment.CreatedAt,
-				&assignment.UpdatedAt,
-				&assignment.BillItem.ID,
-				&assignment.BillItem.Name,
-				&assignment.BillItem.Price,
-				&assignment.BillItem.Currency,
-				&assignment.BillItem.CreatedAt,
-				&assignment.BillItem.UpdatedAt,
-			)
-			if err != nil {
-				return nil, err
+		itemID, ok := current[item.ID]
+		if !ok {
+			// The line belongs to another bill or was deleted meanwhile
+			return models.NotFound("assignment")
+		}
+		delete(current, item.ID)
+
+		// Lines keep items that were trashed since, only new ones must be live
+		if item.ItemID != itemID {
+			if err := checkWorkspace(ctx, tx, "bill_items", item.ItemID, workspaceID); err != nil {
+				return err
 			}
-			bill.Items = append(bill.Items, assignment)
 		}
-		if err = itemRows.Err(); err != nil {
-			return nil, err
+		if err := updateLine(ctx, tx, item, workspaceID); err != nil {
+			return err
 		}
+	}
 
-		bills = append(bills, bill)
+	// What is left was removed from the bill
+	for id := range current {
+		if err := deleteLine(ctx, tx, id, workspaceID); err != nil {
+			return err
+		}
 	}
-	return bills, rows.Err()
+	return tx.Commit()
 }
 
-func (r *SQLiteBillRepository) Update(bill *models.Bill) error {
+// updateBill updates the row of a bill, lines excluded
+func updateBill(ctx context.Context, tx txn, bill *models.Bill, workspaceID int64) error {
+	var issuerID, receiverID int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT issuer_id, receiver_id FROM bills
+		WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
+	`, bill.ID, workspaceID).Scan(&issuerID, &receiverID)
+	if err == sql.ErrNoRows {
+		// The workspace has no such bill, or it is in the trash
+		return models.NotFound("bill")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Bills keep parties that were trashed since, only new ones must be live
+	if bill.IssuerID != issuerID {
+		if err := checkWorkspace(ctx, tx, "issuers", bill.IssuerID, workspaceID); err != nil {
+			return err
+		}
+	}
+	if bill.ReceiverID != receiverID {
+		if err := checkWorkspace(ctx, tx, "receivers", bill.ReceiverID, workspaceID); err != nil {
+			return err
+		}
+	}
+
+	before, err := snapshotRow(ctx, tx, models.EntityBills, bill.ID, workspaceID)
+	if err != nil {
+		return err
+	}
+
 	bill.UpdatedAt = time.Now()
-	_, err := r.db.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE bills
-		SET due_date = ?, currency = ?, original_total = ?, eur_total = ?,
-			paid = ?, issuer_id = ?, receiver_id = ?, updated_at = ?
-		WHERE id = ?
+		SET issue_date = ?, due_date = ?, currency = ?, original_total = ?,
+			eur_total = ?, paid = ?, issuer_id = ?, receiver_id = ?, updated_at = ?
+		WHERE id = ? AND workspace_id = ?
 	`,
+		bill.IssueDate,
 		bill.DueDate,
 		bill.Currency,
 		bill.OriginalTotal,
@@ -305,11 +559,128 @@ func (r *SQLiteBillRepository) Update(bill *models.Bill) error {
 		bill.ReceiverID,
 		bill.UpdatedAt,
 		bill.ID,
+		workspaceID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return recordChange(ctx, tx, models.EntityBills, bill.ID, workspaceID, models.AuditUpdate, before)
+}
+
+// insertLine adds a line to the bill it names, after checking its item
+// belongs to the workspace
+func insertLine(ctx context.Context, tx txn, item *models.BillItemAssignment, workspaceID int64) error {
+	if err := checkWorkspace(ctx, tx, "bill_items", item.ItemID, workspaceID); err != nil {
+		return err
+	}
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO bill_item_assignments (
+			workspace_id, bill_id, item_id, quantity, price, currency,
+			exchange_rate, original_amount, eur_amount,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		workspaceID,
+		item.BillID,
+		item.ItemID,
+		item.Quantity,
+		item.Price,
+		item.Currency,
+		item.ExchangeRate,
+		item.OriginalAmount,
+		item.EURAmount,
+		time.Now(),
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+	item.ID = id
+
+	return recordChange(ctx, tx, models.EntityBillLines, id, workspaceID, models.AuditCreate, nil)
 }
 
-func (r *SQLiteBillRepository) Delete(id int64) error {
-	_, err := r.db.Exec("DELETE FROM bills WHERE id = ?", id)
-	return err
+// updateLine updates a line of a bill, its item included
+func updateLine(ctx context.Context, tx txn, item *models.BillItemAssignment, workspaceID int64) error {
+	before, err := snapshotRow(ctx, tx, models.EntityBillLines, item.ID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("assignment")
+	}
+
+	item.UpdatedAt = time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE bill_item_assignments
+		SET item_id = ?, quantity = ?, price = ?, currency = ?, exchange_rate = ?,
+			original_amount = ?, eur_amount = ?, updated_at = ?
+		WHERE id = ? AND workspace_id = ?
+	`,
+		item.ItemID,
+		item.Quantity,
+		item.Price,
+		item.Currency,
+		item.ExchangeRate,
+		item.OriginalAmount,
+		item.EURAmount,
+		item.UpdatedAt,
+		item.ID,
+		workspaceID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return recordChange(ctx, tx, models.EntityBillLines, item.ID, workspaceID, models.AuditUpdate, before)
+}
+
+func (r *SQLiteBillRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityBills, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("bill")
+	}
+
+	// The lines stay with the bill, so restoring it brings them back
+	result, err := tx.ExecContext(ctx, "UPDATE bills SET deleted_at = ? WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL", time.Now(), id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Already in the trash
+		return models.NotFound("bill")
+	}
+
+	if err := recordChange(ctx, tx, models.EntityBills, id, workspaceID, models.AuditDelete, before); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkParties makes sure the issuer and receiver of the bill belong to its
+// workspace
+func checkParties(ctx context.Context, q queryRower, bill *models.Bill, workspaceID int64) error {
+	if err := checkWorkspace(ctx, q, "issuers", bill.IssuerID, workspaceID); err != nil {
+		return err
+	}
+	return checkWorkspace(ctx, q, "receivers", bill.ReceiverID, workspaceID)
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"errors"
+
+	"bills/internal/models"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// constraintError turns a violated UNIQUE or FOREIGN KEY constraint into a
+// models.ErrConflict with the given message, other errors are returned as is
+func constraintError(err error, message string) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		return models.Conflict(message)
+	}
+	// Class 23 holds the integrity constraint violations of PostgreSQL
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Class() == "23" {
+		return models.Conflict(message)
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sortColumn returns the expression of the column named by sort in columns
+// and whether it is sorted in descending order, as marked by a leading "-".
+// Unset and unknown columns sort by fallback
+func sortColumn(sort, fallback string, columns map[string]string) (string, bool) {
+	name := strings.TrimPrefix(sort, "-")
+	if _, ok := columns[name]; !ok {
+		return sortColumn(fallback, fallback, columns)
+	}
+	return columns[name], strings.HasPrefix(sort, "-")
+}
+
+// keyset returns the order of a page sorted by column and id, and the
+// condition that starts it after the row with the id of the next argument.
+// from selects the row the sort value of that id is read from, with the same
+// aliases as the query. The condition takes the id, the workspace and the id
+// again, so a row of another workspace is as unknown as a missing one and
+// leaves nothing to follow it
+func keyset(column, idColumn, workspaceColumn, from string, desc bool) (order, after string) {
+	direction, cmp := "ASC", ">"
+	if desc {
+		direction, cmp = "DESC", "<"
+	}
+	order = fmt.Sprintf("%s %s, %s %s", column, direction, idColumn, direction)
+	after = fmt.Sprintf("(%s, %s) %s ((SELECT %s FROM %s WHERE %s = ? AND %s = ?), ?)", column, idColumn, cmp, column, from, idColumn, workspaceColumn)
+	return order, after
+}
+
+// likePattern returns the LIKE pattern matching text anywhere in a value,
+// wildcards in text escaped with a backslash
+func likePattern(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(strings.TrimSpace(text)) + "%"
+}
+
+// startOfDay returns midnight of the day of t in UTC, the way dates from
+// forms are stored
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,492 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"bills/internal/models"
+	"bills/internal/tenant"
+)
+
+// IntegrityRepository finds the rows that break the rules the other
+// repositories keep and repairs them. It works on every workspace at once,
+// trashed rows included
+type IntegrityRepository interface {
+	// Check lists the problems of the database in the order Fix repairs
+	// them
+	Check(ctx context.Context) ([]*models.IntegrityProblem, error)
+	// Fix repairs a Fixable problem from the rows as they are now, so fixing
+	// the problems of one Check in order leaves consistent data. Run both in
+	// one unit of work
+	Fix(ctx context.Context, problem *models.IntegrityProblem) error
+}
+
+// SQLiteIntegrityRepository implements IntegrityRepository using SQLite
+type SQLiteIntegrityRepository struct {
+	db conn
+}
+
+// NewSQLiteIntegrityRepository creates a new SQLite repository instance
+func NewSQLiteIntegrityRepository(db *sql.DB) *SQLiteIntegrityRepository {
+	return &SQLiteIntegrityRepository{db: dbConn{db}}
+}
+
+// amountTolerance is how far a stored amount may be from the computed one,
+// half a cent, before it counts as a problem
+const amountTolerance = 0.005
+
+func amountsDiffer(stored, computed float64) bool {
+	return math.Abs(stored-computed) > amountTolerance
+}
+
+// missingReference is a column of bills or lines that refers to a row of the
+// workspace of the bill
+type missingReference struct {
+	kind, entity, label string
+	query               string
+}
+
+// missingReferences select the workspace, the missing ID and how many rows
+// refer to it. A row of another workspace counts as missing
+var missingReferences = []missingReference{
+	{models.ProblemMissingIssuer, models.EntityIssuers, "issuer", `
+		SELECT b.workspace_id, b.issuer_id, COUNT(*)
+		FROM bills b
+		LEFT JOIN issuers i ON i.id = b.issuer_id AND i.workspace_id = b.workspace_id
+		WHERE i.id IS NULL
+		GROUP BY b.workspace_id, b.issuer_id
+		ORDER BY b.workspace_id, b.issuer_id`},
+	{models.ProblemMissingReceiver, models.EntityReceivers, "receiver", `
+		SELECT b.workspace_id, b.receiver_id, COUNT(*)
+		FROM bills b
+		LEFT JOIN receivers r ON r.id = b.receiver_id AND r.workspace_id = b.workspace_id
+		WHERE r.id IS NULL
+		GROUP BY b.workspace_id, b.receiver_id
+		ORDER BY b.workspace_id, b.receiver_id`},
+	{models.ProblemMissingItem, models.EntityBillItems, "bill item", `
+		SELECT b.workspace_id, a.item_id, COUNT(*)
+		FROM bill_item_assignments a
+		JOIN bills b ON b.id = a.bill_id
+		LEFT JOIN bill_items i ON i.id = a.item_id AND i.workspace_id = b.workspace_id
+		WHERE i.id IS NULL
+		GROUP BY b.workspace_id, a.item_id
+		ORDER BY b.workspace_id, a.item_id`},
+}
+
+// Check lists the problems of the database
+func (r *SQLiteIntegrityRepository) Check(ctx context.Context) ([]*models.IntegrityProblem, error) {
+	problems, err := r.orphanLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range missingReferences {
+		rows, err := r.db.QueryContext(ctx, ref.query)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			problem := &models.IntegrityProblem{Kind: ref.kind, Entity: ref.entity, Fixable: true}
+			var count int
+			if err := rows.Scan(&problem.WorkspaceID, &problem.ID, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			problem.Detail = fmt.Sprintf("%d %s refer to %s %d, which is not in workspace %d",
+				count, ref.referrers(), ref.label, problem.ID, problem.WorkspaceID)
+			problems = append(problems, problem)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	bills, workspaces, err := r.bills(ctx)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, checkCurrencies(bills, workspaces)...)
+	problems = append(problems, checkAmounts(bills, workspaces)...)
+	return problems, nil
+}
+
+// referrers names the rows that refer to a missing row
+func (ref missingReference) referrers() string {
+	if ref.kind == models.ProblemMissingItem {
+		return "lines"
+	}
+	return "bills"
+}
+
+func (r *SQLiteIntegrityRepository) orphanLines(ctx context.Context) ([]*models.IntegrityProblem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT a.id, a.bill_id
+		FROM bill_item_assignments a
+		LEFT JOIN bills b ON b.id = a.bill_id
+		WHERE b.id IS NULL
+		ORDER BY a.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []*models.IntegrityProblem
+	for rows.Next() {
+		var id, billID int64
+		if err := rows.Scan(&id, &billID); err != nil {
+			return nil, err
+		}
+		problems = append(problems, &models.IntegrityProblem{
+			Kind:    models.ProblemOrphanLine,
+			Entity:  models.EntityBillLines,
+			ID:      id,
+			Detail:  fmt.Sprintf("line %d belongs to bill %d, which does not exist", id, billID),
+			Fixable: true,
+		})
+	}
+	return problems, rows.Err()
+}
+
+// bills loads every bill with its lines, only the columns the checks need,
+// and the workspace of every bill by its ID
+func (r *SQLiteIntegrityRepository) bills(ctx context.Context) ([]*models.Bill, map[int64]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, workspace_id, currency, original_total, eur_total
+		FROM bills
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, nil, err
+	}
+	var bills []*models.Bill
+	byID := make(map[int64]*models.Bill)
+	workspaces := make(map[int64]int64)
+	for rows.Next() {
+		bill := &models.Bill{}
+		var workspaceID int64
+		if err := rows.Scan(&bill.ID, &workspaceID, &bill.Currency, &bill.OriginalTotal, &bill.EURTotal); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		bills = append(bills, bill)
+		byID[bill.ID] = bill
+		workspaces[bill.ID] = workspaceID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT id, bill_id, item_id, quantity, price, currency, exchange_rate, original_amount, eur_amount
+		FROM bill_item_assignments
+		ORDER BY bill_id, id
+	`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		line := &models.BillItemAssignment{}
+		if err := rows.Scan(&line.ID, &line.BillID, &line.ItemID, &line.Quantity, &line.Price,
+			&line.Currency, &line.ExchangeRate, &line.OriginalAmount, &line.EURAmount); err != nil {
+			return nil, nil, err
+		}
+		if bill, ok := byID[line.BillID]; ok {
+			bill.Items = append(bill.Items, line)
+		}
+	}
+	return bills, workspaces, rows.Err()
+}
+
+// checkCurrencies finds the lines and bills in a currency that is not
+// supported. Currencies only spelled differently are fixed in place, a bill
+// in an unknown currency takes the one of its lines
+func checkCurrencies(bills []*models.Bill, workspaces map[int64]int64) []*models.IntegrityProblem {
+	var problems []*models.IntegrityProblem
+	for _, bill := range bills {
+		for _, line := range bill.Items {
+			normalized, ok := models.NormalizeCurrency(line.Currency)
+			if normalized == line.Currency && ok {
+				continue
+			}
+			problem := &models.IntegrityProblem{
+				Kind:        models.ProblemLineCurrency,
+				Entity:      models.EntityBillLines,
+				ID:          line.ID,
+				WorkspaceID: workspaces[bill.ID],
+				Fixable:     ok,
+			}
+			if ok {
+				problem.Detail = fmt.Sprintf("line %d of bill %d is in %q, stored as %s", line.ID, bill.ID, line.Currency, normalized)
+			} else {
+				problem.Detail = fmt.Sprintf("line %d of bill %d is in %q, which is not a supported currency", line.ID, bill.ID, line.Currency)
+			}
+			problems = append(problems, problem)
+		}
+	}
+
+	for _, bill := range bills {
+		currency, ok := billCurrency(bill)
+		if ok && currency == bill.Currency {
+			continue
+		}
+		problem := &models.IntegrityProblem{
+			Kind:        models.ProblemBillCurrency,
+			Entity:      models.EntityBills,
+			ID:          bill.ID,
+			WorkspaceID: workspaces[bill.ID],
+			Fixable:     ok,
+		}
+		switch normalized, _ := models.NormalizeCurrency(bill.Currency); {
+		case ok && normalized == currency:
+			problem.Detail = fmt.Sprintf("bill %d is in %q, stored as %s", bill.ID, bill.Currency, currency)
+		case ok:
+			problem.Detail = fmt.Sprintf("bill %d is in %q, which is not a supported currency, its lines are in %s", bill.ID, bill.Currency, currency)
+		default:
+			problem.Detail = fmt.Sprintf("bill %d is in %q, which is not a supported currency, and so are some of its lines", bill.ID, bill.Currency)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// billCurrency returns the currency bill should be in and whether it can be
+// told. A supported currency is kept, otherwise the bill takes the currency
+// of its lines as it does when they change
+func billCurrency(bill *models.Bill) (string, bool) {
+	if normalized, ok := models.NormalizeCurrency(bill.Currency); ok {
+		return normalized, true
+	}
+	resolved := &models.Bill{}
+	for _, line := range bill.Items {
+		currency, ok := models.NormalizeCurrency(line.Currency)
+		if !ok {
+			return bill.Currency, false
+		}
+		resolved.Items = append(resolved.Items, &models.BillItemAssignment{Currency: currency})
+	}
+	resolved.ResolveCurrency()
+	return resolved.Currency, true
+}
+
+// checkAmounts recomputes the amounts of every line and the totals of every
+// bill from them, in the currencies checkCurrencies fixes them to. Bills in a
+// currency that cannot be told are left to checkCurrencies
+func checkAmounts(bills []*models.Bill, workspaces map[int64]int64) []*models.IntegrityProblem {
+	var lines, totals []*models.IntegrityProblem
+	for _, bill := range bills {
+		computed := &models.Bill{}
+		var known bool
+		computed.Currency, known = billCurrency(bill)
+		for _, line := range bill.Items {
+			want := *line
+			if currency, ok := models.NormalizeCurrency(line.Currency); ok {
+				want.Currency = currency
+			}
+			want.CalculateAmounts()
+			computed.Items = append(computed.Items, &want)
+			if !amountsDiffer(line.OriginalAmount, want.OriginalAmount) && !amountsDiffer(line.EURAmount, want.EURAmount) {
+				continue
+			}
+			lines = append(lines, &models.IntegrityProblem{
+				Kind:        models.ProblemLineAmounts,
+				Entity:      models.EntityBillLines,
+				ID:          line.ID,
+				WorkspaceID: workspaces[bill.ID],
+				Detail: fmt.Sprintf("line %d of bill %d stores %.2f %s, %.2f EUR, its quantity, price and rate make %.2f %s, %.2f EUR",
+					line.ID, bill.ID, line.OriginalAmount, want.Currency, line.EURAmount, want.OriginalAmount, want.Currency, want.EURAmount),
+				Fixable: true,
+			})
+		}
+
+		// Totals depend on the currency of the bill
+		if !known {
+			continue
+		}
+		computed.CalculateTotals()
+		if !amountsDiffer(bill.OriginalTotal, computed.OriginalTotal) && !amountsDiffer(bill.EURTotal, computed.EURTotal) {
+			continue
+		}
+		totals = append(totals, &models.IntegrityProblem{
+			Kind:        models.ProblemBillTotals,
+			Entity:      models.EntityBills,
+			ID:          bill.ID,
+			WorkspaceID: workspaces[bill.ID],
+			Detail: fmt.Sprintf("bill %d stores a total of %.2f %s, %.2f EUR, its lines make %.2f %s, %.2f EUR",
+				bill.ID, bill.OriginalTotal, bill.Currency, bill.EURTotal, computed.OriginalTotal, computed.Currency, computed.EURTotal),
+			Fixable: true,
+		})
+	}
+	return append(lines, totals...)
+}
+
+// Fix repairs a problem Check found
+func (r *SQLiteIntegrityRepository) Fix(ctx context.Context, problem *models.IntegrityProblem) error {
+	if !problem.Fixable {
+		return fmt.Errorf("%s %d needs to be fixed by hand: %s", problem.Entity, problem.ID, problem.Detail)
+	}
+
+	switch problem.Kind {
+	case models.ProblemMissingIssuer, models.ProblemMissingReceiver, models.ProblemMissingItem:
+		// Goes through the other repositories, which start transactions of
+		// their own
+		return r.replaceMissing(ctx, problem)
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	switch problem.Kind {
+	case models.ProblemOrphanLine:
+		_, err = tx.ExecContext(ctx, "DELETE FROM bill_item_assignments WHERE id = ?", problem.ID)
+	case models.ProblemLineCurrency, models.ProblemLineAmounts:
+		err = fixLine(ctx, tx, problem)
+	case models.ProblemBillCurrency, models.ProblemBillTotals:
+		err = fixBill(ctx, tx, problem)
+	default:
+		err = fmt.Errorf("unknown integrity problem %q", problem.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceMissing points the bills or lines that refer to a missing row at a
+// stand-in named after it. The stand-in goes to the trash of the workspace
+// right away, where it can be restored or purged along with its bills
+func (r *SQLiteIntegrityRepository) replaceMissing(ctx context.Context, problem *models.IntegrityProblem) error {
+	ctx = tenant.WithWorkspace(ctx, problem.WorkspaceID)
+
+	var standInID int64
+	var update string
+	var trash func(ctx context.Context, id int64) error
+	switch problem.Kind {
+	case models.ProblemMissingIssuer:
+		issuers := &SQLiteIssuerRepository{db: r.db}
+		issuer := &models.Issuer{Name: fmt.Sprintf("Missing issuer %d", problem.ID)}
+		if err := issuers.Create(ctx, issuer); err != nil {
+			return err
+		}
+		standInID, trash = issuer.ID, issuers.Delete
+		update = "UPDATE bills SET issuer_id = ?, updated_at = ? WHERE issuer_id = ? AND workspace_id = ?"
+	case models.ProblemMissingReceiver:
+		receivers := &SQLiteReceiverRepository{db: r.db}
+		receiver := &models.Receiver{Name: fmt.Sprintf("Missing receiver %d", problem.ID)}
+		if err := receivers.Create(ctx, receiver); err != nil {
+			return err
+		}
+		standInID, trash = receiver.ID, receivers.Delete
+		update = "UPDATE bills SET receiver_id = ?, updated_at = ? WHERE receiver_id = ? AND workspace_id = ?"
+	default:
+		// The stand-in item costs what its first line does
+		item := &models.BillItem{Name: fmt.Sprintf("Missing bill item %d", problem.ID)}
+		err := r.db.QueryRowContext(ctx, `
+			SELECT a.price, a.currency
+			FROM bill_item_assignments a
+			JOIN bills b ON b.id = a.bill_id
+			WHERE a.item_id = ? AND b.workspace_id = ?
+			ORDER BY a.id
+			LIMIT 1
+		`, problem.ID, problem.WorkspaceID).Scan(&item.Price, &item.Currency)
+		if err != nil {
+			return err
+		}
+		items := &SQLiteBillItemRepository{db: r.db}
+		if err := items.Create(ctx, item); err != nil {
+			return err
+		}
+		standInID, trash = item.ID, items.Delete
+		update = `
+			UPDATE bill_item_assignments SET item_id = ?, updated_at = ?
+			WHERE item_id = ? AND bill_id IN (SELECT id FROM bills WHERE workspace_id = ?)`
+	}
+
+	if _, err := r.db.ExecContext(ctx, update, standInID, time.Now(), problem.ID, problem.WorkspaceID); err != nil {
+		return err
+	}
+	return trash(ctx, standInID)
+}
+
+// fixLine stores the currency of a line the way it is spelled when it is
+// supported, or the amounts its quantity, price and rate make
+func fixLine(ctx context.Context, tx txn, problem *models.IntegrityProblem) error {
+	line := &models.BillItemAssignment{ID: problem.ID}
+	err := tx.QueryRowContext(ctx, `
+		SELECT quantity, price, currency, exchange_rate
+		FROM bill_item_assignments
+		WHERE id = ?
+	`, line.ID).Scan(&line.Quantity, &line.Price, &line.Currency, &line.ExchangeRate)
+	if err != nil {
+		return err
+	}
+	if currency, ok := models.NormalizeCurrency(line.Currency); ok {
+		line.Currency = currency
+	}
+
+	if problem.Kind == models.ProblemLineCurrency {
+		_, err = tx.ExecContext(ctx, "UPDATE bill_item_assignments SET currency = ?, updated_at = ? WHERE id = ?",
+			line.Currency, time.Now(), line.ID)
+		return err
+	}
+	line.CalculateAmounts()
+	_, err = tx.ExecContext(ctx, "UPDATE bill_item_assignments SET original_amount = ?, eur_amount = ?, updated_at = ? WHERE id = ?",
+		line.OriginalAmount, line.EURAmount, time.Now(), line.ID)
+	return err
+}
+
+// fixBill stores the currency billCurrency tells for a bill, or the totals
+// of the amounts its lines store
+func fixBill(ctx context.Context, tx txn, problem *models.IntegrityProblem) error {
+	bill := &models.Bill{ID: problem.ID}
+	if err := tx.QueryRowContext(ctx, "SELECT currency FROM bills WHERE id = ?", bill.ID).Scan(&bill.Currency); err != nil {
+		return err
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT currency, original_amount, eur_amount
+		FROM bill_item_assignments
+		WHERE bill_id = ?
+		ORDER BY id
+	`, bill.ID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		line := &models.BillItemAssignment{}
+		if err := rows.Scan(&line.Currency, &line.OriginalAmount, &line.EURAmount); err != nil {
+			rows.Close()
+			return err
+		}
+		if currency, ok := models.NormalizeCurrency(line.Currency); ok {
+			line.Currency = currency
+		}
+		bill.Items = append(bill.Items, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	currency, ok := billCurrency(bill)
+	if problem.Kind == models.ProblemBillCurrency {
+		if !ok {
+			return fmt.Errorf("bill %d is in %q and so are some of its lines", bill.ID, bill.Currency)
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE bills SET currency = ?, updated_at = ? WHERE id = ?", currency, time.Now(), bill.ID)
+		return err
+	}
+	bill.Currency = currency
+	bill.CalculateTotals()
+	_, err = tx.ExecContext(ctx, "UPDATE bills SET original_total = ?, eur_total = ?, updated_at = ? WHERE id = ?",
+		bill.OriginalTotal, bill.EURTotal, time.Now(), bill.ID)
+	return err
+}
@@ -1,56 +1,57 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"bills/internal/models"
+	"bills/internal/tenant"
 )
 
-// IssuerRepository defines the interface for issuer storage operations
+// IssuerRepository defines the interface for issuer storage operations. Every
+// method works in the workspace of ctx. Delete moves the issuer to the trash,
+// trashed issuers are left out until restored, see TrashRepository.
+// GetByID, Update and Delete return models.ErrNotFound when the workspace has
+// no such issuer outside the trash
 type IssuerRepository interface {
-	Create(issuer *models.Issuer) error
-	GetByID(id int64) (*models.Issuer, error)
-	GetAll() ([]*models.Issuer, error)
-	Update(issuer *models.Issuer) error
-	Delete(id int64) error
+	Create(ctx context.Context, issuer *models.Issuer) error
+	GetByID(ctx context.Context, id int64) (*models.Issuer, error)
+	GetAll(ctx context.Context) ([]*models.Issuer, error)
+	Update(ctx context.Context, issuer *models.Issuer) error
+	Delete(ctx context.Context, id int64) error
 }
 
 // SQLiteIssuerRepository implements IssuerRepository using SQLite
 type SQLiteIssuerRepository struct {
-	db *sql.DB
+	db conn
 }
 
 // NewSQLiteIssuerRepository creates a new SQLite repository instance
 func NewSQLiteIssuerRepository(db *sql.DB) *SQLiteIssuerRepository {
-	return &SQLiteIssuerRepository{db: db}
+	return &SQLiteIssuerRepository{db: dbConn{db}}
 }
 
-// InitIssuerDB initializes the issuers table
-func InitIssuerDB(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS issuers (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			vat_number TEXT NOT NULL,
-			street TEXT NOT NULL,
-			city TEXT NOT NULL,
-			state TEXT NOT NULL,
-			zip_code TEXT NOT NULL,
-			country TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		)
-	`)
-	return err
-}
+func (r *SQLiteIssuerRepository) Create(ctx context.Context, issuer *models.Issuer) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-func (r *SQLiteIssuerRepository) Create(issuer *models.Issuer) error {
 	query := `
-		INSERT INTO issuers (name, vat_number, street, city, state, zip_code, country, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO issuers (workspace_id, name, vat_number, street, city, state, zip_code, country, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
-	result, err := r.db.Exec(query,
+	var id int64
+	err = tx.QueryRowContext(ctx, query,
+		workspaceID,
 		issuer.Name,
 		issuer.VATNumber,
 		issuer.Street,
@@ -60,25 +61,29 @@ func (r *SQLiteIssuerRepository) Create(issuer *models.Issuer) error {
 		issuer.Country,
 		time.Now(),
 		time.Now(),
-	)
+	).Scan(&id)
 	if err != nil {
 		return err
 	}
+	issuer.ID = id
 
-	id, err := result.LastInsertId()
-	if err != nil {
+	if err := recordChange(ctx, tx, models.EntityIssuers, id, workspaceID, models.AuditCreate, nil); err != nil {
 		return err
 	}
-	issuer.ID = id
-	return nil
+	return tx.Commit()
 }
 
-func (r *SQLiteIssuerRepository) GetByID(id int64) (*models.Issuer, error) {
+func (r *SQLiteIssuerRepository) GetByID(ctx context.Context, id int64) (*models.Issuer, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	issuer := &models.Issuer{}
-	err := r.db.QueryRow(`
+	err = r.db.QueryRowContext(ctx, `
 		SELECT id, name, vat_number, street, city, state, zip_code, country, created_at, updated_at
-		FROM issuers WHERE id = ?
-	`, id).Scan(
+		FROM issuers WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
+	`, id, workspaceID).Scan(
 		&issuer.ID,
 		&issuer.Name,
 		&issuer.VATNumber,
@@ -91,16 +96,21 @@ func (r *SQLiteIssuerRepository) GetByID(id int64) (*models.Issuer, error) {
 		&issuer.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, models.NotFound("issuer")
 	}
 	return issuer, err
 }
 
-func (r *SQLiteIssuerRepository) GetAll() ([]*models.Issuer, error) {
-	rows, err := r.db.Query(`
+func (r *SQLiteIssuerRepository) GetAll(ctx context.Context) ([]*models.Issuer, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, name, vat_number, street, city, state, zip_code, country, created_at, updated_at
-		FROM issuers ORDER BY name ASC
-	`)
+		FROM issuers WHERE workspace_id = ? AND deleted_at IS NULL ORDER BY name ASC
+	`, workspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -129,12 +139,31 @@ func (r *SQLiteIssuerRepository) GetAll() ([]*models.Issuer, error) {
 	return issuers, rows.Err()
 }
 
-func (r *SQLiteIssuerRepository) Update(issuer *models.Issuer) error {
+func (r *SQLiteIssuerRepository) Update(ctx context.Context, issuer *models.Issuer) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityIssuers, issuer.ID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("issuer")
+	}
+
 	issuer.UpdatedAt = time.Now()
-	_, err := r.db.Exec(`
+	result, err := tx.ExecContext(ctx, `
 		UPDATE issuers
 		SET name = ?, vat_number = ?, street = ?, city = ?, state = ?, zip_code = ?, country = ?, updated_at = ?
-		WHERE id = ?
+		WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
 	`,
 		issuer.Name,
 		issuer.VATNumber,
@@ -145,11 +174,57 @@ func (r *SQLiteIssuerRepository) Update(issuer *models.Issuer) error {
 		issuer.Country,
 		issuer.UpdatedAt,
 		issuer.ID,
+		workspaceID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Trashed rows are not changed
+		return models.NotFound("issuer")
+	}
+
+	if err := recordChange(ctx, tx, models.EntityIssuers, issuer.ID, workspaceID, models.AuditUpdate, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *SQLiteIssuerRepository) Delete(id int64) error {
-	_, err := r.db.Exec("DELETE FROM issuers WHERE id = ?", id)
-	return err
+func (r *SQLiteIssuerRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityIssuers, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("issuer")
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE issuers SET deleted_at = ? WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL", time.Now(), id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Already in the trash
+		return models.NotFound("issuer")
+	}
+
+	if err := recordChange(ctx, tx, models.EntityIssuers, id, workspaceID, models.AuditDelete, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
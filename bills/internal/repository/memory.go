@@ -0,0 +1,563 @@
+package repository
+
+import (
+	"bills/internal/models"
+	"bills/internal/tenant"
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore holds the issuers, receivers, bill items, bills and bill lines
+// of the in-memory repositories. Repositories of the same store see each
+// other's records the way the SQL ones share a database, and may be used from
+// several goroutines at once. Nothing is kept once the process exits
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data *memoryData
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: &memoryData{
+		issuers:   newMemoryTable[models.Issuer](),
+		receivers: newMemoryTable[models.Receiver](),
+		items:     newMemoryTable[models.BillItem](),
+		bills:     newMemoryTable[models.Bill](),
+		lines:     newMemoryTable[models.BillItemAssignment](),
+	}}
+}
+
+// read calls fn with the records of the store, other writers kept out
+func (s *MemoryStore) read(fn func(d *memoryData) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(s.data)
+}
+
+// write calls fn with the records of the store, everyone else kept out. fn
+// checks what it needs before it changes anything, so a failed call leaves
+// the records as they were
+func (s *MemoryStore) write(fn func(d *memoryData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.data)
+}
+
+// memoryData is the content of a store, a table per entity
+type memoryData struct {
+	issuers   *memoryTable[models.Issuer]
+	receivers *memoryTable[models.Receiver]
+	items     *memoryTable[models.BillItem]
+	bills     *memoryTable[models.Bill]
+	lines     *memoryTable[models.BillItemAssignment]
+}
+
+// clone copies the tables, the records themselves are values and never
+// changed in place
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		issuers:   d.issuers.clone(),
+		receivers: d.receivers.clone(),
+		items:     d.items.clone(),
+		bills:     d.bills.clone(),
+		lines:     d.lines.clone(),
+	}
+}
+
+// memoryRecord is a stored model along with the columns the model leaves
+// out. deletedAt is zero outside the trash
+type memoryRecord[T any] struct {
+	workspaceID int64
+	deletedAt   time.Time
+	value       T
+}
+
+// memoryTable holds the records of an entity by id. Ids are never reused
+type memoryTable[T any] struct {
+	lastID int64
+	rows   map[int64]memoryRecord[T]
+}
+
+func newMemoryTable[T any]() *memoryTable[T] {
+	return &memoryTable[T]{rows: make(map[int64]memoryRecord[T])}
+}
+
+func (t *memoryTable[T]) clone() *memoryTable[T] {
+	return &memoryTable[T]{lastID: t.lastID, rows: maps.Clone(t.rows)}
+}
+
+// nextID returns the id of the next record
+func (t *memoryTable[T]) nextID() int64 {
+	t.lastID++
+	return t.lastID
+}
+
+// live returns the record with the id if it belongs to the workspace and is
+// not in the trash
+func (t *memoryTable[T]) live(id, workspaceID int64) (memoryRecord[T], bool) {
+	r, ok := t.rows[id]
+	return r, ok && r.workspaceID == workspaceID && r.deletedAt.IsZero()
+}
+
+// liveValues returns the records of the workspace outside the trash
+func (t *memoryTable[T]) liveValues(workspaceID int64) []T {
+	var values []T
+	for _, r := range t.rows {
+		if r.workspaceID == workspaceID && r.deletedAt.IsZero() {
+			values = append(values, r.value)
+		}
+	}
+	return values
+}
+
+// trash moves the record with the id to the trash, reporting whether it was
+// live in the workspace
+func (t *memoryTable[T]) trash(id, workspaceID int64) bool {
+	r, ok := t.live(id, workspaceID)
+	if !ok {
+		return false
+	}
+	r.deletedAt = time.Now()
+	t.rows[id] = r
+	return true
+}
+
+// notInWorkspace is the error of a record that is missing from the workspace
+// or in the trash, the same the SQL repositories return
+func notInWorkspace(table string, id int64) error {
+	return fmt.Errorf("%w: %s %d", ErrNotInWorkspace, table, id)
+}
+
+// containsFold reports whether text holds the filter text in any case, the
+// way LIKE patterns match
+func containsFold(value, text string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(strings.TrimSpace(text)))
+}
+
+// page orders rows, starts them after the row anchor returns for the After
+// of a filter and cuts them to limit, one more than asked kept to tell
+// whether there is a next page. compare orders two rows by the sorted column
+// alone, ties are broken by id
+func page[T any](rows []*T, id func(*T) int64, compare func(a, b *T) int, desc bool, after int64, anchor func(int64) (*T, bool), limit int) ([]*T, int64) {
+	order := func(a, b *T) int {
+		c := compare(a, b)
+		if c == 0 {
+			c = cmp.Compare(id(a), id(b))
+		}
+		if desc {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(rows, order)
+
+	if after != 0 {
+		// Like the SQL keyset, an unknown row leaves nothing to follow it
+		from, ok := anchor(after)
+		if !ok {
+			return nil, 0
+		}
+		start := 0
+		for start < len(rows) && order(rows[start], from) <= 0 {
+			start++
+		}
+		rows = rows[start:]
+	}
+
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+		return rows, id(rows[limit-1])
+	}
+	return rows, 0
+}
+
+// MemoryIssuerRepository implements IssuerRepository in memory
+type MemoryIssuerRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryIssuerRepository creates a new in-memory repository instance
+func NewMemoryIssuerRepository(store *MemoryStore) *MemoryIssuerRepository {
+	return &MemoryIssuerRepository{store: store}
+}
+
+func (r *MemoryIssuerRepository) Create(ctx context.Context, issuer *models.Issuer) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		stored := *issuer
+		stored.ID = d.issuers.nextID()
+		stored.CreatedAt = time.Now()
+		stored.UpdatedAt = stored.CreatedAt
+		d.issuers.rows[stored.ID] = memoryRecord[models.Issuer]{workspaceID: workspaceID, value: stored}
+		issuer.ID = stored.ID
+		return nil
+	})
+}
+
+func (r *MemoryIssuerRepository) GetByID(ctx context.Context, id int64) (*models.Issuer, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var issuer *models.Issuer
+	err = r.store.read(func(d *memoryData) error {
+		record, ok := d.issuers.live(id, workspaceID)
+		if !ok {
+			return models.NotFound("issuer")
+		}
+		issuer = &record.value
+		return nil
+	})
+	return issuer, err
+}
+
+func (r *MemoryIssuerRepository) GetAll(ctx context.Context) ([]*models.Issuer, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var issuers []*models.Issuer
+	err = r.store.read(func(d *memoryData) error {
+		for _, issuer := range d.issuers.liveValues(workspaceID) {
+			issuers = append(issuers, &issuer)
+		}
+		return nil
+	})
+	slices.SortFunc(issuers, func(a, b *models.Issuer) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return issuers, err
+}
+
+func (r *MemoryIssuerRepository) Update(ctx context.Context, issuer *models.Issuer) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		record, ok := d.issuers.live(issuer.ID, workspaceID)
+		if !ok {
+			return models.NotFound("issuer")
+		}
+		issuer.UpdatedAt = time.Now()
+		stored := *issuer
+		stored.CreatedAt = record.value.CreatedAt
+		record.value = stored
+		d.issuers.rows[issuer.ID] = record
+		return nil
+	})
+}
+
+func (r *MemoryIssuerRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if !d.issuers.trash(id, workspaceID) {
+			return models.NotFound("issuer")
+		}
+		return nil
+	})
+}
+
+// MemoryReceiverRepository implements ReceiverRepository in memory
+type MemoryReceiverRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryReceiverRepository creates a new in-memory repository instance
+func NewMemoryReceiverRepository(store *MemoryStore) *MemoryReceiverRepository {
+	return &MemoryReceiverRepository{store: store}
+}
+
+func (r *MemoryReceiverRepository) Create(ctx context.Context, receiver *models.Receiver) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		stored := *receiver
+		stored.ID = d.receivers.nextID()
+		stored.CreatedAt = time.Now()
+		stored.UpdatedAt = stored.CreatedAt
+		d.receivers.rows[stored.ID] = memoryRecord[models.Receiver]{workspaceID: workspaceID, value: stored}
+		receiver.ID = stored.ID
+		return nil
+	})
+}
+
+func (r *MemoryReceiverRepository) GetByID(ctx context.Context, id int64) (*models.Receiver, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var receiver *models.Receiver
+	err = r.store.read(func(d *memoryData) error {
+		record, ok := d.receivers.live(id, workspaceID)
+		if !ok {
+			return models.NotFound("receiver")
+		}
+		receiver = &record.value
+		return nil
+	})
+	return receiver, err
+}
+
+func (r *MemoryReceiverRepository) GetAll(ctx context.Context) ([]*models.Receiver, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var receivers []*models.Receiver
+	err = r.store.read(func(d *memoryData) error {
+		for _, receiver := range d.receivers.liveValues(workspaceID) {
+			receivers = append(receivers, &receiver)
+		}
+		return nil
+	})
+	slices.SortFunc(receivers, func(a, b *models.Receiver) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return receivers, err
+}
+
+func (r *MemoryReceiverRepository) Update(ctx context.Context, receiver *models.Receiver) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		record, ok := d.receivers.live(receiver.ID, workspaceID)
+		if !ok {
+			return models.NotFound("receiver")
+		}
+		receiver.UpdatedAt = time.Now()
+		stored := *receiver
+		stored.CreatedAt = record.value.CreatedAt
+		record.value = stored
+		d.receivers.rows[receiver.ID] = record
+		return nil
+	})
+}
+
+func (r *MemoryReceiverRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if !d.receivers.trash(id, workspaceID) {
+			return models.NotFound("receiver")
+		}
+		return nil
+	})
+}
+
+// MemoryBillItemRepository implements BillItemRepository in memory
+type MemoryBillItemRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryBillItemRepository creates a new in-memory repository instance
+func NewMemoryBillItemRepository(store *MemoryStore) *MemoryBillItemRepository {
+	return &MemoryBillItemRepository{store: store}
+}
+
+func (r *MemoryBillItemRepository) Create(ctx context.Context, item *models.BillItem) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		stored := *item
+		stored.ID = d.items.nextID()
+		stored.CreatedAt = time.Now()
+		stored.UpdatedAt = stored.CreatedAt
+		d.items.rows[stored.ID] = memoryRecord[models.BillItem]{workspaceID: workspaceID, value: stored}
+		item.ID = stored.ID
+		return nil
+	})
+}
+
+func (r *MemoryBillItemRepository) GetByID(ctx context.Context, id int64) (*models.BillItem, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var item *models.BillItem
+	err = r.store.read(func(d *memoryData) error {
+		record, ok := d.items.live(id, workspaceID)
+		if !ok {
+			return models.NotFound("bill item")
+		}
+		item = &record.value
+		return nil
+	})
+	return item, err
+}
+
+func (r *MemoryBillItemRepository) GetAll(ctx context.Context) ([]*models.BillItem, error) {
+	page, err := r.List(ctx, models.BillItemFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// memoryBillItemSorts compares bill items by the columns of
+// models.BillItemSorts
+var memoryBillItemSorts = map[string]func(a, b *models.BillItem) int{
+	models.BillItemSortName:  func(a, b *models.BillItem) int { return cmp.Compare(a.Name, b.Name) },
+	models.BillItemSortPrice: func(a, b *models.BillItem) int { return cmp.Compare(a.Price, b.Price) },
+}
+
+func (r *MemoryBillItemRepository) List(ctx context.Context, filter models.BillItemFilter) (*models.BillItemPage, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BillItemPage{}
+	err = r.store.read(func(d *memoryData) error {
+		var items []*models.BillItem
+		for _, item := range d.items.liveValues(workspaceID) {
+			if filter.Currency != "" && item.Currency != filter.Currency {
+				continue
+			}
+			if strings.TrimSpace(filter.Text) != "" && !containsFold(item.Name, filter.Text) {
+				continue
+			}
+			items = append(items, &item)
+		}
+
+		compare, desc := memorySort(filter.Sort, models.BillItemSortName, memoryBillItemSorts)
+		anchor := func(id int64) (*models.BillItem, bool) {
+			record, ok := d.items.rows[id]
+			return &record.value, ok && record.workspaceID == workspaceID
+		}
+		result.Items, result.Next = page(items, func(item *models.BillItem) int64 { return item.ID }, compare, desc, filter.After, anchor, filter.Limit)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// memorySort returns the comparison of the column named by sort and whether
+// it is descending, see sortColumn
+func memorySort[T any](sort, fallback string, compares map[string]func(a, b *T) int) (func(a, b *T) int, bool) {
+	name := strings.TrimPrefix(sort, "-")
+	if _, ok := compares[name]; !ok {
+		return memorySort(fallback, fallback, compares)
+	}
+	return compares[name], strings.HasPrefix(sort, "-")
+}
+
+func (r *MemoryBillItemRepository) Update(ctx context.Context, item *models.BillItem) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		record, ok := d.items.live(item.ID, workspaceID)
+		if !ok {
+			return models.NotFound("bill item")
+		}
+		item.UpdatedAt = time.Now()
+		stored := *item
+		stored.CreatedAt = record.value.CreatedAt
+		record.value = stored
+		d.items.rows[item.ID] = record
+		return nil
+	})
+}
+
+func (r *MemoryBillItemRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if !d.items.trash(id, workspaceID) {
+			return models.NotFound("bill item")
+		}
+		return nil
+	})
+}
+
+// MemoryUnitOfWork implements UnitOfWork with the in-memory repositories.
+// A unit works on a copy of the store that replaces it once the function
+// returns nil, and keeps every other caller out meanwhile. Only the
+// repositories the store holds are set in its Tx
+type MemoryUnitOfWork struct {
+	store *MemoryStore
+	wrap  []func(tx *Tx)
+}
+
+// NewMemoryUnitOfWork creates a new in-memory unit of work, see
+// NewSQLiteUnitOfWork for wrap
+func NewMemoryUnitOfWork(store *MemoryStore, wrap ...func(tx *Tx)) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{store: store, wrap: wrap}
+}
+
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(tx *Tx) error) error {
+	tx, err := u.run(fn)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range tx.afterCommit {
+		fn()
+	}
+	return nil
+}
+
+// run calls fn with the repositories of a copy of the store and keeps the
+// copy when it succeeds
+func (u *MemoryUnitOfWork) run(fn func(tx *Tx) error) (*Tx, error) {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	work := &MemoryStore{data: u.store.data.clone()}
+	tx := &Tx{
+		Bills:               NewMemoryBillRepository(work),
+		BillItems:           NewMemoryBillItemRepository(work),
+		BillItemAssignments: NewMemoryBillItemAssignmentRepository(work),
+		Issuers:             NewMemoryIssuerRepository(work),
+		Receivers:           NewMemoryReceiverRepository(work),
+	}
+	for _, wrap := range u.wrap {
+		wrap(tx)
+	}
+
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+	u.store.data = work.data
+	return tx, nil
+}
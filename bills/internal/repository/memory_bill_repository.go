@@ -0,0 +1,539 @@
+package repository
+
+import (
+	"bills/internal/models"
+	"bills/internal/tenant"
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MemoryBillRepository implements BillRepository in memory
+type MemoryBillRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryBillRepository creates a new in-memory repository instance
+func NewMemoryBillRepository(store *MemoryStore) *MemoryBillRepository {
+	return &MemoryBillRepository{store: store}
+}
+
+func (r *MemoryBillRepository) Create(ctx context.Context, bill *models.Bill) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if err := d.checkParties(bill, workspaceID); err != nil {
+			return err
+		}
+		for _, item := range bill.Items {
+			if _, ok := d.items.live(item.ItemID, workspaceID); !ok {
+				return notInWorkspace("bill_items", item.ItemID)
+			}
+		}
+
+		stored := storedBill(bill)
+		stored.ID = d.bills.nextID()
+		stored.CreatedAt = time.Now()
+		stored.UpdatedAt = stored.CreatedAt
+		d.bills.rows[stored.ID] = memoryRecord[models.Bill]{workspaceID: workspaceID, value: stored}
+		bill.ID = stored.ID
+
+		for _, item := range bill.Items {
+			item.BillID = bill.ID
+			d.insertLine(item, workspaceID)
+		}
+		return nil
+	})
+}
+
+func (r *MemoryBillRepository) GetByID(ctx context.Context, id int64) (*models.Bill, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bill *models.Bill
+	err = r.store.read(func(d *memoryData) error {
+		record, ok := d.bills.live(id, workspaceID)
+		if !ok {
+			return models.NotFound("bill")
+		}
+		bill = d.bill(record.value)
+		bill.Items = d.billLines(bill.ID, workspaceID)
+		return nil
+	})
+	return bill, err
+}
+
+func (r *MemoryBillRepository) GetAll(ctx context.Context) ([]*models.Bill, error) {
+	page, err := r.List(ctx, models.BillFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Bills, nil
+}
+
+func (r *MemoryBillRepository) GetByIssuer(ctx context.Context, issuerID int64) ([]*models.Bill, error) {
+	page, err := r.List(ctx, models.BillFilter{IssuerID: issuerID})
+	if err != nil {
+		return nil, err
+	}
+	return page.Bills, nil
+}
+
+func (r *MemoryBillRepository) GetByReceiver(ctx context.Context, receiverID int64) ([]*models.Bill, error) {
+	page, err := r.List(ctx, models.BillFilter{ReceiverID: receiverID})
+	if err != nil {
+		return nil, err
+	}
+	return page.Bills, nil
+}
+
+func (r *MemoryBillRepository) SumByIssuer(ctx context.Context, issuerID int64) (*models.BillTotals, error) {
+	return r.sum(ctx, func(bill *models.Bill) bool { return bill.IssuerID == issuerID })
+}
+
+func (r *MemoryBillRepository) SumByReceiver(ctx context.Context, receiverID int64) (*models.BillTotals, error) {
+	return r.sum(ctx, func(bill *models.Bill) bool { return bill.ReceiverID == receiverID })
+}
+
+// memoryBillSorts compares bills by the columns of models.BillSorts
+var memoryBillSorts = map[string]func(a, b *models.Bill) int{
+	models.BillSortDueDate:   func(a, b *models.Bill) int { return a.DueDate.Compare(b.DueDate) },
+	models.BillSortIssueDate: func(a, b *models.Bill) int { return a.IssueDate.Compare(b.IssueDate) },
+	models.BillSortAmount:    func(a, b *models.Bill) int { return cmp.Compare(a.EURTotal, b.EURTotal) },
+	models.BillSortIssuer:    func(a, b *models.Bill) int { return cmp.Compare(a.IssuerName, b.IssuerName) },
+	models.BillSortReceiver:  func(a, b *models.Bill) int { return cmp.Compare(a.ReceiverName, b.ReceiverName) },
+}
+
+func (r *MemoryBillRepository) List(ctx context.Context, filter models.BillFilter) (*models.BillPage, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BillPage{}
+	err = r.store.read(func(d *memoryData) error {
+		var bills []*models.Bill
+		for _, value := range d.bills.liveValues(workspaceID) {
+			bill := d.bill(value)
+			if d.matches(bill, filter, workspaceID) {
+				bills = append(bills, bill)
+			}
+		}
+
+		compare, desc := memorySort(filter.Sort, "-"+models.BillSortDueDate, memoryBillSorts)
+		anchor := func(id int64) (*models.Bill, bool) {
+			record, ok := d.bills.rows[id]
+			if !ok || record.workspaceID != workspaceID {
+				return nil, false
+			}
+			return d.bill(record.value), true
+		}
+		result.Bills, result.Next = page(bills, func(bill *models.Bill) int64 { return bill.ID }, compare, desc, filter.After, anchor, filter.Limit)
+
+		if !filter.SkipItems {
+			for _, bill := range result.Bills {
+				bill.Items = d.billLines(bill.ID, workspaceID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// matches reports whether the bill passes the filter, sorting and paging
+// aside
+func (d *memoryData) matches(bill *models.Bill, filter models.BillFilter, workspaceID int64) bool {
+	switch filter.Status {
+	case models.BillStatusPaid:
+		if !bill.Paid {
+			return false
+		}
+	case models.BillStatusUnpaid:
+		if bill.Paid {
+			return false
+		}
+	case models.BillStatusOverdue:
+		if bill.Paid || !bill.DueDate.Before(startOfDay(time.Now())) {
+			return false
+		}
+	}
+	if filter.IssuerID != 0 && bill.IssuerID != filter.IssuerID {
+		return false
+	}
+	if filter.ReceiverID != 0 && bill.ReceiverID != filter.ReceiverID {
+		return false
+	}
+	if filter.Currency != "" && bill.Currency != filter.Currency {
+		return false
+	}
+	if !filter.DueFrom.IsZero() && bill.DueDate.Before(startOfDay(filter.DueFrom)) {
+		return false
+	}
+	if !filter.DueTo.IsZero() && !bill.DueDate.Before(startOfDay(filter.DueTo).AddDate(0, 0, 1)) {
+		return false
+	}
+	if filter.MinAmount > 0 && bill.EURTotal < filter.MinAmount {
+		return false
+	}
+	if filter.MaxAmount > 0 && bill.EURTotal > filter.MaxAmount {
+		return false
+	}
+	if strings.TrimSpace(filter.Text) != "" {
+		return containsFold(bill.IssuerName, filter.Text) ||
+			containsFold(bill.ReceiverName, filter.Text) ||
+			d.hasLineNamed(bill.ID, workspaceID, filter.Text)
+	}
+	return true
+}
+
+// hasLineNamed reports whether the bill has a line with an item whose name
+// holds text
+func (d *memoryData) hasLineNamed(billID, workspaceID int64, text string) bool {
+	for _, line := range d.billLines(billID, workspaceID) {
+		if containsFold(line.BillItem.Name, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// sum adds up the bills of the workspace of ctx outside the trash that match
+func (r *MemoryBillRepository) sum(ctx context.Context, match func(bill *models.Bill) bool) (*models.BillTotals, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := &models.BillTotals{}
+	err = r.store.read(func(d *memoryData) error {
+		for _, bill := range d.bills.liveValues(workspaceID) {
+			if !match(&bill) {
+				continue
+			}
+			totals.Count++
+			totals.Billed += bill.EURTotal
+			if bill.Paid {
+				totals.Paid += bill.EURTotal
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	totals.Outstanding = totals.Billed - totals.Paid
+	return totals, nil
+}
+
+func (r *MemoryBillRepository) Update(ctx context.Context, bill *models.Bill) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if err := d.checkBillUpdate(bill, workspaceID); err != nil {
+			return err
+		}
+		d.updateBill(bill)
+		return nil
+	})
+}
+
+// UpdateWithItems updates the bill together with its lines, the way
+// SQLiteBillRepository.UpdateWithItems does
+func (r *MemoryBillRepository) UpdateWithItems(ctx context.Context, bill *models.Bill) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range bill.Items {
+		item.CalculateAmounts()
+	}
+	bill.ResolveCurrency()
+	bill.CalculateTotals()
+
+	return r.store.write(func(d *memoryData) error {
+		if err := d.checkBillUpdate(bill, workspaceID); err != nil {
+			return err
+		}
+
+		current := make(map[int64]int64)
+		for _, line := range d.billLines(bill.ID, workspaceID) {
+			current[line.ID] = line.ItemID
+		}
+		for _, item := range bill.Items {
+			itemID, ok := current[item.ID]
+			if item.ID != 0 && !ok {
+				// The line belongs to another bill or was deleted meanwhile
+				return models.NotFound("assignment")
+			}
+			// Lines keep items that were trashed since, only new ones must be live
+			if item.ID == 0 || item.ItemID != itemID {
+				if _, ok := d.items.live(item.ItemID, workspaceID); !ok {
+					return notInWorkspace("bill_items", item.ItemID)
+				}
+			}
+		}
+
+		d.updateBill(bill)
+		for _, item := range bill.Items {
+			item.BillID = bill.ID
+			if item.ID == 0 {
+				d.insertLine(item, workspaceID)
+				continue
+			}
+			delete(current, item.ID)
+			d.updateLine(item, true)
+		}
+
+		// What is left was removed from the bill
+		for id := range current {
+			delete(d.lines.rows, id)
+		}
+		return nil
+	})
+}
+
+func (r *MemoryBillRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The lines stay with the bill, so restoring it brings them back
+	return r.store.write(func(d *memoryData) error {
+		if !d.bills.trash(id, workspaceID) {
+			return models.NotFound("bill")
+		}
+		return nil
+	})
+}
+
+// storedBill returns the bill as it is stored, without what is read from
+// other tables
+func storedBill(bill *models.Bill) models.Bill {
+	stored := *bill
+	stored.Issuer = nil
+	stored.Receiver = nil
+	stored.Items = nil
+	stored.IssuerName = ""
+	stored.ReceiverName = ""
+	return stored
+}
+
+// bill returns a copy of a stored bill with the names of its parties
+func (d *memoryData) bill(stored models.Bill) *models.Bill {
+	bill := stored
+	bill.IssuerName = d.issuers.rows[bill.IssuerID].value.Name
+	bill.ReceiverName = d.receivers.rows[bill.ReceiverID].value.Name
+	return &bill
+}
+
+// checkParties makes sure the issuer and receiver of the bill belong to the
+// workspace and are not in the trash
+func (d *memoryData) checkParties(bill *models.Bill, workspaceID int64) error {
+	if _, ok := d.issuers.live(bill.IssuerID, workspaceID); !ok {
+		return notInWorkspace("issuers", bill.IssuerID)
+	}
+	if _, ok := d.receivers.live(bill.ReceiverID, workspaceID); !ok {
+		return notInWorkspace("receivers", bill.ReceiverID)
+	}
+	return nil
+}
+
+// checkBillUpdate makes sure the bill can be updated. Bills keep parties that
+// were trashed since, only new ones must be live
+func (d *memoryData) checkBillUpdate(bill *models.Bill, workspaceID int64) error {
+	record, ok := d.bills.live(bill.ID, workspaceID)
+	if !ok {
+		// The workspace has no such bill, or it is in the trash
+		return models.NotFound("bill")
+	}
+	if bill.IssuerID != record.value.IssuerID {
+		if _, ok := d.issuers.live(bill.IssuerID, workspaceID); !ok {
+			return notInWorkspace("issuers", bill.IssuerID)
+		}
+	}
+	if bill.ReceiverID != record.value.ReceiverID {
+		if _, ok := d.receivers.live(bill.ReceiverID, workspaceID); !ok {
+			return notInWorkspace("receivers", bill.ReceiverID)
+		}
+	}
+	return nil
+}
+
+// updateBill stores the bill, lines excluded, once checkBillUpdate passed
+func (d *memoryData) updateBill(bill *models.Bill) {
+	record := d.bills.rows[bill.ID]
+	bill.UpdatedAt = time.Now()
+	stored := storedBill(bill)
+	stored.CreatedAt = record.value.CreatedAt
+	record.value = stored
+	d.bills.rows[bill.ID] = record
+}
+
+// billLines returns the lines of a bill with their catalog items, in the
+// order they were added
+func (d *memoryData) billLines(billID, workspaceID int64) []*models.BillItemAssignment {
+	var lines []*models.BillItemAssignment
+	for _, line := range d.lines.liveValues(workspaceID) {
+		if line.BillID == billID {
+			lines = append(lines, d.line(line))
+		}
+	}
+	slices.SortFunc(lines, func(a, b *models.BillItemAssignment) int { return cmp.Compare(a.ID, b.ID) })
+	return lines
+}
+
+// line returns a copy of a stored line with its catalog item
+func (d *memoryData) line(stored models.BillItemAssignment) *models.BillItemAssignment {
+	line := stored
+	item := d.items.rows[line.ItemID].value
+	line.BillItem = &item
+	return &line
+}
+
+// insertLine stores a new line of the bill it names
+func (d *memoryData) insertLine(item *models.BillItemAssignment, workspaceID int64) {
+	stored := *item
+	stored.BillItem = nil
+	stored.ID = d.lines.nextID()
+	stored.CreatedAt = time.Now()
+	stored.UpdatedAt = stored.CreatedAt
+	d.lines.rows[stored.ID] = memoryRecord[models.BillItemAssignment]{workspaceID: workspaceID, value: stored}
+	item.ID = stored.ID
+}
+
+// updateLine stores the amounts of a line, and its item when withItem is
+// set. The line and the bill it belongs to are kept
+func (d *memoryData) updateLine(item *models.BillItemAssignment, withItem bool) {
+	record := d.lines.rows[item.ID]
+	item.UpdatedAt = time.Now()
+	stored := *item
+	stored.BillItem = nil
+	stored.BillID = record.value.BillID
+	stored.CreatedAt = record.value.CreatedAt
+	if !withItem {
+		stored.ItemID = record.value.ItemID
+	}
+	record.value = stored
+	d.lines.rows[item.ID] = record
+}
+
+// MemoryBillItemAssignmentRepository implements BillItemAssignmentRepository
+// in memory
+type MemoryBillItemAssignmentRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryBillItemAssignmentRepository creates a new in-memory repository
+// instance
+func NewMemoryBillItemAssignmentRepository(store *MemoryStore) *MemoryBillItemAssignmentRepository {
+	return &MemoryBillItemAssignmentRepository{store: store}
+}
+
+func (r *MemoryBillItemAssignmentRepository) Create(ctx context.Context, assignment *models.BillItemAssignment) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if _, ok := d.bills.live(assignment.BillID, workspaceID); !ok {
+			return notInWorkspace("bills", assignment.BillID)
+		}
+		if _, ok := d.items.live(assignment.ItemID, workspaceID); !ok {
+			return notInWorkspace("bill_items", assignment.ItemID)
+		}
+		d.insertLine(assignment, workspaceID)
+		return nil
+	})
+}
+
+func (r *MemoryBillItemAssignmentRepository) GetByID(ctx context.Context, id int64) (*models.BillItemAssignment, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignment *models.BillItemAssignment
+	err = r.store.read(func(d *memoryData) error {
+		record, ok := d.lines.live(id, workspaceID)
+		if !ok {
+			return models.NotFound("assignment")
+		}
+		assignment = d.line(record.value)
+		return nil
+	})
+	return assignment, err
+}
+
+func (r *MemoryBillItemAssignmentRepository) GetByBillID(ctx context.Context, billID int64) ([]*models.BillItemAssignment, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []*models.BillItemAssignment
+	err = r.store.read(func(d *memoryData) error {
+		assignments = d.billLines(billID, workspaceID)
+		return nil
+	})
+	return assignments, err
+}
+
+func (r *MemoryBillItemAssignmentRepository) Update(ctx context.Context, assignment *models.BillItemAssignment) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if _, ok := d.lines.live(assignment.ID, workspaceID); !ok {
+			return models.NotFound("assignment")
+		}
+		d.updateLine(assignment, false)
+		return nil
+	})
+}
+
+func (r *MemoryBillItemAssignmentRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		if _, ok := d.lines.live(id, workspaceID); !ok {
+			return models.NotFound("assignment")
+		}
+		delete(d.lines.rows, id)
+		return nil
+	})
+}
+
+func (r *MemoryBillItemAssignmentRepository) DeleteByBillID(ctx context.Context, billID int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(func(d *memoryData) error {
+		for _, line := range d.billLines(billID, workspaceID) {
+			delete(d.lines.rows, line.ID)
+		}
+		return nil
+	})
+}
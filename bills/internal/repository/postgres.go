@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// The PostgreSQL repositories run the statements of the SQLite ones, which
+// stick to SQL that both databases understand. pgConn takes care of the two
+// differences left: PostgreSQL numbers its placeholders, and its LIKE is case
+// sensitive where SQLite's is not
+
+// PostgresBillRepository implements BillRepository using PostgreSQL
+type PostgresBillRepository struct {
+	SQLiteBillRepository
+}
+
+// NewPostgresBillRepository creates a new PostgreSQL repository instance
+func NewPostgresBillRepository(db *sql.DB) *PostgresBillRepository {
+	return &PostgresBillRepository{SQLiteBillRepository{db: newPgConn(db)}}
+}
+
+// PostgresBillItemRepository implements BillItemRepository using PostgreSQL
+type PostgresBillItemRepository struct {
+	SQLiteBillItemRepository
+}
+
+// NewPostgresBillItemRepository creates a new PostgreSQL repository instance
+func NewPostgresBillItemRepository(db *sql.DB) *PostgresBillItemRepository {
+	return &PostgresBillItemRepository{SQLiteBillItemRepository{db: newPgConn(db)}}
+}
+
+// PostgresBillItemAssignmentRepository implements
+// BillItemAssignmentRepository using PostgreSQL
+type PostgresBillItemAssignmentRepository struct {
+	SQLiteBillItemAssignmentRepository
+}
+
+// NewPostgresBillItemAssignmentRepository creates a new PostgreSQL repository
+// instance
+func NewPostgresBillItemAssignmentRepository(db *sql.DB) *PostgresBillItemAssignmentRepository {
+	return &PostgresBillItemAssignmentRepository{SQLiteBillItemAssignmentRepository{db: newPgConn(db)}}
+}
+
+// PostgresIssuerRepository implements IssuerRepository using PostgreSQL
+type PostgresIssuerRepository struct {
+	SQLiteIssuerRepository
+}
+
+// NewPostgresIssuerRepository creates a new PostgreSQL repository instance
+func NewPostgresIssuerRepository(db *sql.DB) *PostgresIssuerRepository {
+	return &PostgresIssuerRepository{SQLiteIssuerRepository{db: newPgConn(db)}}
+}
+
+// PostgresReceiverRepository implements ReceiverRepository using PostgreSQL
+type PostgresReceiverRepository struct {
+	SQLiteReceiverRepository
+}
+
+// NewPostgresReceiverRepository creates a new PostgreSQL repository instance
+func NewPostgresReceiverRepository(db *sql.DB) *PostgresReceiverRepository {
+	return &PostgresReceiverRepository{SQLiteReceiverRepository{db: newPgConn(db)}}
+}
+
+// PostgresAuditRepository implements AuditRepository using PostgreSQL. The
+// json_extract its history filter uses is defined by the migrations
+type PostgresAuditRepository struct {
+	SQLiteAuditRepository
+}
+
+// NewPostgresAuditRepository creates a new PostgreSQL repository instance
+func NewPostgresAuditRepository(db *sql.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{SQLiteAuditRepository{db: newPgConn(db)}}
+}
+
+// PostgresTrashRepository implements TrashRepository using PostgreSQL
+type PostgresTrashRepository struct {
+	SQLiteTrashRepository
+}
+
+// NewPostgresTrashRepository creates a new PostgreSQL repository instance
+func NewPostgresTrashRepository(db *sql.DB) *PostgresTrashRepository {
+	return &PostgresTrashRepository{SQLiteTrashRepository{db: newPgConn(db)}}
+}
+
+// PostgresUserRepository implements UserRepository using PostgreSQL
+type PostgresUserRepository struct {
+	SQLiteUserRepository
+}
+
+// NewPostgresUserRepository creates a new PostgreSQL repository instance
+func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{SQLiteUserRepository{db: newPgConn(db)}}
+}
+
+// PostgresSessionRepository implements SessionRepository using PostgreSQL
+type PostgresSessionRepository struct {
+	SQLiteSessionRepository
+}
+
+// NewPostgresSessionRepository creates a new PostgreSQL repository instance
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{SQLiteSessionRepository{db: newPgConn(db)}}
+}
+
+// PostgresWorkspaceRepository implements WorkspaceRepository using PostgreSQL
+type PostgresWorkspaceRepository struct {
+	SQLiteWorkspaceRepository
+}
+
+// NewPostgresWorkspaceRepository creates a new PostgreSQL repository instance
+func NewPostgresWorkspaceRepository(db *sql.DB) *PostgresWorkspaceRepository {
+	return &PostgresWorkspaceRepository{SQLiteWorkspaceRepository{db: newPgConn(db)}}
+}
+
+// PostgresAPITokenRepository implements APITokenRepository using PostgreSQL
+type PostgresAPITokenRepository struct {
+	SQLiteAPITokenRepository
+}
+
+// NewPostgresAPITokenRepository creates a new PostgreSQL repository instance
+func NewPostgresAPITokenRepository(db *sql.DB) *PostgresAPITokenRepository {
+	return &PostgresAPITokenRepository{SQLiteAPITokenRepository{db: newPgConn(db)}}
+}
+
+// PostgresIntegrityRepository implements IntegrityRepository using
+// PostgreSQL
+type PostgresIntegrityRepository struct {
+	SQLiteIntegrityRepository
+}
+
+// NewPostgresIntegrityRepository creates a new PostgreSQL repository instance
+func NewPostgresIntegrityRepository(db *sql.DB) *PostgresIntegrityRepository {
+	return &PostgresIntegrityRepository{SQLiteIntegrityRepository{db: newPgConn(db)}}
+}
+
+// PostgresUnitOfWork implements UnitOfWork using the PostgreSQL repositories
+type PostgresUnitOfWork struct {
+	SQLiteUnitOfWork
+}
+
+// NewPostgresUnitOfWork creates a new PostgreSQL unit of work, see
+// NewSQLiteUnitOfWork for wrap
+func NewPostgresUnitOfWork(db *sql.DB, wrap ...func(tx *Tx)) *PostgresUnitOfWork {
+	return &PostgresUnitOfWork{SQLiteUnitOfWork{db: db, wrap: wrap, repositories: newPostgresTx}}
+}
+
+// newPostgresTx returns the PostgreSQL repositories of a unit of work
+func newPostgresTx(c conn) *Tx {
+	c = pgConn{c}
+	return &Tx{
+		Bills:               &PostgresBillRepository{SQLiteBillRepository{db: c}},
+		BillItems:           &PostgresBillItemRepository{SQLiteBillItemRepository{db: c}},
+		BillItemAssignments: &PostgresBillItemAssignmentRepository{SQLiteBillItemAssignmentRepository{db: c}},
+		Issuers:             &PostgresIssuerRepository{SQLiteIssuerRepository{db: c}},
+		Receivers:           &PostgresReceiverRepository{SQLiteReceiverRepository{db: c}},
+		Audit:               &PostgresAuditRepository{SQLiteAuditRepository{db: c}},
+		Trash:               &PostgresTrashRepository{SQLiteTrashRepository{db: c}},
+		Users:               &PostgresUserRepository{SQLiteUserRepository{db: c}},
+		Sessions:            &PostgresSessionRepository{SQLiteSessionRepository{db: c}},
+		Workspaces:          &PostgresWorkspaceRepository{SQLiteWorkspaceRepository{db: c}},
+		APITokens:           &PostgresAPITokenRepository{SQLiteAPITokenRepository{db: c}},
+		Integrity:           &PostgresIntegrityRepository{SQLiteIntegrityRepository{db: c}},
+	}
+}
+
+func newPgConn(db *sql.DB) conn {
+	return pgConn{dbConn{db}}
+}
+
+// pgConn runs the statements of the repositories on PostgreSQL, see rebind
+type pgConn struct {
+	conn
+}
+
+func (c pgConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.conn.ExecContext(ctx, rebind(query), args...)
+}
+
+func (c pgConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn.QueryContext(ctx, rebind(query), args...)
+}
+
+func (c pgConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.conn.QueryRowContext(ctx, rebind(query), args...)
+}
+
+func (c pgConn) begin(ctx context.Context) (txn, error) {
+	tx, err := c.conn.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pgTxn{tx}, nil
+}
+
+// pgTxn is the transaction of a single repository call on PostgreSQL
+type pgTxn struct {
+	txn
+}
+
+func (tx pgTxn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.txn.ExecContext(ctx, rebind(query), args...)
+}
+
+func (tx pgTxn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.txn.QueryContext(ctx, rebind(query), args...)
+}
+
+func (tx pgTxn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.txn.QueryRowContext(ctx, rebind(query), args...)
+}
+
+// rebind turns a statement written for SQLite into one for PostgreSQL. The
+// ? placeholders become $1, $2 and so on, and LIKE becomes ILIKE. Quoted
+// strings and identifiers are left as they are
+func rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		case ch == 'L' && strings.HasPrefix(query[i:], "LIKE") && isWordEdge(query, i-1) && isWordEdge(query, i+4):
+			b.WriteString("ILIKE")
+			i += len("LIKE") - 1
+			continue
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// isWordEdge reports whether the byte at i of query does not continue a word
+func isWordEdge(query string, i int) bool {
+	if i < 0 || i >= len(query) {
+		return true
+	}
+	ch := query[i]
+	return !(ch == '_' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z')
+}
@@ -1,56 +1,57 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"bills/internal/models"
+	"bills/internal/tenant"
 )
 
-// ReceiverRepository defines the interface for receiver storage operations
+// ReceiverRepository defines the interface for receiver storage operations. Every
+// method works in the workspace of ctx. Delete moves the receiver to the trash,
+// trashed receivers are left out until restored, see TrashRepository.
+// GetByID, Update and Delete return models.ErrNotFound when the workspace has
+// no such receiver outside the trash
 type ReceiverRepository interface {
-	Create(receiver *models.Receiver) error
-	GetByID(id int64) (*models.Receiver, error)
-	GetAll() ([]*models.Receiver, error)
-	Update(receiver *models.Receiver) error
-	Delete(id int64) error
+	Create(ctx context.Context, receiver *models.Receiver) error
+	GetByID(ctx context.Context, id int64) (*models.Receiver, error)
+	GetAll(ctx context.Context) ([]*models.Receiver, error)
+	Update(ctx context.Context, receiver *models.Receiver) error
+	Delete(ctx context.Context, id int64) error
 }
 
 // SQLiteReceiverRepository implements ReceiverRepository using SQLite
 type SQLiteReceiverRepository struct {
-	db *sql.DB
+	db conn
 }
 
 // NewSQLiteReceiverRepository creates a new SQLite repository instance
 func NewSQLiteReceiverRepository(db *sql.DB) *SQLiteReceiverRepository {
-	return &SQLiteReceiverRepository{db: db}
+	return &SQLiteReceiverRepository{db: dbConn{db}}
 }
 
-// InitReceiverDB initializes the receivers table
-func InitReceiverDB(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS receivers (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			vat_number TEXT NOT NULL,
-			street TEXT NOT NULL,
-			city TEXT NOT NULL,
-			state TEXT NOT NULL,
-			zip_code TEXT NOT NULL,
-			country TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		)
-	`)
-	return err
-}
+func (r *SQLiteReceiverRepository) Create(ctx context.Context, receiver *models.Receiver) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-func (r *SQLiteReceiverRepository) Create(receiver *models.Receiver) error {
 	query := `
-		INSERT INTO receivers (name, vat_number, street, city, state, zip_code, country, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO receivers (workspace_id, name, vat_number, street, city, state, zip_code, country, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
-	result, err := r.db.Exec(query,
+	var id int64
+	err = tx.QueryRowContext(ctx, query,
+		workspaceID,
 		receiver.Name,
 		receiver.VATNumber,
 		receiver.Street,
@@ -60,25 +61,29 @@ func (r *SQLiteReceiverRepository) Create(receiver *models.Receiver) error {
 		receiver.Country,
 		time.Now(),
 		time.Now(),
-	)
+	).Scan(&id)
 	if err != nil {
 		return err
 	}
+	receiver.ID = id
 
-	id, err := result.LastInsertId()
-	if err != nil {
+	if err := recordChange(ctx, tx, models.EntityReceivers, id, workspaceID, models.AuditCreate, nil); err != nil {
 		return err
 	}
-	receiver.ID = id
-	return nil
+	return tx.Commit()
 }
 
-func (r *SQLiteReceiverRepository) GetByID(id int64) (*models.Receiver, error) {
+func (r *SQLiteReceiverRepository) GetByID(ctx context.Context, id int64) (*models.Receiver, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	receiver := &models.Receiver{}
-	err := r.db.QueryRow(`
+	err = r.db.QueryRowContext(ctx, `
 		SELECT id, name, vat_number, street, city, state, zip_code, country, created_at, updated_at
-		FROM receivers WHERE id = ?
-	`, id).Scan(
+		FROM receivers WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
+	`, id, workspaceID).Scan(
 		&receiver.ID,
 		&receiver.Name,
 		&receiver.VATNumber,
@@ -91,16 +96,21 @@ func (r *SQLiteReceiverRepository) GetByID(id int64) (*models.Receiver, error) {
 		&receiver.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, nil
+		return nil, models.NotFound("receiver")
 	}
 	return receiver, err
 }
 
-func (r *SQLiteReceiverRepository) GetAll() ([]*models.Receiver, error) {
-	rows, err := r.db.Query(`
+func (r *SQLiteReceiverRepository) GetAll(ctx context.Context) ([]*models.Receiver, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, name, vat_number, street, city, state, zip_code, country, created_at, updated_at
-		FROM receivers ORDER BY name ASC
-	`)
+		FROM receivers WHERE workspace_id = ? AND deleted_at IS NULL ORDER BY name ASC
+	`, workspaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -129,12 +139,31 @@ func (r *SQLiteReceiverRepository) GetAll() ([]*models.Receiver, error) {
 	return receivers, rows.Err()
 }
 
-func (r *SQLiteReceiverRepository) Update(receiver *models.Receiver) error {
+func (r *SQLiteReceiverRepository) Update(ctx context.Context, receiver *models.Receiver) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityReceivers, receiver.ID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("receiver")
+	}
+
 	receiver.UpdatedAt = time.Now()
-	_, err := r.db.Exec(`
+	result, err := tx.ExecContext(ctx, `
 		UPDATE receivers
 		SET name = ?, vat_number = ?, street = ?, city = ?, state = ?, zip_code = ?, country = ?, updated_at = ?
-		WHERE id = ?
+		WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
 	`,
 		receiver.Name,
 		receiver.VATNumber,
@@ -145,11 +174,57 @@ func (r *SQLiteReceiverRepository) Update(receiver *models.Receiver) error {
 		receiver.Country,
 		receiver.UpdatedAt,
 		receiver.ID,
+		workspaceID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Trashed rows are not changed
+		return models.NotFound("receiver")
+	}
+
+	if err := recordChange(ctx, tx, models.EntityReceivers, receiver.ID, workspaceID, models.AuditUpdate, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *SQLiteReceiverRepository) Delete(id int64) error {
-	_, err := r.db.Exec("DELETE FROM receivers WHERE id = ?", id)
-	return err
+func (r *SQLiteReceiverRepository) Delete(ctx context.Context, id int64) error {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, models.EntityReceivers, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.NotFound("receiver")
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE receivers SET deleted_at = ? WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL", time.Now(), id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Already in the trash
+		return models.NotFound("receiver")
+	}
+
+	if err := recordChange(ctx, tx, models.EntityReceivers, id, workspaceID, models.AuditDelete, before); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
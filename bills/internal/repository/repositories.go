@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"bills/db"
+	"database/sql"
+)
+
+// Repositories holds a repository of every kind, all working on one database
+type Repositories struct {
+	Bills               BillRepository
+	BillItems           BillItemRepository
+	BillItemAssignments BillItemAssignmentRepository
+	Issuers             IssuerRepository
+	Receivers           ReceiverRepository
+	Audit               AuditRepository
+	Trash               TrashRepository
+	Users               UserRepository
+	Sessions            SessionRepository
+	Workspaces          WorkspaceRepository
+	APITokens           APITokenRepository
+	Search              SearchRepository
+	Integrity           IntegrityRepository
+}
+
+// NewRepositories returns the repositories of the database sqlDB was opened on,
+// PostgreSQL or SQLite
+func NewRepositories(sqlDB *sql.DB) *Repositories {
+	if db.IsPostgres(sqlDB) {
+		return &Repositories{
+			Bills:               NewPostgresBillRepository(sqlDB),
+			BillItems:           NewPostgresBillItemRepository(sqlDB),
+			BillItemAssignments: NewPostgresBillItemAssignmentRepository(sqlDB),
+			Issuers:             NewPostgresIssuerRepository(sqlDB),
+			Receivers:           NewPostgresReceiverRepository(sqlDB),
+			Audit:               NewPostgresAuditRepository(sqlDB),
+			Trash:               NewPostgresTrashRepository(sqlDB),
+			Users:               NewPostgresUserRepository(sqlDB),
+			Sessions:            NewPostgresSessionRepository(sqlDB),
+			Workspaces:          NewPostgresWorkspaceRepository(sqlDB),
+			APITokens:           NewPostgresAPITokenRepository(sqlDB),
+			Search:              NewPostgresSearchRepository(sqlDB),
+			Integrity:           NewPostgresIntegrityRepository(sqlDB),
+		}
+	}
+	return &Repositories{
+		Bills:               NewSQLiteBillRepository(sqlDB),
+		BillItems:           NewSQLiteBillItemRepository(sqlDB),
+		BillItemAssignments: NewSQLiteBillItemAssignmentRepository(sqlDB),
+		Issuers:             NewSQLiteIssuerRepository(sqlDB),
+		Receivers:           NewSQLiteReceiverRepository(sqlDB),
+		Audit:               NewSQLiteAuditRepository(sqlDB),
+		Trash:               NewSQLiteTrashRepository(sqlDB),
+		Users:               NewSQLiteUserRepository(sqlDB),
+		Sessions:            NewSQLiteSessionRepository(sqlDB),
+		Workspaces:          NewSQLiteWorkspaceRepository(sqlDB),
+		APITokens:           NewSQLiteAPITokenRepository(sqlDB),
+		Search:              NewSQLiteSearchRepository(sqlDB),
+		Integrity:           NewSQLiteIntegrityRepository(sqlDB),
+	}
+}
+
+// NewUnitOfWork returns the unit of work of the database sqlDB was opened on,
+// see NewSQLiteUnitOfWork for wrap
+func NewUnitOfWork(sqlDB *sql.DB, wrap ...func(tx *Tx)) UnitOfWork {
+	if db.IsPostgres(sqlDB) {
+		return NewPostgresUnitOfWork(sqlDB, wrap...)
+	}
+	return NewSQLiteUnitOfWork(sqlDB, wrap...)
+}
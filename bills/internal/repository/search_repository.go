@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"bills/internal/models"
+	"bills/internal/tenant"
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// SearchRepository finds bills, parties and catalog items in the workspace of
+// ctx
+type SearchRepository interface {
+	// Search returns up to limit results of each entity matching every word
+	// of query, grouped in the order of models.SearchEntities
+	Search(ctx context.Context, query string, limit int) ([]*models.SearchGroup, error)
+}
+
+// SQLiteSearchRepository implements SearchRepository using the FTS5 search
+// index, see db.SyncSearchIndex. Databases without the index are searched
+// with LIKE
+type SQLiteSearchRepository struct {
+	db conn
+}
+
+// NewSQLiteSearchRepository creates a new SQLite repository instance
+func NewSQLiteSearchRepository(db *sql.DB) *SQLiteSearchRepository {
+	return &SQLiteSearchRepository{db: dbConn{db}}
+}
+
+// searchMatch ranks the index entries with bm25, matches in the title
+// weighing ten times those in the detail
+const searchMatch = `
+	SELECT entity, entity_id, title, detail, bm25(search_index, 0, 0, 0, 10, 1) AS score
+	FROM search_index
+	WHERE search_index MATCH ? AND workspace_id = ?`
+
+// searchSources selects what the search index holds for each entity, amount
+// formatting the total of a bill with two decimals
+func searchSources(amount string) string {
+	return `
+		SELECT 'bills' AS entity, b.id AS entity_id, b.workspace_id, '#' || b.id AS title,
+			COALESCE(i.name, '') || ' → ' || COALESCE(r.name, '') || ', ' || ` + amount + ` || ' ' || b.currency AS detail
+		FROM bills b
+		LEFT JOIN issuers i ON i.id = b.issuer_id
+		LEFT JOIN receivers r ON r.id = b.receiver_id
+		WHERE b.deleted_at IS NULL
+		UNION ALL
+		SELECT 'issuers', id, workspace_id, name,
+			vat_number || ', ' || street || ', ' || zip_code || ' ' || city || ', ' || state || ', ' || country
+		FROM issuers WHERE deleted_at IS NULL
+		UNION ALL
+		SELECT 'receivers', id, workspace_id, name,
+			vat_number || ', ' || street || ', ' || zip_code || ' ' || city || ', ' || state || ', ' || country
+		FROM receivers WHERE deleted_at IS NULL
+		UNION ALL
+		SELECT 'bill_items', id, workspace_id, name, currency
+		FROM bill_items WHERE deleted_at IS NULL`
+}
+
+func (r *SQLiteSearchRepository) Search(ctx context.Context, query string, limit int) ([]*models.SearchGroup, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := models.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	indexed, err := r.indexed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !indexed {
+		matches, args := likeMatches(searchSources("printf('%.2f', b.original_total)"), terms, workspaceID)
+		return searchGroups(ctx, r.db, matches, args, limit)
+	}
+
+	// Every word matches as the prefix of a word in the entry
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + term + `"*`
+	}
+	return searchGroups(ctx, r.db, searchMatch, []interface{}{strings.Join(phrases, " "), workspaceID}, limit)
+}
+
+// likeMatches returns the entries of sources in the workspace that contain
+// every term, titles starting with the first term coming first
+func likeMatches(sources string, terms []string, workspaceID int64) (string, []interface{}) {
+	conditions := []string{"workspace_id = ?"}
+	args := []interface{}{terms[0] + "%", workspaceID}
+	for _, term := range terms {
+		conditions = append(conditions, `(title || ' ' || detail) LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(term))
+	}
+	return `
+		SELECT entity, entity_id, title, detail, CASE WHEN title LIKE ? THEN 0 ELSE 1 END AS score
+		FROM (` + sources + `) AS entries
+		WHERE ` + strings.Join(conditions, " AND "), args
+}
+
+// searchGroups runs matches, which selects the entity, id, title, detail and
+// score of the matching entries, and groups up to limit of the best scored
+// entries of each entity
+func searchGroups(ctx context.Context, db conn, matches string, args []interface{}, limit int) ([]*models.SearchGroup, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT entity, entity_id, title, detail FROM (
+			SELECT entity, entity_id, title, detail, score,
+				ROW_NUMBER() OVER (PARTITION BY entity ORDER BY score, title) AS n
+			FROM (`+matches+`) AS matches
+		) AS ranked
+		WHERE n <= ?
+		ORDER BY score, title
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byEntity := make(map[string]*models.SearchGroup)
+	for rows.Next() {
+		result := &models.SearchResult{}
+		if err := rows.Scan(&result.Entity, &result.ID, &result.Title, &result.Detail); err != nil {
+			return nil, err
+		}
+		group, ok := byEntity[result.Entity]
+		if !ok {
+			group = &models.SearchGroup{Entity: result.Entity}
+			byEntity[result.Entity] = group
+		}
+		group.Results = append(group.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var groups []*models.SearchGroup
+	for _, entity := range models.SearchEntities() {
+		if group, ok := byEntity[entity]; ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// indexed reports whether the search index exists and is kept in sync. Its
+// triggers are only there while it is
+func (r *SQLiteSearchRepository) indexed(ctx context.Context) (bool, error) {
+	var found int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM sqlite_master WHERE type = 'trigger' AND name = 'search_bills_insert'").Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PostgresSearchRepository implements SearchRepository using PostgreSQL,
+// which has no search index and is searched with ILIKE. Results match
+// substrings and come in the order of the rows, unranked, which is one of the
+// reasons PostgreSQL support is experimental, see db.PostgresOptIn
+type PostgresSearchRepository struct {
+	db conn
+}
+
+// NewPostgresSearchRepository creates a new PostgreSQL repository instance
+func NewPostgresSearchRepository(db *sql.DB) *PostgresSearchRepository {
+	return &PostgresSearchRepository{db: newPgConn(db)}
+}
+
+func (r *PostgresSearchRepository) Search(ctx context.Context, query string, limit int) ([]*models.SearchGroup, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := models.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	matches, args := likeMatches(searchSources("to_char(b.original_total, 'FM999999999990.00')"), terms, workspaceID)
+	return searchGroups(ctx, r.db, matches, args, limit)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"bills/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SessionRepository defines the interface for login session storage operations
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error)
+	Delete(ctx context.Context, tokenHash string) error
+	SetWorkspace(ctx context.Context, tokenHash string, workspaceID int64) error
+	DeleteByUserID(ctx context.Context, userID int64) error
+	DeleteExpired(ctx context.Context) error
+}
+
+// SQLiteSessionRepository implements SessionRepository using SQLite
+type SQLiteSessionRepository struct {
+	db conn
+}
+
+// NewSQLiteSessionRepository creates a new SQLite repository instance
+func NewSQLiteSessionRepository(db *sql.DB) *SQLiteSessionRepository {
+	return &SQLiteSessionRepository{db: dbConn{db}}
+}
+
+func (r *SQLiteSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sessions (token_hash, user_id, workspace_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, session.TokenHash, session.UserID, nullID(session.WorkspaceID), session.ExpiresAt, session.CreatedAt)
+	return err
+}
+
+func (r *SQLiteSessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	session := &models.Session{}
+	var workspaceID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT token_hash, user_id, workspace_id, expires_at, created_at
+		FROM sessions WHERE token_hash = ?
+	`, tokenHash).Scan(
+		&session.TokenHash,
+		&session.UserID,
+		&workspaceID,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("session")
+	}
+	session.WorkspaceID = workspaceID.Int64
+	return session, err
+}
+
+func (r *SQLiteSessionRepository) SetWorkspace(ctx context.Context, tokenHash string, workspaceID int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE sessions SET workspace_id = ? WHERE token_hash = ?", nullID(workspaceID), tokenHash)
+	return err
+}
+
+func (r *SQLiteSessionRepository) Delete(ctx context.Context, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM sessions WHERE token_hash = ?", tokenHash)
+	return err
+}
+
+func (r *SQLiteSessionRepository) DeleteByUserID(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = ?", userID)
+	return err
+}
+
+func (r *SQLiteSessionRepository) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM sessions WHERE expires_at <= ?", time.Now())
+	return err
+}
+
+// nullID stores a zero id as NULL
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
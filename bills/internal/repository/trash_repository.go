@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"bills/internal/models"
+	"bills/internal/tenant"
+)
+
+var (
+	// ErrInUse is returned when purging a party or catalog item that bills
+	// still use, trashed bills included. It matches models.ErrConflict
+	ErrInUse = models.Conflict("still used by bills")
+
+	// errNotTrashed is returned when restoring or purging a row that is not in
+	// the trash of the workspace
+	errNotTrashed = models.NotFound("trashed row")
+)
+
+// TrashRepository restores and purges the rows other repositories moved to
+// the trash. Every method but PurgeDeletedBefore works in the workspace of ctx
+type TrashRepository interface {
+	GetAll(ctx context.Context) ([]*models.TrashedItem, error)
+	Restore(ctx context.Context, entity string, id int64) error
+	Purge(ctx context.Context, entity string, id int64) error
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// SQLiteTrashRepository implements TrashRepository using SQLite
+type SQLiteTrashRepository struct {
+	db conn
+}
+
+// NewSQLiteTrashRepository creates a new SQLite repository instance
+func NewSQLiteTrashRepository(db *sql.DB) *SQLiteTrashRepository {
+	return &SQLiteTrashRepository{db: dbConn{db}}
+}
+
+// trashLabels selects the id, name and deletion time of the trashed rows of
+// each entity. Bills are named after their receiver and also select their
+// total and currency for the label
+var trashLabels = map[string]string{
+	models.EntityBills: `
+		SELECT b.id, COALESCE(r.name, ''), b.deleted_at, b.original_total, b.currency
+		FROM bills b
+		LEFT JOIN receivers r ON b.receiver_id = r.id
+		WHERE b.workspace_id = ? AND b.deleted_at IS NOT NULL`,
+	models.EntityBillItems: `
+		SELECT id, name, deleted_at, NULL, NULL FROM bill_items
+		WHERE workspace_id = ? AND deleted_at IS NOT NULL`,
+	models.EntityIssuers: `
+		SELECT id, name, deleted_at, NULL, NULL FROM issuers
+		WHERE workspace_id = ? AND deleted_at IS NOT NULL`,
+	models.EntityReceivers: `
+		SELECT id, name, deleted_at, NULL, NULL FROM receivers
+		WHERE workspace_id = ? AND deleted_at IS NOT NULL`,
+}
+
+// GetAll returns the trashed rows of every entity, most recently deleted
+// first
+func (r *SQLiteTrashRepository) GetAll(ctx context.Context) ([]*models.TrashedItem, error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*models.TrashedItem
+	for _, entity := range models.TrashEntities() {
+		rows, err := r.db.QueryContext(ctx, trashLabels[entity], workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			item := &models.TrashedItem{Entity: entity}
+			var total sql.NullFloat64
+			var currency sql.NullString
+			if err := rows.Scan(&item.ID, &item.Label, &item.DeletedAt, &total, &currency); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if total.Valid {
+				item.Label = fmt.Sprintf("%s, %.2f %s", item.Label, total.Float64, currency.String)
+			}
+			items = append(items, item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Restore takes a row out of the trash. Rows that are not in the trash give
+// models.ErrNotFound
+func (r *SQLiteTrashRepository) Restore(ctx context.Context, entity string, id int64) error {
+	if !models.IsTrashEntity(entity) {
+		return models.Invalid("entity", fmt.Sprintf("%q cannot be restored", entity))
+	}
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, entity, id, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE "+entity+" SET deleted_at = NULL WHERE id = ? AND workspace_id = ? AND deleted_at IS NOT NULL", id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Not in the trash of this workspace
+		return errNotTrashed
+	}
+
+	if err := recordChange(ctx, tx, entity, id, workspaceID, models.AuditRestore, before); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Purge deletes a trashed row for good. Purging a bill purges its lines,
+// parties and catalog items still used by bills give ErrInUse and rows that
+// are not in the trash models.ErrNotFound
+func (r *SQLiteTrashRepository) Purge(ctx context.Context, entity string, id int64) error {
+	if !models.IsTrashEntity(entity) {
+		return models.Invalid("entity", fmt.Sprintf("%q cannot be purged", entity))
+	}
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return err
+	}
+	return r.purge(ctx, entity, id, workspaceID)
+}
+
+// PurgeDeletedBefore purges the rows of every workspace that were trashed
+// before cutoff, for the retention job. Rows still in use stay in the trash
+// until the bills using them are purged. It returns how many rows were purged
+func (r *SQLiteTrashRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	purged := 0
+	for _, entity := range models.TrashEntities() {
+		rows, err := r.db.QueryContext(ctx, "SELECT id, workspace_id FROM "+entity+" WHERE deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+		if err != nil {
+			return purged, err
+		}
+		type row struct{ id, workspaceID int64 }
+		var expired []row
+		for rows.Next() {
+			var e row
+			if err := rows.Scan(&e.id, &e.workspaceID); err != nil {
+				rows.Close()
+				return purged, err
+			}
+			expired = append(expired, e)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return purged, err
+		}
+
+		for _, e := range expired {
+			err := r.purge(ctx, entity, e.id, e.workspaceID)
+			if errors.Is(err, ErrInUse) || errors.Is(err, models.ErrNotFound) {
+				// Still in use, or restored meanwhile
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// purge deletes a trashed row of the workspace with an audit event, in one
+// transaction
+func (r *SQLiteTrashRepository) purge(ctx context.Context, entity string, id, workspaceID int64) error {
+	tx, err := r.db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, "SELECT deleted_at FROM "+entity+" WHERE id = ? AND workspace_id = ?", id, workspaceID).Scan(&deletedAt)
+	if err == sql.ErrNoRows || (err == nil && !deletedAt.Valid) {
+		// Only rows in the trash of this workspace are purged
+		return errNotTrashed
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := checkUnused(ctx, tx, entity, id); err != nil {
+		return err
+	}
+
+	before, err := snapshotRow(ctx, tx, entity, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	if entity == models.EntityBills {
+		if err := deleteBillLines(ctx, tx, id, workspaceID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+entity+" WHERE id = ?", id); err != nil {
+		return constraintError(err, "still referenced by other records")
+	}
+
+	if err := recordChange(ctx, tx, entity, id, workspaceID, models.AuditPurge, before); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkUnused returns ErrInUse when bills still use the party or catalog item
+func checkUnused(ctx context.Context, q queryRower, entity string, id int64) error {
+	var query string
+	switch entity {
+	case models.EntityIssuers:
+		query = "SELECT 1 FROM bills WHERE issuer_id = ? LIMIT 1"
+	case models.EntityReceivers:
+		query = "SELECT 1 FROM bills WHERE receiver_id = ? LIMIT 1"
+	case models.EntityBillItems:
+		query = "SELECT 1 FROM bill_item_assignments WHERE item_id = ? LIMIT 1"
+	default:
+		return nil
+	}
+
+	var found int
+	err := q.QueryRowContext(ctx, query, id).Scan(&found)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s %d", ErrInUse, entity, id)
+}
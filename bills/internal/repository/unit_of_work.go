@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// UnitOfWork runs work that spans several repositories in one transaction
+type UnitOfWork interface {
+	// Do calls fn with repositories that all work in a new transaction. The
+	// transaction commits when fn returns nil and rolls back when it returns
+	// an error or panics
+	Do(ctx context.Context, fn func(tx *Tx) error) error
+}
+
+// Tx holds the repositories of a unit of work. They must not be used once
+// the function given to UnitOfWork.Do returned
+type Tx struct {
+	Bills               BillRepository
+	BillItems           BillItemRepository
+	BillItemAssignments BillItemAssignmentRepository
+	Issuers             IssuerRepository
+	Receivers           ReceiverRepository
+	Audit               AuditRepository
+	Trash               TrashRepository
+	Users               UserRepository
+	Sessions            SessionRepository
+	Workspaces          WorkspaceRepository
+	APITokens           APITokenRepository
+	Integrity           IntegrityRepository
+
+	afterCommit []func()
+}
+
+// AfterCommit registers fn to run once the unit of work committed. Nothing
+// runs when it rolls back
+func (tx *Tx) AfterCommit(fn func()) {
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+// SQLiteUnitOfWork implements UnitOfWork using the SQLite repositories
+type SQLiteUnitOfWork struct {
+	db   *sql.DB
+	wrap []func(tx *Tx)
+
+	// repositories returns the repositories working in a transaction
+	repositories func(c conn) *Tx
+}
+
+// NewSQLiteUnitOfWork creates a new SQLite unit of work. The wrap functions
+// get the repositories of every unit before its function does and may
+// replace them, such as with a repository that streams the changes it makes
+func NewSQLiteUnitOfWork(db *sql.DB, wrap ...func(tx *Tx)) *SQLiteUnitOfWork {
+	return &SQLiteUnitOfWork{db: db, wrap: wrap, repositories: newSQLiteTx}
+}
+
+func (u *SQLiteUnitOfWork) Do(ctx context.Context, fn func(tx *Tx) error) error {
+	sqlTx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer sqlTx.Rollback()
+
+	tx := u.repositories(&txConn{Tx: sqlTx})
+	for _, wrap := range u.wrap {
+		wrap(tx)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		return err
+	}
+
+	for _, fn := range tx.afterCommit {
+		fn()
+	}
+	return nil
+}
+
+// newSQLiteTx returns the SQLite repositories of a unit of work
+func newSQLiteTx(c conn) *Tx {
+	return &Tx{
+		Bills:               &SQLiteBillRepository{db: c},
+		BillItems:           &SQLiteBillItemRepository{db: c},
+		BillItemAssignments: &SQLiteBillItemAssignmentRepository{db: c},
+		Issuers:             &SQLiteIssuerRepository{db: c},
+		Receivers:           &SQLiteReceiverRepository{db: c},
+		Audit:               &SQLiteAuditRepository{db: c},
+		Trash:               &SQLiteTrashRepository{db: c},
+		Users:               &SQLiteUserRepository{db: c},
+		Sessions:            &SQLiteSessionRepository{db: c},
+		Workspaces:          &SQLiteWorkspaceRepository{db: c},
+		APITokens:           &SQLiteAPITokenRepository{db: c},
+		Integrity:           &SQLiteIntegrityRepository{db: c},
+	}
+}
+
+// conn runs the statements of the repositories, either on the database or in
+// the transaction of a unit of work
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+
+	// begin starts the transaction of a single repository call
+	begin(ctx context.Context) (txn, error)
+}
+
+// txn is the transaction of a single repository call
+type txn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Commit() error
+	Rollback() error
+}
+
+// dbConn runs statements on the database, every repository call in a
+// transaction of its own
+type dbConn struct {
+	*sql.DB
+}
+
+func (c dbConn) begin(ctx context.Context) (txn, error) {
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// txConn runs statements in the transaction of a unit of work. Repository
+// calls run in a savepoint so a failed call leaves nothing behind even when
+// the unit goes on and commits
+type txConn struct {
+	*sql.Tx
+	savepoints int
+}
+
+func (c *txConn) begin(ctx context.Context) (txn, error) {
+	c.savepoints++
+	sp := &savepoint{Tx: c.Tx, ctx: ctx, name: fmt.Sprintf("call_%d", c.savepoints)}
+	if _, err := c.ExecContext(ctx, "SAVEPOINT "+sp.name); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// savepoint is a repository call inside the transaction of a unit of work.
+// Committing releases the savepoint and leaves the changes to the unit
+type savepoint struct {
+	*sql.Tx
+	ctx  context.Context
+	name string
+	done bool
+}
+
+func (sp *savepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.ExecContext(sp.ctx, "RELEASE SAVEPOINT "+sp.name)
+	return err
+}
+
+func (sp *savepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	if _, err := sp.ExecContext(sp.ctx, "ROLLBACK TO SAVEPOINT "+sp.name); err != nil {
+		return err
+	}
+	_, err := sp.ExecContext(sp.ctx, "RELEASE SAVEPOINT "+sp.name)
+	return err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"bills/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UserRepository defines the interface for user storage operations
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetAll(ctx context.Context) ([]*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// SQLiteUserRepository implements UserRepository using SQLite
+type SQLiteUserRepository struct {
+	db conn
+}
+
+// NewSQLiteUserRepository creates a new SQLite repository instance
+func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
+	return &SQLiteUserRepository{db: dbConn{db}}
+}
+
+func (r *SQLiteUserRepository) Create(ctx context.Context, user *models.User) error {
+	now := time.Now()
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO users (username, password_hash, instance_admin, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`, user.Username, user.PasswordHash, user.InstanceAdmin, now, now).Scan(&id)
+	if err != nil {
+		return constraintError(err, "username is taken")
+	}
+	user.ID = id
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	return nil
+}
+
+func (r *SQLiteUserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	return r.getOne(ctx, "SELECT id, username, password_hash, instance_admin, created_at, updated_at FROM users WHERE id = ?", id)
+}
+
+func (r *SQLiteUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.getOne(ctx, "SELECT id, username, password_hash, instance_admin, created_at, updated_at FROM users WHERE username = ?", username)
+}
+
+func (r *SQLiteUserRepository) getOne(ctx context.Context, query string, arg interface{}) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.InstanceAdmin,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("user")
+	}
+	return user, err
+}
+
+func (r *SQLiteUserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, username, password_hash, instance_admin, created_at, updated_at
+		FROM users ORDER BY username ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.PasswordHash,
+			&user.InstanceAdmin,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *SQLiteUserRepository) Update(ctx context.Context, user *models.User) error {
+	user.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET username = ?, password_hash = ?, instance_admin = ?, updated_at = ? WHERE id = ?
+	`, user.Username, user.PasswordHash, user.InstanceAdmin, user.UpdatedAt, user.ID)
+	return constraintError(err, "username is taken")
+}
+
+func (r *SQLiteUserRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"bills/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WorkspaceRepository defines the interface for workspace and membership
+// storage operations
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *models.Workspace) error
+	GetByID(ctx context.Context, id int64) (*models.Workspace, error)
+	GetByName(ctx context.Context, name string) (*models.Workspace, error)
+	GetAll(ctx context.Context) ([]*models.Workspace, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*models.Workspace, error)
+	AddMember(ctx context.Context, workspaceID, userID int64, role models.Role) error
+	RemoveMember(ctx context.Context, workspaceID, userID int64) error
+	IsMember(ctx context.Context, workspaceID, userID int64) (bool, error)
+	GetMembers(ctx context.Context, workspaceID int64) ([]*models.User, error)
+	GetRole(ctx context.Context, workspaceID, userID int64) (models.Role, error)
+	SetRole(ctx context.Context, workspaceID, userID int64, role models.Role) error
+	CountByRole(ctx context.Context, workspaceID int64, role models.Role) (int, error)
+}
+
+// SQLiteWorkspaceRepository implements WorkspaceRepository using SQLite
+type SQLiteWorkspaceRepository struct {
+	db conn
+}
+
+// NewSQLiteWorkspaceRepository creates a new SQLite repository instance
+func NewSQLiteWorkspaceRepository(db *sql.DB) *SQLiteWorkspaceRepository {
+	return &SQLiteWorkspaceRepository{db: dbConn{db}}
+}
+
+func (r *SQLiteWorkspaceRepository) Create(ctx context.Context, workspace *models.Workspace) error {
+	now := time.Now()
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO workspaces (name, created_at, updated_at)
+		VALUES (?, ?, ?)
+		RETURNING id
+	`, workspace.Name, now, now).Scan(&id)
+	if err != nil {
+		return constraintError(err, "workspace name is taken")
+	}
+	workspace.ID = id
+	workspace.CreatedAt = now
+	workspace.UpdatedAt = now
+	return nil
+}
+
+func (r *SQLiteWorkspaceRepository) GetByID(ctx context.Context, id int64) (*models.Workspace, error) {
+	return r.getOne(ctx, "SELECT id, name, created_at, updated_at FROM workspaces WHERE id = ?", id)
+}
+
+func (r *SQLiteWorkspaceRepository) GetByName(ctx context.Context, name string) (*models.Workspace, error) {
+	return r.getOne(ctx, "SELECT id, name, created_at, updated_at FROM workspaces WHERE name = ?", name)
+}
+
+func (r *SQLiteWorkspaceRepository) GetAll(ctx context.Context) ([]*models.Workspace, error) {
+	return r.getMany(ctx, "SELECT id, name, created_at, updated_at FROM workspaces ORDER BY name ASC")
+}
+
+func (r *SQLiteWorkspaceRepository) GetByUserID(ctx context.Context, userID int64) ([]*models.Workspace, error) {
+	return r.getMany(ctx, `
+		SELECT w.id, w.name, w.created_at, w.updated_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = ?
+		ORDER BY w.name ASC
+	`, userID)
+}
+
+// AddMember makes the user a member of the workspace with the given role.
+// Users that already are keep the role they have
+func (r *SQLiteWorkspaceRepository) AddMember(ctx context.Context, workspaceID, userID int64, role models.Role) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`, workspaceID, userID, role, time.Now())
+	return err
+}
+
+func (r *SQLiteWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?", workspaceID, userID)
+	return err
+}
+
+func (r *SQLiteWorkspaceRepository) IsMember(ctx context.Context, workspaceID, userID int64) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM workspace_members WHERE workspace_id = ? AND user_id = ?
+	`, workspaceID, userID).Scan(&count)
+	return count > 0, err
+}
+
+// GetMembers returns the users of the workspace with their role in it
+func (r *SQLiteWorkspaceRepository) GetMembers(ctx context.Context, workspaceID int64) ([]*models.User, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, u.username, u.password_hash, m.role, u.created_at, u.updated_at
+		FROM users u
+		JOIN workspace_members m ON m.user_id = u.id
+		WHERE m.workspace_id = ?
+		ORDER BY u.username ASC
+	`, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.PasswordHash,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// GetRole returns the role of the user in the workspace, or a
+// models.ErrNotFound error when they are not a member
+func (r *SQLiteWorkspaceRepository) GetRole(ctx context.Context, workspaceID, userID int64) (models.Role, error) {
+	var role models.Role
+	err := r.db.QueryRowContext(ctx, `
+		SELECT role FROM workspace_members WHERE workspace_id = ? AND user_id = ?
+	`, workspaceID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", models.NotFound("workspace member")
+	}
+	return role, err
+}
+
+// SetRole changes the role of a member of the workspace
+func (r *SQLiteWorkspaceRepository) SetRole(ctx context.Context, workspaceID, userID int64, role models.Role) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workspace_members SET role = ? WHERE workspace_id = ? AND user_id = ?
+	`, role, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return models.NotFound("workspace member")
+	}
+	return nil
+}
+
+// CountByRole returns how many members of the workspace have the role
+func (r *SQLiteWorkspaceRepository) CountByRole(ctx context.Context, workspaceID int64, role models.Role) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM workspace_members WHERE workspace_id = ? AND role = ?
+	`, workspaceID, role).Scan(&count)
+	return count, err
+}
+
+func (r *SQLiteWorkspaceRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Workspace, error) {
+	workspace := &models.Workspace{}
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&workspace.ID,
+		&workspace.Name,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("workspace")
+	}
+	return workspace, err
+}
+
+func (r *SQLiteWorkspaceRepository) getMany(ctx context.Context, query string, args ...interface{}) ([]*models.Workspace, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []*models.Workspace
+	for rows.Next() {
+		workspace := &models.Workspace{}
+		if err := rows.Scan(
+			&workspace.ID,
+			&workspace.Name,
+			&workspace.CreatedAt,
+			&workspace.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	return workspaces, rows.Err()
+}
+
+// ErrNotInWorkspace is returned when a record refers to a bill, party or
+// catalog item that does not exist in the workspace it is stored in
+var ErrNotInWorkspace = errors.New("referenced record does not exist in this workspace")
+
+// queryRower is implemented by both conn and txn
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// checkWorkspace returns ErrNotInWorkspace unless the row of table with the
+// given id belongs to the workspace and is not in the trash
+func checkWorkspace(ctx context.Context, q queryRower, table string, id, workspaceID int64) error {
+	var found int
+	err := q.QueryRowContext(ctx, "SELECT 1 FROM "+table+" WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL", id, workspaceID).Scan(&found)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s %d", ErrNotInWorkspace, table, id)
+	}
+	return err
+}
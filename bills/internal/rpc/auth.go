@@ -0,0 +1,189 @@
+package rpc
+
+import (
+	"bills/internal/auth"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AuthorizationMetadataKey is the metadata key clients send their API
+	// token with, as "Bearer <token>"
+	AuthorizationMetadataKey = "authorization"
+
+	// WorkspaceMetadataKey is the metadata key clients may send the id of the
+	// workspace they expect to work in with. Calls always work in the
+	// workspace of their token, naming another one is refused
+	WorkspaceMetadataKey = "workspace-id"
+)
+
+// Permissions holds the permission needed by every method registered in
+// NewServer, keyed by full method name. Methods missing from it are refused,
+// see Unguarded
+var Permissions = map[string]models.Permission{
+	"/bills.v1.BillService/CreateBill": models.PermissionCreateBills,
+	"/bills.v1.BillService/GetBill":    models.PermissionView,
+	"/bills.v1.BillService/ListBills":  models.PermissionView,
+	"/bills.v1.BillService/UpdateBill": models.PermissionEditBills,
+	"/bills.v1.BillService/DeleteBill": models.PermissionDeleteBills,
+	"/bills.v1.BillService/WatchBills": models.PermissionView,
+
+	"/bills.v1.BillItemAssignmentService/CreateBillItemAssignment":  models.PermissionEditBills,
+	"/bills.v1.BillItemAssignmentService/GetBillItemAssignment":     models.PermissionView,
+	"/bills.v1.BillItemAssignmentService/ListBillItemAssignments":   models.PermissionView,
+	"/bills.v1.BillItemAssignmentService/UpdateBillItemAssignment":  models.PermissionEditBills,
+	"/bills.v1.BillItemAssignmentService/DeleteBillItemAssignment":  models.PermissionEditBills,
+	"/bills.v1.BillItemAssignmentService/DeleteBillItemAssignments": models.PermissionEditBills,
+
+	"/bills.v1.IssuerService/CreateIssuer": models.PermissionManageParties,
+	"/bills.v1.IssuerService/GetIssuer":    models.PermissionView,
+	"/bills.v1.IssuerService/ListIssuers":  models.PermissionView,
+	"/bills.v1.IssuerService/UpdateIssuer": models.PermissionManageParties,
+	"/bills.v1.IssuerService/DeleteIssuer": models.PermissionDeleteParties,
+
+	"/bills.v1.ReceiverService/CreateReceiver": models.PermissionManageParties,
+	"/bills.v1.ReceiverService/GetReceiver":    models.PermissionView,
+	"/bills.v1.ReceiverService/ListReceivers":  models.PermissionView,
+	"/bills.v1.ReceiverService/UpdateReceiver": models.PermissionManageParties,
+	"/bills.v1.ReceiverService/DeleteReceiver": models.PermissionDeleteParties,
+
+	"/bills.v1.BillItemService/CreateBillItem": models.PermissionManageCatalog,
+	"/bills.v1.BillItemService/GetBillItem":    models.PermissionView,
+	"/bills.v1.BillItemService/ListBillItems":  models.PermissionView,
+	"/bills.v1.BillItemService/UpdateBillItem": models.PermissionManageCatalog,
+	"/bills.v1.BillItemService/DeleteBillItem": models.PermissionDeleteCatalog,
+
+	// Lets tools such as grpcurl discover the services
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      models.PermissionView,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": models.PermissionView,
+}
+
+// AuthInterceptor signs every RPC in with the API token of its metadata, the
+// way auth.Tokens signs in HTTP requests. Calls work in the workspace of the
+// token as its user, with the role the user has there, and are refused unless
+// both the role and the scope of the token allow the method
+type AuthInterceptor struct {
+	tokens     *auth.Tokens
+	workspaces repository.WorkspaceRepository
+}
+
+// NewAuthInterceptor creates a new AuthInterceptor instance
+func NewAuthInterceptor(tokens *auth.Tokens, workspaces repository.WorkspaceRepository) *AuthInterceptor {
+	return &AuthInterceptor{
+		tokens:     tokens,
+		workspaces: workspaces,
+	}
+}
+
+// Unary authorizes unary RPCs
+func (i *AuthInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := i.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream authorizes streaming RPCs
+func (i *AuthInterceptor) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := i.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &scopedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize returns ctx working in the workspace of the token of its metadata
+// and made by its user, once they may call the method there
+func (i *AuthInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	plain, ok := bearerToken(md)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, AuthorizationMetadataKey+" metadata with a Bearer API token is required")
+	}
+
+	token, user, err := i.tokens.Authenticate(ctx, plain)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if values := md.Get(WorkspaceMetadataKey); len(values) > 0 {
+		id, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil || id <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid "+WorkspaceMetadataKey)
+		}
+		if id != token.WorkspaceID {
+			return nil, status.Error(codes.PermissionDenied, "this token works in another workspace")
+		}
+	}
+
+	user.Role, err = i.workspaces.GetRole(ctx, token.WorkspaceID, user.ID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, status.Error(codes.PermissionDenied, "you are not a member of the workspace of this token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	permission, ok := Permissions[method]
+	if !ok || !token.Allows(permission) || !user.Can(permission) {
+		return nil, status.Error(codes.PermissionDenied, "you do not have permission to do this")
+	}
+
+	ctx = tenant.WithWorkspace(ctx, token.WorkspaceID)
+	return tenant.WithActor(ctx, user.ID), nil
+}
+
+// Unguarded returns the methods registered on server that have no entry in
+// Permissions, sorted
+func Unguarded(server *grpc.Server) []string {
+	var missing []string
+	for name, service := range server.GetServiceInfo() {
+		for _, method := range service.Methods {
+			key := "/" + name + "/" + method.Name
+			if _, ok := Permissions[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// bearerToken returns the token of the authorization metadata
+func bearerToken(md metadata.MD) (string, bool) {
+	values := md.Get(AuthorizationMetadataKey)
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// scopedStream replaces the context of a server stream
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *scopedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,175 @@
+package rpc
+
+import (
+	"bills/internal/repository"
+	pb "bills/internal/rpc/billsv1"
+	"context"
+)
+
+// BillItemAssignmentServer implements the BillItemAssignmentService. Every
+// change to a line also refreshes the totals of its bill, in the same
+// transaction
+type BillItemAssignmentServer struct {
+	pb.UnimplementedBillItemAssignmentServiceServer
+	uow          repository.UnitOfWork
+	repo         repository.BillItemAssignmentRepository
+	billRepo     repository.BillRepository
+	billItemRepo repository.BillItemRepository
+}
+
+// NewBillItemAssignmentServer creates a new BillItemAssignmentServer instance
+func NewBillItemAssignmentServer(
+	uow repository.UnitOfWork,
+	repo repository.BillItemAssignmentRepository,
+	billRepo repository.BillRepository,
+	billItemRepo repository.BillItemRepository,
+) *BillItemAssignmentServer {
+	return &BillItemAssignmentServer{
+		uow:          uow,
+		repo:         repo,
+		billRepo:     billRepo,
+		billItemRepo: billItemRepo,
+	}
+}
+
+// CreateBillItemAssignment adds a line to a bill
+func (s *BillItemAssignmentServer) CreateBillItemAssignment(ctx context.Context, req *pb.CreateBillItemAssignmentRequest) (*pb.BillItemAssignment, error) {
+	in := req.GetAssignment()
+	if in == nil {
+		return nil, missing("assignment")
+	}
+
+	if err := s.checkBill(ctx, in.BillId); err != nil {
+		return nil, err
+	}
+
+	assignment, err := newAssignment(in.BillId, fromAssignment(in))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBillItem(ctx, s.billItemRepo, assignment.ItemID); err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.Create(ctx, assignment); err != nil {
+			return err
+		}
+		return recalculateBill(ctx, tx.Bills, assignment.BillID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAssignment(assignment), nil
+}
+
+// GetBillItemAssignment returns a single bill line
+func (s *BillItemAssignmentServer) GetBillItemAssignment(ctx context.Context, req *pb.GetBillItemAssignmentRequest) (*pb.BillItemAssignment, error) {
+	assignment, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toAssignment(assignment), nil
+}
+
+// ListBillItemAssignments returns the lines of a bill
+func (s *BillItemAssignmentServer) ListBillItemAssignments(ctx context.Context, req *pb.ListBillItemAssignmentsRequest) (*pb.ListBillItemAssignmentsResponse, error) {
+	if err := s.checkBill(ctx, req.GetBillId()); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repo.GetByBillID(ctx, req.GetBillId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListBillItemAssignmentsResponse{}
+	for _, assignment := range assignments {
+		resp.Assignments = append(resp.Assignments, toAssignment(assignment))
+	}
+	return resp, nil
+}
+
+// UpdateBillItemAssignment changes the quantity, price and currency of a line
+func (s *BillItemAssignmentServer) UpdateBillItemAssignment(ctx context.Context, req *pb.UpdateBillItemAssignmentRequest) (*pb.BillItemAssignment, error) {
+	in := req.GetAssignment()
+	if in == nil {
+		return nil, missing("assignment")
+	}
+
+	assignment, err := s.repo.GetByID(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The bill and catalog item of a line are fixed, only its pricing can
+	// change
+	changes := fromAssignment(in)
+	changes.ItemID = assignment.ItemID
+	updated, err := newAssignment(assignment.BillID, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment.Quantity = updated.Quantity
+	assignment.Price = updated.Price
+	assignment.Currency = updated.Currency
+	assignment.ExchangeRate = updated.ExchangeRate
+	assignment.CalculateAmounts()
+
+	err = s.uow.Do(ctx, func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.Update(ctx, assignment); err != nil {
+			return err
+		}
+		return recalculateBill(ctx, tx.Bills, assignment.BillID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAssignment(assignment), nil
+}
+
+// DeleteBillItemAssignment removes a line from its bill
+func (s *BillItemAssignmentServer) DeleteBillItemAssignment(ctx context.Context, req *pb.DeleteBillItemAssignmentRequest) (*pb.DeleteBillItemAssignmentResponse, error) {
+	assignment, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.Delete(ctx, assignment.ID); err != nil {
+			return err
+		}
+		return recalculateBill(ctx, tx.Bills, assignment.BillID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DeleteBillItemAssignmentResponse{}, nil
+}
+
+// DeleteBillItemAssignments removes every line of a bill
+func (s *BillItemAssignmentServer) DeleteBillItemAssignments(ctx context.Context, req *pb.DeleteBillItemAssignmentsRequest) (*pb.DeleteBillItemAssignmentsResponse, error) {
+	if err := s.checkBill(ctx, req.GetBillId()); err != nil {
+		return nil, err
+	}
+
+	err := s.uow.Do(ctx, func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.DeleteByBillID(ctx, req.GetBillId()); err != nil {
+			return err
+		}
+		return recalculateBill(ctx, tx.Bills, req.GetBillId())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DeleteBillItemAssignmentsResponse{}, nil
+}
+
+// checkBill makes sure the bill a line belongs to exists
+func (s *BillItemAssignmentServer) checkBill(ctx context.Context, id int64) error {
+	if _, err := s.billRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	pb "bills/internal/rpc/billsv1"
+	"context"
+)
+
+// BillItemServer implements the BillItemService
+type BillItemServer struct {
+	pb.UnimplementedBillItemServiceServer
+	repo repository.BillItemRepository
+}
+
+// NewBillItemServer creates a new BillItemServer instance
+func NewBillItemServer(repo repository.BillItemRepository) *BillItemServer {
+	return &BillItemServer{repo: repo}
+}
+
+// CreateBillItem stores a new catalog item, defaulting its currency
+func (s *BillItemServer) CreateBillItem(ctx context.Context, req *pb.CreateBillItemRequest) (*pb.BillItem, error) {
+	in := req.GetBillItem()
+	if in == nil {
+		return nil, missing("bill_item")
+	}
+
+	currency := in.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency()
+	}
+
+	item := models.NewBillItem(in.Name, in.Price, currency)
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	return toBillItem(item), nil
+}
+
+// GetBillItem returns a single catalog item
+func (s *BillItemServer) GetBillItem(ctx context.Context, req *pb.GetBillItemRequest) (*pb.BillItem, error) {
+	item, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toBillItem(item), nil
+}
+
+// ListBillItems returns the whole catalog
+func (s *BillItemServer) ListBillItems(ctx context.Context, req *pb.ListBillItemsRequest) (*pb.ListBillItemsResponse, error) {
+	items, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListBillItemsResponse{}
+	for _, item := range items {
+		resp.BillItems = append(resp.BillItems, toBillItem(item))
+	}
+	return resp, nil
+}
+
+// UpdateBillItem replaces the name, price and currency of a catalog item
+func (s *BillItemServer) UpdateBillItem(ctx context.Context, req *pb.UpdateBillItemRequest) (*pb.BillItem, error) {
+	in := req.GetBillItem()
+	if in == nil {
+		return nil, missing("bill_item")
+	}
+
+	item, err := s.repo.GetByID(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Name = in.Name
+	item.Price = in.Price
+	item.Currency = in.Currency
+	if item.Currency == "" {
+		item.Currency = models.DefaultCurrency()
+	}
+
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, item); err != nil {
+		return nil, err
+	}
+	return toBillItem(item), nil
+}
+
+// DeleteBillItem moves a catalog item to the trash
+func (s *BillItemServer) DeleteBillItem(ctx context.Context, req *pb.DeleteBillItemRequest) (*pb.DeleteBillItemResponse, error) {
+	item, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Delete(ctx, item.ID); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteBillItemResponse{}, nil
+}
@@ -0,0 +1,240 @@
+package rpc
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	pb "bills/internal/rpc/billsv1"
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BillServer implements the BillService
+type BillServer struct {
+	pb.UnimplementedBillServiceServer
+	watcher      *BillWatcher
+	receiverRepo repository.ReceiverRepository
+	issuerRepo   repository.IssuerRepository
+	billItemRepo repository.BillItemRepository
+}
+
+// NewBillServer creates a new BillServer instance. Bills are read and written
+// through the watcher so WatchBills sees every change
+func NewBillServer(
+	watcher *BillWatcher,
+	receiverRepo repository.ReceiverRepository,
+	issuerRepo repository.IssuerRepository,
+	billItemRepo repository.BillItemRepository,
+) *BillServer {
+	return &BillServer{
+		watcher:      watcher,
+		receiverRepo: receiverRepo,
+		issuerRepo:   issuerRepo,
+		billItemRepo: billItemRepo,
+	}
+}
+
+// CreateBill stores a new bill together with its items
+func (s *BillServer) CreateBill(ctx context.Context, req *pb.CreateBillRequest) (*pb.Bill, error) {
+	in := req.GetBill()
+	if in == nil {
+		return nil, missing("bill")
+	}
+
+	bill := models.NewBill(fromTimestamp(in.DueDate), in.IssuerId, in.ReceiverId)
+	bill.Paid = in.Paid
+	for i, item := range in.Items {
+		assignment, err := newAssignment(0, fromAssignment(item))
+		if err != nil {
+			return nil, fmt.Errorf("items[%d]: %w", i, err)
+		}
+		bill.Items = append(bill.Items, assignment)
+	}
+
+	bill.ResolveCurrency()
+	bill.CalculateTotals()
+
+	if err := s.validate(ctx, bill); err != nil {
+		return nil, err
+	}
+
+	if err := s.watcher.Create(ctx, bill); err != nil {
+		return nil, err
+	}
+	return toBill(bill), nil
+}
+
+// GetBill returns a single bill with its items
+func (s *BillServer) GetBill(ctx context.Context, req *pb.GetBillRequest) (*pb.Bill, error) {
+	bill, err := s.watcher.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toBill(bill), nil
+}
+
+// ListBills returns all bills with their items
+func (s *BillServer) ListBills(ctx context.Context, req *pb.ListBillsRequest) (*pb.ListBillsResponse, error) {
+	bills, err := s.watcher.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListBillsResponse{}
+	for _, bill := range bills {
+		resp.Bills = append(resp.Bills, toBill(bill))
+	}
+	return resp, nil
+}
+
+// UpdateBill changes the due date, parties and paid status of a bill
+func (s *BillServer) UpdateBill(ctx context.Context, req *pb.UpdateBillRequest) (*pb.Bill, error) {
+	in := req.GetBill()
+	if in == nil {
+		return nil, missing("bill")
+	}
+
+	bill, err := s.watcher.GetByID(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	bill.DueDate = fromTimestamp(in.DueDate)
+	bill.IssuerID = in.IssuerId
+	bill.ReceiverID = in.ReceiverId
+	bill.Paid = in.Paid
+
+	if err := s.validate(ctx, bill); err != nil {
+		return nil, err
+	}
+
+	if err := s.watcher.Update(ctx, bill); err != nil {
+		return nil, err
+	}
+	return toBill(bill), nil
+}
+
+// DeleteBill moves a bill and its items to the trash
+func (s *BillServer) DeleteBill(ctx context.Context, req *pb.DeleteBillRequest) (*pb.DeleteBillResponse, error) {
+	bill, err := s.watcher.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.watcher.Delete(ctx, bill.ID); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteBillResponse{}, nil
+}
+
+// WatchBills streams bill changes until the client goes away
+func (s *BillServer) WatchBills(req *pb.WatchBillsRequest, stream pb.BillService_WatchBillsServer) error {
+	ctx := stream.Context()
+
+	// Subscribe before reading existing bills so nothing created in between
+	// is missed
+	events, cancel, err := s.watcher.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if req.GetIncludeExisting() {
+		bills, err := s.watcher.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, bill := range bills {
+			event := &pb.BillEvent{Type: pb.BillEvent_TYPE_CREATED, Bill: toBill(bill)}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher fell behind, reconnect to resume")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// validate checks the bill itself and that everything it references exists
+func (s *BillServer) validate(ctx context.Context, bill *models.Bill) error {
+	if err := bill.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.issuerRepo.GetByID(ctx, bill.IssuerID); err != nil {
+		return reference(err, "issuer_id", bill.IssuerID)
+	}
+
+	if _, err := s.receiverRepo.GetByID(ctx, bill.ReceiverID); err != nil {
+		return reference(err, "receiver_id", bill.ReceiverID)
+	}
+
+	for _, item := range bill.Items {
+		if err := checkBillItem(ctx, s.billItemRepo, item.ItemID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newAssignment builds an assignment from RPC input, defaulting the currency
+// and exchange rate the same way the bill form does
+func newAssignment(billID int64, in *models.BillItemAssignment) (*models.BillItemAssignment, error) {
+	currency := in.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency()
+	}
+	if !models.IsSupportedCurrency(currency) {
+		return nil, models.Invalid("currency", fmt.Sprintf("%q is not supported", currency))
+	}
+
+	assignment := models.NewBillItemAssignment(billID, in.ItemID, in.Quantity, in.Price, currency, in.ExchangeRate)
+	if err := assignment.Validate(); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// checkBillItem makes sure the catalog item referenced by an assignment exists
+func checkBillItem(ctx context.Context, repo repository.BillItemRepository, itemID int64) error {
+	if _, err := repo.GetByID(ctx, itemID); err != nil {
+		return reference(err, "item_id", itemID)
+	}
+	return nil
+}
+
+// reference reports a referenced record that does not exist as invalid input
+func reference(err error, field string, id int64) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return models.Invalid(field, fmt.Sprintf("%d does not exist", id))
+	}
+	return err
+}
+
+// recalculateBill refreshes the stored currency and totals of a bill after its
+// items changed
+func recalculateBill(ctx context.Context, repo repository.BillRepository, id int64) error {
+	bill, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	bill.ResolveCurrency()
+	bill.CalculateTotals()
+	return repo.Update(ctx, bill)
+}
@@ -0,0 +1,2661 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: bills/v1/bills.proto
+
+package billsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type BillEvent_Type int32
+
+const (
+	BillEvent_TYPE_UNSPECIFIED BillEvent_Type = 0
+	BillEvent_TYPE_CREATED     BillEvent_Type = 1
+	BillEvent_TYPE_UPDATED     BillEvent_Type = 2
+	BillEvent_TYPE_DELETED     BillEvent_Type = 3
+)
+
+// Enum value maps for BillEvent_Type.
+var (
+	BillEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_UPDATED",
+		3: "TYPE_DELETED",
+	}
+	BillEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CREATED":     1,
+		"TYPE_UPDATED":     2,
+		"TYPE_DELETED":     3,
+	}
+)
+
+func (x BillEvent_Type) Enum() *BillEvent_Type {
+	p := new(BillEvent_Type)
+	*p = x
+	return p
+}
+
+func (x BillEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BillEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_bills_v1_bills_proto_enumTypes[0].Descriptor()
+}
+
+func (BillEvent_Type) Type() protoreflect.EnumType {
+	return &file_bills_v1_bills_proto_enumTypes[0]
+}
+
+func (x BillEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BillEvent_Type.Descriptor instead.
+func (BillEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{13, 0}
+}
+
+type Bill struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	IssuerId      int64                  `protobuf:"varint,2,opt,name=issuer_id,json=issuerId,proto3" json:"issuer_id,omitempty"`
+	ReceiverId    int64                  `protobuf:"varint,3,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"`
+	DueDate       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	Currency      string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	OriginalTotal float64                `protobuf:"fixed64,6,opt,name=original_total,json=originalTotal,proto3" json:"original_total,omitempty"`
+	EurTotal      float64                `protobuf:"fixed64,7,opt,name=eur_total,json=eurTotal,proto3" json:"eur_total,omitempty"`
+	Paid          bool                   `protobuf:"varint,8,opt,name=paid,proto3" json:"paid,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Items         []*BillItemAssignment  `protobuf:"bytes,11,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bill) Reset() {
+	*x = Bill{}
+	mi := &file_bills_v1_bills_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bill) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bill) ProtoMessage() {}
+
+func (x *Bill) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bill.ProtoReflect.Descriptor instead.
+func (*Bill) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Bill) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Bill) GetIssuerId() int64 {
+	if x != nil {
+		return x.IssuerId
+	}
+	return 0
+}
+
+func (x *Bill) GetReceiverId() int64 {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return 0
+}
+
+func (x *Bill) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+func (x *Bill) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Bill) GetOriginalTotal() float64 {
+	if x != nil {
+		return x.OriginalTotal
+	}
+	return 0
+}
+
+func (x *Bill) GetEurTotal() float64 {
+	if x != nil {
+		return x.EurTotal
+	}
+	return 0
+}
+
+func (x *Bill) GetPaid() bool {
+	if x != nil {
+		return x.Paid
+	}
+	return false
+}
+
+func (x *Bill) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Bill) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Bill) GetItems() []*BillItemAssignment {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BillItemAssignment struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	BillId         int64                  `protobuf:"varint,2,opt,name=bill_id,json=billId,proto3" json:"bill_id,omitempty"`
+	ItemId         int64                  `protobuf:"varint,3,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Quantity       int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price          float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	Currency       string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	ExchangeRate   float64                `protobuf:"fixed64,7,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	OriginalAmount float64                `protobuf:"fixed64,8,opt,name=original_amount,json=originalAmount,proto3" json:"original_amount,omitempty"`
+	EurAmount      float64                `protobuf:"fixed64,9,opt,name=eur_amount,json=eurAmount,proto3" json:"eur_amount,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BillItemAssignment) Reset() {
+	*x = BillItemAssignment{}
+	mi := &file_bills_v1_bills_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BillItemAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BillItemAssignment) ProtoMessage() {}
+
+func (x *BillItemAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BillItemAssignment.ProtoReflect.Descriptor instead.
+func (*BillItemAssignment) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BillItemAssignment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetBillId() int64 {
+	if x != nil {
+		return x.BillId
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetItemId() int64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *BillItemAssignment) GetExchangeRate() float64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetOriginalAmount() float64 {
+	if x != nil {
+		return x.OriginalAmount
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetEurAmount() float64 {
+	if x != nil {
+		return x.EurAmount
+	}
+	return 0
+}
+
+func (x *BillItemAssignment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *BillItemAssignment) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Issuer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	VatNumber     string                 `protobuf:"bytes,3,opt,name=vat_number,json=vatNumber,proto3" json:"vat_number,omitempty"`
+	Street        string                 `protobuf:"bytes,4,opt,name=street,proto3" json:"street,omitempty"`
+	City          string                 `protobuf:"bytes,5,opt,name=city,proto3" json:"city,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	ZipCode       string                 `protobuf:"bytes,7,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Country       string                 `protobuf:"bytes,8,opt,name=country,proto3" json:"country,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Issuer) Reset() {
+	*x = Issuer{}
+	mi := &file_bills_v1_bills_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Issuer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Issuer) ProtoMessage() {}
+
+func (x *Issuer) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Issuer.ProtoReflect.Descriptor instead.
+func (*Issuer) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Issuer) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Issuer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Issuer) GetVatNumber() string {
+	if x != nil {
+		return x.VatNumber
+	}
+	return ""
+}
+
+func (x *Issuer) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *Issuer) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Issuer) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Issuer) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *Issuer) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Issuer) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Issuer) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Receiver struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	VatNumber     string                 `protobuf:"bytes,3,opt,name=vat_number,json=vatNumber,proto3" json:"vat_number,omitempty"`
+	Street        string                 `protobuf:"bytes,4,opt,name=street,proto3" json:"street,omitempty"`
+	City          string                 `protobuf:"bytes,5,opt,name=city,proto3" json:"city,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	ZipCode       string                 `protobuf:"bytes,7,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	Country       string                 `protobuf:"bytes,8,opt,name=country,proto3" json:"country,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Receiver) Reset() {
+	*x = Receiver{}
+	mi := &file_bills_v1_bills_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Receiver) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Receiver) ProtoMessage() {}
+
+func (x *Receiver) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Receiver.ProtoReflect.Descriptor instead.
+func (*Receiver) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Receiver) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Receiver) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Receiver) GetVatNumber() string {
+	if x != nil {
+		return x.VatNumber
+	}
+	return ""
+}
+
+func (x *Receiver) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *Receiver) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Receiver) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Receiver) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
+func (x *Receiver) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Receiver) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Receiver) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type BillItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BillItem) Reset() {
+	*x = BillItem{}
+	mi := &file_bills_v1_bills_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BillItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BillItem) ProtoMessage() {}
+
+func (x *BillItem) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BillItem.ProtoReflect.Descriptor instead.
+func (*BillItem) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BillItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *BillItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BillItem) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *BillItem) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *BillItem) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *BillItem) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CreateBillRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only issuer_id, receiver_id, due_date, paid and items are read, the
+	// currency and totals are calculated from the items
+	Bill          *Bill `protobuf:"bytes,1,opt,name=bill,proto3" json:"bill,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBillRequest) Reset() {
+	*x = CreateBillRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBillRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBillRequest) ProtoMessage() {}
+
+func (x *CreateBillRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBillRequest.ProtoReflect.Descriptor instead.
+func (*CreateBillRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateBillRequest) GetBill() *Bill {
+	if x != nil {
+		return x.Bill
+	}
+	return nil
+}
+
+type GetBillRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBillRequest) Reset() {
+	*x = GetBillRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBillRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBillRequest) ProtoMessage() {}
+
+func (x *GetBillRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBillRequest.ProtoReflect.Descriptor instead.
+func (*GetBillRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetBillRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListBillsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillsRequest) Reset() {
+	*x = ListBillsRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillsRequest) ProtoMessage() {}
+
+func (x *ListBillsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillsRequest.ProtoReflect.Descriptor instead.
+func (*ListBillsRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{7}
+}
+
+type ListBillsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bills         []*Bill                `protobuf:"bytes,1,rep,name=bills,proto3" json:"bills,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillsResponse) Reset() {
+	*x = ListBillsResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillsResponse) ProtoMessage() {}
+
+func (x *ListBillsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillsResponse.ProtoReflect.Descriptor instead.
+func (*ListBillsResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListBillsResponse) GetBills() []*Bill {
+	if x != nil {
+		return x.Bills
+	}
+	return nil
+}
+
+type UpdateBillRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Changes the due date, parties and paid status of a bill. Items are
+	// managed through BillItemAssignmentService
+	Bill          *Bill `protobuf:"bytes,1,opt,name=bill,proto3" json:"bill,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBillRequest) Reset() {
+	*x = UpdateBillRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBillRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBillRequest) ProtoMessage() {}
+
+func (x *UpdateBillRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBillRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBillRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateBillRequest) GetBill() *Bill {
+	if x != nil {
+		return x.Bill
+	}
+	return nil
+}
+
+type DeleteBillRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillRequest) Reset() {
+	*x = DeleteBillRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillRequest) ProtoMessage() {}
+
+func (x *DeleteBillRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBillRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteBillRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteBillResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillResponse) Reset() {
+	*x = DeleteBillResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillResponse) ProtoMessage() {}
+
+func (x *DeleteBillResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBillResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{11}
+}
+
+type WatchBillsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Send every existing bill as a CREATED event before streaming changes
+	IncludeExisting bool `protobuf:"varint,1,opt,name=include_existing,json=includeExisting,proto3" json:"include_existing,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchBillsRequest) Reset() {
+	*x = WatchBillsRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchBillsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBillsRequest) ProtoMessage() {}
+
+func (x *WatchBillsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBillsRequest.ProtoReflect.Descriptor instead.
+func (*WatchBillsRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchBillsRequest) GetIncludeExisting() bool {
+	if x != nil {
+		return x.IncludeExisting
+	}
+	return false
+}
+
+type BillEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  BillEvent_Type         `protobuf:"varint,1,opt,name=type,proto3,enum=bills.v1.BillEvent_Type" json:"type,omitempty"`
+	// The bill after the change. Deleted bills carry their last known state
+	Bill          *Bill `protobuf:"bytes,2,opt,name=bill,proto3" json:"bill,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BillEvent) Reset() {
+	*x = BillEvent{}
+	mi := &file_bills_v1_bills_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BillEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BillEvent) ProtoMessage() {}
+
+func (x *BillEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BillEvent.ProtoReflect.Descriptor instead.
+func (*BillEvent) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BillEvent) GetType() BillEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return BillEvent_TYPE_UNSPECIFIED
+}
+
+func (x *BillEvent) GetBill() *Bill {
+	if x != nil {
+		return x.Bill
+	}
+	return nil
+}
+
+type CreateBillItemAssignmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Assignment    *BillItemAssignment    `protobuf:"bytes,1,opt,name=assignment,proto3" json:"assignment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBillItemAssignmentRequest) Reset() {
+	*x = CreateBillItemAssignmentRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBillItemAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBillItemAssignmentRequest) ProtoMessage() {}
+
+func (x *CreateBillItemAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBillItemAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*CreateBillItemAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CreateBillItemAssignmentRequest) GetAssignment() *BillItemAssignment {
+	if x != nil {
+		return x.Assignment
+	}
+	return nil
+}
+
+type GetBillItemAssignmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBillItemAssignmentRequest) Reset() {
+	*x = GetBillItemAssignmentRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBillItemAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBillItemAssignmentRequest) ProtoMessage() {}
+
+func (x *GetBillItemAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBillItemAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*GetBillItemAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetBillItemAssignmentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListBillItemAssignmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BillId        int64                  `protobuf:"varint,1,opt,name=bill_id,json=billId,proto3" json:"bill_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillItemAssignmentsRequest) Reset() {
+	*x = ListBillItemAssignmentsRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillItemAssignmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillItemAssignmentsRequest) ProtoMessage() {}
+
+func (x *ListBillItemAssignmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillItemAssignmentsRequest.ProtoReflect.Descriptor instead.
+func (*ListBillItemAssignmentsRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListBillItemAssignmentsRequest) GetBillId() int64 {
+	if x != nil {
+		return x.BillId
+	}
+	return 0
+}
+
+type ListBillItemAssignmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Assignments   []*BillItemAssignment  `protobuf:"bytes,1,rep,name=assignments,proto3" json:"assignments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillItemAssignmentsResponse) Reset() {
+	*x = ListBillItemAssignmentsResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillItemAssignmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillItemAssignmentsResponse) ProtoMessage() {}
+
+func (x *ListBillItemAssignmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillItemAssignmentsResponse.ProtoReflect.Descriptor instead.
+func (*ListBillItemAssignmentsResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListBillItemAssignmentsResponse) GetAssignments() []*BillItemAssignment {
+	if x != nil {
+		return x.Assignments
+	}
+	return nil
+}
+
+type UpdateBillItemAssignmentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Changes the quantity, price and currency of a line, its bill and catalog
+	// item are fixed
+	Assignment    *BillItemAssignment `protobuf:"bytes,1,opt,name=assignment,proto3" json:"assignment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBillItemAssignmentRequest) Reset() {
+	*x = UpdateBillItemAssignmentRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBillItemAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBillItemAssignmentRequest) ProtoMessage() {}
+
+func (x *UpdateBillItemAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBillItemAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBillItemAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpdateBillItemAssignmentRequest) GetAssignment() *BillItemAssignment {
+	if x != nil {
+		return x.Assignment
+	}
+	return nil
+}
+
+type DeleteBillItemAssignmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillItemAssignmentRequest) Reset() {
+	*x = DeleteBillItemAssignmentRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillItemAssignmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillItemAssignmentRequest) ProtoMessage() {}
+
+func (x *DeleteBillItemAssignmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillItemAssignmentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBillItemAssignmentRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteBillItemAssignmentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteBillItemAssignmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillItemAssignmentResponse) Reset() {
+	*x = DeleteBillItemAssignmentResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillItemAssignmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillItemAssignmentResponse) ProtoMessage() {}
+
+func (x *DeleteBillItemAssignmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillItemAssignmentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBillItemAssignmentResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{20}
+}
+
+type DeleteBillItemAssignmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BillId        int64                  `protobuf:"varint,1,opt,name=bill_id,json=billId,proto3" json:"bill_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillItemAssignmentsRequest) Reset() {
+	*x = DeleteBillItemAssignmentsRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillItemAssignmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillItemAssignmentsRequest) ProtoMessage() {}
+
+func (x *DeleteBillItemAssignmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillItemAssignmentsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBillItemAssignmentsRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteBillItemAssignmentsRequest) GetBillId() int64 {
+	if x != nil {
+		return x.BillId
+	}
+	return 0
+}
+
+type DeleteBillItemAssignmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillItemAssignmentsResponse) Reset() {
+	*x = DeleteBillItemAssignmentsResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillItemAssignmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillItemAssignmentsResponse) ProtoMessage() {}
+
+func (x *DeleteBillItemAssignmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillItemAssignmentsResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBillItemAssignmentsResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{22}
+}
+
+type CreateIssuerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issuer        *Issuer                `protobuf:"bytes,1,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateIssuerRequest) Reset() {
+	*x = CreateIssuerRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateIssuerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateIssuerRequest) ProtoMessage() {}
+
+func (x *CreateIssuerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateIssuerRequest.ProtoReflect.Descriptor instead.
+func (*CreateIssuerRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateIssuerRequest) GetIssuer() *Issuer {
+	if x != nil {
+		return x.Issuer
+	}
+	return nil
+}
+
+type GetIssuerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIssuerRequest) Reset() {
+	*x = GetIssuerRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIssuerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIssuerRequest) ProtoMessage() {}
+
+func (x *GetIssuerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIssuerRequest.ProtoReflect.Descriptor instead.
+func (*GetIssuerRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetIssuerRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListIssuersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuersRequest) Reset() {
+	*x = ListIssuersRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuersRequest) ProtoMessage() {}
+
+func (x *ListIssuersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuersRequest.ProtoReflect.Descriptor instead.
+func (*ListIssuersRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{25}
+}
+
+type ListIssuersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issuers       []*Issuer              `protobuf:"bytes,1,rep,name=issuers,proto3" json:"issuers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuersResponse) Reset() {
+	*x = ListIssuersResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuersResponse) ProtoMessage() {}
+
+func (x *ListIssuersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuersResponse.ProtoReflect.Descriptor instead.
+func (*ListIssuersResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListIssuersResponse) GetIssuers() []*Issuer {
+	if x != nil {
+		return x.Issuers
+	}
+	return nil
+}
+
+type UpdateIssuerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issuer        *Issuer                `protobuf:"bytes,1,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateIssuerRequest) Reset() {
+	*x = UpdateIssuerRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateIssuerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateIssuerRequest) ProtoMessage() {}
+
+func (x *UpdateIssuerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateIssuerRequest.ProtoReflect.Descriptor instead.
+func (*UpdateIssuerRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UpdateIssuerRequest) GetIssuer() *Issuer {
+	if x != nil {
+		return x.Issuer
+	}
+	return nil
+}
+
+type DeleteIssuerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIssuerRequest) Reset() {
+	*x = DeleteIssuerRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIssuerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIssuerRequest) ProtoMessage() {}
+
+func (x *DeleteIssuerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIssuerRequest.ProtoReflect.Descriptor instead.
+func (*DeleteIssuerRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DeleteIssuerRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteIssuerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIssuerResponse) Reset() {
+	*x = DeleteIssuerResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIssuerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIssuerResponse) ProtoMessage() {}
+
+func (x *DeleteIssuerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIssuerResponse.ProtoReflect.Descriptor instead.
+func (*DeleteIssuerResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{29}
+}
+
+type CreateReceiverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Receiver      *Receiver              `protobuf:"bytes,1,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReceiverRequest) Reset() {
+	*x = CreateReceiverRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReceiverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReceiverRequest) ProtoMessage() {}
+
+func (x *CreateReceiverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReceiverRequest.ProtoReflect.Descriptor instead.
+func (*CreateReceiverRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CreateReceiverRequest) GetReceiver() *Receiver {
+	if x != nil {
+		return x.Receiver
+	}
+	return nil
+}
+
+type GetReceiverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReceiverRequest) Reset() {
+	*x = GetReceiverRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReceiverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReceiverRequest) ProtoMessage() {}
+
+func (x *GetReceiverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReceiverRequest.ProtoReflect.Descriptor instead.
+func (*GetReceiverRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetReceiverRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListReceiversRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReceiversRequest) Reset() {
+	*x = ListReceiversRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReceiversRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReceiversRequest) ProtoMessage() {}
+
+func (x *ListReceiversRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReceiversRequest.ProtoReflect.Descriptor instead.
+func (*ListReceiversRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{32}
+}
+
+type ListReceiversResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Receivers     []*Receiver            `protobuf:"bytes,1,rep,name=receivers,proto3" json:"receivers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReceiversResponse) Reset() {
+	*x = ListReceiversResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReceiversResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReceiversResponse) ProtoMessage() {}
+
+func (x *ListReceiversResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReceiversResponse.ProtoReflect.Descriptor instead.
+func (*ListReceiversResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListReceiversResponse) GetReceivers() []*Receiver {
+	if x != nil {
+		return x.Receivers
+	}
+	return nil
+}
+
+type UpdateReceiverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Receiver      *Receiver              `protobuf:"bytes,1,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateReceiverRequest) Reset() {
+	*x = UpdateReceiverRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateReceiverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReceiverRequest) ProtoMessage() {}
+
+func (x *UpdateReceiverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReceiverRequest.ProtoReflect.Descriptor instead.
+func (*UpdateReceiverRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UpdateReceiverRequest) GetReceiver() *Receiver {
+	if x != nil {
+		return x.Receiver
+	}
+	return nil
+}
+
+type DeleteReceiverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteReceiverRequest) Reset() {
+	*x = DeleteReceiverRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteReceiverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReceiverRequest) ProtoMessage() {}
+
+func (x *DeleteReceiverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReceiverRequest.ProtoReflect.Descriptor instead.
+func (*DeleteReceiverRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DeleteReceiverRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteReceiverResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteReceiverResponse) Reset() {
+	*x = DeleteReceiverResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteReceiverResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReceiverResponse) ProtoMessage() {}
+
+func (x *DeleteReceiverResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReceiverResponse.ProtoReflect.Descriptor instead.
+func (*DeleteReceiverResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{36}
+}
+
+type CreateBillItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BillItem      *BillItem              `protobuf:"bytes,1,opt,name=bill_item,json=billItem,proto3" json:"bill_item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBillItemRequest) Reset() {
+	*x = CreateBillItemRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBillItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBillItemRequest) ProtoMessage() {}
+
+func (x *CreateBillItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBillItemRequest.ProtoReflect.Descriptor instead.
+func (*CreateBillItemRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *CreateBillItemRequest) GetBillItem() *BillItem {
+	if x != nil {
+		return x.BillItem
+	}
+	return nil
+}
+
+type GetBillItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBillItemRequest) Reset() {
+	*x = GetBillItemRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBillItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBillItemRequest) ProtoMessage() {}
+
+func (x *GetBillItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBillItemRequest.ProtoReflect.Descriptor instead.
+func (*GetBillItemRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetBillItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListBillItemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillItemsRequest) Reset() {
+	*x = ListBillItemsRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillItemsRequest) ProtoMessage() {}
+
+func (x *ListBillItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillItemsRequest.ProtoReflect.Descriptor instead.
+func (*ListBillItemsRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{39}
+}
+
+type ListBillItemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BillItems     []*BillItem            `protobuf:"bytes,1,rep,name=bill_items,json=billItems,proto3" json:"bill_items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBillItemsResponse) Reset() {
+	*x = ListBillItemsResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBillItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBillItemsResponse) ProtoMessage() {}
+
+func (x *ListBillItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBillItemsResponse.ProtoReflect.Descriptor instead.
+func (*ListBillItemsResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListBillItemsResponse) GetBillItems() []*BillItem {
+	if x != nil {
+		return x.BillItems
+	}
+	return nil
+}
+
+type UpdateBillItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BillItem      *BillItem              `protobuf:"bytes,1,opt,name=bill_item,json=billItem,proto3" json:"bill_item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBillItemRequest) Reset() {
+	*x = UpdateBillItemRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBillItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBillItemRequest) ProtoMessage() {}
+
+func (x *UpdateBillItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBillItemRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBillItemRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *UpdateBillItemRequest) GetBillItem() *BillItem {
+	if x != nil {
+		return x.BillItem
+	}
+	return nil
+}
+
+type DeleteBillItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillItemRequest) Reset() {
+	*x = DeleteBillItemRequest{}
+	mi := &file_bills_v1_bills_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillItemRequest) ProtoMessage() {}
+
+func (x *DeleteBillItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillItemRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBillItemRequest) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DeleteBillItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteBillItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBillItemResponse) Reset() {
+	*x = DeleteBillItemResponse{}
+	mi := &file_bills_v1_bills_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBillItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBillItemResponse) ProtoMessage() {}
+
+func (x *DeleteBillItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bills_v1_bills_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBillItemResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBillItemResponse) Descriptor() ([]byte, []int) {
+	return file_bills_v1_bills_proto_rawDescGZIP(), []int{43}
+}
+
+var File_bills_v1_bills_proto protoreflect.FileDescriptor
+
+const file_bills_v1_bills_proto_rawDesc = "" +
+	"\n" +
+	"\x14bills/v1/bills.proto\x12\bbills.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa9\x03\n" +
+	"\x04Bill\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
+	"\tissuer_id\x18\x02 \x01(\x03R\bissuerId\x12\x1f\n" +
+	"\vreceiver_id\x18\x03 \x01(\x03R\n" +
+	"receiverId\x125\n" +
+	"\bdue_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\adueDate\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12%\n" +
+	"\x0eoriginal_total\x18\x06 \x01(\x01R\roriginalTotal\x12\x1b\n" +
+	"\teur_total\x18\a \x01(\x01R\beurTotal\x12\x12\n" +
+	"\x04paid\x18\b \x01(\bR\x04paid\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x122\n" +
+	"\x05items\x18\v \x03(\v2\x1c.bills.v1.BillItemAssignmentR\x05items\"\x87\x03\n" +
+	"\x12BillItemAssignment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\abill_id\x18\x02 \x01(\x03R\x06billId\x12\x17\n" +
+	"\aitem_id\x18\x03 \x01(\x03R\x06itemId\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12#\n" +
+	"\rexchange_rate\x18\a \x01(\x01R\fexchangeRate\x12'\n" +
+	"\x0foriginal_amount\x18\b \x01(\x01R\x0eoriginalAmount\x12\x1d\n" +
+	"\n" +
+	"eur_amount\x18\t \x01(\x01R\teurAmount\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xb8\x02\n" +
+	"\x06Issuer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"vat_number\x18\x03 \x01(\tR\tvatNumber\x12\x16\n" +
+	"\x06street\x18\x04 \x01(\tR\x06street\x12\x12\n" +
+	"\x04city\x18\x05 \x01(\tR\x04city\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12\x19\n" +
+	"\bzip_code\x18\a \x01(\tR\azipCode\x12\x18\n" +
+	"\acountry\x18\b \x01(\tR\acountry\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xba\x02\n" +
+	"\bReceiver\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"vat_number\x18\x03 \x01(\tR\tvatNumber\x12\x16\n" +
+	"\x06street\x18\x04 \x01(\tR\x06street\x12\x12\n" +
+	"\x04city\x18\x05 \x01(\tR\x04city\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12\x19\n" +
+	"\bzip_code\x18\a \x01(\tR\azipCode\x12\x18\n" +
+	"\acountry\x18\b \x01(\tR\acountry\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xd6\x01\n" +
+	"\bBillItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"7\n" +
+	"\x11CreateBillRequest\x12\"\n" +
+	"\x04bill\x18\x01 \x01(\v2\x0e.bills.v1.BillR\x04bill\" \n" +
+	"\x0eGetBillRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x12\n" +
+	"\x10ListBillsRequest\"9\n" +
+	"\x11ListBillsResponse\x12$\n" +
+	"\x05bills\x18\x01 \x03(\v2\x0e.bills.v1.BillR\x05bills\"7\n" +
+	"\x11UpdateBillRequest\x12\"\n" +
+	"\x04bill\x18\x01 \x01(\v2\x0e.bills.v1.BillR\x04bill\"#\n" +
+	"\x11DeleteBillRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x14\n" +
+	"\x12DeleteBillResponse\">\n" +
+	"\x11WatchBillsRequest\x12)\n" +
+	"\x10include_existing\x18\x01 \x01(\bR\x0fincludeExisting\"\xb1\x01\n" +
+	"\tBillEvent\x12,\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x18.bills.v1.BillEvent.TypeR\x04type\x12\"\n" +
+	"\x04bill\x18\x02 \x01(\v2\x0e.bills.v1.BillR\x04bill\"R\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x10\n" +
+	"\fTYPE_UPDATED\x10\x02\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x03\"_\n" +
+	"\x1fCreateBillItemAssignmentRequest\x12<\n" +
+	"\n" +
+	"assignment\x18\x01 \x01(\v2\x1c.bills.v1.BillItemAssignmentR\n" +
+	"assignment\".\n" +
+	"\x1cGetBillItemAssignmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"9\n" +
+	"\x1eListBillItemAssignmentsRequest\x12\x17\n" +
+	"\abill_id\x18\x01 \x01(\x03R\x06billId\"a\n" +
+	"\x1fListBillItemAssignmentsResponse\x12>\n" +
+	"\vassignments\x18\x01 \x03(\v2\x1c.bills.v1.BillItemAssignmentR\vassignments\"_\n" +
+	"\x1fUpdateBillItemAssignmentRequest\x12<\n" +
+	"\n" +
+	"assignment\x18\x01 \x01(\v2\x1c.bills.v1.BillItemAssignmentR\n" +
+	"assignment\"1\n" +
+	"\x1fDeleteBillItemAssignmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\"\n" +
+	" DeleteBillItemAssignmentResponse\";\n" +
+	" DeleteBillItemAssignmentsRequest\x12\x17\n" +
+	"\abill_id\x18\x01 \x01(\x03R\x06billId\"#\n" +
+	"!DeleteBillItemAssignmentsResponse\"?\n" +
+	"\x13CreateIssuerRequest\x12(\n" +
+	"\x06issuer\x18\x01 \x01(\v2\x10.bills.v1.IssuerR\x06issuer\"\"\n" +
+	"\x10GetIssuerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x14\n" +
+	"\x12ListIssuersRequest\"A\n" +
+	"\x13ListIssuersResponse\x12*\n" +
+	"\aissuers\x18\x01 \x03(\v2\x10.bills.v1.IssuerR\aissuers\"?\n" +
+	"\x13UpdateIssuerRequest\x12(\n" +
+	"\x06issuer\x18\x01 \x01(\v2\x10.bills.v1.IssuerR\x06issuer\"%\n" +
+	"\x13DeleteIssuerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x16\n" +
+	"\x14DeleteIssuerResponse\"G\n" +
+	"\x15CreateReceiverRequest\x12.\n" +
+	"\breceiver\x18\x01 \x01(\v2\x12.bills.v1.ReceiverR\breceiver\"$\n" +
+	"\x12GetReceiverRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x16\n" +
+	"\x14ListReceiversRequest\"I\n" +
+	"\x15ListReceiversResponse\x120\n" +
+	"\treceivers\x18\x01 \x03(\v2\x12.bills.v1.ReceiverR\treceivers\"G\n" +
+	"\x15UpdateReceiverRequest\x12.\n" +
+	"\breceiver\x18\x01 \x01(\v2\x12.bills.v1.ReceiverR\breceiver\"'\n" +
+	"\x15DeleteReceiverRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x18\n" +
+	"\x16DeleteReceiverResponse\"H\n" +
+	"\x15CreateBillItemRequest\x12/\n" +
+	"\tbill_item\x18\x01 \x01(\v2\x12.bills.v1.BillItemR\bbillItem\"$\n" +
+	"\x12GetBillItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x16\n" +
+	"\x14ListBillItemsRequest\"J\n" +
+	"\x15ListBillItemsResponse\x121\n" +
+	"\n" +
+	"bill_items\x18\x01 \x03(\v2\x12.bills.v1.BillItemR\tbillItems\"H\n" +
+	"\x15UpdateBillItemRequest\x12/\n" +
+	"\tbill_item\x18\x01 \x01(\v2\x12.bills.v1.BillItemR\bbillItem\"'\n" +
+	"\x15DeleteBillItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x18\n" +
+	"\x16DeleteBillItemResponse2\x89\x03\n" +
+	"\vBillService\x129\n" +
+	"\n" +
+	"CreateBill\x12\x1b.bills.v1.CreateBillRequest\x1a\x0e.bills.v1.Bill\x123\n" +
+	"\aGetBill\x12\x18.bills.v1.GetBillRequest\x1a\x0e.bills.v1.Bill\x12D\n" +
+	"\tListBills\x12\x1a.bills.v1.ListBillsRequest\x1a\x1b.bills.v1.ListBillsResponse\x129\n" +
+	"\n" +
+	"UpdateBill\x12\x1b.bills.v1.UpdateBillRequest\x1a\x0e.bills.v1.Bill\x12G\n" +
+	"\n" +
+	"DeleteBill\x12\x1b.bills.v1.DeleteBillRequest\x1a\x1c.bills.v1.DeleteBillResponse\x12@\n" +
+	"\n" +
+	"WatchBills\x12\x1b.bills.v1.WatchBillsRequest\x1a\x13.bills.v1.BillEvent0\x012\x9d\x05\n" +
+	"\x19BillItemAssignmentService\x12c\n" +
+	"\x18CreateBillItemAssignment\x12).bills.v1.CreateBillItemAssignmentRequest\x1a\x1c.bills.v1.BillItemAssignment\x12]\n" +
+	"\x15GetBillItemAssignment\x12&.bills.v1.GetBillItemAssignmentRequest\x1a\x1c.bills.v1.BillItemAssignment\x12n\n" +
+	"\x17ListBillItemAssignments\x12(.bills.v1.ListBillItemAssignmentsRequest\x1a).bills.v1.ListBillItemAssignmentsResponse\x12c\n" +
+	"\x18UpdateBillItemAssignment\x12).bills.v1.UpdateBillItemAssignmentRequest\x1a\x1c.bills.v1.BillItemAssignment\x12q\n" +
+	"\x18DeleteBillItemAssignment\x12).bills.v1.DeleteBillItemAssignmentRequest\x1a*.bills.v1.DeleteBillItemAssignmentResponse\x12t\n" +
+	"\x19DeleteBillItemAssignments\x12*.bills.v1.DeleteBillItemAssignmentsRequest\x1a+.bills.v1.DeleteBillItemAssignmentsResponse2\xe7\x02\n" +
+	"\rIssuerService\x12?\n" +
+	"\fCreateIssuer\x12\x1d.bills.v1.CreateIssuerRequest\x1a\x10.bills.v1.Issuer\x129\n" +
+	"\tGetIssuer\x12\x1a.bills.v1.GetIssuerRequest\x1a\x10.bills.v1.Issuer\x12J\n" +
+	"\vListIssuers\x12\x1c.bills.v1.ListIssuersRequest\x1a\x1d.bills.v1.ListIssuersResponse\x12?\n" +
+	"\fUpdateIssuer\x12\x1d.bills.v1.UpdateIssuerRequest\x1a\x10.bills.v1.Issuer\x12M\n" +
+	"\fDeleteIssuer\x12\x1d.bills.v1.DeleteIssuerRequest\x1a\x1e.bills.v1.DeleteIssuerResponse2\x87\x03\n" +
+	"\x0fReceiverService\x12E\n" +
+	"\x0eCreateReceiver\x12\x1f.bills.v1.CreateReceiverRequest\x1a\x12.bills.v1.Receiver\x12?\n" +
+	"\vGetReceiver\x12\x1c.bills.v1.GetReceiverRequest\x1a\x12.bills.v1.Receiver\x12P\n" +
+	"\rListReceivers\x12\x1e.bills.v1.ListReceiversRequest\x1a\x1f.bills.v1.ListReceiversResponse\x12E\n" +
+	"\x0eUpdateReceiver\x12\x1f.bills.v1.UpdateReceiverRequest\x1a\x12.bills.v1.Receiver\x12S\n" +
+	"\x0eDeleteReceiver\x12\x1f.bills.v1.DeleteReceiverRequest\x1a .bills.v1.DeleteReceiverResponse2\x87\x03\n" +
+	"\x0fBillItemService\x12E\n" +
+	"\x0eCreateBillItem\x12\x1f.bills.v1.CreateBillItemRequest\x1a\x12.bills.v1.BillItem\x12?\n" +
+	"\vGetBillItem\x12\x1c.bills.v1.GetBillItemRequest\x1a\x12.bills.v1.BillItem\x12P\n" +
+	"\rListBillItems\x12\x1e.bills.v1.ListBillItemsRequest\x1a\x1f.bills.v1.ListBillItemsResponse\x12E\n" +
+	"\x0eUpdateBillItem\x12\x1f.bills.v1.UpdateBillItemRequest\x1a\x12.bills.v1.BillItem\x12S\n" +
+	"\x0eDeleteBillItem\x12\x1f.bills.v1.DeleteBillItemRequest\x1a .bills.v1.DeleteBillItemResponseB$Z\"bills/internal/rpc/billsv1;billsv1b\x06proto3"
+
+var (
+	file_bills_v1_bills_proto_rawDescOnce sync.Once
+	file_bills_v1_bills_proto_rawDescData []byte
+)
+
+func file_bills_v1_bills_proto_rawDescGZIP() []byte {
+	file_bills_v1_bills_proto_rawDescOnce.Do(func() {
+		file_bills_v1_bills_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bills_v1_bills_proto_rawDesc), len(file_bills_v1_bills_proto_rawDesc)))
+	})
+	return file_bills_v1_bills_proto_rawDescData
+}
+
+var file_bills_v1_bills_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_bills_v1_bills_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
+var file_bills_v1_bills_proto_goTypes = []any{
+	(BillEvent_Type)(0),                       // 0: bills.v1.BillEvent.Type
+	(*Bill)(nil),                              // 1: bills.v1.Bill
+	(*BillItemAssignment)(nil),                // 2: bills.v1.BillItemAssignment
+	(*Issuer)(nil),                            // 3: bills.v1.Issuer
+	(*Receiver)(nil),                          // 4: bills.v1.Receiver
+	(*BillItem)(nil),                          // 5: bills.v1.BillItem
+	(*CreateBillRequest)(nil),                 // 6: bills.v1.CreateBillRequest
+	(*GetBillRequest)(nil),                    // 7: bills.v1.GetBillRequest
+	(*ListBillsRequest)(nil),                  // 8: bills.v1.ListBillsRequest
+	(*ListBillsResponse)(nil),                 // 9: bills.v1.ListBillsResponse
+	(*UpdateBillRequest)(nil),                 // 10: bills.v1.UpdateBillRequest
+	(*DeleteBillRequest)(nil),                 // 11: bills.v1.DeleteBillRequest
+	(*DeleteBillResponse)(nil),                // 12: bills.v1.DeleteBillResponse
+	(*WatchBillsRequest)(nil),                 // 13: bills.v1.WatchBillsRequest
+	(*BillEvent)(nil),                         // 14: bills.v1.BillEvent
+	(*CreateBillItemAssignmentRequest)(nil),   // 15: bills.v1.CreateBillItemAssignmentRequest
+	(*GetBillItemAssignmentRequest)(nil),      // 16: bills.v1.GetBillItemAssignmentRequest
+	(*ListBillItemAssignmentsRequest)(nil),    // 17: bills.v1.ListBillItemAssignmentsRequest
+	(*ListBillItemAssignmentsResponse)(nil),   // 18: bills.v1.ListBillItemAssignmentsResponse
+	(*UpdateBillItemAssignmentRequest)(nil),   // 19: bills.v1.UpdateBillItemAssignmentRequest
+	(*DeleteBillItemAssignmentRequest)(nil),   // 20: bills.v1.DeleteBillItemAssignmentRequest
+	(*DeleteBillItemAssignmentResponse)(nil),  // 21: bills.v1.DeleteBillItemAssignmentResponse
+	(*DeleteBillItemAssignmentsRequest)(nil),  // 22: bills.v1.DeleteBillItemAssignmentsRequest
+	(*DeleteBillItemAssignmentsResponse)(nil), // 23: bills.v1.DeleteBillItemAssignmentsResponse
+	(*CreateIssuerRequest)(nil),               // 24: bills.v1.CreateIssuerRequest
+	(*GetIssuerRequest)(nil),                  // 25: bills.v1.GetIssuerRequest
+	(*ListIssuersRequest)(nil),                // 26: bills.v1.ListIssuersRequest
+	(*ListIssuersResponse)(nil),               // 27: bills.v1.ListIssuersResponse
+	(*UpdateIssuerRequest)(nil),               // 28: bills.v1.UpdateIssuerRequest
+	(*DeleteIssuerRequest)(nil),               // 29: bills.v1.DeleteIssuerRequest
+	(*DeleteIssuerResponse)(nil),              // 30: bills.v1.DeleteIssuerResponse
+	(*CreateReceiverRequest)(nil),             // 31: bills.v1.CreateReceiverRequest
+	(*GetReceiverRequest)(nil),                // 32: bills.v1.GetReceiverRequest
+	(*ListReceiversRequest)(nil),              // 33: bills.v1.ListReceiversRequest
+	(*ListReceiversResponse)(nil),             // 34: bills.v1.ListReceiversResponse
+	(*UpdateReceiverRequest)(nil),             // 35: bills.v1.UpdateReceiverRequest
+	(*DeleteReceiverRequest)(nil),             // 36: bills.v1.DeleteReceiverRequest
+	(*DeleteReceiverResponse)(nil),            // 37: bills.v1.DeleteReceiverResponse
+	(*CreateBillItemRequest)(nil),             // 38: bills.v1.CreateBillItemRequest
+	(*GetBillItemRequest)(nil),                // 39: bills.v1.GetBillItemRequest
+	(*ListBillItemsRequest)(nil),              // 40: bills.v1.ListBillItemsRequest
+	(*ListBillItemsResponse)(nil),             // 41: bills.v1.ListBillItemsResponse
+	(*UpdateBillItemRequest)(nil),             // 42: bills.v1.UpdateBillItemRequest
+	(*DeleteBillItemRequest)(nil),             // 43: bills.v1.DeleteBillItemRequest
+	(*DeleteBillItemResponse)(nil),            // 44: bills.v1.DeleteBillItemResponse
+	(*timestamppb.Timestamp)(nil),             // 45: google.protobuf.Timestamp
+}
+var file_bills_v1_bills_proto_depIdxs = []int32{
+	45, // 0: bills.v1.Bill.due_date:type_name -> google.protobuf.Timestamp
+	45, // 1: bills.v1.Bill.created_at:type_name -> google.protobuf.Timestamp
+	45, // 2: bills.v1.Bill.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 3: bills.v1.Bill.items:type_name -> bills.v1.BillItemAssignment
+	45, // 4: bills.v1.BillItemAssignment.created_at:type_name -> google.protobuf.Timestamp
+	45, // 5: bills.v1.BillItemAssignment.updated_at:type_name -> google.protobuf.Timestamp
+	45, // 6: bills.v1.Issuer.created_at:type_name -> google.protobuf.Timestamp
+	45, // 7: bills.v1.Issuer.updated_at:type_name -> google.protobuf.Timestamp
+	45, // 8: bills.v1.Receiver.created_at:type_name -> google.protobuf.Timestamp
+	45, // 9: bills.v1.Receiver.updated_at:type_name -> google.protobuf.Timestamp
+	45, // 10: bills.v1.BillItem.created_at:type_name -> google.protobuf.Timestamp
+	45, // 11: bills.v1.BillItem.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 12: bills.v1.CreateBillRequest.bill:type_name -> bills.v1.Bill
+	1,  // 13: bills.v1.ListBillsResponse.bills:type_name -> bills.v1.Bill
+	1,  // 14: bills.v1.UpdateBillRequest.bill:type_name -> bills.v1.Bill
+	0,  // 15: bills.v1.BillEvent.type:type_name -> bills.v1.BillEvent.Type
+	1,  // 16: bills.v1.BillEvent.bill:type_name -> bills.v1.Bill
+	2,  // 17: bills.v1.CreateBillItemAssignmentRequest.assignment:type_name -> bills.v1.BillItemAssignment
+	2,  // 18: bills.v1.ListBillItemAssignmentsResponse.assignments:type_name -> bills.v1.BillItemAssignment
+	2,  // 19: bills.v1.UpdateBillItemAssignmentRequest.assignment:type_name -> bills.v1.BillItemAssignment
+	3,  // 20: bills.v1.CreateIssuerRequest.issuer:type_name -> bills.v1.Issuer
+	3,  // 21: bills.v1.ListIssuersResponse.issuers:type_name -> bills.v1.Issuer
+	3,  // 22: bills.v1.UpdateIssuerRequest.issuer:type_name -> bills.v1.Issuer
+	4,  // 23: bills.v1.CreateReceiverRequest.receiver:type_name -> bills.v1.Receiver
+	4,  // 24: bills.v1.ListReceiversResponse.receivers:type_name -> bills.v1.Receiver
+	4,  // 25: bills.v1.UpdateReceiverRequest.receiver:type_name -> bills.v1.Receiver
+	5,  // 26: bills.v1.CreateBillItemRequest.bill_item:type_name -> bills.v1.BillItem
+	5,  // 27: bills.v1.ListBillItemsResponse.bill_items:type_name -> bills.v1.BillItem
+	5,  // 28: bills.v1.UpdateBillItemRequest.bill_item:type_name -> bills.v1.BillItem
+	6,  // 29: bills.v1.BillService.CreateBill:input_type -> bills.v1.CreateBillRequest
+	7,  // 30: bills.v1.BillService.GetBill:input_type -> bills.v1.GetBillRequest
+	8,  // 31: bills.v1.BillService.ListBills:input_type -> bills.v1.ListBillsRequest
+	10, // 32: bills.v1.BillService.UpdateBill:input_type -> bills.v1.UpdateBillRequest
+	11, // 33: bills.v1.BillService.DeleteBill:input_type -> bills.v1.DeleteBillRequest
+	13, // 34: bills.v1.BillService.WatchBills:input_type -> bills.v1.WatchBillsRequest
+	15, // 35: bills.v1.BillItemAssignmentService.CreateBillItemAssignment:input_type -> bills.v1.CreateBillItemAssignmentRequest
+	16, // 36: bills.v1.BillItemAssignmentService.GetBillItemAssignment:input_type -> bills.v1.GetBillItemAssignmentRequest
+	17, // 37: bills.v1.BillItemAssignmentService.ListBillItemAssignments:input_type -> bills.v1.ListBillItemAssignmentsRequest
+	19, // 38: bills.v1.BillItemAssignmentService.UpdateBillItemAssignment:input_type -> bills.v1.UpdateBillItemAssignmentRequest
+	20, // 39: bills.v1.BillItemAssignmentService.DeleteBillItemAssignment:input_type -> bills.v1.DeleteBillItemAssignmentRequest
+	22, // 40: bills.v1.BillItemAssignmentService.DeleteBillItemAssignments:input_type -> bills.v1.DeleteBillItemAssignmentsRequest
+	24, // 41: bills.v1.IssuerService.CreateIssuer:input_type -> bills.v1.CreateIssuerRequest
+	25, // 42: bills.v1.IssuerService.GetIssuer:input_type -> bills.v1.GetIssuerRequest
+	26, // 43: bills.v1.IssuerService.ListIssuers:input_type -> bills.v1.ListIssuersRequest
+	28, // 44: bills.v1.IssuerService.UpdateIssuer:input_type -> bills.v1.UpdateIssuerRequest
+	29, // 45: bills.v1.IssuerService.DeleteIssuer:input_type -> bills.v1.DeleteIssuerRequest
+	31, // 46: bills.v1.ReceiverService.CreateReceiver:input_type -> bills.v1.CreateReceiverRequest
+	32, // 47: bills.v1.ReceiverService.GetReceiver:input_type -> bills.v1.GetReceiverRequest
+	33, // 48: bills.v1.ReceiverService.ListReceivers:input_type -> bills.v1.ListReceiversRequest
+	35, // 49: bills.v1.ReceiverService.UpdateReceiver:input_type -> bills.v1.UpdateReceiverRequest
+	36, // 50: bills.v1.ReceiverService.DeleteReceiver:input_type -> bills.v1.DeleteReceiverRequest
+	38, // 51: bills.v1.BillItemService.CreateBillItem:input_type -> bills.v1.CreateBillItemRequest
+	39, // 52: bills.v1.BillItemService.GetBillItem:input_type -> bills.v1.GetBillItemRequest
+	40, // 53: bills.v1.BillItemService.ListBillItems:input_type -> bills.v1.ListBillItemsRequest
+	42, // 54: bills.v1.BillItemService.UpdateBillItem:input_type -> bills.v1.UpdateBillItemRequest
+	43, // 55: bills.v1.BillItemService.DeleteBillItem:input_type -> bills.v1.DeleteBillItemRequest
+	1,  // 56: bills.v1.BillService.CreateBill:output_type -> bills.v1.Bill
+	1,  // 57: bills.v1.BillService.GetBill:output_type -> bills.v1.Bill
+	9,  // 58: bills.v1.BillService.ListBills:output_type -> bills.v1.ListBillsResponse
+	1,  // 59: bills.v1.BillService.UpdateBill:output_type -> bills.v1.Bill
+	12, // 60: bills.v1.BillService.DeleteBill:output_type -> bills.v1.DeleteBillResponse
+	14, // 61: bills.v1.BillService.WatchBills:output_type -> bills.v1.BillEvent
+	2,  // 62: bills.v1.BillItemAssignmentService.CreateBillItemAssignment:output_type -> bills.v1.BillItemAssignment
+	2,  // 63: bills.v1.BillItemAssignmentService.GetBillItemAssignment:output_type -> bills.v1.BillItemAssignment
+	18, // 64: bills.v1.BillItemAssignmentService.ListBillItemAssignments:output_type -> bills.v1.ListBillItemAssignmentsResponse
+	2,  // 65: bills.v1.BillItemAssignmentService.UpdateBillItemAssignment:output_type -> bills.v1.BillItemAssignment
+	21, // 66: bills.v1.BillItemAssignmentService.DeleteBillItemAssignment:output_type -> bills.v1.DeleteBillItemAssignmentResponse
+	23, // 67: bills.v1.BillItemAssignmentService.DeleteBillItemAssignments:output_type -> bills.v1.DeleteBillItemAssignmentsResponse
+	3,  // 68: bills.v1.IssuerService.CreateIssuer:output_type -> bills.v1.Issuer
+	3,  // 69: bills.v1.IssuerService.GetIssuer:output_type -> bills.v1.Issuer
+	27, // 70: bills.v1.IssuerService.ListIssuers:output_type -> bills.v1.ListIssuersResponse
+	3,  // 71: bills.v1.IssuerService.UpdateIssuer:output_type -> bills.v1.Issuer
+	30, // 72: bills.v1.IssuerService.DeleteIssuer:output_type -> bills.v1.DeleteIssuerResponse
+	4,  // 73: bills.v1.ReceiverService.CreateReceiver:output_type -> bills.v1.Receiver
+	4,  // 74: bills.v1.ReceiverService.GetReceiver:output_type -> bills.v1.Receiver
+	34, // 75: bills.v1.ReceiverService.ListReceivers:output_type -> bills.v1.ListReceiversResponse
+	4,  // 76: bills.v1.ReceiverService.UpdateReceiver:output_type -> bills.v1.Receiver
+	37, // 77: bills.v1.ReceiverService.DeleteReceiver:output_type -> bills.v1.DeleteReceiverResponse
+	5,  // 78: bills.v1.BillItemService.CreateBillItem:output_type -> bills.v1.BillItem
+	5,  // 79: bills.v1.BillItemService.GetBillItem:output_type -> bills.v1.BillItem
+	41, // 80: bills.v1.BillItemService.ListBillItems:output_type -> bills.v1.ListBillItemsResponse
+	5,  // 81: bills.v1.BillItemService.UpdateBillItem:output_type -> bills.v1.BillItem
+	44, // 82: bills.v1.BillItemService.DeleteBillItem:output_type -> bills.v1.DeleteBillItemResponse
+	56, // [56:83] is the sub-list for method output_type
+	29, // [29:56] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
+}
+
+func init() { file_bills_v1_bills_proto_init() }
+func file_bills_v1_bills_proto_init() {
+	if File_bills_v1_bills_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bills_v1_bills_proto_rawDesc), len(file_bills_v1_bills_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   44,
+			NumExtensions: 0,
+			NumServices:   5,
+		},
+		GoTypes:           file_bills_v1_bills_proto_goTypes,
+		DependencyIndexes: file_bills_v1_bills_proto_depIdxs,
+		EnumInfos:         file_bills_v1_bills_proto_enumTypes,
+		MessageInfos:      file_bills_v1_bills_proto_msgTypes,
+	}.Build()
+	File_bills_v1_bills_proto = out.File
+	file_bills_v1_bills_proto_goTypes = nil
+	file_bills_v1_bills_proto_depIdxs = nil
+}
@@ -0,0 +1,1394 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: bills/v1/bills.proto
+
+package billsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BillService_CreateBill_FullMethodName = "/bills.v1.BillService/CreateBill"
+	BillService_GetBill_FullMethodName    = "/bills.v1.BillService/GetBill"
+	BillService_ListBills_FullMethodName  = "/bills.v1.BillService/ListBills"
+	BillService_UpdateBill_FullMethodName = "/bills.v1.BillService/UpdateBill"
+	BillService_DeleteBill_FullMethodName = "/bills.v1.BillService/DeleteBill"
+	BillService_WatchBills_FullMethodName = "/bills.v1.BillService/WatchBills"
+)
+
+// BillServiceClient is the client API for BillService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BillService manages bills and their lines
+type BillServiceClient interface {
+	CreateBill(ctx context.Context, in *CreateBillRequest, opts ...grpc.CallOption) (*Bill, error)
+	GetBill(ctx context.Context, in *GetBillRequest, opts ...grpc.CallOption) (*Bill, error)
+	ListBills(ctx context.Context, in *ListBillsRequest, opts ...grpc.CallOption) (*ListBillsResponse, error)
+	UpdateBill(ctx context.Context, in *UpdateBillRequest, opts ...grpc.CallOption) (*Bill, error)
+	DeleteBill(ctx context.Context, in *DeleteBillRequest, opts ...grpc.CallOption) (*DeleteBillResponse, error)
+	// WatchBills streams every change made to a bill until the client cancels
+	WatchBills(ctx context.Context, in *WatchBillsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BillEvent], error)
+}
+
+type billServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBillServiceClient(cc grpc.ClientConnInterface) BillServiceClient {
+	return &billServiceClient{cc}
+}
+
+func (c *billServiceClient) CreateBill(ctx context.Context, in *CreateBillRequest, opts ...grpc.CallOption) (*Bill, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Bill)
+	err := c.cc.Invoke(ctx, BillService_CreateBill_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billServiceClient) GetBill(ctx context.Context, in *GetBillRequest, opts ...grpc.CallOption) (*Bill, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Bill)
+	err := c.cc.Invoke(ctx, BillService_GetBill_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billServiceClient) ListBills(ctx context.Context, in *ListBillsRequest, opts ...grpc.CallOption) (*ListBillsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBillsResponse)
+	err := c.cc.Invoke(ctx, BillService_ListBills_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billServiceClient) UpdateBill(ctx context.Context, in *UpdateBillRequest, opts ...grpc.CallOption) (*Bill, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Bill)
+	err := c.cc.Invoke(ctx, BillService_UpdateBill_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billServiceClient) DeleteBill(ctx context.Context, in *DeleteBillRequest, opts ...grpc.CallOption) (*DeleteBillResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBillResponse)
+	err := c.cc.Invoke(ctx, BillService_DeleteBill_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billServiceClient) WatchBills(ctx context.Context, in *WatchBillsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BillEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BillService_ServiceDesc.Streams[0], BillService_WatchBills_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchBillsRequest, BillEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BillService_WatchBillsClient = grpc.ServerStreamingClient[BillEvent]
+
+// BillServiceServer is the server API for BillService service.
+// All implementations must embed UnimplementedBillServiceServer
+// for forward compatibility.
+//
+// BillService manages bills and their lines
+type BillServiceServer interface {
+	CreateBill(context.Context, *CreateBillRequest) (*Bill, error)
+	GetBill(context.Context, *GetBillRequest) (*Bill, error)
+	ListBills(context.Context, *ListBillsRequest) (*ListBillsResponse, error)
+	UpdateBill(context.Context, *UpdateBillRequest) (*Bill, error)
+	DeleteBill(context.Context, *DeleteBillRequest) (*DeleteBillResponse, error)
+	// WatchBills streams every change made to a bill until the client cancels
+	WatchBills(*WatchBillsRequest, grpc.ServerStreamingServer[BillEvent]) error
+	mustEmbedUnimplementedBillServiceServer()
+}
+
+// UnimplementedBillServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBillServiceServer struct{}
+
+func (UnimplementedBillServiceServer) CreateBill(context.Context, *CreateBillRequest) (*Bill, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBill not implemented")
+}
+func (UnimplementedBillServiceServer) GetBill(context.Context, *GetBillRequest) (*Bill, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBill not implemented")
+}
+func (UnimplementedBillServiceServer) ListBills(context.Context, *ListBillsRequest) (*ListBillsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBills not implemented")
+}
+func (UnimplementedBillServiceServer) UpdateBill(context.Context, *UpdateBillRequest) (*Bill, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBill not implemented")
+}
+func (UnimplementedBillServiceServer) DeleteBill(context.Context, *DeleteBillRequest) (*DeleteBillResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBill not implemented")
+}
+func (UnimplementedBillServiceServer) WatchBills(*WatchBillsRequest, grpc.ServerStreamingServer[BillEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBills not implemented")
+}
+func (UnimplementedBillServiceServer) mustEmbedUnimplementedBillServiceServer() {}
+func (UnimplementedBillServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeBillServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BillServiceServer will
+// result in compilation errors.
+type UnsafeBillServiceServer interface {
+	mustEmbedUnimplementedBillServiceServer()
+}
+
+func RegisterBillServiceServer(s grpc.ServiceRegistrar, srv BillServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBillServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BillService_ServiceDesc, srv)
+}
+
+func _BillService_CreateBill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillServiceServer).CreateBill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillService_CreateBill_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillServiceServer).CreateBill(ctx, req.(*CreateBillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillService_GetBill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillServiceServer).GetBill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillService_GetBill_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillServiceServer).GetBill(ctx, req.(*GetBillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillService_ListBills_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBillsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillServiceServer).ListBills(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillService_ListBills_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillServiceServer).ListBills(ctx, req.(*ListBillsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillService_UpdateBill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillServiceServer).UpdateBill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillService_UpdateBill_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillServiceServer).UpdateBill(ctx, req.(*UpdateBillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillService_DeleteBill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillServiceServer).DeleteBill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillService_DeleteBill_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillServiceServer).DeleteBill(ctx, req.(*DeleteBillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillService_WatchBills_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBillsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BillServiceServer).WatchBills(m, &grpc.GenericServerStream[WatchBillsRequest, BillEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BillService_WatchBillsServer = grpc.ServerStreamingServer[BillEvent]
+
+// BillService_ServiceDesc is the grpc.ServiceDesc for BillService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BillService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bills.v1.BillService",
+	HandlerType: (*BillServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateBill",
+			Handler:    _BillService_CreateBill_Handler,
+		},
+		{
+			MethodName: "GetBill",
+			Handler:    _BillService_GetBill_Handler,
+		},
+		{
+			MethodName: "ListBills",
+			Handler:    _BillService_ListBills_Handler,
+		},
+		{
+			MethodName: "UpdateBill",
+			Handler:    _BillService_UpdateBill_Handler,
+		},
+		{
+			MethodName: "DeleteBill",
+			Handler:    _BillService_DeleteBill_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchBills",
+			Handler:       _BillService_WatchBills_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "bills/v1/bills.proto",
+}
+
+const (
+	BillItemAssignmentService_CreateBillItemAssignment_FullMethodName  = "/bills.v1.BillItemAssignmentService/CreateBillItemAssignment"
+	BillItemAssignmentService_GetBillItemAssignment_FullMethodName     = "/bills.v1.BillItemAssignmentService/GetBillItemAssignment"
+	BillItemAssignmentService_ListBillItemAssignments_FullMethodName   = "/bills.v1.BillItemAssignmentService/ListBillItemAssignments"
+	BillItemAssignmentService_UpdateBillItemAssignment_FullMethodName  = "/bills.v1.BillItemAssignmentService/UpdateBillItemAssignment"
+	BillItemAssignmentService_DeleteBillItemAssignment_FullMethodName  = "/bills.v1.BillItemAssignmentService/DeleteBillItemAssignment"
+	BillItemAssignmentService_DeleteBillItemAssignments_FullMethodName = "/bills.v1.BillItemAssignmentService/DeleteBillItemAssignments"
+)
+
+// BillItemAssignmentServiceClient is the client API for BillItemAssignmentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BillItemAssignmentService manages the lines of a bill. Every change to a
+// line also refreshes the totals of its bill
+type BillItemAssignmentServiceClient interface {
+	CreateBillItemAssignment(ctx context.Context, in *CreateBillItemAssignmentRequest, opts ...grpc.CallOption) (*BillItemAssignment, error)
+	GetBillItemAssignment(ctx context.Context, in *GetBillItemAssignmentRequest, opts ...grpc.CallOption) (*BillItemAssignment, error)
+	ListBillItemAssignments(ctx context.Context, in *ListBillItemAssignmentsRequest, opts ...grpc.CallOption) (*ListBillItemAssignmentsResponse, error)
+	UpdateBillItemAssignment(ctx context.Context, in *UpdateBillItemAssignmentRequest, opts ...grpc.CallOption) (*BillItemAssignment, error)
+	DeleteBillItemAssignment(ctx context.Context, in *DeleteBillItemAssignmentRequest, opts ...grpc.CallOption) (*DeleteBillItemAssignmentResponse, error)
+	DeleteBillItemAssignments(ctx context.Context, in *DeleteBillItemAssignmentsRequest, opts ...grpc.CallOption) (*DeleteBillItemAssignmentsResponse, error)
+}
+
+type billItemAssignmentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBillItemAssignmentServiceClient(cc grpc.ClientConnInterface) BillItemAssignmentServiceClient {
+	return &billItemAssignmentServiceClient{cc}
+}
+
+func (c *billItemAssignmentServiceClient) CreateBillItemAssignment(ctx context.Context, in *CreateBillItemAssignmentRequest, opts ...grpc.CallOption) (*BillItemAssignment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillItemAssignment)
+	err := c.cc.Invoke(ctx, BillItemAssignmentService_CreateBillItemAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemAssignmentServiceClient) GetBillItemAssignment(ctx context.Context, in *GetBillItemAssignmentRequest, opts ...grpc.CallOption) (*BillItemAssignment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillItemAssignment)
+	err := c.cc.Invoke(ctx, BillItemAssignmentService_GetBillItemAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemAssignmentServiceClient) ListBillItemAssignments(ctx context.Context, in *ListBillItemAssignmentsRequest, opts ...grpc.CallOption) (*ListBillItemAssignmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBillItemAssignmentsResponse)
+	err := c.cc.Invoke(ctx, BillItemAssignmentService_ListBillItemAssignments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemAssignmentServiceClient) UpdateBillItemAssignment(ctx context.Context, in *UpdateBillItemAssignmentRequest, opts ...grpc.CallOption) (*BillItemAssignment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillItemAssignment)
+	err := c.cc.Invoke(ctx, BillItemAssignmentService_UpdateBillItemAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemAssignmentServiceClient) DeleteBillItemAssignment(ctx context.Context, in *DeleteBillItemAssignmentRequest, opts ...grpc.CallOption) (*DeleteBillItemAssignmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBillItemAssignmentResponse)
+	err := c.cc.Invoke(ctx, BillItemAssignmentService_DeleteBillItemAssignment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemAssignmentServiceClient) DeleteBillItemAssignments(ctx context.Context, in *DeleteBillItemAssignmentsRequest, opts ...grpc.CallOption) (*DeleteBillItemAssignmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBillItemAssignmentsResponse)
+	err := c.cc.Invoke(ctx, BillItemAssignmentService_DeleteBillItemAssignments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BillItemAssignmentServiceServer is the server API for BillItemAssignmentService service.
+// All implementations must embed UnimplementedBillItemAssignmentServiceServer
+// for forward compatibility.
+//
+// BillItemAssignmentService manages the lines of a bill. Every change to a
+// line also refreshes the totals of its bill
+type BillItemAssignmentServiceServer interface {
+	CreateBillItemAssignment(context.Context, *CreateBillItemAssignmentRequest) (*BillItemAssignment, error)
+	GetBillItemAssignment(context.Context, *GetBillItemAssignmentRequest) (*BillItemAssignment, error)
+	ListBillItemAssignments(context.Context, *ListBillItemAssignmentsRequest) (*ListBillItemAssignmentsResponse, error)
+	UpdateBillItemAssignment(context.Context, *UpdateBillItemAssignmentRequest) (*BillItemAssignment, error)
+	DeleteBillItemAssignment(context.Context, *DeleteBillItemAssignmentRequest) (*DeleteBillItemAssignmentResponse, error)
+	DeleteBillItemAssignments(context.Context, *DeleteBillItemAssignmentsRequest) (*DeleteBillItemAssignmentsResponse, error)
+	mustEmbedUnimplementedBillItemAssignmentServiceServer()
+}
+
+// UnimplementedBillItemAssignmentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBillItemAssignmentServiceServer struct{}
+
+func (UnimplementedBillItemAssignmentServiceServer) CreateBillItemAssignment(context.Context, *CreateBillItemAssignmentRequest) (*BillItemAssignment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBillItemAssignment not implemented")
+}
+func (UnimplementedBillItemAssignmentServiceServer) GetBillItemAssignment(context.Context, *GetBillItemAssignmentRequest) (*BillItemAssignment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBillItemAssignment not implemented")
+}
+func (UnimplementedBillItemAssignmentServiceServer) ListBillItemAssignments(context.Context, *ListBillItemAssignmentsRequest) (*ListBillItemAssignmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBillItemAssignments not implemented")
+}
+func (UnimplementedBillItemAssignmentServiceServer) UpdateBillItemAssignment(context.Context, *UpdateBillItemAssignmentRequest) (*BillItemAssignment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBillItemAssignment not implemented")
+}
+func (UnimplementedBillItemAssignmentServiceServer) DeleteBillItemAssignment(context.Context, *DeleteBillItemAssignmentRequest) (*DeleteBillItemAssignmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBillItemAssignment not implemented")
+}
+func (UnimplementedBillItemAssignmentServiceServer) DeleteBillItemAssignments(context.Context, *DeleteBillItemAssignmentsRequest) (*DeleteBillItemAssignmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBillItemAssignments not implemented")
+}
+func (UnimplementedBillItemAssignmentServiceServer) mustEmbedUnimplementedBillItemAssignmentServiceServer() {
+}
+func (UnimplementedBillItemAssignmentServiceServer) testEmbeddedByValue() {}
+
+// UnsafeBillItemAssignmentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BillItemAssignmentServiceServer will
+// result in compilation errors.
+type UnsafeBillItemAssignmentServiceServer interface {
+	mustEmbedUnimplementedBillItemAssignmentServiceServer()
+}
+
+func RegisterBillItemAssignmentServiceServer(s grpc.ServiceRegistrar, srv BillItemAssignmentServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBillItemAssignmentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BillItemAssignmentService_ServiceDesc, srv)
+}
+
+func _BillItemAssignmentService_CreateBillItemAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBillItemAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemAssignmentServiceServer).CreateBillItemAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemAssignmentService_CreateBillItemAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemAssignmentServiceServer).CreateBillItemAssignment(ctx, req.(*CreateBillItemAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemAssignmentService_GetBillItemAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBillItemAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemAssignmentServiceServer).GetBillItemAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemAssignmentService_GetBillItemAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemAssignmentServiceServer).GetBillItemAssignment(ctx, req.(*GetBillItemAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemAssignmentService_ListBillItemAssignments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBillItemAssignmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemAssignmentServiceServer).ListBillItemAssignments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemAssignmentService_ListBillItemAssignments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemAssignmentServiceServer).ListBillItemAssignments(ctx, req.(*ListBillItemAssignmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemAssignmentService_UpdateBillItemAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBillItemAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemAssignmentServiceServer).UpdateBillItemAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemAssignmentService_UpdateBillItemAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemAssignmentServiceServer).UpdateBillItemAssignment(ctx, req.(*UpdateBillItemAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemAssignmentService_DeleteBillItemAssignment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBillItemAssignmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemAssignmentServiceServer).DeleteBillItemAssignment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemAssignmentService_DeleteBillItemAssignment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemAssignmentServiceServer).DeleteBillItemAssignment(ctx, req.(*DeleteBillItemAssignmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemAssignmentService_DeleteBillItemAssignments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBillItemAssignmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemAssignmentServiceServer).DeleteBillItemAssignments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemAssignmentService_DeleteBillItemAssignments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemAssignmentServiceServer).DeleteBillItemAssignments(ctx, req.(*DeleteBillItemAssignmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BillItemAssignmentService_ServiceDesc is the grpc.ServiceDesc for BillItemAssignmentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BillItemAssignmentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bills.v1.BillItemAssignmentService",
+	HandlerType: (*BillItemAssignmentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateBillItemAssignment",
+			Handler:    _BillItemAssignmentService_CreateBillItemAssignment_Handler,
+		},
+		{
+			MethodName: "GetBillItemAssignment",
+			Handler:    _BillItemAssignmentService_GetBillItemAssignment_Handler,
+		},
+		{
+			MethodName: "ListBillItemAssignments",
+			Handler:    _BillItemAssignmentService_ListBillItemAssignments_Handler,
+		},
+		{
+			MethodName: "UpdateBillItemAssignment",
+			Handler:    _BillItemAssignmentService_UpdateBillItemAssignment_Handler,
+		},
+		{
+			MethodName: "DeleteBillItemAssignment",
+			Handler:    _BillItemAssignmentService_DeleteBillItemAssignment_Handler,
+		},
+		{
+			MethodName: "DeleteBillItemAssignments",
+			Handler:    _BillItemAssignmentService_DeleteBillItemAssignments_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bills/v1/bills.proto",
+}
+
+const (
+	IssuerService_CreateIssuer_FullMethodName = "/bills.v1.IssuerService/CreateIssuer"
+	IssuerService_GetIssuer_FullMethodName    = "/bills.v1.IssuerService/GetIssuer"
+	IssuerService_ListIssuers_FullMethodName  = "/bills.v1.IssuerService/ListIssuers"
+	IssuerService_UpdateIssuer_FullMethodName = "/bills.v1.IssuerService/UpdateIssuer"
+	IssuerService_DeleteIssuer_FullMethodName = "/bills.v1.IssuerService/DeleteIssuer"
+)
+
+// IssuerServiceClient is the client API for IssuerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IssuerService manages the parties that issue bills
+type IssuerServiceClient interface {
+	CreateIssuer(ctx context.Context, in *CreateIssuerRequest, opts ...grpc.CallOption) (*Issuer, error)
+	GetIssuer(ctx context.Context, in *GetIssuerRequest, opts ...grpc.CallOption) (*Issuer, error)
+	ListIssuers(ctx context.Context, in *ListIssuersRequest, opts ...grpc.CallOption) (*ListIssuersResponse, error)
+	UpdateIssuer(ctx context.Context, in *UpdateIssuerRequest, opts ...grpc.CallOption) (*Issuer, error)
+	DeleteIssuer(ctx context.Context, in *DeleteIssuerRequest, opts ...grpc.CallOption) (*DeleteIssuerResponse, error)
+}
+
+type issuerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIssuerServiceClient(cc grpc.ClientConnInterface) IssuerServiceClient {
+	return &issuerServiceClient{cc}
+}
+
+func (c *issuerServiceClient) CreateIssuer(ctx context.Context, in *CreateIssuerRequest, opts ...grpc.CallOption) (*Issuer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issuer)
+	err := c.cc.Invoke(ctx, IssuerService_CreateIssuer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issuerServiceClient) GetIssuer(ctx context.Context, in *GetIssuerRequest, opts ...grpc.CallOption) (*Issuer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issuer)
+	err := c.cc.Invoke(ctx, IssuerService_GetIssuer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issuerServiceClient) ListIssuers(ctx context.Context, in *ListIssuersRequest, opts ...grpc.CallOption) (*ListIssuersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIssuersResponse)
+	err := c.cc.Invoke(ctx, IssuerService_ListIssuers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issuerServiceClient) UpdateIssuer(ctx context.Context, in *UpdateIssuerRequest, opts ...grpc.CallOption) (*Issuer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issuer)
+	err := c.cc.Invoke(ctx, IssuerService_UpdateIssuer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issuerServiceClient) DeleteIssuer(ctx context.Context, in *DeleteIssuerRequest, opts ...grpc.CallOption) (*DeleteIssuerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteIssuerResponse)
+	err := c.cc.Invoke(ctx, IssuerService_DeleteIssuer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IssuerServiceServer is the server API for IssuerService service.
+// All implementations must embed UnimplementedIssuerServiceServer
+// for forward compatibility.
+//
+// IssuerService manages the parties that issue bills
+type IssuerServiceServer interface {
+	CreateIssuer(context.Context, *CreateIssuerRequest) (*Issuer, error)
+	GetIssuer(context.Context, *GetIssuerRequest) (*Issuer, error)
+	ListIssuers(context.Context, *ListIssuersRequest) (*ListIssuersResponse, error)
+	UpdateIssuer(context.Context, *UpdateIssuerRequest) (*Issuer, error)
+	DeleteIssuer(context.Context, *DeleteIssuerRequest) (*DeleteIssuerResponse, error)
+	mustEmbedUnimplementedIssuerServiceServer()
+}
+
+// UnimplementedIssuerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIssuerServiceServer struct{}
+
+func (UnimplementedIssuerServiceServer) CreateIssuer(context.Context, *CreateIssuerRequest) (*Issuer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateIssuer not implemented")
+}
+func (UnimplementedIssuerServiceServer) GetIssuer(context.Context, *GetIssuerRequest) (*Issuer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIssuer not implemented")
+}
+func (UnimplementedIssuerServiceServer) ListIssuers(context.Context, *ListIssuersRequest) (*ListIssuersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIssuers not implemented")
+}
+func (UnimplementedIssuerServiceServer) UpdateIssuer(context.Context, *UpdateIssuerRequest) (*Issuer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateIssuer not implemented")
+}
+func (UnimplementedIssuerServiceServer) DeleteIssuer(context.Context, *DeleteIssuerRequest) (*DeleteIssuerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteIssuer not implemented")
+}
+func (UnimplementedIssuerServiceServer) mustEmbedUnimplementedIssuerServiceServer() {}
+func (UnimplementedIssuerServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeIssuerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IssuerServiceServer will
+// result in compilation errors.
+type UnsafeIssuerServiceServer interface {
+	mustEmbedUnimplementedIssuerServiceServer()
+}
+
+func RegisterIssuerServiceServer(s grpc.ServiceRegistrar, srv IssuerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIssuerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IssuerService_ServiceDesc, srv)
+}
+
+func _IssuerService_CreateIssuer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateIssuerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssuerServiceServer).CreateIssuer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssuerService_CreateIssuer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssuerServiceServer).CreateIssuer(ctx, req.(*CreateIssuerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssuerService_GetIssuer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIssuerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssuerServiceServer).GetIssuer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssuerService_GetIssuer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssuerServiceServer).GetIssuer(ctx, req.(*GetIssuerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssuerService_ListIssuers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIssuersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssuerServiceServer).ListIssuers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssuerService_ListIssuers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssuerServiceServer).ListIssuers(ctx, req.(*ListIssuersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssuerService_UpdateIssuer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateIssuerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssuerServiceServer).UpdateIssuer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssuerService_UpdateIssuer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssuerServiceServer).UpdateIssuer(ctx, req.(*UpdateIssuerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IssuerService_DeleteIssuer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteIssuerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssuerServiceServer).DeleteIssuer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IssuerService_DeleteIssuer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssuerServiceServer).DeleteIssuer(ctx, req.(*DeleteIssuerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IssuerService_ServiceDesc is the grpc.ServiceDesc for IssuerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IssuerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bills.v1.IssuerService",
+	HandlerType: (*IssuerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateIssuer",
+			Handler:    _IssuerService_CreateIssuer_Handler,
+		},
+		{
+			MethodName: "GetIssuer",
+			Handler:    _IssuerService_GetIssuer_Handler,
+		},
+		{
+			MethodName: "ListIssuers",
+			Handler:    _IssuerService_ListIssuers_Handler,
+		},
+		{
+			MethodName: "UpdateIssuer",
+			Handler:    _IssuerService_UpdateIssuer_Handler,
+		},
+		{
+			MethodName: "DeleteIssuer",
+			Handler:    _IssuerService_DeleteIssuer_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bills/v1/bills.proto",
+}
+
+const (
+	ReceiverService_CreateReceiver_FullMethodName = "/bills.v1.ReceiverService/CreateReceiver"
+	ReceiverService_GetReceiver_FullMethodName    = "/bills.v1.ReceiverService/GetReceiver"
+	ReceiverService_ListReceivers_FullMethodName  = "/bills.v1.ReceiverService/ListReceivers"
+	ReceiverService_UpdateReceiver_FullMethodName = "/bills.v1.ReceiverService/UpdateReceiver"
+	ReceiverService_DeleteReceiver_FullMethodName = "/bills.v1.ReceiverService/DeleteReceiver"
+)
+
+// ReceiverServiceClient is the client API for ReceiverService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReceiverService manages the parties that receive bills
+type ReceiverServiceClient interface {
+	CreateReceiver(ctx context.Context, in *CreateReceiverRequest, opts ...grpc.CallOption) (*Receiver, error)
+	GetReceiver(ctx context.Context, in *GetReceiverRequest, opts ...grpc.CallOption) (*Receiver, error)
+	ListReceivers(ctx context.Context, in *ListReceiversRequest, opts ...grpc.CallOption) (*ListReceiversResponse, error)
+	UpdateReceiver(ctx context.Context, in *UpdateReceiverRequest, opts ...grpc.CallOption) (*Receiver, error)
+	DeleteReceiver(ctx context.Context, in *DeleteReceiverRequest, opts ...grpc.CallOption) (*DeleteReceiverResponse, error)
+}
+
+type receiverServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReceiverServiceClient(cc grpc.ClientConnInterface) ReceiverServiceClient {
+	return &receiverServiceClient{cc}
+}
+
+func (c *receiverServiceClient) CreateReceiver(ctx context.Context, in *CreateReceiverRequest, opts ...grpc.CallOption) (*Receiver, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Receiver)
+	err := c.cc.Invoke(ctx, ReceiverService_CreateReceiver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverServiceClient) GetReceiver(ctx context.Context, in *GetReceiverRequest, opts ...grpc.CallOption) (*Receiver, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Receiver)
+	err := c.cc.Invoke(ctx, ReceiverService_GetReceiver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverServiceClient) ListReceivers(ctx context.Context, in *ListReceiversRequest, opts ...grpc.CallOption) (*ListReceiversResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReceiversResponse)
+	err := c.cc.Invoke(ctx, ReceiverService_ListReceivers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverServiceClient) UpdateReceiver(ctx context.Context, in *UpdateReceiverRequest, opts ...grpc.CallOption) (*Receiver, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Receiver)
+	err := c.cc.Invoke(ctx, ReceiverService_UpdateReceiver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *receiverServiceClient) DeleteReceiver(ctx context.Context, in *DeleteReceiverRequest, opts ...grpc.CallOption) (*DeleteReceiverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteReceiverResponse)
+	err := c.cc.Invoke(ctx, ReceiverService_DeleteReceiver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReceiverServiceServer is the server API for ReceiverService service.
+// All implementations must embed UnimplementedReceiverServiceServer
+// for forward compatibility.
+//
+// ReceiverService manages the parties that receive bills
+type ReceiverServiceServer interface {
+	CreateReceiver(context.Context, *CreateReceiverRequest) (*Receiver, error)
+	GetReceiver(context.Context, *GetReceiverRequest) (*Receiver, error)
+	ListReceivers(context.Context, *ListReceiversRequest) (*ListReceiversResponse, error)
+	UpdateReceiver(context.Context, *UpdateReceiverRequest) (*Receiver, error)
+	DeleteReceiver(context.Context, *DeleteReceiverRequest) (*DeleteReceiverResponse, error)
+	mustEmbedUnimplementedReceiverServiceServer()
+}
+
+// UnimplementedReceiverServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReceiverServiceServer struct{}
+
+func (UnimplementedReceiverServiceServer) CreateReceiver(context.Context, *CreateReceiverRequest) (*Receiver, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReceiver not implemented")
+}
+func (UnimplementedReceiverServiceServer) GetReceiver(context.Context, *GetReceiverRequest) (*Receiver, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReceiver not implemented")
+}
+func (UnimplementedReceiverServiceServer) ListReceivers(context.Context, *ListReceiversRequest) (*ListReceiversResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReceivers not implemented")
+}
+func (UnimplementedReceiverServiceServer) UpdateReceiver(context.Context, *UpdateReceiverRequest) (*Receiver, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReceiver not implemented")
+}
+func (UnimplementedReceiverServiceServer) DeleteReceiver(context.Context, *DeleteReceiverRequest) (*DeleteReceiverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReceiver not implemented")
+}
+func (UnimplementedReceiverServiceServer) mustEmbedUnimplementedReceiverServiceServer() {}
+func (UnimplementedReceiverServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeReceiverServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReceiverServiceServer will
+// result in compilation errors.
+type UnsafeReceiverServiceServer interface {
+	mustEmbedUnimplementedReceiverServiceServer()
+}
+
+func RegisterReceiverServiceServer(s grpc.ServiceRegistrar, srv ReceiverServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReceiverServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReceiverService_ServiceDesc, srv)
+}
+
+func _ReceiverService_CreateReceiver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServiceServer).CreateReceiver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverService_CreateReceiver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServiceServer).CreateReceiver(ctx, req.(*CreateReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverService_GetReceiver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServiceServer).GetReceiver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverService_GetReceiver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServiceServer).GetReceiver(ctx, req.(*GetReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverService_ListReceivers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReceiversRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServiceServer).ListReceivers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverService_ListReceivers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServiceServer).ListReceivers(ctx, req.(*ListReceiversRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverService_UpdateReceiver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServiceServer).UpdateReceiver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverService_UpdateReceiver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServiceServer).UpdateReceiver(ctx, req.(*UpdateReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReceiverService_DeleteReceiver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReceiverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReceiverServiceServer).DeleteReceiver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReceiverService_DeleteReceiver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReceiverServiceServer).DeleteReceiver(ctx, req.(*DeleteReceiverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReceiverService_ServiceDesc is the grpc.ServiceDesc for ReceiverService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReceiverService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bills.v1.ReceiverService",
+	HandlerType: (*ReceiverServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReceiver",
+			Handler:    _ReceiverService_CreateReceiver_Handler,
+		},
+		{
+			MethodName: "GetReceiver",
+			Handler:    _ReceiverService_GetReceiver_Handler,
+		},
+		{
+			MethodName: "ListReceivers",
+			Handler:    _ReceiverService_ListReceivers_Handler,
+		},
+		{
+			MethodName: "UpdateReceiver",
+			Handler:    _ReceiverService_UpdateReceiver_Handler,
+		},
+		{
+			MethodName: "DeleteReceiver",
+			Handler:    _ReceiverService_DeleteReceiver_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bills/v1/bills.proto",
+}
+
+const (
+	BillItemService_CreateBillItem_FullMethodName = "/bills.v1.BillItemService/CreateBillItem"
+	BillItemService_GetBillItem_FullMethodName    = "/bills.v1.BillItemService/GetBillItem"
+	BillItemService_ListBillItems_FullMethodName  = "/bills.v1.BillItemService/ListBillItems"
+	BillItemService_UpdateBillItem_FullMethodName = "/bills.v1.BillItemService/UpdateBillItem"
+	BillItemService_DeleteBillItem_FullMethodName = "/bills.v1.BillItemService/DeleteBillItem"
+)
+
+// BillItemServiceClient is the client API for BillItemService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BillItemService manages the catalog of services and products
+type BillItemServiceClient interface {
+	CreateBillItem(ctx context.Context, in *CreateBillItemRequest, opts ...grpc.CallOption) (*BillItem, error)
+	GetBillItem(ctx context.Context, in *GetBillItemRequest, opts ...grpc.CallOption) (*BillItem, error)
+	ListBillItems(ctx context.Context, in *ListBillItemsRequest, opts ...grpc.CallOption) (*ListBillItemsResponse, error)
+	UpdateBillItem(ctx context.Context, in *UpdateBillItemRequest, opts ...grpc.CallOption) (*BillItem, error)
+	DeleteBillItem(ctx context.Context, in *DeleteBillItemRequest, opts ...grpc.CallOption) (*DeleteBillItemResponse, error)
+}
+
+type billItemServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBillItemServiceClient(cc grpc.ClientConnInterface) BillItemServiceClient {
+	return &billItemServiceClient{cc}
+}
+
+func (c *billItemServiceClient) CreateBillItem(ctx context.Context, in *CreateBillItemRequest, opts ...grpc.CallOption) (*BillItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillItem)
+	err := c.cc.Invoke(ctx, BillItemService_CreateBillItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemServiceClient) GetBillItem(ctx context.Context, in *GetBillItemRequest, opts ...grpc.CallOption) (*BillItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillItem)
+	err := c.cc.Invoke(ctx, BillItemService_GetBillItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemServiceClient) ListBillItems(ctx context.Context, in *ListBillItemsRequest, opts ...grpc.CallOption) (*ListBillItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBillItemsResponse)
+	err := c.cc.Invoke(ctx, BillItemService_ListBillItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemServiceClient) UpdateBillItem(ctx context.Context, in *UpdateBillItemRequest, opts ...grpc.CallOption) (*BillItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillItem)
+	err := c.cc.Invoke(ctx, BillItemService_UpdateBillItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billItemServiceClient) DeleteBillItem(ctx context.Context, in *DeleteBillItemRequest, opts ...grpc.CallOption) (*DeleteBillItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBillItemResponse)
+	err := c.cc.Invoke(ctx, BillItemService_DeleteBillItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BillItemServiceServer is the server API for BillItemService service.
+// All implementations must embed UnimplementedBillItemServiceServer
+// for forward compatibility.
+//
+// BillItemService manages the catalog of services and products
+type BillItemServiceServer interface {
+	CreateBillItem(context.Context, *CreateBillItemRequest) (*BillItem, error)
+	GetBillItem(context.Context, *GetBillItemRequest) (*BillItem, error)
+	ListBillItems(context.Context, *ListBillItemsRequest) (*ListBillItemsResponse, error)
+	UpdateBillItem(context.Context, *UpdateBillItemRequest) (*BillItem, error)
+	DeleteBillItem(context.Context, *DeleteBillItemRequest) (*DeleteBillItemResponse, error)
+	mustEmbedUnimplementedBillItemServiceServer()
+}
+
+// UnimplementedBillItemServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBillItemServiceServer struct{}
+
+func (UnimplementedBillItemServiceServer) CreateBillItem(context.Context, *CreateBillItemRequest) (*BillItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBillItem not implemented")
+}
+func (UnimplementedBillItemServiceServer) GetBillItem(context.Context, *GetBillItemRequest) (*BillItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBillItem not implemented")
+}
+func (UnimplementedBillItemServiceServer) ListBillItems(context.Context, *ListBillItemsRequest) (*ListBillItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBillItems not implemented")
+}
+func (UnimplementedBillItemServiceServer) UpdateBillItem(context.Context, *UpdateBillItemRequest) (*BillItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBillItem not implemented")
+}
+func (UnimplementedBillItemServiceServer) DeleteBillItem(context.Context, *DeleteBillItemRequest) (*DeleteBillItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBillItem not implemented")
+}
+func (UnimplementedBillItemServiceServer) mustEmbedUnimplementedBillItemServiceServer() {}
+func (UnimplementedBillItemServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeBillItemServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BillItemServiceServer will
+// result in compilation errors.
+type UnsafeBillItemServiceServer interface {
+	mustEmbedUnimplementedBillItemServiceServer()
+}
+
+func RegisterBillItemServiceServer(s grpc.ServiceRegistrar, srv BillItemServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBillItemServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BillItemService_ServiceDesc, srv)
+}
+
+func _BillItemService_CreateBillItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBillItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemServiceServer).CreateBillItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemService_CreateBillItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemServiceServer).CreateBillItem(ctx, req.(*CreateBillItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemService_GetBillItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBillItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemServiceServer).GetBillItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemService_GetBillItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemServiceServer).GetBillItem(ctx, req.(*GetBillItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemService_ListBillItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBillItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemServiceServer).ListBillItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemService_ListBillItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemServiceServer).ListBillItems(ctx, req.(*ListBillItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemService_UpdateBillItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBillItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemServiceServer).UpdateBillItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemService_UpdateBillItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemServiceServer).UpdateBillItem(ctx, req.(*UpdateBillItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillItemService_DeleteBillItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBillItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillItemServiceServer).DeleteBillItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BillItemService_DeleteBillItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillItemServiceServer).DeleteBillItem(ctx, req.(*DeleteBillItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BillItemService_ServiceDesc is the grpc.ServiceDesc for BillItemService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BillItemService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bills.v1.BillItemService",
+	HandlerType: (*BillItemServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateBillItem",
+			Handler:    _BillItemService_CreateBillItem_Handler,
+		},
+		{
+			MethodName: "GetBillItem",
+			Handler:    _BillItemService_GetBillItem_Handler,
+		},
+		{
+			MethodName: "ListBillItems",
+			Handler:    _BillItemService_ListBillItems_Handler,
+		},
+		{
+			MethodName: "UpdateBillItem",
+			Handler:    _BillItemService_UpdateBillItem_Handler,
+		},
+		{
+			MethodName: "DeleteBillItem",
+			Handler:    _BillItemService_DeleteBillItem_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bills/v1/bills.proto",
+}
@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"bills/internal/models"
+	pb "bills/internal/rpc/billsv1"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toBill(b *models.Bill) *pb.Bill {
+	out := &pb.Bill{
+		Id:            b.ID,
+		IssuerId:      b.IssuerID,
+		ReceiverId:    b.ReceiverID,
+		DueDate:       timestamp(b.DueDate),
+		Currency:      b.Currency,
+		OriginalTotal: b.OriginalTotal,
+		EurTotal:      b.EURTotal,
+		Paid:          b.Paid,
+		CreatedAt:     timestamp(b.CreatedAt),
+		UpdatedAt:     timestamp(b.UpdatedAt),
+	}
+	for _, item := range b.Items {
+		out.Items = append(out.Items, toAssignment(item))
+	}
+	return out
+}
+
+func toAssignment(a *models.BillItemAssignment) *pb.BillItemAssignment {
+	return &pb.BillItemAssignment{
+		Id:             a.ID,
+		BillId:         a.BillID,
+		ItemId:         a.ItemID,
+		Quantity:       int32(a.Quantity),
+		Price:          a.Price,
+		Currency:       a.Currency,
+		ExchangeRate:   a.ExchangeRate,
+		OriginalAmount: a.OriginalAmount,
+		EurAmount:      a.EURAmount,
+		CreatedAt:      timestamp(a.CreatedAt),
+		UpdatedAt:      timestamp(a.UpdatedAt),
+	}
+}
+
+func fromAssignment(a *pb.BillItemAssignment) *models.BillItemAssignment {
+	return &models.BillItemAssignment{
+		ID:           a.GetId(),
+		BillID:       a.GetBillId(),
+		ItemID:       a.GetItemId(),
+		Quantity:     int(a.GetQuantity()),
+		Price:        a.GetPrice(),
+		Currency:     a.GetCurrency(),
+		ExchangeRate: a.GetExchangeRate(),
+	}
+}
+
+func toIssuer(i *models.Issuer) *pb.Issuer {
+	return &pb.Issuer{
+		Id:        i.ID,
+		Name:      i.Name,
+		VatNumber: i.VATNumber,
+		Street:    i.Street,
+		City:      i.City,
+		State:     i.State,
+		ZipCode:   i.ZipCode,
+		Country:   i.Country,
+		CreatedAt: timestamp(i.CreatedAt),
+		UpdatedAt: timestamp(i.UpdatedAt),
+	}
+}
+
+func toReceiver(r *models.Receiver) *pb.Receiver {
+	return &pb.Receiver{
+		Id:        r.ID,
+		Name:      r.Name,
+		VatNumber: r.VATNumber,
+		Street:    r.Street,
+		City:      r.City,
+		State:     r.State,
+		ZipCode:   r.ZipCode,
+		Country:   r.Country,
+		CreatedAt: timestamp(r.CreatedAt),
+		UpdatedAt: timestamp(r.UpdatedAt),
+	}
+}
+
+func toBillItem(i *models.BillItem) *pb.BillItem {
+	return &pb.BillItem{
+		Id:        i.ID,
+		Name:      i.Name,
+		Price:     i.Price,
+		Currency:  i.Currency,
+		CreatedAt: timestamp(i.CreatedAt),
+		UpdatedAt: timestamp(i.UpdatedAt),
+	}
+}
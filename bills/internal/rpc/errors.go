@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// notFound returns a NotFound status for the given entity
+func notFound(entity string) error {
+	return status.Error(codes.NotFound, entity+" not found")
+}
+
+// missing returns an InvalidArgument status for a request without its message
+func missing(field string) error {
+	return status.Error(codes.InvalidArgument, field+" is required")
+}
+
+// toStatus reports domain errors with their code: missing records as
+// NotFound, conflicts as FailedPrecondition and invalid input, references to
+// records of other workspaces included, as InvalidArgument and calls that ran
+// out of time as DeadlineExceeded. Other errors that
+// are not gRPC statuses are logged and reported as Internal without leaking
+// their details to the client
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var ve *models.ValidationError
+	switch {
+	case errors.As(err, &ve), errors.Is(err, repository.ErrNotInWorkspace):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "the database took too long to answer")
+	}
+
+	log.Printf("grpc: %v", err)
+	return status.Error(codes.Internal, codes.Internal.String())
+}
+
+// UnaryErrorInterceptor applies toStatus to the errors of unary RPCs
+func UnaryErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	return resp, toStatus(err)
+}
+
+// StreamErrorInterceptor applies toStatus to the errors of streaming RPCs
+func StreamErrorInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return toStatus(handler(srv, ss))
+}
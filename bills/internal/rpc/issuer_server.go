@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	pb "bills/internal/rpc/billsv1"
+	"context"
+)
+
+// IssuerServer implements the IssuerService
+type IssuerServer struct {
+	pb.UnimplementedIssuerServiceServer
+	repo repository.IssuerRepository
+}
+
+// NewIssuerServer creates a new IssuerServer instance
+func NewIssuerServer(repo repository.IssuerRepository) *IssuerServer {
+	return &IssuerServer{repo: repo}
+}
+
+// CreateIssuer stores a new issuer
+func (s *IssuerServer) CreateIssuer(ctx context.Context, req *pb.CreateIssuerRequest) (*pb.Issuer, error) {
+	in := req.GetIssuer()
+	if in == nil {
+		return nil, missing("issuer")
+	}
+
+	issuer := models.NewIssuer(in.Name, in.VatNumber, in.Street, in.City, in.State, in.ZipCode, in.Country)
+	if err := issuer.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, issuer); err != nil {
+		return nil, err
+	}
+	return toIssuer(issuer), nil
+}
+
+// GetIssuer returns a single issuer
+func (s *IssuerServer) GetIssuer(ctx context.Context, req *pb.GetIssuerRequest) (*pb.Issuer, error) {
+	issuer, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toIssuer(issuer), nil
+}
+
+// ListIssuers returns all issuers
+func (s *IssuerServer) ListIssuers(ctx context.Context, req *pb.ListIssuersRequest) (*pb.ListIssuersResponse, error) {
+	issuers, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListIssuersResponse{}
+	for _, issuer := range issuers {
+		resp.Issuers = append(resp.Issuers, toIssuer(issuer))
+	}
+	return resp, nil
+}
+
+// UpdateIssuer replaces the details of an issuer
+func (s *IssuerServer) UpdateIssuer(ctx context.Context, req *pb.UpdateIssuerRequest) (*pb.Issuer, error) {
+	in := req.GetIssuer()
+	if in == nil {
+		return nil, missing("issuer")
+	}
+
+	issuer, err := s.repo.GetByID(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer.Name = in.Name
+	issuer.VATNumber = in.VatNumber
+	issuer.Street = in.Street
+	issuer.City = in.City
+	issuer.State = in.State
+	issuer.ZipCode = in.ZipCode
+	issuer.Country = in.Country
+
+	if err := issuer.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, issuer); err != nil {
+		return nil, err
+	}
+	return toIssuer(issuer), nil
+}
+
+// DeleteIssuer moves an issuer to the trash
+func (s *IssuerServer) DeleteIssuer(ctx context.Context, req *pb.DeleteIssuerRequest) (*pb.DeleteIssuerResponse, error) {
+	issuer, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Delete(ctx, issuer.ID); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteIssuerResponse{}, nil
+}
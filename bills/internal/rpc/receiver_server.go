@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	pb "bills/internal/rpc/billsv1"
+	"context"
+)
+
+// ReceiverServer implements the ReceiverService
+type ReceiverServer struct {
+	pb.UnimplementedReceiverServiceServer
+	repo repository.ReceiverRepository
+}
+
+// NewReceiverServer creates a new ReceiverServer instance
+func NewReceiverServer(repo repository.ReceiverRepository) *ReceiverServer {
+	return &ReceiverServer{repo: repo}
+}
+
+// CreateReceiver stores a new receiver
+func (s *ReceiverServer) CreateReceiver(ctx context.Context, req *pb.CreateReceiverRequest) (*pb.Receiver, error) {
+	in := req.GetReceiver()
+	if in == nil {
+		return nil, missing("receiver")
+	}
+
+	receiver := models.NewReceiver(in.Name, in.VatNumber, in.Street, in.City, in.State, in.ZipCode, in.Country)
+	if err := receiver.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, receiver); err != nil {
+		return nil, err
+	}
+	return toReceiver(receiver), nil
+}
+
+// GetReceiver returns a single receiver
+func (s *ReceiverServer) GetReceiver(ctx context.Context, req *pb.GetReceiverRequest) (*pb.Receiver, error) {
+	receiver, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toReceiver(receiver), nil
+}
+
+// ListReceivers returns all receivers
+func (s *ReceiverServer) ListReceivers(ctx context.Context, req *pb.ListReceiversRequest) (*pb.ListReceiversResponse, error) {
+	receivers, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListReceiversResponse{}
+	for _, receiver := range receivers {
+		resp.Receivers = append(resp.Receivers, toReceiver(receiver))
+	}
+	return resp, nil
+}
+
+// UpdateReceiver replaces the details of a receiver
+func (s *ReceiverServer) UpdateReceiver(ctx context.Context, req *pb.UpdateReceiverRequest) (*pb.Receiver, error) {
+	in := req.GetReceiver()
+	if in == nil {
+		return nil, missing("receiver")
+	}
+
+	receiver, err := s.repo.GetByID(ctx, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver.Name = in.Name
+	receiver.VATNumber = in.VatNumber
+	receiver.Street = in.Street
+	receiver.City = in.City
+	receiver.State = in.State
+	receiver.ZipCode = in.ZipCode
+	receiver.Country = in.Country
+
+	if err := receiver.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, receiver); err != nil {
+		return nil, err
+	}
+	return toReceiver(receiver), nil
+}
+
+// DeleteReceiver moves a receiver to the trash
+func (s *ReceiverServer) DeleteReceiver(ctx context.Context, req *pb.DeleteReceiverRequest) (*pb.DeleteReceiverResponse, error) {
+	receiver, err := s.repo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Delete(ctx, receiver.ID); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteReceiverResponse{}, nil
+}
@@ -0,0 +1,63 @@
+// Package rpc serves the bills, parties and catalog over gRPC. The service
+// definitions live in proto/bills/v1/bills.proto and the generated code in
+// the billsv1 package, regenerate it with make proto
+package rpc
+
+import (
+	"bills/internal/auth"
+	"bills/internal/repository"
+	pb "bills/internal/rpc/billsv1"
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer creates a gRPC server with every service registered. Bills are
+// read and written through watcher so WatchBills streams their changes, the
+// watcher should be joined to uow for the changes made in transactions. Calls
+// are signed in with the API token of their AuthorizationMetadataKey metadata
+// and work in its workspace. Unary calls give up after timeout, 0 lets them
+// run as long as their client waits
+func NewServer(
+	timeout time.Duration,
+	uow repository.UnitOfWork,
+	tokens *auth.Tokens,
+	workspaces repository.WorkspaceRepository,
+	watcher *BillWatcher,
+	receiverRepo repository.ReceiverRepository,
+	issuerRepo repository.IssuerRepository,
+	billItemRepo repository.BillItemRepository,
+	billItemAssignRepo repository.BillItemAssignmentRepository,
+) *grpc.Server {
+	authorize := NewAuthInterceptor(tokens, workspaces)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryErrorInterceptor, TimeoutInterceptor(timeout), authorize.Unary),
+		grpc.ChainStreamInterceptor(StreamErrorInterceptor, authorize.Stream),
+	)
+
+	pb.RegisterBillServiceServer(server, NewBillServer(watcher, receiverRepo, issuerRepo, billItemRepo))
+	pb.RegisterBillItemAssignmentServiceServer(server, NewBillItemAssignmentServer(uow, billItemAssignRepo, watcher, billItemRepo))
+	pb.RegisterIssuerServiceServer(server, NewIssuerServer(issuerRepo))
+	pb.RegisterReceiverServiceServer(server, NewReceiverServer(receiverRepo))
+	pb.RegisterBillItemServiceServer(server, NewBillItemServer(billItemRepo))
+
+	// Lets tools such as grpcurl discover the services
+	reflection.Register(server)
+
+	return server
+}
+
+// TimeoutInterceptor cancels unary calls that take longer than timeout. Streams
+// such as WatchBills are left alone since they are meant to stay open
+func TimeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
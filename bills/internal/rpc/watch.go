@@ -0,0 +1,141 @@
+package rpc
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	pb "bills/internal/rpc/billsv1"
+	"bills/internal/tenant"
+	"context"
+	"sync"
+)
+
+// watchBuffer is the number of events a subscriber may fall behind before it
+// is dropped
+const watchBuffer = 64
+
+// BillWatcher wraps a BillRepository and publishes every bill it creates,
+// updates or deletes to the subscribers of WatchBills. Wrap the repository
+// once and hand the watcher to every handler so changes made through the
+// pages and the JSON API are streamed as well, and Join to the unit of work
+// so are the changes made in transactions. Subscribers only receive the
+// events of their own workspace
+type BillWatcher struct {
+	publishingBills
+
+	mu          sync.Mutex
+	subscribers map[chan *pb.BillEvent]int64
+}
+
+// NewBillWatcher creates a new BillWatcher around repo
+func NewBillWatcher(repo repository.BillRepository) *BillWatcher {
+	w := &BillWatcher{subscribers: make(map[chan *pb.BillEvent]int64)}
+	w.publishingBills = publishingBills{BillRepository: repo, publish: w.publish}
+	return w
+}
+
+// Join makes the bills of the unit of work tx publish their changes once it
+// committed. Hand it to repository.NewSQLiteUnitOfWork
+func (w *BillWatcher) Join(tx *repository.Tx) {
+	tx.Bills = &publishingBills{
+		BillRepository: tx.Bills,
+		publish: func(ctx context.Context, eventType pb.BillEvent_Type, bill *models.Bill) {
+			tx.AfterCommit(func() { w.publish(ctx, eventType, bill) })
+		},
+	}
+}
+
+// publishingBills wraps a BillRepository and hands every bill it creates,
+// updates or deletes to publish
+type publishingBills struct {
+	repository.BillRepository
+	publish func(ctx context.Context, eventType pb.BillEvent_Type, bill *models.Bill)
+}
+
+// Create stores the bill and publishes a created event
+func (b *publishingBills) Create(ctx context.Context, bill *models.Bill) error {
+	if err := b.BillRepository.Create(ctx, bill); err != nil {
+		return err
+	}
+	b.publish(ctx, pb.BillEvent_TYPE_CREATED, bill)
+	return nil
+}
+
+// Update stores the bill and publishes an updated event
+func (b *publishingBills) Update(ctx context.Context, bill *models.Bill) error {
+	if err := b.BillRepository.Update(ctx, bill); err != nil {
+		return err
+	}
+	b.publish(ctx, pb.BillEvent_TYPE_UPDATED, bill)
+	return nil
+}
+
+// UpdateWithItems stores the bill with its lines and publishes an updated
+// event
+func (b *publishingBills) UpdateWithItems(ctx context.Context, bill *models.Bill) error {
+	if err := b.BillRepository.UpdateWithItems(ctx, bill); err != nil {
+		return err
+	}
+	b.publish(ctx, pb.BillEvent_TYPE_UPDATED, bill)
+	return nil
+}
+
+// Delete moves the bill to the trash and publishes a deleted event carrying
+// its last known state
+func (b *publishingBills) Delete(ctx context.Context, id int64) error {
+	bill, err := b.BillRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := b.BillRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	b.publish(ctx, pb.BillEvent_TYPE_DELETED, bill)
+	return nil
+}
+
+// Subscribe registers a new subscriber to the bills of the workspace of ctx.
+// The returned channel is closed when the subscriber falls too far behind or
+// cancel is called
+func (w *BillWatcher) Subscribe(ctx context.Context) (<-chan *pb.BillEvent, func(), error) {
+	workspaceID, err := tenant.WorkspaceID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan *pb.BillEvent, watchBuffer)
+
+	w.mu.Lock()
+	w.subscribers[ch] = workspaceID
+	w.mu.Unlock()
+
+	cancel := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if _, ok := w.subscribers[ch]; ok {
+			delete(w.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, nil
+}
+
+// publish sends an event to every subscriber of the workspace of ctx without
+// blocking the write that caused it
+func (w *BillWatcher) publish(ctx context.Context, eventType pb.BillEvent_Type, bill *models.Bill) {
+	// The write succeeded so the workspace is known
+	workspaceID, _ := tenant.WorkspaceID(ctx)
+	event := &pb.BillEvent{Type: eventType, Bill: toBill(bill)}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch, subscribed := range w.subscribers {
+		if subscribed != workspaceID {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+			delete(w.subscribers, ch)
+			close(ch)
+		}
+	}
+}
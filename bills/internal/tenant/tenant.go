@@ -0,0 +1,45 @@
+// Package tenant carries the workspace a request works in and the user making
+// it through its context. Repositories read the workspace back to scope every
+// query, so data of other workspaces can never be read or changed, and the
+// user to record who made each change
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoWorkspace is returned by repositories called without a workspace in
+// their context
+var ErrNoWorkspace = errors.New("no workspace selected")
+
+type contextKey struct{}
+
+type actorKey struct{}
+
+// WithWorkspace returns a copy of ctx that works in the given workspace
+func WithWorkspace(ctx context.Context, workspaceID int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, workspaceID)
+}
+
+// WorkspaceID returns the workspace of ctx, or ErrNoWorkspace when none was
+// set
+func WorkspaceID(ctx context.Context) (int64, error) {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	if !ok || id == 0 {
+		return 0, ErrNoWorkspace
+	}
+	return id, nil
+}
+
+// WithActor returns a copy of ctx whose changes are made by the given user
+func WithActor(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorID returns the user making the changes of ctx. ok is false for changes
+// made outside a signed in request
+func ActorID(ctx context.Context) (id int64, ok bool) {
+	id, ok = ctx.Value(actorKey{}).(int64)
+	return id, ok && id != 0
+}
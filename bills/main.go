@@ -2,19 +2,29 @@ package main
 
 import (
 	"bills/db"
+	"bills/internal/api"
+	"bills/internal/auth"
 	"bills/internal/handlers"
+	"bills/internal/jobs"
+	"bills/internal/models"
+	"bills/internal/openapi"
 	"bills/internal/repository"
-	"database/sql"
+	"bills/internal/rpc"
+	"bills/internal/tenant"
+	"context"
+	"flag"
 	"html/template"
 	"io"
 	"log"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	_ "github.com/mattn/go-sqlite3"
 )
 
 // Template renderer for Echo
@@ -23,15 +33,49 @@ type Template struct {
 }
 
 func (t *Template) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	// Every page shows who is signed in and which workspace they work in
+	if m, ok := data.(map[string]interface{}); ok {
+		if _, set := m["CurrentUser"]; !set {
+			m["CurrentUser"] = auth.CurrentUser(c)
+		}
+		if _, set := m["CurrentWorkspace"]; !set {
+			m["CurrentWorkspace"] = auth.CurrentWorkspace(c)
+			m["Workspaces"] = auth.Workspaces(c)
+		}
+		// Forms show what was sent and what is wrong with it, see
+		// handlers.renderInvalid
+		if _, set := m["Errors"]; !set {
+			m["Errors"] = map[string]string{}
+		}
+		if _, set := m["Form"]; !set {
+			m["Form"] = url.Values{}
+		}
+	}
+
 	// List of partial templates that should be rendered directly
 	partials := map[string]bool{
-		"bills-list":        true,
-		"bill-items-list":   true,
-		"issuers-list":      true,
-		"receivers-list":    true,
-		"bill-items-select": true,
-		"issuers-select":    true,
-		"receivers-select":  true,
+		"bills-list":          true,
+		"bill-items-list":     true,
+		"issuers-list":        true,
+		"receivers-list":      true,
+		"bill-items-select":   true,
+		"issuers-select":      true,
+		"receivers-select":    true,
+		"users-list":          true,
+		"tokens-list":         true,
+		"audit-list":          true,
+		"trash-list":          true,
+		"backups-list":        true,
+		"search-results":      true,
+		"error-message":       true,
+		"issuer-form":         true,
+		"receiver-form":       true,
+		"bill-item-form":      true,
+		"bill-form":           true,
+		"issuer-edit-form":    true,
+		"receiver-edit-form":  true,
+		"bill-item-edit-form": true,
+		"bill-edit-form":      true,
 	}
 
 	// If it's a partial template, render it directly
@@ -49,25 +93,106 @@ func main() {
 		log.Println("No .env file found")
 	}
 
-	// Initialize database
-	dbPath := "bills.db"
-	sqlDB, err := sql.Open("sqlite3", dbPath)
+	// --demo keeps everything in memory and starts from sample data, nothing
+	// is written to disk and a restart starts over
+	demo := flag.Bool("demo", false, "Run on an in-memory store seeded with sample data")
+	flag.Parse()
+
+	// Initialize database. DATABASE_URL picks PostgreSQL with a postgres://
+	// URL, which is experimental, see db.PostgresOptIn. Anything else is the
+	// path of a SQLite database, bills.db by default
+	databaseURL := os.Getenv("DATABASE_URL")
+	if *demo {
+		databaseURL = demoDatabase
+	}
+	sqlDB, err := db.Open(databaseURL)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer sqlDB.Close()
+	if db.IsPostgres(sqlDB) {
+		log.Println("PostgreSQL support is experimental, search matches substrings without ranking")
+	}
 
 	// Run migrations
-	if err := db.MigrateDB(sqlDB, dbPath); err != nil {
+	if err := db.MigrateDB(sqlDB, databaseURL); err != nil {
+		log.Fatal(err)
+	}
+
+	// Refuse to start on a schema the models do not expect
+	if err := db.CheckSchema(sqlDB); err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize repositories for the database in use
+	repos := repository.NewRepositories(sqlDB)
+	// Bills go through the watcher so gRPC WatchBills streams every change,
+	// whichever server made it
+	billRepo := rpc.NewBillWatcher(repos.Bills)
+	receiverRepo := repos.Receivers
+	issuerRepo := repos.Issuers
+	billItemRepo := repos.BillItems
+	billItemAssignmentRepo := repos.BillItemAssignments
+	userRepo := repos.Users
+	sessionRepo := repos.Sessions
+	workspaceRepo := repos.Workspaces
+	apiTokenRepo := repos.APITokens
+	auditRepo := repos.Audit
+	trashRepo := repos.Trash
+	searchRepo := repos.Search
+
+	// Changes that span several repositories run in one transaction, bills
+	// changed in them are streamed once it committed
+	var uow repository.UnitOfWork = repository.NewUnitOfWork(sqlDB, billRepo.Join)
+
+	// The demo keeps bills and what they refer to in a memory store. Users,
+	// sessions and workspaces stay in the in-memory SQLite database, which
+	// means the audit log, the trash and search do not see the demo data
+	if *demo {
+		store := repository.NewMemoryStore()
+		billRepo = rpc.NewBillWatcher(repository.NewMemoryBillRepository(store))
+		receiverRepo = repository.NewMemoryReceiverRepository(store)
+		issuerRepo = repository.NewMemoryIssuerRepository(store)
+		billItemRepo = repository.NewMemoryBillItemRepository(store)
+		billItemAssignmentRepo = repository.NewMemoryBillItemAssignmentRepository(store)
+		uow = repository.NewMemoryUnitOfWork(store, billRepo.Join)
+
+		if err := seedDemo(context.Background(), uow, userRepo, workspaceRepo); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Drop sessions that expired while the server was down
+	if err := sessionRepo.DeleteExpired(context.Background()); err != nil {
 		log.Fatal(err)
 	}
+	sessions := auth.NewSessions(userRepo, sessionRepo, workspaceRepo)
+	tokens := auth.NewTokens(apiTokenRepo, userRepo, workspaceRepo)
 
-	// Initialize repositories
-	billRepo := repository.NewSQLiteBillRepository(sqlDB)
-	receiverRepo := repository.NewSQLiteReceiverRepository(sqlDB)
-	issuerRepo := repository.NewSQLiteIssuerRepository(sqlDB)
-	billItemRepo := repository.NewSQLiteBillItemRepository(sqlDB)
-	billItemAssignmentRepo := repository.NewSQLiteBillItemAssignmentRepository(sqlDB)
+	// Requests give up on the database after DB_TIMEOUT, 5s by default, and
+	// are answered with a 503. 0 lets them wait as long as it takes
+	dbTimeout := 5 * time.Second
+	if value := os.Getenv("DB_TIMEOUT"); value != "" {
+		dbTimeout, err = time.ParseDuration(value)
+		if err != nil || dbTimeout < 0 {
+			log.Fatalf("invalid DB_TIMEOUT %q", value)
+		}
+	}
+
+	// Snapshots of the SQLite database go to BACKUP_DIR, backups by default,
+	// and only the BACKUP_KEEP newest are kept, 7 by default. 0 keeps them all
+	backupDir := os.Getenv("BACKUP_DIR")
+	if backupDir == "" {
+		backupDir = "backups"
+	}
+	backupKeep := 7
+	if value := os.Getenv("BACKUP_KEEP"); value != "" {
+		backupKeep, err = strconv.Atoi(value)
+		if err != nil || backupKeep < 0 {
+			log.Fatalf("invalid BACKUP_KEEP %q", value)
+		}
+	}
+	backups := db.NewBackups(sqlDB, backupDir, backupKeep)
 
 	// Initialize Echo
 	e := echo.New()
@@ -75,7 +200,17 @@ func main() {
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
+	if dbTimeout > 0 {
+		e.Use(middleware.ContextTimeoutWithConfig(middleware.ContextTimeoutConfig{
+			Timeout: dbTimeout,
+			// Left to api.HTTPError so pages and API clients get the same 503
+			ErrorHandler: func(err error, c echo.Context) error { return err },
+		}))
+	}
 	e.Use(middleware.CORS())
+	e.Use(tokens.Middleware)
+	e.Use(sessions.Middleware)
+	e.Use(auth.Authorize)
 
 	// Initialize templates - each template is now a complete HTML file
 	t := &Template{
@@ -87,30 +222,73 @@ func main() {
 		}).ParseFiles(
 			"templates/bills.html",
 			"templates/bills-list.html",
+			"templates/bill.html",
 			"templates/bill-form.html",
+			"templates/bill-edit-form.html",
 			"templates/bill-items-select.html",
 			"templates/bill-items.html",
 			"templates/bill-items-list.html",
+			"templates/bill-item-form.html",
+			"templates/bill-item-edit-form.html",
 			"templates/issuers.html",
+			"templates/party.html",
 			"templates/issuers-list.html",
 			"templates/issuers-select.html",
+			"templates/issuer-form.html",
+			"templates/issuer-edit-form.html",
 			"templates/receivers.html",
 			"templates/receivers-list.html",
 			"templates/receivers-select.html",
+			"templates/receiver-form.html",
+			"templates/receiver-edit-form.html",
+			"templates/login.html",
+			"templates/user-menu.html",
+			"templates/users.html",
+			"templates/users-list.html",
+			"templates/tokens.html",
+			"templates/tokens-list.html",
+			"templates/audit.html",
+			"templates/audit-list.html",
+			"templates/trash.html",
+			"templates/trash-list.html",
+			"templates/backups.html",
+			"templates/backups-list.html",
+			"templates/search.html",
+			"templates/search-results.html",
+			"templates/error.html",
 		)),
 	}
 	e.Renderer = t
+	e.HTTPErrorHandler = handlers.ErrorHandler
 
 	// Initialize handlers
-	billHandler := handlers.NewBillHandler(billRepo, receiverRepo, issuerRepo, billItemRepo, billItemAssignmentRepo, t.templates)
-	receiverHandler := handlers.NewReceiverHandler(receiverRepo, t.templates)
-	issuerHandler := handlers.NewIssuerHandler(issuerRepo, t.templates)
-	billItemHandler := handlers.NewBillItemHandler(billItemRepo, t.templates)
+	billHandler := handlers.NewBillHandler(uow, billRepo, receiverRepo, issuerRepo, billItemRepo, auditRepo, t.templates)
+	receiverHandler := handlers.NewReceiverHandler(uow, receiverRepo, billRepo, t.templates)
+	issuerHandler := handlers.NewIssuerHandler(uow, issuerRepo, billRepo, t.templates)
+	billItemHandler := handlers.NewBillItemHandler(uow, billItemRepo, t.templates)
+	authHandler := handlers.NewAuthHandler(sessions)
+	userHandler := handlers.NewUserHandler(workspaceRepo)
+	tokenHandler := handlers.NewTokenHandler(tokens, apiTokenRepo)
+	auditHandler := handlers.NewAuditHandler(auditRepo)
+	trashHandler := handlers.NewTrashHandler(trashRepo)
+	searchHandler := handlers.NewSearchHandler(searchRepo)
+	backupHandler := handlers.NewBackupHandler(backups, uow)
+
+	// Auth routes, the login page is the only route reachable without a session
+	e.GET("/login", authHandler.RenderLogin)
+	e.POST("/login", authHandler.Login)
+	e.POST("/logout", authHandler.Logout)
+	e.POST("/workspaces/switch", authHandler.SwitchWorkspace)
 
 	// Bill routes
 	e.GET("/", billHandler.RenderBills)
 	e.POST("/bills", billHandler.CreateBill)
 	e.GET("/bills", billHandler.RenderBills)
+	e.GET("/bills/list", billHandler.GetBillsList)
+	e.GET("/bills/:id", billHandler.RenderBill)
+	e.GET("/bills/:id/lines", billHandler.GetBillLines)
+	e.GET("/bills/:id/edit", billHandler.EditBill)
+	e.PUT("/bills/:id", billHandler.UpdateBill)
 	e.POST("/bills/:id/toggle", billHandler.TogglePaid)
 	e.DELETE("/bills/:id", billHandler.DeleteBill)
 
@@ -119,6 +297,9 @@ func main() {
 	e.POST("/receivers", receiverHandler.CreateReceiver)
 	e.GET("/receivers/list", receiverHandler.GetReceiversList)
 	e.GET("/receivers/select", receiverHandler.GetReceiversSelect)
+	e.GET("/receivers/:id", receiverHandler.RenderReceiver)
+	e.GET("/receivers/:id/edit", receiverHandler.EditReceiver)
+	e.PUT("/receivers/:id", receiverHandler.UpdateReceiver)
 	e.DELETE("/receivers/:id", receiverHandler.DeleteReceiver)
 
 	// Issuer routes
@@ -126,6 +307,9 @@ func main() {
 	e.POST("/issuers", issuerHandler.CreateIssuer)
 	e.GET("/issuers/list", issuerHandler.GetIssuersList)
 	e.GET("/issuers/select", issuerHandler.GetIssuersSelect)
+	e.GET("/issuers/:id", issuerHandler.RenderIssuer)
+	e.GET("/issuers/:id/edit", issuerHandler.EditIssuer)
+	e.PUT("/issuers/:id", issuerHandler.UpdateIssuer)
 	e.DELETE("/issuers/:id", issuerHandler.DeleteIssuer)
 
 	// Bill Item routes
@@ -133,8 +317,106 @@ func main() {
 	e.POST("/bill-items", billItemHandler.CreateBillItem)
 	e.GET("/bill-items/list", billItemHandler.GetBillItemsList)
 	e.GET("/bill-items/select", billItemHandler.GetBillItemsSelect)
+	e.GET("/bill-items/:id/edit", billItemHandler.EditBillItem)
+	e.PUT("/bill-items/:id", billItemHandler.UpdateBillItem)
 	e.DELETE("/bill-items/:id", billItemHandler.DeleteBillItem)
 
+	// Search routes
+	e.GET("/search", searchHandler.RenderSearch)
+	e.GET("/search/results", searchHandler.GetSearchResults)
+
+	// Admin routes
+	e.GET("/admin/users", userHandler.RenderUsers)
+	e.POST("/admin/users/:id/role", userHandler.UpdateRole)
+	e.GET("/audit", auditHandler.RenderAudit)
+	e.GET("/audit/list", auditHandler.GetAuditList)
+	e.GET("/trash", trashHandler.RenderTrash)
+	e.POST("/trash/:entity/:id/restore", trashHandler.RestoreItem)
+	e.DELETE("/trash/:entity/:id", trashHandler.PurgeItem)
+	e.GET("/admin/backups", backupHandler.RenderBackups)
+	e.POST("/admin/backups", backupHandler.CreateBackup)
+	e.GET("/admin/backups/:name", backupHandler.DownloadBackup)
+	e.GET("/admin/archive", backupHandler.DownloadArchive)
+
+	// API token routes
+	e.GET("/account/tokens", tokenHandler.RenderTokens)
+	e.POST("/account/tokens", tokenHandler.CreateToken)
+	e.DELETE("/account/tokens/:id", tokenHandler.RevokeToken)
+
+	// JSON API routes
+	billAPI := api.NewBillHandler(billRepo, receiverRepo, issuerRepo, billItemRepo)
+	issuerAPI := api.NewIssuerHandler(issuerRepo)
+	receiverAPI := api.NewReceiverHandler(receiverRepo)
+	billItemAPI := api.NewBillItemHandler(billItemRepo)
+	assignmentAPI := api.NewBillItemAssignmentHandler(uow, billItemAssignmentRepo, billRepo, billItemRepo)
+
+	v1 := e.Group("/api/v1", api.ErrorMiddleware)
+	v1.GET("/bills", billAPI.List)
+	v1.POST("/bills", billAPI.Create)
+	v1.GET("/bills/:id", billAPI.Get)
+	v1.PUT("/bills/:id", billAPI.Update)
+	v1.DELETE("/bills/:id", billAPI.Delete)
+	v1.GET("/bills/:id/items", assignmentAPI.List)
+	v1.POST("/bills/:id/items", assignmentAPI.Create)
+	v1.GET("/assignments/:id", assignmentAPI.Get)
+	v1.PUT("/assignments/:id", assignmentAPI.Update)
+	v1.DELETE("/assignments/:id", assignmentAPI.Delete)
+	v1.GET("/issuers", issuerAPI.List)
+	v1.POST("/issuers", issuerAPI.Create)
+	v1.GET("/issuers/:id", issuerAPI.Get)
+	v1.PUT("/issuers/:id", issuerAPI.Update)
+	v1.DELETE("/issuers/:id", issuerAPI.Delete)
+	v1.GET("/receivers", receiverAPI.List)
+	v1.POST("/receivers", receiverAPI.Create)
+	v1.GET("/receivers/:id", receiverAPI.Get)
+	v1.PUT("/receivers/:id", receiverAPI.Update)
+	v1.DELETE("/receivers/:id", receiverAPI.Delete)
+	v1.GET("/bill-items", billItemAPI.List)
+	v1.POST("/bill-items", billItemAPI.Create)
+	v1.GET("/bill-items/:id", billItemAPI.Get)
+	v1.PUT("/bill-items/:id", billItemAPI.Update)
+	v1.DELETE("/bill-items/:id", billItemAPI.Delete)
+
+	// API description
+	e.GET("/openapi.json", openapi.Handler(e))
+	e.GET("/api/docs", openapi.Explorer)
+
+	// Refuse to start with a route that no permission guards
+	if missing := auth.Unguarded(e.Routes()); len(missing) > 0 {
+		log.Fatalf("routes without a permission in auth.Permissions: %v", missing)
+	}
+
+	// Trashed rows are purged after TRASH_RETENTION_DAYS, 30 by default. 0
+	// keeps them until they are purged by hand
+	retentionDays := 30
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		retentionDays, err = strconv.Atoi(value)
+		if err != nil || retentionDays < 0 {
+			log.Fatalf("invalid TRASH_RETENTION_DAYS %q", value)
+		}
+	}
+	if retentionDays > 0 {
+		retention := time.Duration(retentionDays) * 24 * time.Hour
+		go jobs.PurgeTrash(context.Background(), trashRepo, retention, time.Hour)
+	}
+
+	// Start the gRPC server on its own port. Calls sign in with an API token
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+	lis, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+	grpcServer := rpc.NewServer(dbTimeout, uow, tokens, workspaceRepo, billRepo, receiverRepo, issuerRepo, billItemRepo, billItemAssignmentRepo)
+	if missing := rpc.Unguarded(grpcServer); len(missing) > 0 {
+		log.Fatalf("gRPC methods without a permission in rpc.Permissions: %v", missing)
+	}
+	go func() {
+		log.Fatal(grpcServer.Serve(lis))
+	}()
+
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -142,3 +424,77 @@ func main() {
 	}
 	e.Logger.Fatal(e.Start(":" + port))
 }
+
+// demoDatabase is the SQLite database of --demo, it lives as long as the
+// server keeps a connection to it open
+const demoDatabase = "file:demo?mode=memory&cache=shared"
+
+// Sign in to the demo with these
+const (
+	demoUsername = "demo"
+	demoPassword = "demo1234"
+)
+
+// seedDemo fills the default workspace with a few parties, items and bills
+// and creates an admin to sign in with
+func seedDemo(ctx context.Context, uow repository.UnitOfWork, userRepo repository.UserRepository, workspaceRepo repository.WorkspaceRepository) error {
+	ctx = tenant.WithWorkspace(ctx, models.DefaultWorkspaceID)
+
+	err := uow.Do(ctx, func(tx *repository.Tx) error {
+		issuer := models.NewIssuer("Tech Solutions Inc.", "US123456789", "123 Silicon Valley", "San Francisco", "CA", "94105", "USA")
+		if err := tx.Issuers.Create(ctx, issuer); err != nil {
+			return err
+		}
+
+		receivers := []*models.Receiver{
+			models.NewReceiver("Global Corp", "UK789123456", "321 Business Ave", "London", "England", "EC1A 1BB", "UK"),
+			models.NewReceiver("Nordic Innovations AS", "NO123789456", "654 Innovation Road", "Oslo", "Oslo", "0150", "Norway"),
+		}
+		for _, receiver := range receivers {
+			if err := tx.Receivers.Create(ctx, receiver); err != nil {
+				return err
+			}
+		}
+
+		items := []*models.BillItem{
+			models.NewBillItem("Software Development Services", 150.00, "EUR"),
+			models.NewBillItem("Cloud Hosting", 80.00, "EUR"),
+			models.NewBillItem("Technical Support", 90.00, "EUR"),
+		}
+		for _, item := range items {
+			if err := tx.BillItems.Create(ctx, item); err != nil {
+				return err
+			}
+		}
+
+		// One bill that is overdue and one due next month
+		for i, receiver := range receivers {
+			bill := models.NewBill(time.Now().AddDate(0, i*2-1, 0), issuer.ID, receiver.ID)
+			bill.Items = []*models.BillItemAssignment{
+				models.NewBillItemAssignment(0, items[0].ID, 10*(i+1), items[0].Price, items[0].Currency, 1.0),
+				models.NewBillItemAssignment(0, items[1+i].ID, 1, items[1+i].Price, items[1+i].Currency, 1.0),
+			}
+			bill.CalculateTotals()
+			if err := tx.Bills.Create(ctx, bill); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	user, err := models.NewUser(demoUsername, demoPassword)
+	if err != nil {
+		return err
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+	if err := workspaceRepo.AddMember(ctx, models.DefaultWorkspaceID, user.ID, models.RoleAdmin); err != nil {
+		return err
+	}
+	log.Printf("Demo mode, sign in as %s with password %s", demoUsername, demoPassword)
+	return nil
+}
@@ -0,0 +1,280 @@
+package api_test
+
+import (
+	"bills/internal/api"
+	"bills/internal/auth"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func setupServer(sqlDB *sql.DB) *echo.Echo {
+	repos := repository.NewRepositories(sqlDB)
+	billRepo := repos.Bills
+	receiverRepo := repos.Receivers
+	issuerRepo := repos.Issuers
+	billItemRepo := repos.BillItems
+	assignmentRepo := repos.BillItemAssignments
+
+	billAPI := api.NewBillHandler(billRepo, receiverRepo, issuerRepo, billItemRepo)
+	issuerAPI := api.NewIssuerHandler(issuerRepo)
+	assignmentAPI := api.NewBillItemAssignmentHandler(repository.NewUnitOfWork(sqlDB), assignmentRepo, billRepo, billItemRepo)
+
+	e := echo.New()
+	e.Use(inDefaultWorkspace)
+	v1 := e.Group("/api/v1", api.ErrorMiddleware)
+	v1.POST("/bills", billAPI.Create)
+	v1.GET("/bills/:id", billAPI.Get)
+	v1.PUT("/bills/:id", billAPI.Update)
+	v1.DELETE("/bills/:id", billAPI.Delete)
+	v1.POST("/bills/:id/items", assignmentAPI.Create)
+	v1.POST("/issuers", issuerAPI.Create)
+	v1.GET("/issuers/:id", issuerAPI.Get)
+	return e
+}
+
+// inDefaultWorkspace stands in for the session middleware, which picks the
+// workspace of signed in users
+func inDefaultWorkspace(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+		return next(c)
+	}
+}
+
+func doJSON(e *echo.Echo, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func createTestData(t *testing.T, db *sql.DB) (int64, int64, int64) {
+	repos := repository.NewRepositories(db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	issuer := models.NewIssuer("Test Issuer", "123456", "123 Street", "City", "State", "12345", "Country")
+	if err := repos.Issuers.Create(ctx, issuer); err != nil {
+		t.Fatalf("Failed to create test issuer: %v", err)
+	}
+
+	receiver := models.NewReceiver("Test Receiver", "654321", "321 Street", "City", "State", "54321", "Country")
+	if err := repos.Receivers.Create(ctx, receiver); err != nil {
+		t.Fatalf("Failed to create test receiver: %v", err)
+	}
+
+	billItem := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
+	if err := repos.BillItems.Create(ctx, billItem); err != nil {
+		t.Fatalf("Failed to create test bill item: %v", err)
+	}
+
+	return issuer.ID, receiver.ID, billItem.ID
+}
+
+func TestBillAPI(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+
+	e := setupServer(db)
+	issuerID, receiverID, itemID := createTestData(t, db)
+
+	var billID int64
+
+	t.Run("Create", func(t *testing.T) {
+		body := fmt.Sprintf(`{
+			"due_date": "2025-03-01T00:00:00Z",
+			"issuer_id": %d,
+			"receiver_id": %d,
+			"items": [{"item_id": %d, "quantity": 2, "price": 100, "currency": "EUR"}]
+		}`, issuerID, receiverID, itemID)
+
+		rec := doJSON(e, http.MethodPost, "/api/v1/bills", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var bill models.Bill
+		if err := json.Unmarshal(rec.Body.Bytes(), &bill); err != nil {
+			t.Fatalf("Failed to decode bill: %v", err)
+		}
+		if bill.ID == 0 {
+			t.Error("Expected bill ID to be set")
+		}
+		if bill.EURTotal != 200.00 {
+			t.Errorf("Expected EUR total 200.00, got %.2f", bill.EURTotal)
+		}
+		billID = bill.ID
+	})
+
+	t.Run("Add item recalculates totals", func(t *testing.T) {
+		body := fmt.Sprintf(`{"item_id": %d, "quantity": 1, "price": 50, "currency": "EUR"}`, itemID)
+		rec := doJSON(e, http.MethodPost, fmt.Sprintf("/api/v1/bills/%d/items", billID), body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		rec = doJSON(e, http.MethodGet, fmt.Sprintf("/api/v1/bills/%d", billID), "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+
+		var bill models.Bill
+		if err := json.Unmarshal(rec.Body.Bytes(), &bill); err != nil {
+			t.Fatalf("Failed to decode bill: %v", err)
+		}
+		if len(bill.Items) != 2 {
+			t.Errorf("Expected 2 items, got %d", len(bill.Items))
+		}
+		if bill.EURTotal != 250.00 {
+			t.Errorf("Expected EUR total 250.00, got %.2f", bill.EURTotal)
+		}
+		if bill.Issuer == nil || bill.Issuer.ID != issuerID {
+			t.Error("Expected issuer to be loaded")
+		}
+	})
+
+	t.Run("Unknown issuer is rejected", func(t *testing.T) {
+		body := fmt.Sprintf(`{"due_date": "2025-03-01T00:00:00Z", "issuer_id": 9999, "receiver_id": %d, "items": [{"item_id": %d, "quantity": 1, "price": 10}]}`, receiverID, itemID)
+		rec := doJSON(e, http.MethodPost, "/api/v1/bills", body)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Field errors are listed", func(t *testing.T) {
+		body := fmt.Sprintf(`{"issue_date": "2025-03-10T00:00:00Z", "due_date": "2025-03-01T00:00:00Z", "issuer_id": %d, "receiver_id": %d}`, issuerID, receiverID)
+		rec := doJSON(e, http.MethodPost, "/api/v1/bills", body)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d", rec.Code)
+		}
+
+		var resp api.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode error: %v", err)
+		}
+		want := map[string]string{
+			"due_date": "must not be before the issue date",
+			"items":    "must have at least one line",
+		}
+		if len(resp.Error.Fields) != len(want) {
+			t.Errorf("Expected fields %v, got %v", want, resp.Error.Fields)
+		}
+		for field, message := range want {
+			if resp.Error.Fields[field] != message {
+				t.Errorf("%s = %q, want %q", field, resp.Error.Fields[field], message)
+			}
+		}
+	})
+
+	t.Run("Update keeps the paid status when left out", func(t *testing.T) {
+		update := func(paid string) models.Bill {
+			t.Helper()
+			body := fmt.Sprintf(`{"due_date": "2025-04-01T00:00:00Z", "issuer_id": %d, "receiver_id": %d%s}`, issuerID, receiverID, paid)
+			rec := doJSON(e, http.MethodPut, fmt.Sprintf("/api/v1/bills/%d", billID), body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var bill models.Bill
+			if err := json.Unmarshal(rec.Body.Bytes(), &bill); err != nil {
+				t.Fatalf("Failed to decode bill: %v", err)
+			}
+			return bill
+		}
+
+		if bill := update(`, "paid": true`); !bill.Paid {
+			t.Error("Expected the bill to be paid")
+		}
+		if bill := update(""); !bill.Paid {
+			t.Error("Expected the bill to stay paid")
+		}
+		if bill := update(`, "paid": false`); bill.Paid {
+			t.Error("Expected the bill to be unpaid")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		rec := doJSON(e, http.MethodDelete, fmt.Sprintf("/api/v1/bills/%d", billID), "")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", rec.Code)
+		}
+
+		rec = doJSON(e, http.MethodGet, fmt.Sprintf("/api/v1/bills/%d", billID), "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rec.Code)
+		}
+	})
+}
+
+func TestAPIErrors(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+
+	e := setupServer(db)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Missing bill",
+			method:     http.MethodGet,
+			path:       "/api/v1/bills/424242",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid id",
+			method:     http.MethodGet,
+			path:       "/api/v1/issuers/abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Malformed body",
+			method:     http.MethodPost,
+			path:       "/api/v1/issuers",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Empty issuer name",
+			method:     http.MethodPost,
+			path:       "/api/v1/issuers",
+			body:       `{"name": "  "}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doJSON(e, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			var resp api.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode error body: %v", err)
+			}
+			if resp.Error.Status != tt.wantStatus {
+				t.Errorf("Expected error status %d, got %d", tt.wantStatus, resp.Error.Status)
+			}
+			if resp.Error.Message == "" {
+				t.Error("Expected error message to be set")
+			}
+		})
+	}
+}
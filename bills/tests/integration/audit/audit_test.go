@@ -0,0 +1,295 @@
+package audit_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/fixture"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func createUser(t *testing.T, sqlDB *sql.DB, username string) *models.User {
+	user, err := models.NewUser(username, "correct horse")
+	if err != nil {
+		t.Fatalf("Failed to build user: %v", err)
+	}
+	if err := repository.NewRepositories(sqlDB).Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	return user
+}
+
+// events lists the events of one row, oldest first
+func events(t *testing.T, repo repository.AuditRepository, ctx context.Context, entity string, id int64) []*models.AuditEvent {
+	list, err := repo.List(ctx, models.AuditFilter{Entity: entity, EntityID: id})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return list
+}
+
+func TestRepositoryWritesAreAudited(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	repos := repository.NewRepositories(sqlDB)
+
+	alice := createUser(t, sqlDB, "alice")
+	ctx := tenant.WithActor(tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID), alice.ID)
+	audit := repos.Audit
+	issuers := repos.Issuers
+	receivers := repos.Receivers
+	items := repos.BillItems
+	bills := repos.Bills
+	trash := repos.Trash
+
+	t.Run("Receiver lifecycle", func(t *testing.T) {
+		receiver := models.NewReceiver("ACME", "VAT1", "Street", "City", "State", "1000", "Country")
+		if err := receivers.Create(ctx, receiver); err != nil {
+			t.Fatal(err)
+		}
+		receiver.Name = "ACME Corp"
+		if err := receivers.Update(ctx, receiver); err != nil {
+			t.Fatal(err)
+		}
+		if err := receivers.Delete(ctx, receiver.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		got := events(t, audit, ctx, models.EntityReceivers, receiver.ID)
+		if len(got) != 3 {
+			t.Fatalf("Expected 3 events, got %d", len(got))
+		}
+		for i, action := range []models.AuditAction{models.AuditCreate, models.AuditUpdate, models.AuditDelete} {
+			if got[i].Action != action {
+				t.Errorf("Event %d: expected %s, got %s", i, action, got[i].Action)
+			}
+			if got[i].ActorID == nil || *got[i].ActorID != alice.ID || got[i].ActorName != "alice" {
+				t.Errorf("Event %d: expected alice as actor, got %v %q", i, got[i].ActorID, got[i].ActorName)
+			}
+		}
+		if got[0].Before != nil || got[0].After == nil {
+			t.Error("Expected a create to only have an after state")
+		}
+		if !changed(t, got[2], "deleted_at") {
+			t.Error("Expected a delete to move the receiver to the trash")
+		}
+
+		changes, err := got[1].Changes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var renamed bool
+		for _, change := range changes {
+			if change.Field == "name" {
+				renamed = change.Before == "ACME" && change.After == "ACME Corp"
+			}
+			if change.Field == "vat_number" {
+				t.Error("Expected unchanged fields to be left out")
+			}
+		}
+		if !renamed {
+			t.Errorf("Expected the rename in the changes, got %+v", changes)
+		}
+	})
+
+	t.Run("Bill with lines", func(t *testing.T) {
+		issuer := models.NewIssuer("Issuer", "VAT2", "Street", "City", "State", "1000", "Country")
+		if err := issuers.Create(ctx, issuer); err != nil {
+			t.Fatal(err)
+		}
+		receiver := models.NewReceiver("Receiver", "VAT3", "Street", "City", "State", "1000", "Country")
+		if err := receivers.Create(ctx, receiver); err != nil {
+			t.Fatal(err)
+		}
+		item := models.NewBillItem("Hosting", 10, models.DefaultCurrency())
+		if err := items.Create(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+
+		bill := models.NewBill(time.Now(), issuer.ID, receiver.ID)
+		bill.Items = append(bill.Items, models.NewBillItemAssignment(0, item.ID, 3, 10, models.DefaultCurrency(), 1))
+		bill.CalculateTotals()
+		if err := bills.Create(ctx, bill); err != nil {
+			t.Fatal(err)
+		}
+		line := bill.Items[0]
+
+		bill.Paid = true
+		if err := bills.Update(ctx, bill); err != nil {
+			t.Fatal(err)
+		}
+		if err := bills.Delete(ctx, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := trash.Purge(ctx, models.EntityBills, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		billEvents := events(t, audit, ctx, models.EntityBills, bill.ID)
+		if len(billEvents) != 4 || billEvents[3].Action != models.AuditPurge || billEvents[3].After != nil {
+			t.Fatalf("Expected the bill to be created, updated, trashed and purged, got %d events", len(billEvents))
+		}
+		lineEvents := events(t, audit, ctx, models.EntityBillLines, line.ID)
+		if len(lineEvents) != 2 || lineEvents[0].Action != models.AuditCreate || lineEvents[1].Action != models.AuditDelete {
+			t.Fatalf("Expected the line to be created and deleted, got %d events", len(lineEvents))
+		}
+
+		changes, err := lineEvents[1].Changes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, change := range changes {
+			if change.Field == "quantity" && change.Before != 3.0 {
+				t.Errorf("Expected the deleted line to record its quantity, got %v", change.Before)
+			}
+		}
+
+		history, err := audit.List(ctx, models.AuditFilter{BillID: bill.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != len(billEvents)+len(lineEvents) {
+			t.Errorf("Expected the history of the bill to include its deleted line, got %d events", len(history))
+		}
+	})
+
+	t.Run("Failed writes leave no event", func(t *testing.T) {
+		before, err := audit.List(ctx, models.AuditFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bill := models.NewBill(time.Now(), 999, 999)
+		if err := bills.Create(ctx, bill); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Fatalf("Expected ErrNotInWorkspace, got %v", err)
+		}
+
+		after, err := audit.List(ctx, models.AuditFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(after) != len(before) {
+			t.Errorf("Expected no new events, got %d", len(after)-len(before))
+		}
+	})
+
+	t.Run("Changes without a user", func(t *testing.T) {
+		system := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+		item := models.NewBillItem("Seeded", 1, models.DefaultCurrency())
+		if err := items.Create(system, item); err != nil {
+			t.Fatal(err)
+		}
+		got := events(t, audit, ctx, models.EntityBillItems, item.ID)
+		if len(got) != 1 || got[0].ActorID != nil {
+			t.Errorf("Expected one event without an actor, got %+v", got)
+		}
+	})
+
+	t.Run("Filter by user", func(t *testing.T) {
+		bob := createUser(t, sqlDB, "bob")
+		bobCtx := tenant.WithActor(tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID), bob.ID)
+		if err := items.Create(bobCtx, models.NewBillItem("Bob's item", 1, models.DefaultCurrency())); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := audit.List(ctx, models.AuditFilter{ActorID: bob.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].ActorName != "bob" {
+			t.Errorf("Expected the one event of bob, got %d", len(got))
+		}
+
+		actors, err := audit.GetActors(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actors) != 2 {
+			t.Errorf("Expected alice and bob as actors, got %d", len(actors))
+		}
+	})
+
+	t.Run("Events are append-only", func(t *testing.T) {
+		if _, err := sqlDB.Exec("UPDATE audit_events SET action = 'create'"); err == nil {
+			t.Error("Expected updates to be refused")
+		}
+		if _, err := sqlDB.Exec("DELETE FROM audit_events"); err == nil {
+			t.Error("Expected deletes to be refused")
+		}
+	})
+}
+
+// changed reports whether the event changed the field
+func changed(t *testing.T, event *models.AuditEvent, field string) bool {
+	changes, err := event.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range changes {
+		if change.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAuditPage(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	repos := repository.NewRepositories(sqlDB)
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	if err := repos.Issuers.Create(ctx, models.NewIssuer("Issuer", "VAT", "Street", "City", "State", "1000", "Country")); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer := &fixture.Renderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	handler := handlers.NewAuditHandler(repos.Audit)
+
+	get := func(target string, h echo.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+		if err := h(c); err != nil {
+			e.HTTPErrorHandler(err, c)
+		}
+		return rec
+	}
+
+	rec := get("/audit?entity=issuers", handler.RenderAudit)
+	if rec.Code != http.StatusOK || renderer.Name != "audit.html" {
+		t.Fatalf("Expected the audit page, got %d %s", rec.Code, renderer.Name)
+	}
+	if got := renderer.Data["Events"].([]*models.AuditEvent); len(got) == 0 {
+		t.Error("Expected the issuer event")
+	}
+
+	get("/audit/list?entity=receivers", handler.GetAuditList)
+	if renderer.Name != "audit-list" {
+		t.Errorf("Expected the list partial, got %s", renderer.Name)
+	}
+	if got := renderer.Data["Events"].([]*models.AuditEvent); len(got) != 0 {
+		t.Errorf("Expected no receiver events, got %d", len(got))
+	}
+
+	if rec := get("/audit?entity=users", handler.RenderAudit); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown entity, got %d", rec.Code)
+	}
+}
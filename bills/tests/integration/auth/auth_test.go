@@ -0,0 +1,500 @@
+package auth_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/tests/integration/routes"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// nameRenderer renders the template name and the signed in user instead of
+// the real templates, followed by the users listed on the users page
+type nameRenderer struct{}
+
+func (nameRenderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	user := "anonymous"
+	if u := auth.CurrentUser(c); u != nil {
+		user = u.Username
+	}
+	if _, err := fmt.Fprintf(w, "%s as %s", name, user); err != nil {
+		return err
+	}
+	values, _ := data.(map[string]interface{})
+	users, _ := values["Users"].([]*models.User)
+	for _, u := range users {
+		if _, err := fmt.Fprintf(w, "\n%s %s", u.Username, u.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setupServer(t *testing.T, sqlDB *sql.DB) (*echo.Echo, *auth.Sessions) {
+	repos := repository.NewRepositories(sqlDB)
+	sessions := auth.NewSessions(
+		repos.Users,
+		repos.Sessions,
+		repos.Workspaces,
+	)
+	tokens := newTokens(sqlDB)
+	authHandler := handlers.NewAuthHandler(sessions)
+	userHandler := handlers.NewUserHandler(repos.Workspaces)
+	tokenHandler := handlers.NewTokenHandler(tokens, repos.APITokens)
+
+	e := echo.New()
+	e.Renderer = nameRenderer{}
+	e.Use(tokens.Middleware)
+	e.Use(sessions.Middleware)
+	e.Use(auth.Authorize)
+
+	e.GET("/login", authHandler.RenderLogin)
+	e.POST("/login", authHandler.Login)
+	e.POST("/logout", authHandler.Logout)
+	e.GET("/bills", func(c echo.Context) error {
+		return c.Render(http.StatusOK, "bills.html", nil)
+	})
+	e.DELETE("/bills/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.POST("/bills/:id/toggle", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.DELETE("/issuers/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/admin/users", userHandler.RenderUsers)
+	e.POST("/admin/users/:id/role", userHandler.UpdateRole)
+	e.GET("/account/tokens", tokenHandler.RenderTokens)
+	e.POST("/account/tokens", tokenHandler.CreateToken)
+	e.DELETE("/account/tokens/:id", tokenHandler.RevokeToken)
+	e.GET("/api/v1/bills", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []string{})
+	})
+	e.POST("/api/v1/bills", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	return e, sessions
+}
+
+func createUser(t *testing.T, sqlDB *sql.DB, username, password string, role models.Role) *models.User {
+	repos := repository.NewRepositories(sqlDB)
+	user, err := models.NewUser(username, password)
+	if err != nil {
+		t.Fatalf("Failed to build user: %v", err)
+	}
+	if err := repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err := repos.Workspaces.AddMember(context.Background(), models.DefaultWorkspaceID, user.ID, role); err != nil {
+		t.Fatalf("Failed to add user to workspace: %v", err)
+	}
+	return user
+}
+
+func do(e *echo.Echo, req *http.Request, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func login(e *echo.Echo, username, password, next string) *httptest.ResponseRecorder {
+	form := url.Values{"username": {username}, "password": {password}, "next": {next}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	return do(e, req)
+}
+
+func sessionCookie(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == auth.CookieName {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	e, _ := setupServer(t, sqlDB)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		headers  map[string]string
+		status   int
+		location string
+	}{
+		{
+			name:     "Browser is redirected to login",
+			method:   http.MethodGet,
+			path:     "/bills",
+			status:   http.StatusSeeOther,
+			location: "/login?next=%2Fbills",
+		},
+		{
+			name:   "HTMX request is told to redirect",
+			method: http.MethodDelete,
+			path:   "/bills/1",
+			headers: map[string]string{
+				"HX-Request": "true",
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "API client gets a JSON error",
+			method: http.MethodGet,
+			path:   "/api/v1/bills",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "Login page is public",
+			method: http.MethodGet,
+			path:   "/login",
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := do(e, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+			if tt.location != "" && rec.Header().Get(echo.HeaderLocation) != tt.location {
+				t.Errorf("Expected redirect to %q, got %q", tt.location, rec.Header().Get(echo.HeaderLocation))
+			}
+			if tt.headers["HX-Request"] != "" && rec.Header().Get("HX-Redirect") == "" {
+				t.Error("Expected HX-Redirect header to be set")
+			}
+		})
+	}
+}
+
+func TestLoginLogout(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	e, _ := setupServer(t, sqlDB)
+	createUser(t, sqlDB, "alice", "correct horse", models.RoleViewer)
+
+	t.Run("Wrong password", func(t *testing.T) {
+		rec := login(e, "alice", "wrong password", "/bills")
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", rec.Code)
+		}
+		if sessionCookie(rec) != nil {
+			t.Error("Expected no session cookie")
+		}
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		rec := login(e, "mallory", "correct horse", "/bills")
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Open redirect is ignored", func(t *testing.T) {
+		rec := login(e, "alice", "correct horse", "//evil.example.com")
+		if rec.Header().Get(echo.HeaderLocation) != "/" {
+			t.Errorf("Expected redirect to /, got %q", rec.Header().Get(echo.HeaderLocation))
+		}
+	})
+
+	rec := login(e, "alice", "correct horse", "/bills")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get(echo.HeaderLocation) != "/bills" {
+		t.Fatalf("Expected redirect to /bills, got %d %q", rec.Code, rec.Header().Get(echo.HeaderLocation))
+	}
+
+	cookie := sessionCookie(rec)
+	if cookie == nil {
+		t.Fatal("Expected a session cookie")
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("Expected an HttpOnly SameSite=Lax cookie, got %+v", cookie)
+	}
+
+	var stored int
+	if err := sqlDB.QueryRow(testdb.Rebind("SELECT COUNT(*) FROM sessions WHERE token_hash = ?"), cookie.Value).Scan(&stored); err != nil {
+		t.Fatalf("Failed to query sessions: %v", err)
+	}
+	if stored != 0 {
+		t.Error("Expected the raw token not to be stored")
+	}
+
+	page := do(e, httptest.NewRequest(http.MethodGet, "/bills", nil), cookie)
+	if page.Code != http.StatusOK || page.Body.String() != "bills.html as alice" {
+		t.Fatalf("Expected the bills page as alice, got %d %q", page.Code, page.Body.String())
+	}
+
+	out := do(e, httptest.NewRequest(http.MethodPost, "/logout", nil), cookie)
+	if out.Code != http.StatusSeeOther {
+		t.Fatalf("Expected redirect after logout, got %d", out.Code)
+	}
+
+	page = do(e, httptest.NewRequest(http.MethodGet, "/bills", nil), cookie)
+	if page.Code != http.StatusSeeOther {
+		t.Errorf("Expected the old cookie to stop working after logout, got %d", page.Code)
+	}
+}
+
+func TestExpiredSession(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	e, sessions := setupServer(t, sqlDB)
+	createUser(t, sqlDB, "bob", "correct horse", models.RoleViewer)
+
+	sessions.TTL = -time.Minute
+	cookie := sessionCookie(login(e, "bob", "correct horse", "/bills"))
+	if cookie == nil {
+		t.Fatal("Expected a session cookie")
+	}
+
+	rec := do(e, httptest.NewRequest(http.MethodGet, "/bills", nil), cookie)
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("Expected an expired session to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	e, _ := setupServer(t, sqlDB)
+
+	cookies := map[models.Role]*http.Cookie{}
+	for _, role := range models.Roles() {
+		createUser(t, sqlDB, string(role), "correct horse", role)
+		cookies[role] = sessionCookie(login(e, string(role), "correct horse", "/"))
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		allow  map[models.Role]bool
+	}{
+		{http.MethodGet, "/bills", map[models.Role]bool{models.RoleAdmin: true, models.RoleAccountant: true, models.RoleViewer: true}},
+		{http.MethodPost, "/bills/1/toggle", map[models.Role]bool{models.RoleAdmin: true, models.RoleAccountant: true}},
+		{http.MethodDelete, "/bills/1", map[models.Role]bool{models.RoleAdmin: true}},
+		{http.MethodDelete, "/issuers/1", map[models.Role]bool{models.RoleAdmin: true}},
+		{http.MethodGet, "/admin/users", map[models.Role]bool{models.RoleAdmin: true}},
+	}
+
+	for _, tt := range tests {
+		for _, role := range models.Roles() {
+			t.Run(fmt.Sprintf("%s %s as %s", tt.method, tt.path, role), func(t *testing.T) {
+				rec := do(e, httptest.NewRequest(tt.method, tt.path, nil), cookies[role])
+
+				forbidden := rec.Code == http.StatusForbidden
+				if tt.allow[role] == forbidden {
+					t.Errorf("Expected allowed=%v, got status %d", tt.allow[role], rec.Code)
+				}
+			})
+		}
+	}
+
+	t.Run("API clients get a JSON error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/bills/1", nil)
+		req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+		rec := do(e, req, cookies[models.RoleViewer])
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `"status":403`) {
+			t.Errorf("Expected a JSON 403, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestUpdateRole(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	e, _ := setupServer(t, sqlDB)
+	admin := createUser(t, sqlDB, "root", "correct horse", models.RoleAdmin)
+	viewer := createUser(t, sqlDB, "carol", "correct horse", models.RoleViewer)
+	cookie := sessionCookie(login(e, "root", "correct horse", "/"))
+	workspaces := repository.NewRepositories(sqlDB).Workspaces
+
+	setRole := func(id int64, role string) *httptest.ResponseRecorder {
+		form := url.Values{"role": {role}}
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/users/%d/role", id), strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.Header.Set("HX-Request", "true")
+		return do(e, req, cookie)
+	}
+
+	t.Run("Promote", func(t *testing.T) {
+		if rec := setRole(viewer.ID, "accountant"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+		role, _ := workspaces.GetRole(context.Background(), models.DefaultWorkspaceID, viewer.ID)
+		if role != models.RoleAccountant {
+			t.Errorf("Expected role accountant, got %s", role)
+		}
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		if rec := setRole(viewer.ID, "owner"); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Last admin stays admin", func(t *testing.T) {
+		setRole(admin.ID, "viewer")
+		role, _ := workspaces.GetRole(context.Background(), models.DefaultWorkspaceID, admin.ID)
+		if role != models.RoleAdmin {
+			t.Errorf("Expected the last admin to keep the admin role, got %s", role)
+		}
+	})
+}
+
+func TestRolesStayInTheirWorkspace(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	e, _ := setupServer(t, sqlDB)
+	workspaces := repository.NewRepositories(sqlDB).Workspaces
+	createUser(t, sqlDB, "root", "correct horse", models.RoleAdmin)
+	carol := createUser(t, sqlDB, "carol", "correct horse", models.RoleViewer)
+	cookie := sessionCookie(login(e, "root", "correct horse", "/"))
+
+	// Dave is an admin of another workspace only, Carol a viewer of both
+	other := models.NewWorkspace("Other")
+	if err := workspaces.Create(context.Background(), other); err != nil {
+		t.Fatalf("Failed to create workspace: %v", err)
+	}
+	dave := createUser(t, sqlDB, "dave", "correct horse", models.RoleAdmin)
+	if err := workspaces.RemoveMember(context.Background(), models.DefaultWorkspaceID, dave.ID); err != nil {
+		t.Fatalf("Failed to remove member: %v", err)
+	}
+	for _, user := range []*models.User{dave, carol} {
+		if err := workspaces.AddMember(context.Background(), other.ID, user.ID, models.RoleAdmin); err != nil {
+			t.Fatalf("Failed to add member: %v", err)
+		}
+	}
+
+	setRole := func(id int64, role string) *httptest.ResponseRecorder {
+		form := url.Values{"role": {role}}
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/users/%d/role", id), strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.Header.Set("HX-Request", "true")
+		return do(e, req, cookie)
+	}
+
+	t.Run("Lists the members of the workspace with their role there", func(t *testing.T) {
+		rec := do(e, httptest.NewRequest(http.MethodGet, "/admin/users", nil), cookie)
+		want := "users.html as root\ncarol viewer\nroot admin"
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("Expected %q, got %d %q", want, rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Users of other workspaces cannot be changed", func(t *testing.T) {
+		if rec := setRole(dave.ID, "viewer"); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rec.Code)
+		}
+		if role, _ := workspaces.GetRole(context.Background(), other.ID, dave.ID); role != models.RoleAdmin {
+			t.Errorf("Expected dave to stay admin of the other workspace, got %s", role)
+		}
+	})
+
+	t.Run("Changes stay in the workspace", func(t *testing.T) {
+		if rec := setRole(carol.ID, "accountant"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+		if role, _ := workspaces.GetRole(context.Background(), other.ID, carol.ID); role != models.RoleAdmin {
+			t.Errorf("Expected carol to stay admin of the other workspace, got %s", role)
+		}
+	})
+
+	t.Run("Permissions follow the role in the workspace", func(t *testing.T) {
+		carolCookie := sessionCookie(login(e, "carol", "correct horse", "/"))
+		rec := do(e, httptest.NewRequest(http.MethodGet, "/admin/users", nil), carolCookie)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Expected an accountant of the default workspace to be refused, got %d", rec.Code)
+		}
+	})
+}
+
+func TestEveryRouteHasAPermission(t *testing.T) {
+	registered := map[string]bool{}
+	for _, key := range routes.Registered(t) {
+		registered[key] = true
+		if strings.HasSuffix(key, " "+auth.LoginPath) {
+			continue
+		}
+		if _, ok := auth.Permissions[key]; !ok {
+			t.Errorf("Route %q is registered in main.go but has no entry in auth.Permissions", key)
+		}
+	}
+
+	for key := range auth.Permissions {
+		if !registered[key] {
+			t.Errorf("auth.Permissions guards %q which is not registered in main.go", key)
+		}
+	}
+}
+
+func TestLookupsReportNotFound(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	repos := repository.NewRepositories(sqlDB)
+	ctx := context.Background()
+
+	lookups := map[string]func() error{
+		"user by id": func() error {
+			_, err := repos.Users.GetByID(ctx, 999)
+			return err
+		},
+		"user by username": func() error {
+			_, err := repos.Users.GetByUsername(ctx, "nobody")
+			return err
+		},
+		"session": func() error {
+			_, err := repos.Sessions.GetByTokenHash(ctx, "missing")
+			return err
+		},
+		"workspace by id": func() error {
+			_, err := repos.Workspaces.GetByID(ctx, 999)
+			return err
+		},
+		"workspace by name": func() error {
+			_, err := repos.Workspaces.GetByName(ctx, "Nowhere")
+			return err
+		},
+		"API token": func() error {
+			_, err := repos.APITokens.GetByTokenHash(ctx, "missing")
+			return err
+		},
+	}
+	for name, lookup := range lookups {
+		if err := lookup(); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package auth_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newTokens(sqlDB *sql.DB) *auth.Tokens {
+	repos := repository.NewRepositories(sqlDB)
+	return auth.NewTokens(
+		repos.APITokens,
+		repos.Users,
+		repos.Workspaces,
+	)
+}
+
+func issue(t *testing.T, sqlDB *sql.DB, user *models.User, scope models.TokenScope, ttl time.Duration) (*models.APIToken, string) {
+	token, plain, err := newTokens(sqlDB).Issue(context.Background(), user.ID, models.DefaultWorkspaceID, "script", scope, ttl)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	return token, plain
+}
+
+func bearer(e *echo.Echo, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	return do(e, req)
+}
+
+func TestBearerTokens(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	repos := repository.NewRepositories(sqlDB)
+
+	e, _ := setupServer(t, sqlDB)
+	repo := repos.APITokens
+	accountant := createUser(t, sqlDB, "accountant", "correct horse", models.RoleAccountant)
+	viewer := createUser(t, sqlDB, "viewer", "correct horse", models.RoleViewer)
+
+	readToken, read := issue(t, sqlDB, accountant, models.ScopeRead, 0)
+	_, readWrite := issue(t, sqlDB, accountant, models.ScopeReadWrite, 30*24*time.Hour)
+	_, viewerToken := issue(t, sqlDB, viewer, models.ScopeReadWrite, 0)
+	_, expired := issue(t, sqlDB, accountant, models.ScopeReadWrite, -time.Hour)
+
+	t.Run("Only the hash is stored", func(t *testing.T) {
+		if !strings.HasPrefix(read, auth.TokenPrefix) {
+			t.Errorf("Expected token to start with %q, got %q", auth.TokenPrefix, read)
+		}
+		var count int
+		if err := sqlDB.QueryRow(testdb.Rebind("SELECT COUNT(*) FROM api_tokens WHERE token_hash = ?"), read).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Error("Expected the plain token not to be stored")
+		}
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"Read token reads", http.MethodGet, "/bills", read, http.StatusOK},
+		{"Read token cannot write", http.MethodPost, "/api/v1/bills", read, http.StatusForbidden},
+		{"Read-write token writes", http.MethodPost, "/api/v1/bills", readWrite, http.StatusCreated},
+		{"Token cannot exceed the role of its user", http.MethodPost, "/api/v1/bills", viewerToken, http.StatusForbidden},
+		{"Token cannot manage tokens", http.MethodGet, "/account/tokens", readWrite, http.StatusForbidden},
+		{"Expired token", http.MethodGet, "/bills", expired, http.StatusUnauthorized},
+		{"Unknown token", http.MethodGet, "/bills", auth.TokenPrefix + "nope", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := bearer(e, tt.method, tt.path, tt.token)
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("Signs in as the user of the token", func(t *testing.T) {
+		rec := bearer(e, http.MethodGet, "/bills", read)
+		if rec.Body.String() != "bills.html as accountant" {
+			t.Errorf("Expected to be signed in as accountant, got %q", rec.Body.String())
+		}
+	})
+
+	t.Run("Invalid tokens get a JSON error", func(t *testing.T) {
+		rec := bearer(e, http.MethodGet, "/bills", expired)
+		if !strings.Contains(rec.Body.String(), `"status":401`) {
+			t.Errorf("Expected a JSON 401, got %s", rec.Body.String())
+		}
+		if rec.Header().Get(echo.HeaderWWWAuthenticate) == "" {
+			t.Error("Expected a WWW-Authenticate header")
+		}
+	})
+
+	t.Run("Records when the token was last used", func(t *testing.T) {
+		stored, err := repo.GetByTokenHash(context.Background(), readToken.TokenHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.LastUsedAt == nil {
+			t.Error("Expected last used time to be set")
+		}
+	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		if err := repo.Delete(context.Background(), readToken.ID, viewer.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Fatalf("Expected the token of another user not to be found, got %v", err)
+		}
+		if rec := bearer(e, http.MethodGet, "/bills", read); rec.Code != http.StatusOK {
+			t.Errorf("Expected tokens of other users to survive a revoke, got %d", rec.Code)
+		}
+
+		if err := repo.Delete(context.Background(), readToken.ID, accountant.ID); err != nil {
+			t.Fatal(err)
+		}
+		if rec := bearer(e, http.MethodGet, "/bills", read); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected a revoked token to be rejected, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Token of a removed member", func(t *testing.T) {
+		if err := repos.Workspaces.RemoveMember(context.Background(), models.DefaultWorkspaceID, viewer.ID); err != nil {
+			t.Fatal(err)
+		}
+		if rec := bearer(e, http.MethodGet, "/bills", viewerToken); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected 403, got %d", rec.Code)
+		}
+	})
+}
+
+func TestManageTokens(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	e, _ := setupServer(t, sqlDB)
+	repo := repository.NewRepositories(sqlDB).APITokens
+	user := createUser(t, sqlDB, "accountant", "correct horse", models.RoleAccountant)
+	cookie := sessionCookie(login(e, "accountant", "correct horse", "/"))
+
+	create := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/account/tokens", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		return do(e, req, cookie)
+	}
+
+	rec := create(url.Values{"name": {"nightly export"}, "scope": {"read"}, "expires_in_days": {"30"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	tokens, err := repo.GetByUserID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 {
+		t.Fatalf("Expected 1 token, got %d", len(tokens))
+	}
+	token := tokens[0]
+	if token.Name != "nightly export" || token.Scope != models.ScopeRead || token.WorkspaceID != models.DefaultWorkspaceID {
+		t.Errorf("Unexpected token %+v", token)
+	}
+	if token.ExpiresAt == nil || token.ExpiresAt.Before(time.Now().Add(29*24*time.Hour)) {
+		t.Errorf("Expected token to expire in 30 days, got %v", token.ExpiresAt)
+	}
+
+	if rec := create(url.Values{"name": {"x"}, "scope": {"admin"}, "expires_in_days": {"0"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown scope to be rejected, got %d", rec.Code)
+	}
+
+	rec = do(e, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/account/tokens/%d", token.ID), nil), cookie)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	tokens, err = repo.GetByUserID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 0 {
+		t.Errorf("Expected the token to be revoked, got %d tokens", len(tokens))
+	}
+
+	// Revoking it again, or a token of another user, finds nothing
+	rec = do(e, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/account/tokens/%d", token.ID), nil), cookie)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a revoked token, got %d", rec.Code)
+	}
+	other := createUser(t, sqlDB, "bookkeeper", "battery staple", models.RoleAccountant)
+	otherToken := models.NewAPIToken(other.ID, models.DefaultWorkspaceID, "other", models.ScopeRead, "hash", 0)
+	if err := repo.Create(context.Background(), otherToken); err != nil {
+		t.Fatal(err)
+	}
+	rec = do(e, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/account/tokens/%d", otherToken.ID), nil), cookie)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for the token of another user, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,235 @@
+package backup_test
+
+import (
+	"bills/db"
+	"bills/internal/archive"
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// createArchiveFixture fills the default workspace and a second one with two
+// bills each, one of them to a receiver in the trash
+func createArchiveFixture(t *testing.T, repos *repository.Repositories) {
+	t.Helper()
+
+	acme := models.NewWorkspace("Acme")
+	if err := repos.Workspaces.Create(context.Background(), acme); err != nil {
+		t.Fatalf("Failed to create workspace: %v", err)
+	}
+
+	for _, workspaceID := range []int64{models.DefaultWorkspaceID, acme.ID} {
+		ctx := tenant.WithWorkspace(context.Background(), workspaceID)
+		issuer := models.NewIssuer(fmt.Sprintf("Studio %d", workspaceID), "CHE-123.456.789", "1 Main St", "Zurich", "ZH", "8000", "Switzerland")
+		receiver := models.NewReceiver("Globex", "NL123456789B01", "2 Canal St", "Amsterdam", "NH", "1012", "Netherlands")
+		gone := models.NewReceiver("Initech", "DE123456789", "3 Ring", "Berlin", "BE", "10115", "Germany")
+		design := models.NewBillItem("Logo design", 100.00, "EUR")
+		hosting := models.NewBillItem("Web hosting", 20.00, "EUR")
+		if err := repos.Issuers.Create(ctx, issuer); err != nil {
+			t.Fatalf("Failed to create issuer: %v", err)
+		}
+		for _, receiver := range []*models.Receiver{receiver, gone} {
+			if err := repos.Receivers.Create(ctx, receiver); err != nil {
+				t.Fatalf("Failed to create receiver: %v", err)
+			}
+		}
+		for _, item := range []*models.BillItem{design, hosting} {
+			if err := repos.BillItems.Create(ctx, item); err != nil {
+				t.Fatalf("Failed to create bill item: %v", err)
+			}
+		}
+
+		for i, receiverID := range []int64{receiver.ID, gone.ID} {
+			bill := models.NewBill(time.Date(2026, 3, 1+i, 0, 0, 0, 0, time.UTC), issuer.ID, receiverID)
+			bill.Paid = i == 0
+			bill.Items = []*models.BillItemAssignment{
+				models.NewBillItemAssignment(0, design.ID, 2, 100.00, "EUR", 1.0),
+				models.NewBillItemAssignment(0, hosting.ID, 1+i, 20.00, "EUR", 1.0),
+			}
+			bill.CalculateTotals()
+			if err := repos.Bills.Create(ctx, bill); err != nil {
+				t.Fatalf("Failed to create bill: %v", err)
+			}
+		}
+		if err := repos.Receivers.Delete(ctx, gone.ID); err != nil {
+			t.Fatalf("Failed to delete receiver: %v", err)
+		}
+	}
+}
+
+// describe lists what an archive holds without the IDs, which an import
+// renumbers
+func describe(a *archive.Archive) []string {
+	var lines []string
+	for _, workspace := range a.Workspaces {
+		var records []string
+		for _, issuer := range workspace.Issuers {
+			records = append(records, "issuer "+issuer.Name)
+		}
+		for _, receiver := range workspace.Receivers {
+			records = append(records, "receiver "+receiver.Name)
+		}
+		for _, item := range workspace.BillItems {
+			records = append(records, fmt.Sprintf("item %s %.2f %s", item.Name, item.Price, item.Currency))
+		}
+		for _, bill := range workspace.Bills {
+			record := fmt.Sprintf("bill %s to %s due %s, %.2f %s, %.2f EUR, paid %v:",
+				bill.IssuerName, bill.ReceiverName, bill.DueDate.Format("2006-01-02"),
+				bill.OriginalTotal, bill.Currency, bill.EURTotal, bill.Paid)
+			for _, line := range bill.Lines {
+				record += fmt.Sprintf(" %d x %s %.2f %s", line.Quantity, line.ItemName, line.Price, line.Currency)
+			}
+			records = append(records, record)
+		}
+		slices.Sort(records)
+		for _, record := range records {
+			lines = append(lines, workspace.Name+": "+record)
+		}
+	}
+	return lines
+}
+
+// roundTrip writes an archive to JSON and reads it back
+func roundTrip(t *testing.T, a *archive.Archive) *archive.Archive {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, a); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	read, err := archive.Read(&buf)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	return read
+}
+
+func TestArchiveAcrossBackends(t *testing.T) {
+	ctx := context.Background()
+
+	source := testdb.Open(t)
+	defer source.Close()
+	sourceRepos := repository.NewRepositories(source)
+	createArchiveFixture(t, sourceRepos)
+
+	exported, err := archive.Export(ctx, repository.NewUnitOfWork(source), sourceRepos.Workspaces)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	want := describe(exported)
+	if len(want) == 0 {
+		t.Fatal("Expected the export to hold records")
+	}
+	for _, line := range want {
+		if strings.Contains(line, "receiver Initech") {
+			t.Errorf("Expected trashed receivers to be left out, got %q", line)
+		}
+	}
+
+	// Into the memory store, whose workspaces live in SQL as in --demo
+	demo := testdb.Open(t)
+	defer demo.Close()
+	demoWorkspaces := repository.NewRepositories(demo).Workspaces
+	store := repository.NewMemoryStore()
+	memory := repository.NewMemoryUnitOfWork(store)
+	summary, err := archive.Import(ctx, roundTrip(t, exported), memory, demoWorkspaces)
+	if err != nil {
+		t.Fatalf("Failed to import into memory: %v", err)
+	}
+	if summary.Workspaces != 2 || summary.Bills != 4 || summary.Receivers != 2 {
+		t.Errorf("Unexpected import %s", summary)
+	}
+	fromMemory, err := archive.Export(ctx, memory, demoWorkspaces)
+	if err != nil {
+		t.Fatalf("Failed to export from memory: %v", err)
+	}
+	if got := describe(fromMemory); !slices.Equal(got, want) {
+		t.Errorf("Memory export differs:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// And back into SQL
+	target := testdb.Open(t)
+	defer target.Close()
+	targetRepos := repository.NewRepositories(target)
+	if _, err := archive.Import(ctx, roundTrip(t, fromMemory), repository.NewUnitOfWork(target), targetRepos.Workspaces); err != nil {
+		t.Fatalf("Failed to import into SQL: %v", err)
+	}
+	fromSQL, err := archive.Export(ctx, repository.NewUnitOfWork(target), targetRepos.Workspaces)
+	if err != nil {
+		t.Fatalf("Failed to export from SQL: %v", err)
+	}
+	if got := describe(fromSQL); !slices.Equal(got, want) {
+		t.Errorf("SQL export differs:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The receiver recreated for the bill that uses it went back to the trash
+	trashed, err := targetRepos.Trash.GetAll(tenant.WithWorkspace(ctx, models.DefaultWorkspaceID))
+	if err != nil {
+		t.Fatalf("Failed to list the trash: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].Entity != models.EntityReceivers || trashed[0].Label != "Initech" {
+		t.Errorf("Expected the trashed receiver in the trash, got %+v", trashed)
+	}
+}
+
+func TestArchiveRefusesOtherFormats(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	repos := repository.NewRepositories(sqlDB)
+
+	newer := &archive.Archive{Version: archive.Version + 1, Workspaces: []archive.Workspace{{Name: "Default"}}}
+	if _, err := archive.Import(context.Background(), newer, repository.NewUnitOfWork(sqlDB), repos.Workspaces); err == nil {
+		t.Error("Expected an archive of another version to be refused")
+	}
+	if _, err := archive.Read(strings.NewReader(`{"version": 1, "users": []}`)); err == nil {
+		t.Error("Expected fields archives do not have to be refused")
+	}
+}
+
+func TestArchiveDownloadHoldsTheWorkspace(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	repos := repository.NewRepositories(sqlDB)
+	createArchiveFixture(t, repos)
+
+	acme, err := repos.Workspaces.GetByName(context.Background(), "Acme")
+	if err != nil || acme == nil {
+		t.Fatalf("Failed to get workspace: %v", err)
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/archive", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	auth.SetWorkspace(c, acme)
+	if err := handlers.NewBackupHandler(db.NewBackups(sqlDB, t.TempDir(), 0), repository.NewUnitOfWork(sqlDB)).DownloadArchive(c); err != nil {
+		t.Fatalf("Failed to download archive: %v", err)
+	}
+
+	downloaded, err := archive.Read(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	for _, line := range describe(downloaded) {
+		if !strings.HasPrefix(line, "Acme: ") || strings.Contains(line, fmt.Sprintf("Studio %d", models.DefaultWorkspaceID)) {
+			t.Errorf("Expected the records of Acme only, got %q", line)
+		}
+	}
+	if len(downloaded.Workspaces) != 1 || len(downloaded.Workspaces[0].Bills) != 2 {
+		t.Errorf("Expected the two bills of Acme, got %+v", downloaded.Workspaces)
+	}
+}
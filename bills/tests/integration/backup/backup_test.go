@@ -0,0 +1,347 @@
+package backup_test
+
+import (
+	"bills/db"
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// openFile opens and migrates a SQLite database file
+func openFile(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	sqlDB, err := db.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.MigrateDB(sqlDB, path); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	return sqlDB
+}
+
+// issuerNames returns the names of the live issuers of the default workspace
+func issuerNames(t *testing.T, sqlDB *sql.DB) []string {
+	t.Helper()
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	issuers, err := repository.NewRepositories(sqlDB).Issuers.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list issuers: %v", err)
+	}
+	var names []string
+	for _, issuer := range issuers {
+		names = append(names, issuer.Name)
+	}
+	return names
+}
+
+func createIssuer(t *testing.T, sqlDB *sql.DB, name string) {
+	t.Helper()
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	issuer := models.NewIssuer(name, "CHE-123.456.789", "1 Main St", "Zurich", "ZH", "8000", "Switzerland")
+	if err := repository.NewRepositories(sqlDB).Issuers.Create(ctx, issuer); err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	if testdb.IsPostgres() {
+		t.Skip("snapshots need SQLite")
+	}
+	ctx := context.Background()
+
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	createIssuer(t, sqlDB, "Acme Studio")
+
+	dir := t.TempDir()
+	backups := db.NewBackups(sqlDB, dir, 0)
+	file, err := backups.Create(ctx)
+	if err != nil {
+		t.Fatalf("Failed to back up: %v", err)
+	}
+	if file.Size == 0 || time.Since(file.CreatedAt) > time.Minute {
+		t.Errorf("Unexpected backup %+v", file)
+	}
+	path, err := backups.Path(file.Name)
+	if err != nil {
+		t.Fatalf("Failed to find the backup: %v", err)
+	}
+
+	// Changes made after the snapshot are not in it
+	createIssuer(t, sqlDB, "Later Ltd")
+
+	// The restore replaces what the database held
+	target := filepath.Join(t.TempDir(), "bills.db")
+	existing := openFile(t, target)
+	createIssuer(t, existing, "Replaced GmbH")
+	existing.Close()
+
+	if err := db.Restore(ctx, path, target); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	restored := openFile(t, target)
+	if names := issuerNames(t, restored); len(names) != 1 || names[0] != "Acme Studio" {
+		t.Errorf("Expected only the issuer of the snapshot, got %v", names)
+	}
+	if err := db.CheckSchema(restored); err != nil {
+		t.Errorf("Expected the restored schema to match, got %v", err)
+	}
+}
+
+func TestRestoreMigratesOlderBackup(t *testing.T) {
+	if testdb.IsPostgres() {
+		t.Skip("snapshots need SQLite")
+	}
+	ctx := context.Background()
+
+	sqlDB := openFile(t, filepath.Join(t.TempDir(), "old.db"))
+	createIssuer(t, sqlDB, "Acme Studio")
+	if err := db.MigrateSteps(sqlDB, -1); err != nil {
+		t.Fatalf("Failed to roll back a migration: %v", err)
+	}
+	old, _, err := db.MigrationVersion(sqlDB)
+	if err != nil {
+		t.Fatalf("Failed to get the migration version: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "old-backup.db")
+	if err := db.Backup(ctx, sqlDB, path); err != nil {
+		t.Fatalf("Failed to back up: %v", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "bills.db")
+	if err := db.Restore(ctx, path, target); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	restored := openFile(t, target)
+	version, dirty, err := db.MigrationVersion(restored)
+	if err != nil || dirty || version <= old {
+		t.Errorf("Expected the restore to migrate past version %d, got %d dirty %v: %v", old, version, dirty, err)
+	}
+	if names := issuerNames(t, restored); len(names) != 1 {
+		t.Errorf("Expected the issuer of the snapshot, got %v", names)
+	}
+}
+
+func TestRestoreRefusesBackups(t *testing.T) {
+	if testdb.IsPostgres() {
+		t.Skip("snapshots need SQLite")
+	}
+
+	tests := []struct {
+		name   string
+		update string
+		want   string
+	}{
+		{"Newer", "UPDATE schema_migrations SET version = 99999999999999", "newer than version"},
+		{"Dirty", "UPDATE schema_migrations SET dirty = true", "migration"},
+		{"NoVersion", "DELETE FROM schema_migrations", "no migration version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			sqlDB := openFile(t, filepath.Join(t.TempDir(), "source.db"))
+			createIssuer(t, sqlDB, "Acme Studio")
+			if _, err := sqlDB.Exec(tt.update); err != nil {
+				t.Fatalf("Failed to run %q: %v", tt.update, err)
+			}
+			path := filepath.Join(t.TempDir(), "backup.db")
+			if err := db.Backup(ctx, sqlDB, path); err != nil {
+				t.Fatalf("Failed to back up: %v", err)
+			}
+
+			target := filepath.Join(t.TempDir(), "bills.db")
+			err := db.Restore(ctx, path, target)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Expected the restore to be refused with %q, got %v", tt.want, err)
+			}
+			if _, err := os.Stat(target); !os.IsNotExist(err) {
+				t.Errorf("Expected a refused restore to leave the target alone, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBackupRotation(t *testing.T) {
+	if testdb.IsPostgres() {
+		t.Skip("snapshots need SQLite")
+	}
+	ctx := context.Background()
+
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	dir := t.TempDir()
+	// Other files of the directory are left alone
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := db.NewBackups(sqlDB, dir, 2)
+	var names []string
+	for i := 0; i < 4; i++ {
+		file, err := backups.Create(ctx)
+		if err != nil {
+			t.Fatalf("Failed to back up: %v", err)
+		}
+		names = append(names, file.Name)
+		// Snapshots are named after the millisecond they were taken
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	files, err := backups.List()
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(files) != 2 || files[0].Name != names[3] || files[1].Name != names[2] {
+		t.Errorf("Expected the 2 newest backups %v, got %+v", names[2:], files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("Expected other files to be kept, got %v", err)
+	}
+
+	for _, name := range []string{names[0], "../bills.db", "notes.txt"} {
+		if _, err := backups.Path(name); err == nil {
+			t.Errorf("Expected no backup called %q", name)
+		}
+	}
+}
+
+func TestBackupsOfEmptyDirectory(t *testing.T) {
+	backups := db.NewBackups(nil, filepath.Join(t.TempDir(), "missing"), 7)
+	files, err := backups.List()
+	if err != nil || len(files) != 0 {
+		t.Errorf("Expected no backups, got %v %v", files, err)
+	}
+}
+
+// listRenderer renders the template name and the number of snapshots listed
+type listRenderer struct{}
+
+func (listRenderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	values, _ := data.(map[string]interface{})
+	files, _ := values["Backups"].([]db.BackupFile)
+	_, err := fmt.Fprintf(w, "%s %d", name, len(files))
+	return err
+}
+
+func TestSnapshotsNeedAnInstanceAdmin(t *testing.T) {
+	if testdb.IsPostgres() {
+		t.Skip("snapshots need SQLite")
+	}
+	ctx := context.Background()
+
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	repos := repository.NewRepositories(sqlDB)
+
+	// Both administer the workspace, only olivia administers the server
+	for _, u := range []struct {
+		username      string
+		role          models.Role
+		instanceAdmin bool
+	}{
+		{"alice", models.RoleAdmin, false},
+		{"olivia", models.RoleAdmin, true},
+	} {
+		user, err := models.NewUser(u.username, "password123")
+		if err != nil {
+			t.Fatalf("Failed to build user: %v", err)
+		}
+		user.InstanceAdmin = u.instanceAdmin
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		if err := repos.Workspaces.AddMember(ctx, models.DefaultWorkspaceID, user.ID, u.role); err != nil {
+			t.Fatalf("Failed to add user to workspace: %v", err)
+		}
+	}
+
+	sessions := auth.NewSessions(repos.Users, repos.Sessions, repos.Workspaces)
+	backups := db.NewBackups(sqlDB, t.TempDir(), 0)
+	backupHandler := handlers.NewBackupHandler(backups, repository.NewUnitOfWork(sqlDB))
+	authHandler := handlers.NewAuthHandler(sessions)
+
+	e := echo.New()
+	e.Renderer = listRenderer{}
+	e.Use(sessions.Middleware)
+	e.Use(auth.Authorize)
+	e.POST("/login", authHandler.Login)
+	e.GET("/admin/backups", backupHandler.RenderBackups)
+	e.POST("/admin/backups", backupHandler.CreateBackup)
+	e.GET("/admin/backups/:name", backupHandler.DownloadBackup)
+
+	do := func(method, target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	login := func(username string) *http.Cookie {
+		form := url.Values{"username": {username}, "password": {"password123"}}
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		for _, cookie := range rec.Result().Cookies() {
+			if cookie.Name == auth.CookieName {
+				return cookie
+			}
+		}
+		t.Fatalf("Failed to sign in %s: %d %s", username, rec.Code, rec.Body.String())
+		return nil
+	}
+	alice, olivia := login("alice"), login("olivia")
+
+	rec := do(http.MethodPost, "/admin/backups", olivia)
+	if rec.Code != http.StatusOK || rec.Body.String() != "backups-list 1" {
+		t.Fatalf("Expected the instance admin to take a snapshot, got %d %q", rec.Code, rec.Body.String())
+	}
+	files, err := backups.List()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected one snapshot, got %v %v", files, err)
+	}
+	download := "/admin/backups/" + files[0].Name
+
+	if rec := do(http.MethodPost, "/admin/backups", alice); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected a workspace admin to be refused a snapshot, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, download, alice); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected a workspace admin to be refused the download, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, download, olivia); rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Errorf("Expected the instance admin to download the snapshot, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/admin/backups", alice); rec.Code != http.StatusOK || rec.Body.String() != "backups.html 0" {
+		t.Errorf("Expected a workspace admin to see no snapshots, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/admin/backups", olivia); rec.Code != http.StatusOK || rec.Body.String() != "backups.html 1" {
+		t.Errorf("Expected the instance admin to see the snapshot, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,53 @@
+// Package fixture holds the records and test doubles the integration tests
+// of several areas share
+package fixture
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateBill creates a bill between two new parties with a line of a new item
+func CreateBill(t testing.TB, ctx context.Context, repos *repository.Repositories, issuer, receiver, item string) *models.Bill {
+	t.Helper()
+
+	i := models.NewIssuer(issuer, "CHE-123.456.789", "Main Street 1", "Zürich", "ZH", "8000", "Switzerland")
+	if err := repos.Issuers.Create(ctx, i); err != nil {
+		t.Fatal(err)
+	}
+	r := models.NewReceiver(receiver, "NL123456789B01", "Harbour Road 2", "Rotterdam", "ZH", "3011", "Netherlands")
+	if err := repos.Receivers.Create(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	it := models.NewBillItem(item, 10, models.DefaultCurrency())
+	if err := repos.BillItems.Create(ctx, it); err != nil {
+		t.Fatal(err)
+	}
+
+	bill := models.NewBill(time.Now(), i.ID, r.ID)
+	bill.Items = append(bill.Items, models.NewBillItemAssignment(0, it.ID, 2, 10, models.DefaultCurrency(), 1))
+	bill.CalculateTotals()
+	if err := repos.Bills.Create(ctx, bill); err != nil {
+		t.Fatal(err)
+	}
+	return bill
+}
+
+// Renderer keeps the template name and the data of the last render instead
+// of rendering the real templates
+type Renderer struct {
+	Name string
+	Data map[string]interface{}
+}
+
+func (r *Renderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	r.Name = name
+	r.Data, _ = data.(map[string]interface{})
+	return nil
+}
@@ -0,0 +1,538 @@
+package grpc_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/rpc"
+	pb "bills/internal/rpc/billsv1"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// setupServer serves every service over an in-memory listener and returns a
+// client connection to it along with the bill watcher
+func setupServer(t *testing.T, sqlDB *sql.DB) (*grpc.ClientConn, *rpc.BillWatcher) {
+	repos := repository.NewRepositories(sqlDB)
+	watcher := rpc.NewBillWatcher(repos.Bills)
+	server := rpc.NewServer(
+		5*time.Second,
+		repository.NewUnitOfWork(sqlDB, watcher.Join),
+		newTokens(sqlDB),
+		repos.Workspaces,
+		watcher,
+		repos.Receivers,
+		repos.Issuers,
+		repos.BillItems,
+		repos.BillItemAssignments,
+	)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, watcher
+}
+
+func newTokens(sqlDB *sql.DB) *auth.Tokens {
+	repos := repository.NewRepositories(sqlDB)
+	return auth.NewTokens(repos.APITokens, repos.Users, repos.Workspaces)
+}
+
+// signIn creates a user with the role in the workspace and returns it along
+// with a client context that sends an API token of the scope for it
+func signIn(t *testing.T, sqlDB *sql.DB, workspaceID int64, role models.Role, scope models.TokenScope) (context.Context, *models.User) {
+	t.Helper()
+
+	repos := repository.NewRepositories(sqlDB)
+	user, err := models.NewUser(fmt.Sprintf("%s-%s-%d", role, scope, workspaceID), "correct horse")
+	if err != nil {
+		t.Fatalf("Failed to build user: %v", err)
+	}
+	if err := repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err := repos.Workspaces.AddMember(context.Background(), workspaceID, user.ID, role); err != nil {
+		t.Fatalf("Failed to add member: %v", err)
+	}
+	_, plain, err := newTokens(sqlDB).Issue(context.Background(), user.ID, workspaceID, "grpc", scope, 0)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	return withToken(plain), user
+}
+
+// withToken returns a client context that sends the API token
+func withToken(plain string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), rpc.AuthorizationMetadataKey, "Bearer "+plain)
+}
+
+// signInAdmin signs in as an admin of the default workspace with a token
+// that may write
+func signInAdmin(t *testing.T, sqlDB *sql.DB) context.Context {
+	t.Helper()
+
+	ctx, _ := signIn(t, sqlDB, models.DefaultWorkspaceID, models.RoleAdmin, models.ScopeReadWrite)
+	return ctx
+}
+
+func createTestData(t *testing.T, ctx context.Context, conn *grpc.ClientConn) (int64, int64, int64) {
+
+	issuer, err := pb.NewIssuerServiceClient(conn).CreateIssuer(ctx, &pb.CreateIssuerRequest{
+		Issuer: &pb.Issuer{Name: "Test Issuer", VatNumber: "123456", Country: "Country"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test issuer: %v", err)
+	}
+
+	receiver, err := pb.NewReceiverServiceClient(conn).CreateReceiver(ctx, &pb.CreateReceiverRequest{
+		Receiver: &pb.Receiver{Name: "Test Receiver", VatNumber: "654321", Country: "Country"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test receiver: %v", err)
+	}
+
+	item, err := pb.NewBillItemServiceClient(conn).CreateBillItem(ctx, &pb.CreateBillItemRequest{
+		BillItem: &pb.BillItem{Name: "Test Item", Price: 100},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test bill item: %v", err)
+	}
+	if item.Currency != models.DefaultCurrency() {
+		t.Errorf("Expected bill item currency to default to %s, got %s", models.DefaultCurrency(), item.Currency)
+	}
+
+	return issuer.Id, receiver.Id, item.Id
+}
+
+func TestBillService(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	conn, _ := setupServer(t, sqlDB)
+	ctx := signInAdmin(t, sqlDB)
+	issuerID, receiverID, itemID := createTestData(t, ctx, conn)
+
+	bills := pb.NewBillServiceClient(conn)
+	assignments := pb.NewBillItemAssignmentServiceClient(conn)
+
+	bill, err := bills.CreateBill(ctx, &pb.CreateBillRequest{Bill: &pb.Bill{
+		IssuerId:   issuerID,
+		ReceiverId: receiverID,
+		DueDate:    timestamppb.New(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)),
+		Items:      []*pb.BillItemAssignment{{ItemId: itemID, Quantity: 2, Price: 100}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+	if bill.Id == 0 {
+		t.Error("Expected bill ID to be set")
+	}
+	if bill.EurTotal != 200.00 {
+		t.Errorf("Expected EUR total 200.00, got %.2f", bill.EurTotal)
+	}
+
+	t.Run("Add line", func(t *testing.T) {
+		line, err := assignments.CreateBillItemAssignment(ctx, &pb.CreateBillItemAssignmentRequest{
+			Assignment: &pb.BillItemAssignment{BillId: bill.Id, ItemId: itemID, Quantity: 1, Price: 50, Currency: "EUR"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to add line: %v", err)
+		}
+		if line.EurAmount != 50.00 {
+			t.Errorf("Expected EUR amount 50.00, got %.2f", line.EurAmount)
+		}
+
+		got, err := bills.GetBill(ctx, &pb.GetBillRequest{Id: bill.Id})
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if got.EurTotal != 250.00 {
+			t.Errorf("Expected EUR total 250.00 after adding a line, got %.2f", got.EurTotal)
+		}
+		if len(got.Items) != 2 {
+			t.Errorf("Expected 2 items, got %d", len(got.Items))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updated, err := bills.UpdateBill(ctx, &pb.UpdateBillRequest{Bill: &pb.Bill{
+			Id:         bill.Id,
+			IssuerId:   issuerID,
+			ReceiverId: receiverID,
+			DueDate:    bill.DueDate,
+			Paid:       true,
+		}})
+		if err != nil {
+			t.Fatalf("Failed to update bill: %v", err)
+		}
+		if !updated.Paid {
+			t.Error("Expected bill to be paid")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if _, err := bills.DeleteBill(ctx, &pb.DeleteBillRequest{Id: bill.Id}); err != nil {
+			t.Fatalf("Failed to delete bill: %v", err)
+		}
+
+		_, err := bills.GetBill(ctx, &pb.GetBillRequest{Id: bill.Id})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound after delete, got %v", err)
+		}
+	})
+}
+
+func TestErrors(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	conn, _ := setupServer(t, sqlDB)
+	ctx := signInAdmin(t, sqlDB)
+	issuerID, receiverID, _ := createTestData(t, ctx, conn)
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{
+			name: "Unknown issuer",
+			call: func() error {
+				_, err := pb.NewIssuerServiceClient(conn).GetIssuer(ctx, &pb.GetIssuerRequest{Id: 999})
+				return err
+			},
+			want: codes.NotFound,
+		},
+		{
+			name: "Issuer without a name",
+			call: func() error {
+				_, err := pb.NewIssuerServiceClient(conn).CreateIssuer(ctx, &pb.CreateIssuerRequest{Issuer: &pb.Issuer{}})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Missing message",
+			call: func() error {
+				_, err := pb.NewReceiverServiceClient(conn).CreateReceiver(ctx, &pb.CreateReceiverRequest{})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Bill with an unknown item",
+			call: func() error {
+				_, err := pb.NewBillServiceClient(conn).CreateBill(ctx, &pb.CreateBillRequest{Bill: &pb.Bill{
+					IssuerId:   issuerID,
+					ReceiverId: receiverID,
+					DueDate:    timestamppb.Now(),
+					Items:      []*pb.BillItemAssignment{{ItemId: 999, Quantity: 1, Price: 10}},
+				}})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Lines of an unknown bill",
+			call: func() error {
+				_, err := pb.NewBillItemAssignmentServiceClient(conn).ListBillItemAssignments(ctx, &pb.ListBillItemAssignmentsRequest{BillId: 999})
+				return err
+			},
+			want: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWatchBills(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	conn, watcher := setupServer(t, sqlDB)
+	admin := signInAdmin(t, sqlDB)
+	issuerID, receiverID, _ := createTestData(t, admin, conn)
+	scoped := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	existing := models.NewBill(time.Now(), issuerID, receiverID)
+	if err := watcher.Create(scoped, existing); err != nil {
+		t.Fatalf("Failed to create existing bill: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(admin, 5*time.Second)
+	defer cancel()
+
+	stream, err := pb.NewBillServiceClient(conn).WatchBills(ctx, &pb.WatchBillsRequest{IncludeExisting: true})
+	if err != nil {
+		t.Fatalf("Failed to watch bills: %v", err)
+	}
+
+	expect := func(wantType pb.BillEvent_Type, wantID int64) {
+		t.Helper()
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Failed to receive event: %v", err)
+		}
+		if event.Type != wantType || event.Bill.GetId() != wantID {
+			t.Fatalf("Expected %v for bill %d, got %v for bill %d", wantType, wantID, event.Type, event.Bill.GetId())
+		}
+	}
+
+	expect(pb.BillEvent_TYPE_CREATED, existing.ID)
+
+	// Changes made outside gRPC, such as through the HTML handlers, are
+	// streamed as long as they go through the watcher
+	bill := models.NewBill(time.Now(), issuerID, receiverID)
+	if err := watcher.Create(scoped, bill); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+	expect(pb.BillEvent_TYPE_CREATED, bill.ID)
+
+	bill.Paid = true
+	if err := watcher.Update(scoped, bill); err != nil {
+		t.Fatalf("Failed to update bill: %v", err)
+	}
+	expect(pb.BillEvent_TYPE_UPDATED, bill.ID)
+
+	if _, err := pb.NewBillServiceClient(conn).DeleteBill(ctx, &pb.DeleteBillRequest{Id: bill.ID}); err != nil {
+		t.Fatalf("Failed to delete bill: %v", err)
+	}
+	expect(pb.BillEvent_TYPE_DELETED, bill.ID)
+
+	// Bills of other workspaces are never streamed
+	other := createTestWorkspace(t, sqlDB, "Other")
+	otherCtx, _ := signIn(t, sqlDB, other, models.RoleAdmin, models.ScopeReadWrite)
+	otherIssuer, err := pb.NewIssuerServiceClient(conn).CreateIssuer(otherCtx, &pb.CreateIssuerRequest{Issuer: &pb.Issuer{Name: "Other Issuer"}})
+	if err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+	otherReceiver, err := pb.NewReceiverServiceClient(conn).CreateReceiver(otherCtx, &pb.CreateReceiverRequest{Receiver: &pb.Receiver{Name: "Other Receiver"}})
+	if err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+	otherItem, err := pb.NewBillItemServiceClient(conn).CreateBillItem(otherCtx, &pb.CreateBillItemRequest{BillItem: &pb.BillItem{Name: "Other Item", Price: 10}})
+	if err != nil {
+		t.Fatalf("Failed to create bill item: %v", err)
+	}
+	if _, err := pb.NewBillServiceClient(conn).CreateBill(otherCtx, &pb.CreateBillRequest{Bill: &pb.Bill{
+		IssuerId:   otherIssuer.Id,
+		ReceiverId: otherReceiver.Id,
+		DueDate:    timestamppb.Now(),
+		Items:      []*pb.BillItemAssignment{{ItemId: otherItem.Id, Quantity: 1, Price: 10}},
+	}}); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+
+	last := models.NewBill(time.Now(), issuerID, receiverID)
+	if err := watcher.Create(scoped, last); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+	expect(pb.BillEvent_TYPE_CREATED, last.ID)
+}
+
+func createTestWorkspace(t *testing.T, sqlDB *sql.DB, name string) int64 {
+	workspace := models.NewWorkspace(name)
+	if err := repository.NewRepositories(sqlDB).Workspaces.Create(context.Background(), workspace); err != nil {
+		t.Fatalf("Failed to create workspace: %v", err)
+	}
+	return workspace.ID
+}
+
+func TestAuthentication(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	conn, _ := setupServer(t, sqlDB)
+	issuers := pb.NewIssuerServiceClient(conn)
+	admin, adminUser := signIn(t, sqlDB, models.DefaultWorkspaceID, models.RoleAdmin, models.ScopeReadWrite)
+	issuer, err := issuers.CreateIssuer(admin, &pb.CreateIssuerRequest{Issuer: &pb.Issuer{Name: "Default Issuer"}})
+	if err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+
+	other := createTestWorkspace(t, sqlDB, "Other")
+	outsider, _ := signIn(t, sqlDB, other, models.RoleAdmin, models.ScopeReadWrite)
+	reader, _ := signIn(t, sqlDB, models.DefaultWorkspaceID, models.RoleAdmin, models.ScopeRead)
+	viewer, _ := signIn(t, sqlDB, models.DefaultWorkspaceID, models.RoleViewer, models.ScopeReadWrite)
+	accountant, _ := signIn(t, sqlDB, models.DefaultWorkspaceID, models.RoleAccountant, models.ScopeReadWrite)
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{
+			name: "Without a token",
+			call: func() error {
+				_, err := issuers.ListIssuers(context.Background(), &pb.ListIssuersRequest{})
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "With a workspace but no token",
+			call: func() error {
+				ctx := metadata.AppendToOutgoingContext(context.Background(), rpc.WorkspaceMetadataKey, "1")
+				_, err := issuers.ListIssuers(ctx, &pb.ListIssuersRequest{})
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "With an unknown token",
+			call: func() error {
+				_, err := issuers.ListIssuers(withToken(auth.TokenPrefix+"unknown"), &pb.ListIssuersRequest{})
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "Naming another workspace than the token",
+			call: func() error {
+				ctx := metadata.AppendToOutgoingContext(outsider, rpc.WorkspaceMetadataKey, fmt.Sprint(models.DefaultWorkspaceID))
+				_, err := issuers.GetIssuer(ctx, &pb.GetIssuerRequest{Id: issuer.Id})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "Reading a record of another workspace",
+			call: func() error {
+				_, err := issuers.GetIssuer(outsider, &pb.GetIssuerRequest{Id: issuer.Id})
+				return err
+			},
+			want: codes.NotFound,
+		},
+		{
+			name: "Writing with a read token",
+			call: func() error {
+				_, err := issuers.CreateIssuer(reader, &pb.CreateIssuerRequest{Issuer: &pb.Issuer{Name: "Read"}})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "Writing as a viewer",
+			call: func() error {
+				_, err := issuers.CreateIssuer(viewer, &pb.CreateIssuerRequest{Issuer: &pb.Issuer{Name: "Viewer"}})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "Deleting as an accountant",
+			call: func() error {
+				_, err := issuers.DeleteIssuer(accountant, &pb.DeleteIssuerRequest{Id: issuer.Id})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "Reading as a viewer",
+			call: func() error {
+				_, err := issuers.GetIssuer(viewer, &pb.GetIssuerRequest{Id: issuer.Id})
+				return err
+			},
+			want: codes.OK,
+		},
+		{
+			name: "Streaming without a token",
+			call: func() error {
+				stream, err := pb.NewBillServiceClient(conn).WatchBills(context.Background(), &pb.WatchBillsRequest{})
+				if err != nil {
+					return err
+				}
+				_, err = stream.Recv()
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Changes are made by the user of the token", func(t *testing.T) {
+		scoped := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+		events, err := repository.NewRepositories(sqlDB).Audit.List(scoped, models.AuditFilter{Entity: models.EntityIssuers, EntityID: issuer.Id})
+		if err != nil {
+			t.Fatalf("Failed to list events: %v", err)
+		}
+		if len(events) != 1 || events[0].ActorID == nil || *events[0].ActorID != adminUser.ID {
+			t.Errorf("Expected one event made by %s, got %+v", adminUser.Username, events)
+		}
+	})
+
+	t.Run("Members removed from the workspace are refused", func(t *testing.T) {
+		if err := repository.NewRepositories(sqlDB).Workspaces.RemoveMember(context.Background(), models.DefaultWorkspaceID, adminUser.ID); err != nil {
+			t.Fatalf("Failed to remove member: %v", err)
+		}
+		_, err := issuers.ListIssuers(admin, &pb.ListIssuersRequest{})
+		if got := status.Code(err); got != codes.PermissionDenied {
+			t.Errorf("Expected %v, got %v", codes.PermissionDenied, got)
+		}
+	})
+}
+
+func TestEveryMethodHasAPermission(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	repos := repository.NewRepositories(sqlDB)
+	watcher := rpc.NewBillWatcher(repos.Bills)
+	server := rpc.NewServer(0, repository.NewUnitOfWork(sqlDB), newTokens(sqlDB), repos.Workspaces, watcher,
+		repos.Receivers, repos.Issuers, repos.BillItems, repos.BillItemAssignments)
+
+	if missing := rpc.Unguarded(server); len(missing) > 0 {
+		t.Errorf("Methods registered in rpc.NewServer without an entry in rpc.Permissions: %v", missing)
+	}
+
+	registered := map[string]bool{}
+	for name, service := range server.GetServiceInfo() {
+		for _, method := range service.Methods {
+			registered["/"+name+"/"+method.Name] = true
+		}
+	}
+	for method := range rpc.Permissions {
+		if !registered[method] {
+			t.Errorf("rpc.Permissions guards %q which is not registered in rpc.NewServer", method)
+		}
+	}
+}
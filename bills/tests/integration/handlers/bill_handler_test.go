@@ -1,10 +1,13 @@
 package handlers_test
 
 import (
-	"bills/db"
+	"bills/internal/auth"
 	"bills/internal/handlers"
 	"bills/internal/models"
 	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
 	"database/sql"
 	"fmt"
 	"html/template"
@@ -16,49 +19,30 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
-	_ "github.com/mattn/go-sqlite3"
 )
 
-func setupTestDB(t *testing.T) *sql.DB {
-	// Use a file-based database for testing
-	testDB, err := sql.Open("sqlite3", "file::memory:?cache=shared")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	// Enable foreign keys
-	_, err = testDB.Exec("PRAGMA foreign_keys = ON")
-	if err != nil {
-		t.Fatalf("Failed to enable foreign keys: %v", err)
-	}
-
-	// Run migrations
-	if err := db.MigrateDB(testDB, "file::memory:?cache=shared"); err != nil {
-		t.Fatalf("Failed to run migrations: %v", err)
-	}
-
-	return testDB
-}
-
 func createTestData(t *testing.T, db *sql.DB) (int64, int64, int64) {
+	repos := repository.NewRepositories(db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
 	// Create test issuer
-	issuerRepo := repository.NewSQLiteIssuerRepository(db)
+	issuerRepo := repos.Issuers
 	issuer := models.NewIssuer("Test Issuer", "123456", "123 Street", "City", "State", "12345", "Country")
-	if err := issuerRepo.Create(issuer); err != nil {
+	if err := issuerRepo.Create(ctx, issuer); err != nil {
 		t.Fatalf("Failed to create test issuer: %v", err)
 	}
 
 	// Create test receiver
-	receiverRepo := repository.NewSQLiteReceiverRepository(db)
+	receiverRepo := repos.Receivers
 	receiver := models.NewReceiver("Test Receiver", "654321", "321 Street", "City", "State", "54321", "Country")
-	if err := receiverRepo.Create(receiver); err != nil {
+	if err := receiverRepo.Create(ctx, receiver); err != nil {
 		t.Fatalf("Failed to create test receiver: %v", err)
 	}
 
 	// Create test bill item
-	billItemRepo := repository.NewSQLiteBillItemRepository(db)
+	billItemRepo := repos.BillItems
 	billItem := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
-	if err := billItemRepo.Create(billItem); err != nil {
+	if err := billItemRepo.Create(ctx, billItem); err != nil {
 		t.Fatalf("Failed to create test bill item: %v", err)
 	}
 
@@ -66,21 +50,21 @@ func createTestData(t *testing.T, db *sql.DB) (int64, int64, int64) {
 }
 
 func TestCreateBill(t *testing.T) {
-	db := setupTestDB(t)
+	db := testdb.Open(t)
 	defer db.Close()
+	repos := repository.NewRepositories(db)
 
 	issuerID, receiverID, itemID := createTestData(t, db)
 
 	// Initialize repositories
-	billRepo := repository.NewSQLiteBillRepository(db)
-	receiverRepo := repository.NewSQLiteReceiverRepository(db)
-	issuerRepo := repository.NewSQLiteIssuerRepository(db)
-	billItemRepo := repository.NewSQLiteBillItemRepository(db)
-	billItemAssignRepo := repository.NewSQLiteBillItemAssignmentRepository(db)
+	billRepo := repos.Bills
+	receiverRepo := repos.Receivers
+	issuerRepo := repos.Issuers
+	billItemRepo := repos.BillItems
 
 	// Initialize handler
 	tmpl := template.Must(template.New("test").Parse("{{.}}"))
-	handler := handlers.NewBillHandler(billRepo, receiverRepo, issuerRepo, billItemRepo, billItemAssignRepo, tmpl)
+	handler := handlers.NewBillHandler(repository.NewUnitOfWork(db), billRepo, receiverRepo, issuerRepo, billItemRepo, repos.Audit, tmpl)
 
 	// Create Echo instance
 	e := echo.New()
@@ -101,6 +85,7 @@ func TestCreateBill(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
 
 	// Test CreateBill
 	if err := handler.CreateBill(c); err != nil {
@@ -108,7 +93,7 @@ func TestCreateBill(t *testing.T) {
 	}
 
 	// Verify bill was created
-	bills, err := billRepo.GetAll()
+	bills, err := billRepo.GetAll(c.Request().Context())
 	if err != nil {
 		t.Fatalf("Failed to get bills: %v", err)
 	}
@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBillPage(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	issuerID, receiverID, itemID := createTestData(t, db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	billRepo := repos.Bills
+	issuerRepo := repos.Issuers
+	handler := handlers.NewBillHandler(
+		repository.NewUnitOfWork(db),
+		billRepo,
+		repos.Receivers,
+		issuerRepo,
+		repos.BillItems,
+		repos.Audit,
+		template.Must(template.New("test").Parse("{{.}}")),
+	)
+
+	renderer := &captureRenderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	e.HTTPErrorHandler = handlers.ErrorHandler
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+			return next(c)
+		}
+	})
+	e.GET("/bills/:id", handler.RenderBill)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	bill := models.NewBill(time.Now().AddDate(0, 0, 14), issuerID, receiverID)
+	bill.Items = append(bill.Items, models.NewBillItemAssignment(0, itemID, 2, 100.00, "USD", 0.9))
+	bill.ResolveCurrency()
+	bill.CalculateTotals()
+	if err := billRepo.Create(ctx, bill); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+	bill.Paid = true
+	if err := billRepo.Update(ctx, bill); err != nil {
+		t.Fatalf("Failed to update bill: %v", err)
+	}
+
+	t.Run("Bill with its parties, lines and history", func(t *testing.T) {
+		rec := get(fmt.Sprintf("/bills/%d", bill.ID))
+		if rec.Code != http.StatusOK || renderer.name != "bill.html" {
+			t.Fatalf("Expected the bill page, got %d %q", rec.Code, renderer.name)
+		}
+
+		shown := renderer.data["Bill"].(*models.Bill)
+		if shown.Issuer.Street != "123 Street" || shown.Receiver.ZipCode != "54321" {
+			t.Errorf("Expected the full addresses of the parties, got %+v and %+v", shown.Issuer, shown.Receiver)
+		}
+		if len(shown.Items) != 1 || shown.Items[0].EURAmount != 180.00 || shown.Items[0].ExchangeRate != 0.9 {
+			t.Errorf("Expected the line with its rate and converted amount, got %+v", shown.Items)
+		}
+
+		// Newest first: the update, the line and the bill being created
+		history := renderer.data["History"].([]*models.AuditEvent)
+		if len(history) != 3 || history[0].Action != models.AuditUpdate || history[1].Entity != models.EntityBillLines {
+			t.Errorf("Expected the bill and line events, got %d events", len(history))
+		}
+	})
+
+	t.Run("Trashed issuer", func(t *testing.T) {
+		if err := issuerRepo.Delete(ctx, issuerID); err != nil {
+			t.Fatalf("Failed to trash issuer: %v", err)
+		}
+
+		rec := get(fmt.Sprintf("/bills/%d", bill.ID))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected the bill page, got %d %s", rec.Code, rec.Body.String())
+		}
+		shown := renderer.data["Bill"].(*models.Bill)
+		if shown.Issuer.Name != "Test Issuer" || renderer.data["IssuerTrashed"] != true {
+			t.Errorf("Expected the issuer to be shown by name, got %+v", shown.Issuer)
+		}
+	})
+
+	t.Run("Missing bill", func(t *testing.T) {
+		if rec := get("/bills/424242"); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", rec.Code)
+		}
+	})
+}
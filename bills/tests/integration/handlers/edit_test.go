@@ -0,0 +1,214 @@
+package handlers_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestEditForms(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	issuerID, receiverID, itemID := createTestData(t, db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	tmpl := template.Must(template.New("test").Parse("{{.}}"))
+	billRepo := repos.Bills
+	issuerRepo := repos.Issuers
+	billItemRepo := repos.BillItems
+	billHandler := handlers.NewBillHandler(
+		repository.NewUnitOfWork(db),
+		billRepo,
+		repos.Receivers,
+		issuerRepo,
+		billItemRepo,
+		repos.Audit,
+		tmpl,
+	)
+	issuerHandler := handlers.NewIssuerHandler(repository.NewUnitOfWork(db), issuerRepo, billRepo, tmpl)
+	billItemHandler := handlers.NewBillItemHandler(repository.NewUnitOfWork(db), billItemRepo, tmpl)
+
+	renderer := &captureRenderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	e.HTTPErrorHandler = handlers.ErrorHandler
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+			return next(c)
+		}
+	})
+	e.GET("/bills/:id/edit", billHandler.EditBill)
+	e.PUT("/bills/:id", billHandler.UpdateBill)
+	e.GET("/issuers/:id/edit", issuerHandler.EditIssuer)
+	e.PUT("/issuers/:id", issuerHandler.UpdateIssuer)
+	e.PUT("/bill-items/:id", billItemHandler.UpdateBillItem)
+
+	do := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Issuer form is filled in", func(t *testing.T) {
+		rec := do(http.MethodGet, fmt.Sprintf("/issuers/%d/edit", issuerID), nil)
+		if rec.Code != http.StatusOK || renderer.name != "issuer-edit-form" {
+			t.Fatalf("Expected the issuer edit form, got %d %q", rec.Code, renderer.name)
+		}
+		if form := renderer.data["Form"].(url.Values); form.Get("name") != "Test Issuer" || form.Get("zip_code") != "12345" {
+			t.Errorf("Expected the form to hold the issuer, got %v", form)
+		}
+	})
+
+	t.Run("Invalid issuer stays in its row", func(t *testing.T) {
+		rec := do(http.MethodPut, fmt.Sprintf("/issuers/%d", issuerID), url.Values{"name": {""}, "country": {"Slovenia"}})
+		if rec.Code != http.StatusUnprocessableEntity || renderer.name != "issuer-edit-form" {
+			t.Fatalf("Expected the issuer edit form back, got %d %q", rec.Code, renderer.name)
+		}
+		if got := rec.Header().Get("HX-Retarget"); got != fmt.Sprintf("#issuer-%d-edit", issuerID) {
+			t.Errorf("Expected the row to be replaced, got %q", got)
+		}
+		if errors := renderer.data["Errors"].(map[string]string); errors["name"] != "is required" {
+			t.Errorf("Expected the name to be required, got %v", errors)
+		}
+	})
+
+	t.Run("Issuer is updated", func(t *testing.T) {
+		form := url.Values{"name": {"Renamed Issuer"}, "vat_number": {"123456"}, "country": {"Slovenia"}}
+		rec := do(http.MethodPut, fmt.Sprintf("/issuers/%d", issuerID), form)
+		if rec.Code != http.StatusOK || renderer.name != "issuers-list" {
+			t.Fatalf("Expected the issuers list, got %d %q", rec.Code, renderer.name)
+		}
+		issuer, err := issuerRepo.GetByID(ctx, issuerID)
+		if err != nil {
+			t.Fatalf("Failed to get issuer: %v", err)
+		}
+		if issuer.Name != "Renamed Issuer" || issuer.Country != "Slovenia" {
+			t.Errorf("Expected the issuer to be updated, got %+v", issuer)
+		}
+	})
+
+	t.Run("Bill item is updated", func(t *testing.T) {
+		form := url.Values{"name": {"Hosting"}, "price": {"120.50"}, "currency": {models.DefaultCurrency()}}
+		rec := do(http.MethodPut, fmt.Sprintf("/bill-items/%d", itemID), form)
+		if rec.Code != http.StatusOK || renderer.name != "bill-items-list" {
+			t.Fatalf("Expected the bill items list, got %d %q", rec.Code, renderer.name)
+		}
+		item, err := billItemRepo.GetByID(ctx, itemID)
+		if err != nil {
+			t.Fatalf("Failed to get bill item: %v", err)
+		}
+		if item.Name != "Hosting" || item.Price != 120.50 {
+			t.Errorf("Expected the bill item to be updated, got %+v", item)
+		}
+	})
+
+	bill := models.NewBill(time.Now().AddDate(0, 0, 14), issuerID, receiverID)
+	kept := models.NewBillItemAssignment(0, itemID, 2, 100.00, models.DefaultCurrency(), 1.0)
+	removed := models.NewBillItemAssignment(0, itemID, 1, 50.00, models.DefaultCurrency(), 1.0)
+	bill.Items = append(bill.Items, kept, removed)
+	bill.CalculateTotals()
+	if err := billRepo.Create(ctx, bill); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+
+	t.Run("Bill form holds its lines", func(t *testing.T) {
+		rec := do(http.MethodGet, fmt.Sprintf("/bills/%d/edit", bill.ID), nil)
+		if rec.Code != http.StatusOK || renderer.name != "bill-edit-form" {
+			t.Fatalf("Expected the bill edit form, got %d %q", rec.Code, renderer.name)
+		}
+		lines := fmt.Sprintf("%+v", renderer.data["Lines"])
+		for _, want := range []string{fmt.Sprintf("LineID:%d", kept.ID), fmt.Sprintf("LineID:%d", removed.ID), "Quantity:2", "Price:50.00"} {
+			if !strings.Contains(lines, want) {
+				t.Errorf("Expected the lines to contain %s, got %s", want, lines)
+			}
+		}
+	})
+
+	billForm := func() url.Values {
+		form := url.Values{}
+		form.Set("issue_date", bill.IssueDate.Format("2006-01-02"))
+		form.Set("due_date", bill.DueDate.Format("2006-01-02"))
+		form.Set("issuer_id", fmt.Sprint(issuerID))
+		form.Set("receiver_id", fmt.Sprint(receiverID))
+		line := func(lineID, quantity, price string) {
+			form.Add("line_ids[]", lineID)
+			form.Add("item_ids[]", fmt.Sprint(itemID))
+			form.Add("quantities[]", quantity)
+			form.Add("prices[]", price)
+			form.Add("currencies[]", models.DefaultCurrency())
+			form.Add("exchange_rates[]", "1.0")
+		}
+		// The first line changes, the second is removed and a third added
+		line(fmt.Sprint(kept.ID), "3", "100.00")
+		line("", "1", "25.00")
+		return form
+	}
+
+	t.Run("Invalid bill stays in its row", func(t *testing.T) {
+		form := billForm()
+		form.Set("due_date", bill.IssueDate.AddDate(0, 0, -1).Format("2006-01-02"))
+
+		rec := do(http.MethodPut, fmt.Sprintf("/bills/%d", bill.ID), form)
+		if rec.Code != http.StatusUnprocessableEntity || renderer.name != "bill-edit-form" {
+			t.Fatalf("Expected the bill edit form back, got %d %q", rec.Code, renderer.name)
+		}
+		if got := rec.Header().Get("HX-Retarget"); got != fmt.Sprintf("#bill-%d-edit", bill.ID) {
+			t.Errorf("Expected the row to be replaced, got %q", got)
+		}
+		if errors := renderer.data["Errors"].(map[string]string); errors["due_date"] != "must not be before the issue date" {
+			t.Errorf("Expected the due date to be rejected, got %v", errors)
+		}
+
+		unchanged, err := billRepo.GetByID(ctx, bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if len(unchanged.Items) != 2 || unchanged.OriginalTotal != 250.00 {
+			t.Errorf("Expected the bill to be left as it was, got %d lines and %.2f", len(unchanged.Items), unchanged.OriginalTotal)
+		}
+	})
+
+	t.Run("Bill lines are added, changed and removed", func(t *testing.T) {
+		rec := do(http.MethodPut, fmt.Sprintf("/bills/%d", bill.ID), billForm())
+		if rec.Code != http.StatusOK || renderer.name != "bills-list" {
+			t.Fatalf("Expected the bills list, got %d %q %s", rec.Code, renderer.name, rec.Body.String())
+		}
+
+		updated, err := billRepo.GetByID(ctx, bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if len(updated.Items) != 2 {
+			t.Fatalf("Expected 2 lines, got %d", len(updated.Items))
+		}
+		if updated.Items[0].ID != kept.ID || updated.Items[0].Quantity != 3 {
+			t.Errorf("Expected the first line to be changed, got %+v", updated.Items[0])
+		}
+		if updated.Items[1].ID == removed.ID || updated.Items[1].Price != 25.00 {
+			t.Errorf("Expected the second line to be replaced, got %+v", updated.Items[1])
+		}
+		if updated.OriginalTotal != 325.00 || updated.EURTotal != 325.00 {
+			t.Errorf("Expected the totals to be recalculated to 325.00, got %.2f %.2f", updated.OriginalTotal, updated.EURTotal)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package handlers_test
+
+import (
+	"bills/internal/api"
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/tests/integration/testdb"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// nameRenderer writes the name of the template and the message it was given
+type nameRenderer struct{}
+
+func (nameRenderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	m, _ := data.(map[string]interface{})
+	_, err := fmt.Fprintf(w, "%s: %v", name, m["Message"])
+	return err
+}
+
+func TestErrorHandler(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	tmpl := template.Must(template.New("test").Parse("{{.}}"))
+	billHandler := handlers.NewBillHandler(
+		repository.NewUnitOfWork(db),
+		repos.Bills,
+		repos.Receivers,
+		repos.Issuers,
+		repos.BillItems,
+		repos.Audit,
+		tmpl,
+	)
+
+	e := echo.New()
+	e.Renderer = nameRenderer{}
+	e.HTTPErrorHandler = handlers.ErrorHandler
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+			return next(c)
+		}
+	})
+	e.POST("/bills", billHandler.CreateBill)
+	e.POST("/bills/:id/toggle", billHandler.TogglePaid)
+	// Gives up before the query gets to run
+	e.GET("/bills/list", billHandler.GetBillsList, middleware.ContextTimeoutWithConfig(middleware.ContextTimeoutConfig{
+		Timeout:      time.Nanosecond,
+		ErrorHandler: func(err error, c echo.Context) error { return err },
+	}))
+	e.GET("/conflict", func(c echo.Context) error {
+		return models.Conflict("still used by bills")
+	})
+	e.GET("/internal", func(c echo.Context) error {
+		return errors.New("database is locked")
+	})
+
+	do := func(method, target string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	htmx := map[string]string{"HX-Request": "true"}
+	jsonAccept := map[string]string{echo.HeaderAccept: echo.MIMEApplicationJSON}
+
+	t.Run("Toggling a missing bill", func(t *testing.T) {
+		rec := do(http.MethodPost, "/bills/424242/toggle", htmx)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("Expected 404, got %d %s", rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("HX-Retarget"); got != "#errors" {
+			t.Errorf("Expected the message to target #errors, got %q", got)
+		}
+		if got := rec.Body.String(); got != "error-message: bill not found" {
+			t.Errorf("Expected the error fragment, got %q", got)
+		}
+	})
+
+	t.Run("Error pages", func(t *testing.T) {
+		rec := do(http.MethodPost, "/bills/424242/toggle", nil)
+		if rec.Code != http.StatusNotFound || rec.Body.String() != "error.html: bill not found" {
+			t.Errorf("Expected the error page, got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		status  int
+		message string
+	}{
+		{"Missing bill", http.MethodPost, "/bills/424242/toggle", http.StatusNotFound, "bill not found"},
+		{"Invalid form", http.MethodPost, "/bills?due_date=soon", http.StatusUnprocessableEntity, "due_date must be a date; issuer_id is required; receiver_id is required; items must have at least one line"},
+		{"Conflict", http.MethodGet, "/conflict", http.StatusConflict, "still used by bills"},
+		{"Database timeout", http.MethodGet, "/bills/list", http.StatusServiceUnavailable, "the database took too long to answer"},
+		{"Internal error", http.MethodGet, "/internal", http.StatusInternalServerError, "Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.method, tt.path, jsonAccept)
+			if rec.Code != tt.status {
+				t.Fatalf("Expected %d, got %d %s", tt.status, rec.Code, rec.Body.String())
+			}
+
+			var resp api.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode error body: %v", err)
+			}
+			if resp.Error.Status != tt.status || resp.Error.Message != tt.message {
+				t.Errorf("Expected %d %q, got %d %q", tt.status, tt.message, resp.Error.Status, resp.Error.Message)
+			}
+			if strings.Contains(rec.Body.String(), "locked") {
+				t.Error("Expected internal errors to be kept from the client")
+			}
+		})
+	}
+}
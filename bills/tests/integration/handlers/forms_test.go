@@ -0,0 +1,217 @@
+package handlers_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// captureRenderer remembers the last template rendered and its data
+type captureRenderer struct {
+	name string
+	data map[string]interface{}
+}
+
+func (r *captureRenderer) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	r.name = name
+	r.data, _ = data.(map[string]interface{})
+	return nil
+}
+
+func TestInvalidForms(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	issuerID, receiverID, itemID := createTestData(t, db)
+
+	tmpl := template.Must(template.New("test").Parse("{{.}}"))
+	billRepo := repos.Bills
+	issuerRepo := repos.Issuers
+	billItemRepo := repos.BillItems
+	billHandler := handlers.NewBillHandler(
+		repository.NewUnitOfWork(db),
+		billRepo,
+		repos.Receivers,
+		issuerRepo,
+		billItemRepo,
+		repos.Audit,
+		tmpl,
+	)
+	issuerHandler := handlers.NewIssuerHandler(repository.NewUnitOfWork(db), issuerRepo, billRepo, tmpl)
+	billItemHandler := handlers.NewBillItemHandler(repository.NewUnitOfWork(db), billItemRepo, tmpl)
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	renderer := &captureRenderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	e.HTTPErrorHandler = handlers.ErrorHandler
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+			return next(c)
+		}
+	})
+	e.POST("/bills", billHandler.CreateBill)
+	e.POST("/issuers", issuerHandler.CreateIssuer)
+	e.POST("/bill-items", billItemHandler.CreateBillItem)
+
+	post := func(target string, form url.Values, htmx bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	today := time.Now()
+	billForm := func() url.Values {
+		form := url.Values{}
+		form.Set("issue_date", today.Format("2006-01-02"))
+		form.Set("due_date", today.AddDate(0, 0, 14).Format("2006-01-02"))
+		form.Set("issuer_id", fmt.Sprint(issuerID))
+		form.Set("receiver_id", fmt.Sprint(receiverID))
+		form.Add("item_ids[]", fmt.Sprint(itemID))
+		form.Add("quantities[]", "2")
+		form.Add("prices[]", "100.00")
+		form.Add("currencies[]", models.DefaultCurrency())
+		form.Add("exchange_rates[]", "1.0")
+		return form
+	}
+
+	billTests := []struct {
+		name   string
+		change func(form url.Values)
+		errors map[string]string
+	}{
+		{"Unparsable line", func(form url.Values) {
+			form.Add("item_ids[]", fmt.Sprint(itemID))
+			form.Add("quantities[]", "two")
+			form.Add("prices[]", "-1")
+			form.Add("currencies[]", models.DefaultCurrency())
+			form.Add("exchange_rates[]", "1.0")
+		}, map[string]string{
+			"items[1].quantity": "must be a whole number",
+			"items[1].price":    "must not be negative",
+		}},
+		{"Due before issued", func(form url.Values) {
+			form.Set("due_date", today.AddDate(0, 0, -1).Format("2006-01-02"))
+		}, map[string]string{
+			"due_date": "must not be before the issue date",
+		}},
+		{"No lines", func(form url.Values) {
+			for _, field := range []string{"item_ids[]", "quantities[]", "prices[]", "currencies[]", "exchange_rates[]"} {
+				form.Del(field)
+			}
+		}, map[string]string{
+			"items": "must have at least one line",
+		}},
+		{"Unsupported currency", func(form url.Values) {
+			form.Set("currencies[]", "XXX")
+		}, map[string]string{
+			"items[0].currency": `"XXX" is not supported`,
+		}},
+	}
+
+	for _, tt := range billTests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := billForm()
+			tt.change(form)
+
+			rec := post("/bills", form, true)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected 422, got %d %s", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("HX-Retarget"); got != "#bill-form" || renderer.name != "bill-form" {
+				t.Errorf("Expected the bill form back, got %q retargeted to %q", renderer.name, got)
+			}
+
+			errors, _ := renderer.data["Errors"].(map[string]string)
+			if len(errors) != len(tt.errors) {
+				t.Errorf("Expected errors %v, got %v", tt.errors, errors)
+			}
+			for field, message := range tt.errors {
+				if errors[field] != message {
+					t.Errorf("%s = %q, want %q", field, errors[field], message)
+				}
+			}
+			if form, _ := renderer.data["Form"].(url.Values); form.Get("issuer_id") != fmt.Sprint(issuerID) {
+				t.Errorf("Expected the form to keep what was sent, got %v", form)
+			}
+		})
+	}
+
+	t.Run("Lines are kept", func(t *testing.T) {
+		form := billForm()
+		form.Set("quantities[]", "0")
+		post("/bills", form, true)
+
+		lines := fmt.Sprintf("%+v", renderer.data["Lines"])
+		if !strings.Contains(lines, "Name:Test Item") || !strings.Contains(lines, "Error:quantity must be greater than zero") {
+			t.Errorf("Expected the line to be shown with its error, got %s", lines)
+		}
+	})
+
+	t.Run("Nothing is saved", func(t *testing.T) {
+		bills, err := billRepo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get bills: %v", err)
+		}
+		if len(bills) != 0 {
+			t.Errorf("Expected no bills to be saved, got %d", len(bills))
+		}
+	})
+
+	t.Run("Blank issuer name", func(t *testing.T) {
+		rec := post("/issuers", url.Values{"name": {" "}, "country": {"Slovenia"}}, true)
+		if rec.Code != http.StatusUnprocessableEntity || rec.Header().Get("HX-Retarget") != "#issuer-form" {
+			t.Fatalf("Expected the issuer form back, got %d %q", rec.Code, rec.Header().Get("HX-Retarget"))
+		}
+		if errors := renderer.data["Errors"].(map[string]string); errors["name"] != "is required" {
+			t.Errorf("Expected the name to be required, got %v", errors)
+		}
+	})
+
+	t.Run("Negative price without HTMX", func(t *testing.T) {
+		rec := post("/bill-items", url.Values{"name": {"Hosting"}, "price": {"-10"}}, false)
+		if rec.Code != http.StatusUnprocessableEntity || renderer.name != "bill-items.html" {
+			t.Fatalf("Expected the bill items page, got %d %q", rec.Code, renderer.name)
+		}
+		if errors := renderer.data["Errors"].(map[string]string); errors["price"] != "must not be negative" {
+			t.Errorf("Expected the price to be rejected, got %v", errors)
+		}
+	})
+
+	t.Run("Valid bill", func(t *testing.T) {
+		rec := post("/bills", billForm(), false)
+		if rec.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %d %s", rec.Code, rec.Body.String())
+		}
+
+		bills, err := billRepo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get bills: %v", err)
+		}
+		if len(bills) != 1 || bills[0].IssueDate.Format("2006-01-02") != today.Format("2006-01-02") {
+			t.Errorf("Expected the bill to be saved with its issue date, got %+v", bills)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBillsList(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	issuerID, receiverID, itemID := createTestData(t, db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	billRepo := repos.Bills
+	handler := handlers.NewBillHandler(
+		repository.NewUnitOfWork(db),
+		billRepo,
+		repos.Receivers,
+		repos.Issuers,
+		repos.BillItems,
+		repos.Audit,
+		template.Must(template.New("test").Parse("{{.}}")),
+	)
+
+	renderer := &captureRenderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	e.HTTPErrorHandler = handlers.ErrorHandler
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+			return next(c)
+		}
+	})
+	e.GET("/bills/list", handler.GetBillsList)
+	e.GET("/bills/:id/lines", handler.GetBillLines)
+	e.POST("/bills/:id/toggle", handler.TogglePaid)
+
+	do := func(method, target, currentURL string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("HX-Request", "true")
+		if currentURL != "" {
+			req.Header.Set("HX-Current-URL", currentURL)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// More bills than fit on a page, the first due first
+	var bills []*models.Bill
+	for i := 0; i < 60; i++ {
+		bill := models.NewBill(time.Now().AddDate(0, 0, i), issuerID, receiverID)
+		bill.Items = append(bill.Items, models.NewBillItemAssignment(0, itemID, 1, float64(10+i), models.DefaultCurrency(), 1.0))
+		bill.CalculateTotals()
+		if err := billRepo.Create(ctx, bill); err != nil {
+			t.Fatalf("Failed to create bill: %v", err)
+		}
+		bills = append(bills, bill)
+	}
+
+	t.Run("Filtered list is pushed to the address bar", func(t *testing.T) {
+		rec := do(http.MethodGet, "/bills/list?sort=due_date&status=unpaid&q=&min_amount=15", "")
+		if rec.Code != http.StatusOK || renderer.name != "bills-list" {
+			t.Fatalf("Expected the bills list, got %d %q %s", rec.Code, renderer.name, rec.Body.String())
+		}
+		if got := rec.Header().Get("HX-Push-Url"); got != "/bills?min_amount=15&sort=due_date&status=unpaid" {
+			t.Errorf("Expected the filters in the pushed URL, got %q", got)
+		}
+		page := renderer.data["Bills"].([]*models.Bill)
+		if len(page) != 50 || page[0].ID != bills[5].ID || renderer.data["Next"] != bills[54].ID {
+			t.Fatalf("Expected a first page of 50 bills from the 6th, got %d", len(page))
+		}
+		if sorts := renderer.data["Sorts"].(map[string]string); sorts["due_date"] != "-due_date" || sorts["amount"] != "amount" {
+			t.Errorf("Expected the due date header to reverse the order, got %v", sorts)
+		}
+	})
+
+	t.Run("Next page comes as rows", func(t *testing.T) {
+		rec := do(http.MethodGet, fmt.Sprintf("/bills/list?sort=due_date&min_amount=15&after=%d", bills[54].ID), "")
+		if rec.Code != http.StatusOK || renderer.name != "bills-rows" {
+			t.Fatalf("Expected the rows of the next page, got %d %q", rec.Code, renderer.name)
+		}
+		if rec.Header().Get("HX-Push-Url") != "" {
+			t.Error("Expected the URL to be left alone")
+		}
+		page := renderer.data["Bills"].([]*models.Bill)
+		if len(page) != 5 || page[0].ID != bills[55].ID || renderer.data["Next"] != int64(0) {
+			t.Errorf("Expected the last 5 bills, got %d", len(page))
+		}
+	})
+
+	t.Run("Lines load when a row is expanded", func(t *testing.T) {
+		do(http.MethodGet, "/bills/list", "")
+		if page := renderer.data["Bills"].([]*models.Bill); page[0].Items != nil {
+			t.Errorf("Expected the list to leave the lines out, got %d", len(page[0].Items))
+		}
+
+		rec := do(http.MethodGet, fmt.Sprintf("/bills/%d/lines", bills[0].ID), "")
+		if rec.Code != http.StatusOK || renderer.name != "bill-lines" {
+			t.Fatalf("Expected the lines of the bill, got %d %q", rec.Code, renderer.name)
+		}
+		if lines := renderer.data["Items"].([]*models.BillItemAssignment); len(lines) != 1 || lines[0].Price != 10 {
+			t.Errorf("Expected the line of the bill, got %+v", lines)
+		}
+	})
+
+	t.Run("Changes keep the filters of the page", func(t *testing.T) {
+		rec := do(http.MethodPost, fmt.Sprintf("/bills/%d/toggle", bills[0].ID), "http://localhost/bills?status=paid&after=3")
+		if rec.Code != http.StatusOK || renderer.name != "bills-list" {
+			t.Fatalf("Expected the bills list, got %d %q", rec.Code, renderer.name)
+		}
+		if page := renderer.data["Bills"].([]*models.Bill); len(page) != 1 || page[0].ID != bills[0].ID {
+			t.Errorf("Expected only the bill just paid, got %d bills", len(page))
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		rec := do(http.MethodGet, "/bills/list?sort=color", "")
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422, got %d", rec.Code)
+		}
+	})
+}
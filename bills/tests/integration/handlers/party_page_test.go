@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestPartyPages(t *testing.T) {
+	db := testdb.Open(t)
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	issuerID, receiverID, itemID := createTestData(t, db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	tmpl := template.Must(template.New("test").Parse("{{.}}"))
+	billRepo := repos.Bills
+	issuerHandler := handlers.NewIssuerHandler(repository.NewUnitOfWork(db), repos.Issuers, billRepo, tmpl)
+	receiverHandler := handlers.NewReceiverHandler(repository.NewUnitOfWork(db), repos.Receivers, billRepo, tmpl)
+
+	renderer := &captureRenderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	e.HTTPErrorHandler = handlers.ErrorHandler
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetWorkspace(c, &models.Workspace{ID: models.DefaultWorkspaceID})
+			return next(c)
+		}
+	})
+	e.GET("/issuers/:id", issuerHandler.RenderIssuer)
+	e.GET("/receivers/:id", receiverHandler.RenderReceiver)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	// One bill paid, one overdue and one still to fall due
+	for _, due := range []struct {
+		days int
+		paid bool
+	}{{-30, true}, {-7, false}, {14, false}} {
+		bill := models.NewBill(time.Now().AddDate(0, 0, due.days), issuerID, receiverID)
+		bill.Items = append(bill.Items, models.NewBillItemAssignment(0, itemID, 1, 100.00, models.DefaultCurrency(), 1.0))
+		bill.CalculateTotals()
+		bill.Paid = due.paid
+		if err := billRepo.Create(ctx, bill); err != nil {
+			t.Fatalf("Failed to create bill: %v", err)
+		}
+	}
+
+	t.Run("Issuer with all its bills", func(t *testing.T) {
+		rec := get(fmt.Sprintf("/issuers/%d", issuerID))
+		if rec.Code != http.StatusOK || renderer.name != "party.html" {
+			t.Fatalf("Expected the party page, got %d %q", rec.Code, renderer.name)
+		}
+		if party := renderer.data["Party"].(*models.Issuer); party.Name != "Test Issuer" {
+			t.Errorf("Expected the issuer, got %+v", party)
+		}
+		if bills := renderer.data["Bills"].([]*models.Bill); len(bills) != 3 {
+			t.Errorf("Expected 3 bills, got %d", len(bills))
+		}
+		want := models.BillTotals{Count: 3, Billed: 300.00, Paid: 100.00, Outstanding: 200.00}
+		if totals := renderer.data["Totals"].(*models.BillTotals); *totals != want {
+			t.Errorf("Expected %+v, got %+v", want, *totals)
+		}
+	})
+
+	t.Run("Quick filters keep the totals", func(t *testing.T) {
+		for status, count := range map[string]int{"paid": 1, "unpaid": 2, "overdue": 1} {
+			rec := get(fmt.Sprintf("/receivers/%d?status=%s", receiverID, status))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected the party page, got %d %s", rec.Code, rec.Body.String())
+			}
+			if bills := renderer.data["Bills"].([]*models.Bill); len(bills) != count {
+				t.Errorf("Expected %d %s bills, got %d", count, status, len(bills))
+			}
+			if totals := renderer.data["Totals"].(*models.BillTotals); totals.Count != 3 {
+				t.Errorf("Expected the totals to cover every bill, got %+v", totals)
+			}
+		}
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		if rec := get(fmt.Sprintf("/issuers/%d?status=lost", issuerID)); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Missing receiver", func(t *testing.T) {
+		if rec := get("/receivers/424242"); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", rec.Code)
+		}
+	})
+}
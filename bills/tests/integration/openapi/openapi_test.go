@@ -0,0 +1,98 @@
+package openapi_test
+
+import (
+	"bills/internal/openapi"
+	"bills/tests/integration/routes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	keys := routes.Registered(t)
+	if len(keys) == 0 {
+		t.Fatal("Expected to find routes in main.go")
+	}
+
+	registered := map[string]bool{}
+	for _, route := range keys {
+		registered[route] = true
+		if _, ok := openapi.Routes[route]; !ok {
+			t.Errorf("Route %q is registered in main.go but has no entry in openapi.Routes", route)
+		}
+	}
+
+	for route := range openapi.Routes {
+		if !registered[route] {
+			t.Errorf("openapi.Routes documents %q which is not registered in main.go", route)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	e := echo.New()
+	noop := func(c echo.Context) error { return nil }
+	e.GET("/bills", noop)
+	v1 := e.Group("/api/v1", func(next echo.HandlerFunc) echo.HandlerFunc { return next })
+	v1.GET("/bills/:id", noop)
+	v1.POST("/bills", noop)
+	v1.GET("/undocumented", noop)
+
+	if missing := openapi.Undocumented(e.Routes()); len(missing) != 1 || missing[0] != "GET /api/v1/undocumented" {
+		t.Errorf("Expected only the undocumented route to be reported, got %v", missing)
+	}
+
+	e.GET("/openapi.json", openapi.Handler(e))
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode document: %v", err)
+	}
+
+	if doc.OpenAPI == "" {
+		t.Error("Expected openapi version to be set")
+	}
+	if _, ok := doc.Paths["/api/v1/undocumented"]; ok {
+		t.Error("Expected undocumented route to be left out")
+	}
+
+	item, ok := doc.Paths["/api/v1/bills/{id}"]
+	if !ok || item.Get == nil {
+		t.Fatal("Expected GET /api/v1/bills/{id} to be documented")
+	}
+	if len(item.Get.Parameters) != 1 || item.Get.Parameters[0].Name != "id" {
+		t.Errorf("Expected id path parameter, got %+v", item.Get.Parameters)
+	}
+
+	create := doc.Paths["/api/v1/bills"]
+	if create == nil || create.Post == nil || create.Post.RequestBody == nil {
+		t.Fatal("Expected POST /api/v1/bills to have a request body")
+	}
+	if _, ok := create.Post.Responses["201"]; !ok {
+		t.Error("Expected POST /api/v1/bills to document a 201 response")
+	}
+
+	bill, ok := doc.Components.Schemas["Bill"]
+	if !ok {
+		t.Fatal("Expected Bill schema to be generated")
+	}
+	if due := bill.Properties["due_date"]; due == nil || due.Format != "date-time" {
+		t.Errorf("Expected due_date to be a date-time, got %+v", due)
+	}
+	if _, ok := bill.Properties["IssuerName"]; ok {
+		t.Error("Expected fields tagged json:\"-\" to be left out")
+	}
+	if items := bill.Properties["items"]; items == nil || items.Items == nil || items.Items.Ref != "#/components/schemas/BillItemAssignment" {
+		t.Errorf("Expected items to reference BillItemAssignment, got %+v", items)
+	}
+}
@@ -3,108 +3,44 @@ package repository_test
 import (
 	"bills/internal/models"
 	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
-func setupTestDB(t *testing.T) *sql.DB {
-	// Use an in-memory database for testing
-	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
+func createTestBill(t *testing.T, db *sql.DB) int64 {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	repos := repository.NewRepositories(db)
 
-	// Enable foreign keys
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
-	if err != nil {
-		t.Fatalf("Failed to enable foreign keys: %v", err)
+	// Create the issuer and receiver the bill refers to
+	issuer := models.NewIssuer("Test Issuer", "123456", "123 Street", "City", "State", "12345", "Country")
+	if err := repos.Issuers.Create(ctx, issuer); err != nil {
+		t.Fatalf("Failed to create test issuer: %v", err)
 	}
-
-	// Create tables
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS bill_items (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			price REAL NOT NULL,
-			currency TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS bills (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			due_date DATETIME NOT NULL,
-			currency TEXT NOT NULL,
-			original_total REAL NOT NULL,
-			eur_total REAL NOT NULL,
-			paid BOOLEAN DEFAULT FALSE,
-			issuer_id INTEGER NOT NULL,
-			receiver_id INTEGER NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS bill_item_assignments (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			bill_id INTEGER NOT NULL,
-			item_id INTEGER NOT NULL,
-			quantity INTEGER NOT NULL,
-			price REAL NOT NULL,
-			currency TEXT NOT NULL,
-			exchange_rate REAL NOT NULL,
-			original_amount REAL NOT NULL,
-			eur_amount REAL NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
-			FOREIGN KEY (item_id) REFERENCES bill_items(id) ON DELETE RESTRICT
-		);
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create tables: %v", err)
+	receiver := models.NewReceiver("Test Receiver", "654321", "321 Street", "City", "State", "54321", "Country")
+	if err := repos.Receivers.Create(ctx, receiver); err != nil {
+		t.Fatalf("Failed to create test receiver: %v", err)
 	}
 
-	return db
-}
-
-func createTestBill(t *testing.T, db *sql.DB) int64 {
 	// Create test bill
-	result, err := db.Exec(`
-		INSERT INTO bills (
-			due_date, currency, original_total, eur_total, paid,
-			issuer_id, receiver_id, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
-		time.Now(),
-		models.DefaultCurrency(),
-		0.0,
-		0.0,
-		false,
-		1, // Dummy issuer ID
-		1, // Dummy receiver ID
-		time.Now(),
-		time.Now(),
-	)
-	if err != nil {
+	bill := models.NewBill(time.Now(), issuer.ID, receiver.ID)
+	if err := repos.Bills.Create(ctx, bill); err != nil {
 		t.Fatalf("Failed to create test bill: %v", err)
 	}
 
-	billID, err := result.LastInsertId()
-	if err != nil {
-		t.Fatalf("Failed to get bill ID: %v", err)
-	}
-
-	return billID
+	return bill.ID
 }
 
 func createTestBillItem(t *testing.T, db *sql.DB) int64 {
 	// Create test bill item
-	billItemRepo := repository.NewSQLiteBillItemRepository(db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	billItemRepo := repository.NewRepositories(db).BillItems
 	billItem := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
-	if err := billItemRepo.Create(billItem); err != nil {
+	if err := billItemRepo.Create(ctx, billItem); err != nil {
 		t.Fatalf("Failed to create test bill item: %v", err)
 	}
 
@@ -112,17 +48,18 @@ func createTestBillItem(t *testing.T, db *sql.DB) int64 {
 }
 
 func TestBillItemAssignmentRepository(t *testing.T) {
-	db := setupTestDB(t)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	db := testdb.Open(t)
 	defer db.Close()
 
-	repo := repository.NewSQLiteBillItemAssignmentRepository(db)
+	repo := repository.NewRepositories(db).BillItemAssignments
 	billID := createTestBill(t, db)
 	itemID := createTestBillItem(t, db)
 
 	// Test Create
 	t.Run("Create", func(t *testing.T) {
 		assignment := models.NewBillItemAssignment(billID, itemID, 2, 100.00, models.DefaultCurrency(), 1.0)
-		err := repo.Create(assignment)
+		err := repo.Create(ctx, assignment)
 		if err != nil {
 			t.Fatalf("Failed to create assignment: %v", err)
 		}
@@ -138,12 +75,12 @@ func TestBillItemAssignmentRepository(t *testing.T) {
 	// Test GetByID
 	t.Run("GetByID", func(t *testing.T) {
 		assignment := models.NewBillItemAssignment(billID, itemID, 2, 100.00, models.DefaultCurrency(), 1.0)
-		err := repo.Create(assignment)
+		err := repo.Create(ctx, assignment)
 		if err != nil {
 			t.Fatalf("Failed to create assignment: %v", err)
 		}
 
-		retrieved, err := repo.GetByID(assignment.ID)
+		retrieved, err := repo.GetByID(ctx, assignment.ID)
 		if err != nil {
 			t.Fatalf("Failed to get assignment: %v", err)
 		}
@@ -172,7 +109,7 @@ func TestBillItemAssignmentRepository(t *testing.T) {
 
 	// Test Update
 	t.Run("Update", func(t *testing.T) {
-		assignments, err := repo.GetByBillID(billID)
+		assignments, err := repo.GetByBillID(ctx, billID)
 		if err != nil {
 			t.Fatalf("Failed to get assignments: %v", err)
 		}
@@ -181,12 +118,12 @@ func TestBillItemAssignmentRepository(t *testing.T) {
 		assignment.Quantity = 3
 		assignment.CalculateAmounts()
 
-		err = repo.Update(assignment)
+		err = repo.Update(ctx, assignment)
 		if err != nil {
 			t.Fatalf("Failed to update assignment: %v", err)
 		}
 
-		updated, err := repo.GetByID(assignment.ID)
+		updated, err := repo.GetByID(ctx, assignment.ID)
 		if err != nil {
 			t.Fatalf("Failed to get updated assignment: %v", err)
 		}
@@ -202,39 +139,36 @@ func TestBillItemAssignmentRepository(t *testing.T) {
 	// Test Delete
 	t.Run("Delete", func(t *testing.T) {
 		assignment := models.NewBillItemAssignment(billID, itemID, 2, 100.00, models.DefaultCurrency(), 1.0)
-		err := repo.Create(assignment)
+		err := repo.Create(ctx, assignment)
 		if err != nil {
 			t.Fatalf("Failed to create assignment: %v", err)
 		}
 
-		err = repo.Delete(assignment.ID)
+		err = repo.Delete(ctx, assignment.ID)
 		if err != nil {
 			t.Fatalf("Failed to delete assignment: %v", err)
 		}
 
-		deleted, err := repo.GetByID(assignment.ID)
-		if err != nil {
-			t.Fatalf("Failed to check deleted assignment: %v", err)
-		}
-		if deleted != nil {
-			t.Error("Expected assignment to be deleted")
+		_, err = repo.GetByID(ctx, assignment.ID)
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected assignment to be deleted, got %v", err)
 		}
 	})
 
 	// Test DeleteByBillID
 	t.Run("DeleteByBillID", func(t *testing.T) {
 		assignment := models.NewBillItemAssignment(billID, itemID, 2, 100.00, models.DefaultCurrency(), 1.0)
-		err := repo.Create(assignment)
+		err := repo.Create(ctx, assignment)
 		if err != nil {
 			t.Fatalf("Failed to create assignment: %v", err)
 		}
 
-		err = repo.DeleteByBillID(billID)
+		err = repo.DeleteByBillID(ctx, billID)
 		if err != nil {
 			t.Fatalf("Failed to delete assignments by bill ID: %v", err)
 		}
 
-		assignments, err := repo.GetByBillID(billID)
+		assignments, err := repo.GetByBillID(ctx, billID)
 		if err != nil {
 			t.Fatalf("Failed to check deleted assignments: %v", err)
 		}
@@ -3,53 +3,30 @@ package repository_test
 import (
 	"bills/internal/models"
 	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"testing"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
 func setupBillItemTestDB(t *testing.T) *sql.DB {
-	// Use an in-memory database for testing
-	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	// Enable foreign keys
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
-	if err != nil {
-		t.Fatalf("Failed to enable foreign keys: %v", err)
-	}
-
-	// Create tables
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS bill_items (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			price REAL NOT NULL,
-			currency TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		);
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create tables: %v", err)
-	}
-
-	return db
+	return testdb.Open(t)
 }
 
 func TestBillItemRepository(t *testing.T) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
 	db := setupBillItemTestDB(t)
 	defer db.Close()
 
-	repo := repository.NewSQLiteBillItemRepository(db)
+	repo := repository.NewRepositories(db).BillItems
 
 	// Test Create
 	t.Run("Create", func(t *testing.T) {
 		item := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
-		err := repo.Create(item)
+		err := repo.Create(ctx, item)
 		if err != nil {
 			t.Fatalf("Failed to create item: %v", err)
 		}
@@ -71,12 +48,12 @@ func TestBillItemRepository(t *testing.T) {
 	// Test GetByID
 	t.Run("GetByID", func(t *testing.T) {
 		item := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
-		err := repo.Create(item)
+		err := repo.Create(ctx, item)
 		if err != nil {
 			t.Fatalf("Failed to create item: %v", err)
 		}
 
-		retrieved, err := repo.GetByID(item.ID)
+		retrieved, err := repo.GetByID(ctx, item.ID)
 		if err != nil {
 			t.Fatalf("Failed to get item: %v", err)
 		}
@@ -97,7 +74,7 @@ func TestBillItemRepository(t *testing.T) {
 
 	// Test GetAll
 	t.Run("GetAll", func(t *testing.T) {
-		items, err := repo.GetAll()
+		items, err := repo.GetAll(ctx)
 		if err != nil {
 			t.Fatalf("Failed to get items: %v", err)
 		}
@@ -125,7 +102,7 @@ func TestBillItemRepository(t *testing.T) {
 	// Test Update
 	t.Run("Update", func(t *testing.T) {
 		item := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
-		err := repo.Create(item)
+		err := repo.Create(ctx, item)
 		if err != nil {
 			t.Fatalf("Failed to create item: %v", err)
 		}
@@ -133,12 +110,12 @@ func TestBillItemRepository(t *testing.T) {
 		item.Name = "Updated Item"
 		item.Price = 150.00
 		item.Currency = "USD"
-		err = repo.Update(item)
+		err = repo.Update(ctx, item)
 		if err != nil {
 			t.Fatalf("Failed to update item: %v", err)
 		}
 
-		updated, err := repo.GetByID(item.ID)
+		updated, err := repo.GetByID(ctx, item.ID)
 		if err != nil {
 			t.Fatalf("Failed to get updated item: %v", err)
 		}
@@ -157,22 +134,64 @@ func TestBillItemRepository(t *testing.T) {
 	// Test Delete
 	t.Run("Delete", func(t *testing.T) {
 		item := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
-		err := repo.Create(item)
+		err := repo.Create(ctx, item)
 		if err != nil {
 			t.Fatalf("Failed to create item: %v", err)
 		}
 
-		err = repo.Delete(item.ID)
+		err = repo.Delete(ctx, item.ID)
 		if err != nil {
 			t.Fatalf("Failed to delete item: %v", err)
 		}
 
-		deleted, err := repo.GetByID(item.ID)
+		_, err = repo.GetByID(ctx, item.ID)
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected item to be deleted, got %v", err)
+		}
+	})
+
+	// Test List
+	t.Run("List", func(t *testing.T) {
+		names := func(items []*models.BillItem) string {
+			var names []string
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			return strings.Join(names, ", ")
+		}
+		for _, item := range []*models.BillItem{
+			models.NewBillItem("Zeta Hosting", 30.00, "USD"),
+			models.NewBillItem("Alpha Hosting", 20.00, "EUR"),
+			models.NewBillItem("Beta Hosting", 10.00, "USD"),
+		} {
+			if err := repo.Create(ctx, item); err != nil {
+				t.Fatalf("Failed to create item: %v", err)
+			}
+		}
+
+		page, err := repo.List(ctx, models.BillItemFilter{Text: "hosting", Currency: "USD"})
 		if err != nil {
-			t.Fatalf("Failed to check deleted item: %v", err)
+			t.Fatalf("Failed to list items: %v", err)
+		}
+		if got := names(page.Items); got != "Beta Hosting, Zeta Hosting" {
+			t.Errorf("Expected the USD hosting items by name, got %s", got)
+		}
+
+		filter := models.BillItemFilter{Text: "HOSTING", Sort: "-" + models.BillItemSortPrice, Limit: 2}
+		first, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("Failed to list items: %v", err)
+		}
+		filter.After = first.Next
+		second, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("Failed to list items: %v", err)
+		}
+		if got := names(first.Items) + " | " + names(second.Items); got != "Zeta Hosting, Alpha Hosting | Beta Hosting" {
+			t.Errorf("Expected the items by price over two pages, got %s", got)
 		}
-		if deleted != nil {
-			t.Error("Expected item to be deleted")
+		if second.Next != 0 {
+			t.Errorf("Expected the last page, got next %d", second.Next)
 		}
 	})
 }
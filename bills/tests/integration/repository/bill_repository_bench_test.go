@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// seedBills stores n bills with two lines each in one transaction, quicker
+// than going through the repository
+func seedBills(b *testing.B, db *sql.DB, n int, issuerID, receiverID, itemID int64) {
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatalf("Failed to begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		var billID int64
+		err := tx.QueryRow(testdb.Rebind(`
+			INSERT INTO bills (workspace_id, issue_date, due_date, currency, original_total,
+				eur_total, paid, issuer_id, receiver_id, created_at, updated_at)
+			VALUES (?, ?, ?, 'EUR', 300, 300, ?, ?, ?, ?, ?)
+			RETURNING id
+		`), models.DefaultWorkspaceID, now, now.AddDate(0, 0, i%365), i%2 == 0, issuerID, receiverID, now, now).Scan(&billID)
+		if err != nil {
+			b.Fatalf("Failed to insert bill: %v", err)
+		}
+		for _, price := range []float64{100, 200} {
+			_, err := tx.Exec(testdb.Rebind(`
+				INSERT INTO bill_item_assignments (workspace_id, bill_id, item_id, quantity, price,
+					currency, exchange_rate, original_amount, eur_amount, created_at, updated_at)
+				VALUES (?, ?, ?, 1, ?, 'EUR', 1, ?, ?, ?, ?)
+			`), models.DefaultWorkspaceID, billID, itemID, price, price, price, now, now)
+			if err != nil {
+				b.Fatalf("Failed to insert line: %v", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func BenchmarkBillRepositoryGetAll(b *testing.B) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	db := setupBillTestDB(b)
+	defer db.Close()
+
+	repo := repository.NewRepositories(db).Bills
+	issuerID, receiverID, itemID := createTestData(b, db)
+	seedBills(b, db, 10000, issuerID, receiverID, itemID)
+
+	b.Run("GetAll", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bills, err := repo.GetAll(ctx)
+			if err != nil {
+				b.Fatalf("Failed to get bills: %v", err)
+			}
+			if len(bills) != 10000 || len(bills[0].Items) != 2 {
+				b.Fatalf("Expected 10000 bills with 2 lines, got %d", len(bills))
+			}
+		}
+	})
+
+	b.Run("List without items", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			page, err := repo.List(ctx, models.BillFilter{SkipItems: true})
+			if err != nil {
+				b.Fatalf("Failed to list bills: %v", err)
+			}
+			if len(page.Bills) != 10000 || page.Bills[0].Items != nil {
+				b.Fatalf("Expected 10000 bills without lines, got %d", len(page.Bills))
+			}
+		}
+	})
+
+	b.Run("List a page", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			page, err := repo.List(ctx, models.BillFilter{Limit: 50})
+			if err != nil {
+				b.Fatalf("Failed to list bills: %v", err)
+			}
+			if len(page.Bills) != 50 || len(page.Bills[0].Items) != 2 {
+				b.Fatalf("Expected 50 bills with 2 lines, got %d", len(page.Bills))
+			}
+		}
+	})
+}
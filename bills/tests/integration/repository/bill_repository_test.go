@@ -3,120 +3,42 @@ package repository_test
 import (
 	"bills/internal/models"
 	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
-func setupBillTestDB(t *testing.T) *sql.DB {
-	// Use an in-memory database for testing
-	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	// Enable foreign keys
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
-	if err != nil {
-		t.Fatalf("Failed to enable foreign keys: %v", err)
-	}
-
-	// Create tables
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS issuers (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			vat_number TEXT NOT NULL,
-			street TEXT NOT NULL,
-			city TEXT NOT NULL,
-			state TEXT NOT NULL,
-			zip_code TEXT NOT NULL,
-			country TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS receivers (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			vat_number TEXT NOT NULL,
-			street TEXT NOT NULL,
-			city TEXT NOT NULL,
-			state TEXT NOT NULL,
-			zip_code TEXT NOT NULL,
-			country TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS bill_items (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			price REAL NOT NULL,
-			currency TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS bills (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			due_date DATETIME NOT NULL,
-			currency TEXT NOT NULL,
-			original_total REAL NOT NULL,
-			eur_total REAL NOT NULL,
-			paid BOOLEAN DEFAULT FALSE,
-			issuer_id INTEGER NOT NULL,
-			receiver_id INTEGER NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			FOREIGN KEY (issuer_id) REFERENCES issuers(id),
-			FOREIGN KEY (receiver_id) REFERENCES receivers(id)
-		);
-
-		CREATE TABLE IF NOT EXISTS bill_item_assignments (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			bill_id INTEGER NOT NULL,
-			item_id INTEGER NOT NULL,
-			quantity INTEGER NOT NULL,
-			price REAL NOT NULL,
-			currency TEXT NOT NULL,
-			exchange_rate REAL NOT NULL,
-			original_amount REAL NOT NULL,
-			eur_amount REAL NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
-			FOREIGN KEY (item_id) REFERENCES bill_items(id) ON DELETE RESTRICT
-		);
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create tables: %v", err)
-	}
-
-	return db
+func setupBillTestDB(t testing.TB) *sql.DB {
+	return testdb.Open(t)
 }
 
-func createTestData(t *testing.T, db *sql.DB) (int64, int64, int64) {
+func createTestData(t testing.TB, db *sql.DB) (int64, int64, int64) {
+	repos := repository.NewRepositories(db)
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
 	// Create test issuer
-	issuerRepo := repository.NewSQLiteIssuerRepository(db)
+	issuerRepo := repos.Issuers
 	issuer := models.NewIssuer("Test Issuer", "123456", "123 Street", "City", "State", "12345", "Country")
-	if err := issuerRepo.Create(issuer); err != nil {
+	if err := issuerRepo.Create(ctx, issuer); err != nil {
 		t.Fatalf("Failed to create test issuer: %v", err)
 	}
 
 	// Create test receiver
-	receiverRepo := repository.NewSQLiteReceiverRepository(db)
+	receiverRepo := repos.Receivers
 	receiver := models.NewReceiver("Test Receiver", "654321", "321 Street", "City", "State", "54321", "Country")
-	if err := receiverRepo.Create(receiver); err != nil {
+	if err := receiverRepo.Create(ctx, receiver); err != nil {
 		t.Fatalf("Failed to create test receiver: %v", err)
 	}
 
 	// Create test bill item
-	billItemRepo := repository.NewSQLiteBillItemRepository(db)
+	billItemRepo := repos.BillItems
 	billItem := models.NewBillItem("Test Item", 100.00, models.DefaultCurrency())
-	if err := billItemRepo.Create(billItem); err != nil {
+	if err := billItemRepo.Create(ctx, billItem); err != nil {
 		t.Fatalf("Failed to create test bill item: %v", err)
 	}
 
@@ -124,10 +46,12 @@ func createTestData(t *testing.T, db *sql.DB) (int64, int64, int64) {
 }
 
 func TestBillRepository(t *testing.T) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
 	db := setupBillTestDB(t)
 	defer db.Close()
+	repos := repository.NewRepositories(db)
 
-	repo := repository.NewSQLiteBillRepository(db)
+	repo := repos.Bills
 	issuerID, receiverID, itemID := createTestData(t, db)
 
 	// Test Create
@@ -137,7 +61,7 @@ func TestBillRepository(t *testing.T) {
 		bill.Items = append(bill.Items, assignment)
 		bill.CalculateTotals()
 
-		err := repo.Create(bill)
+		err := repo.Create(ctx, bill)
 		if err != nil {
 			t.Fatalf("Failed to create bill: %v", err)
 		}
@@ -154,13 +78,13 @@ func TestBillRepository(t *testing.T) {
 		bill.Items = append(bill.Items, assignment)
 		bill.CalculateTotals()
 
-		err := repo.Create(bill)
+		err := repo.Create(ctx, bill)
 		if err != nil {
 			t.Fatalf("Failed to create bill: %v", err)
 		}
 
 		// Get the bill and verify
-		retrieved, err := repo.GetByID(bill.ID)
+		retrieved, err := repo.GetByID(ctx, bill.ID)
 		if err != nil {
 			t.Fatalf("Failed to get bill: %v", err)
 		}
@@ -194,7 +118,7 @@ func TestBillRepository(t *testing.T) {
 
 	// Test GetAll with items
 	t.Run("GetAll with items", func(t *testing.T) {
-		bills, err := repo.GetAll()
+		bills, err := repo.GetAll(ctx)
 		if err != nil {
 			t.Fatalf("Failed to get bills: %v", err)
 		}
@@ -235,18 +159,18 @@ func TestBillRepository(t *testing.T) {
 		bill.Items = append(bill.Items, assignment)
 		bill.CalculateTotals()
 
-		err := repo.Create(bill)
+		err := repo.Create(ctx, bill)
 		if err != nil {
 			t.Fatalf("Failed to create bill: %v", err)
 		}
 
 		bill.Paid = true
-		err = repo.Update(bill)
+		err = repo.Update(ctx, bill)
 		if err != nil {
 			t.Fatalf("Failed to update bill: %v", err)
 		}
 
-		updated, err := repo.GetByID(bill.ID)
+		updated, err := repo.GetByID(ctx, bill.ID)
 		if err != nil {
 			t.Fatalf("Failed to get updated bill: %v", err)
 		}
@@ -256,25 +180,257 @@ func TestBillRepository(t *testing.T) {
 		}
 	})
 
+	// Test UpdateWithItems
+	t.Run("UpdateWithItems", func(t *testing.T) {
+		bill := models.NewBill(time.Now(), issuerID, receiverID)
+		kept := models.NewBillItemAssignment(0, itemID, 2, 100.00, models.DefaultCurrency(), 1.0)
+		removed := models.NewBillItemAssignment(0, itemID, 1, 50.00, models.DefaultCurrency(), 1.0)
+		bill.Items = append(bill.Items, kept, removed)
+		bill.CalculateTotals()
+		if err := repo.Create(ctx, bill); err != nil {
+			t.Fatalf("Failed to create bill: %v", err)
+		}
+
+		// Change the first line, drop the second and add a new one
+		kept.Quantity = 3
+		added := models.NewBillItemAssignment(0, itemID, 1, 25.00, models.DefaultCurrency(), 1.0)
+		bill.Items = []*models.BillItemAssignment{kept, added}
+		if err := repo.UpdateWithItems(ctx, bill); err != nil {
+			t.Fatalf("Failed to update bill: %v", err)
+		}
+
+		updated, err := repo.GetByID(ctx, bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get updated bill: %v", err)
+		}
+		if len(updated.Items) != 2 {
+			t.Fatalf("Expected 2 items, got %d", len(updated.Items))
+		}
+		if updated.Items[0].ID != kept.ID || updated.Items[0].OriginalAmount != 300.00 {
+			t.Errorf("Expected the first line to be updated to 300.00, got %d %.2f", updated.Items[0].ID, updated.Items[0].OriginalAmount)
+		}
+		if updated.Items[1].ID != added.ID || added.ID == 0 {
+			t.Errorf("Expected the new line to be added, got %d", updated.Items[1].ID)
+		}
+		if updated.OriginalTotal != 325.00 || updated.EURTotal != 325.00 {
+			t.Errorf("Expected totals of 325.00, got %.2f %.2f", updated.OriginalTotal, updated.EURTotal)
+		}
+	})
+
+	t.Run("UpdateWithItems is atomic", func(t *testing.T) {
+		bill := models.NewBill(time.Now(), issuerID, receiverID)
+		bill.Items = append(bill.Items, models.NewBillItemAssignment(0, itemID, 2, 100.00, models.DefaultCurrency(), 1.0))
+		bill.CalculateTotals()
+		if err := repo.Create(ctx, bill); err != nil {
+			t.Fatalf("Failed to create bill: %v", err)
+		}
+
+		// A line of another bill cannot be moved over
+		other := models.NewBillItemAssignment(0, itemID, 1, 10.00, models.DefaultCurrency(), 1.0)
+		other.ID = 424242
+		bill.Items = append(bill.Items, other)
+		bill.Paid = true
+		if err := repo.UpdateWithItems(ctx, bill); !errors.Is(err, models.ErrNotFound) {
+			t.Fatalf("Expected a not found error, got %v", err)
+		}
+
+		unchanged, err := repo.GetByID(ctx, bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if unchanged.Paid || unchanged.OriginalTotal != 200.00 || len(unchanged.Items) != 1 {
+			t.Errorf("Expected the bill to be left as it was, got paid=%v total=%.2f lines=%d", unchanged.Paid, unchanged.OriginalTotal, len(unchanged.Items))
+		}
+	})
+
+	// Test GetByReceiver and SumByReceiver
+	t.Run("GetByReceiver and SumByReceiver", func(t *testing.T) {
+		receiver := models.NewReceiver("Other Receiver", "777777", "7 Street", "City", "State", "77777", "Country")
+		if err := repos.Receivers.Create(ctx, receiver); err != nil {
+			t.Fatalf("Failed to create receiver: %v", err)
+		}
+
+		for _, paid := range []bool{true, false, false} {
+			bill := models.NewBill(time.Now(), issuerID, receiver.ID)
+			bill.Items = append(bill.Items, models.NewBillItemAssignment(0, itemID, 1, 100.00, "USD", 0.5))
+			bill.ResolveCurrency()
+			bill.CalculateTotals()
+			bill.Paid = paid
+			if err := repo.Create(ctx, bill); err != nil {
+				t.Fatalf("Failed to create bill: %v", err)
+			}
+		}
+		trashed := models.NewBill(time.Now(), issuerID, receiver.ID)
+		if err := repo.Create(ctx, trashed); err != nil {
+			t.Fatalf("Failed to create bill: %v", err)
+		}
+		if err := repo.Delete(ctx, trashed.ID); err != nil {
+			t.Fatalf("Failed to delete bill: %v", err)
+		}
+
+		bills, err := repo.GetByReceiver(ctx, receiver.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bills: %v", err)
+		}
+		if len(bills) != 3 {
+			t.Fatalf("Expected the 3 bills of the receiver, got %d", len(bills))
+		}
+		for _, bill := range bills {
+			if bill.ReceiverID != receiver.ID || len(bill.Items) != 1 {
+				t.Errorf("Expected a bill of the receiver with its line, got %+v", bill)
+			}
+		}
+
+		totals, err := repo.SumByReceiver(ctx, receiver.ID)
+		if err != nil {
+			t.Fatalf("Failed to sum bills: %v", err)
+		}
+		want := models.BillTotals{Count: 3, Billed: 150.00, Paid: 50.00, Outstanding: 100.00}
+		if *totals != want {
+			t.Errorf("Expected %+v, got %+v", want, *totals)
+		}
+	})
+
 	// Test Delete
 	t.Run("Delete", func(t *testing.T) {
 		bill := models.NewBill(time.Now(), issuerID, receiverID)
-		err := repo.Create(bill)
+		err := repo.Create(ctx, bill)
 		if err != nil {
 			t.Fatalf("Failed to create bill: %v", err)
 		}
 
-		err = repo.Delete(bill.ID)
+		err = repo.Delete(ctx, bill.ID)
 		if err != nil {
 			t.Fatalf("Failed to delete bill: %v", err)
 		}
 
-		deleted, err := repo.GetByID(bill.ID)
+		_, err = repo.GetByID(ctx, bill.ID)
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected bill to be deleted, got %v", err)
+		}
+	})
+}
+
+func TestBillRepositoryList(t *testing.T) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	db := setupBillTestDB(t)
+	defer db.Close()
+	repos := repository.NewRepositories(db)
+
+	repo := repos.Bills
+	issuerID, receiverID, itemID := createTestData(t, db)
+
+	other := models.NewReceiver("Other_Receiver", "777777", "7 Street", "City", "State", "77777", "Country")
+	if err := repos.Receivers.Create(ctx, other); err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+
+	today := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, time.UTC)
+	create := func(days int, receiverID int64, price float64, currency string, paid bool) *models.Bill {
+		bill := models.NewBill(today.AddDate(0, 0, days), issuerID, receiverID)
+		bill.IssueDate = today.AddDate(0, 0, -60)
+		bill.Items = append(bill.Items, models.NewBillItemAssignment(0, itemID, 1, price, currency, 0.5))
+		bill.ResolveCurrency()
+		bill.CalculateTotals()
+		bill.Paid = paid
+		if err := repo.Create(ctx, bill); err != nil {
+			t.Fatalf("Failed to create bill: %v", err)
+		}
+		return bill
+	}
+	paid := create(-30, receiverID, 100, "USD", true)
+	overdue := create(-7, receiverID, 200, "USD", false)
+	pending := create(14, other.ID, 300, "GBP", false)
+	later := create(28, other.ID, 400, "USD", false)
+
+	ids := func(bills []*models.Bill) []int64 {
+		var ids []int64
+		for _, bill := range bills {
+			ids = append(ids, bill.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		filter models.BillFilter
+		want   []*models.Bill
+	}{
+		{"Latest due first", models.BillFilter{}, []*models.Bill{later, pending, overdue, paid}},
+		{"Paid", models.BillFilter{Status: models.BillStatusPaid}, []*models.Bill{paid}},
+		{"Unpaid", models.BillFilter{Status: models.BillStatusUnpaid}, []*models.Bill{later, pending, overdue}},
+		{"Overdue", models.BillFilter{Status: models.BillStatusOverdue}, []*models.Bill{overdue}},
+		{"Receiver", models.BillFilter{ReceiverID: other.ID}, []*models.Bill{later, pending}},
+		{"Currency", models.BillFilter{Currency: "GBP"}, []*models.Bill{pending}},
+		{"Due dates", models.BillFilter{DueFrom: today.AddDate(0, 0, -7), DueTo: today.AddDate(0, 0, 14)}, []*models.Bill{pending, overdue}},
+		{"Amounts", models.BillFilter{MinAmount: 100, MaxAmount: 150}, []*models.Bill{pending, overdue}},
+		{"Party name", models.BillFilter{Text: "other_"}, []*models.Bill{later, pending}},
+		{"Wildcards are literal", models.BillFilter{Text: "%"}, nil},
+		{"Item name", models.BillFilter{Text: "test item"}, []*models.Bill{later, pending, overdue, paid}},
+		{"Amount ascending", models.BillFilter{Sort: models.BillSortAmount}, []*models.Bill{paid, overdue, pending, later}},
+		{"Receiver descending", models.BillFilter{Sort: "-" + models.BillSortReceiver, Status: models.BillStatusUnpaid}, []*models.Bill{overdue, later, pending}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to list bills: %v", err)
+			}
+			if got, want := ids(page.Bills), ids(tt.want); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("Expected bills %v, got %v", want, got)
+			}
+			if page.Next != 0 {
+				t.Errorf("Expected a single page, got next %d", page.Next)
+			}
+		})
+	}
+
+	t.Run("Pages follow each other", func(t *testing.T) {
+		filter := models.BillFilter{Sort: models.BillSortIssuer, Limit: 3}
+		first, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("Failed to list bills: %v", err)
+		}
+		if len(first.Bills) != 3 || first.Next != first.Bills[2].ID {
+			t.Fatalf("Expected a first page of 3 bills, got %d and next %d", len(first.Bills), first.Next)
+		}
+
+		// The bills share their issuer, the id keeps them in order
+		filter.After = first.Next
+		second, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("Failed to list bills: %v", err)
+		}
+		if got := ids(append(first.Bills, second.Bills...)); fmt.Sprint(got) != fmt.Sprint(ids([]*models.Bill{paid, overdue, pending, later})) {
+			t.Errorf("Expected every bill once in order, got %v", got)
+		}
+		if second.Next != 0 {
+			t.Errorf("Expected the last page, got next %d", second.Next)
+		}
+	})
+
+	t.Run("Lines of more bills than fit in one query", func(t *testing.T) {
+		for i := 0; i < 600; i++ {
+			create(60, receiverID, float64(i), "USD", false)
+		}
+
+		bills, err := repo.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get bills: %v", err)
+		}
+		for _, bill := range bills {
+			if len(bill.Items) != 1 || bill.Items[0].BillID != bill.ID || bill.Items[0].OriginalAmount != bill.OriginalTotal {
+				t.Fatalf("Expected bill %d to have its own line, got %+v", bill.ID, bill.Items)
+			}
+		}
+
+		page, err := repo.List(ctx, models.BillFilter{SkipItems: true})
 		if err != nil {
-			t.Fatalf("Failed to check deleted bill: %v", err)
+			t.Fatalf("Failed to list bills: %v", err)
 		}
-		if deleted != nil {
-			t.Error("Expected bill to be deleted")
+		if len(page.Bills) != len(bills) || page.Bills[0].Items != nil {
+			t.Errorf("Expected every bill without its lines")
 		}
 	})
 }
@@ -0,0 +1,644 @@
+package repository_test
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// backend holds the repositories of one storage the conformance tests run
+// against, along with a second workspace to check they are scoped
+type backend struct {
+	issuers     repository.IssuerRepository
+	receivers   repository.ReceiverRepository
+	items       repository.BillItemRepository
+	bills       repository.BillRepository
+	assignments repository.BillItemAssignmentRepository
+	uow         repository.UnitOfWork
+	other       int64
+}
+
+// backends opens an empty storage of every kind the repositories come in.
+// The SQL one is SQLite, or PostgreSQL with TEST_DATABASE_URL
+var backends = map[string]func(t *testing.T) *backend{
+	"SQL": func(t *testing.T) *backend {
+		db := testdb.Open(t)
+		t.Cleanup(func() { db.Close() })
+
+		repos := repository.NewRepositories(db)
+		other := models.NewWorkspace("Other")
+		if err := repos.Workspaces.Create(context.Background(), other); err != nil {
+			t.Fatalf("Failed to create workspace: %v", err)
+		}
+		return &backend{
+			issuers:     repos.Issuers,
+			receivers:   repos.Receivers,
+			items:       repos.BillItems,
+			bills:       repos.Bills,
+			assignments: repos.BillItemAssignments,
+			uow:         repository.NewUnitOfWork(db),
+			other:       other.ID,
+		}
+	},
+	"Memory": func(t *testing.T) *backend {
+		store := repository.NewMemoryStore()
+		return &backend{
+			issuers:     repository.NewMemoryIssuerRepository(store),
+			receivers:   repository.NewMemoryReceiverRepository(store),
+			items:       repository.NewMemoryBillItemRepository(store),
+			bills:       repository.NewMemoryBillRepository(store),
+			assignments: repository.NewMemoryBillItemAssignmentRepository(store),
+			uow:         repository.NewMemoryUnitOfWork(store),
+			other:       models.DefaultWorkspaceID + 1,
+		}
+	},
+}
+
+// conformanceFixture is a bill with two lines and what it refers to
+type conformanceFixture struct {
+	issuer   *models.Issuer
+	receiver *models.Receiver
+	design   *models.BillItem
+	hosting  *models.BillItem
+	bill     *models.Bill
+}
+
+func createConformanceFixture(t *testing.T, ctx context.Context, b *backend) *conformanceFixture {
+	t.Helper()
+
+	f := &conformanceFixture{
+		issuer:   models.NewIssuer("Acme Studio", "CHE-123.456.789", "1 Main St", "Zurich", "ZH", "8000", "Switzerland"),
+		receiver: models.NewReceiver("Globex", "NL123456789B01", "2 Canal St", "Amsterdam", "NH", "1012", "Netherlands"),
+		design:   models.NewBillItem("Logo design", 100.00, "EUR"),
+		hosting:  models.NewBillItem("Web hosting", 20.00, "EUR"),
+	}
+	if err := b.issuers.Create(ctx, f.issuer); err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+	if err := b.receivers.Create(ctx, f.receiver); err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+	for _, item := range []*models.BillItem{f.design, f.hosting} {
+		if err := b.items.Create(ctx, item); err != nil {
+			t.Fatalf("Failed to create bill item: %v", err)
+		}
+	}
+
+	f.bill = models.NewBill(time.Now().AddDate(0, 0, 30), f.issuer.ID, f.receiver.ID)
+	f.bill.Items = []*models.BillItemAssignment{
+		models.NewBillItemAssignment(0, f.design.ID, 2, 100.00, "EUR", 1.0),
+		models.NewBillItemAssignment(0, f.hosting.ID, 1, 20.00, "EUR", 1.0),
+	}
+	f.bill.CalculateTotals()
+	if err := b.bills.Create(ctx, f.bill); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+	return f
+}
+
+// TestRepositoryConformance runs the same checks against every backend, so
+// the in-memory repositories behave the way the SQL ones do
+func TestRepositoryConformance(t *testing.T) {
+	tests := map[string]func(t *testing.T, b *backend){
+		"Issuers":     testIssuerConformance,
+		"Receivers":   testReceiverConformance,
+		"BillItems":   testBillItemConformance,
+		"Bills":       testBillConformance,
+		"Assignments": testAssignmentConformance,
+		"UnitOfWork":  testUnitOfWorkConformance,
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			for name, test := range tests {
+				t.Run(name, func(t *testing.T) {
+					test(t, open(t))
+				})
+			}
+		})
+	}
+}
+
+func testIssuerConformance(t *testing.T, b *backend) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	otherCtx := tenant.WithWorkspace(context.Background(), b.other)
+
+	zeta := models.NewIssuer("Zeta", "1", "Street", "City", "State", "1000", "Country")
+	alpha := models.NewIssuer("Alpha", "2", "Street", "City", "State", "1000", "Country")
+	for _, issuer := range []*models.Issuer{zeta, alpha} {
+		if err := b.issuers.Create(ctx, issuer); err != nil {
+			t.Fatalf("Failed to create issuer: %v", err)
+		}
+		if issuer.ID == 0 {
+			t.Fatal("Expected the issuer ID to be set")
+		}
+	}
+
+	all, err := b.issuers.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list issuers: %v", err)
+	}
+	if len(all) != 2 || all[0].Name != "Alpha" || all[1].Name != "Zeta" {
+		t.Fatalf("Expected the issuers sorted by name, got %v", all)
+	}
+
+	zeta.City = "Basel"
+	if err := b.issuers.Update(ctx, zeta); err != nil {
+		t.Fatalf("Failed to update issuer: %v", err)
+	}
+	stored, err := b.issuers.GetByID(ctx, zeta.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issuer: %v", err)
+	}
+	if stored.City != "Basel" || stored.VATNumber != "1" {
+		t.Errorf("Expected the update to be stored, got %+v", stored)
+	}
+
+	if _, err := b.issuers.GetByID(otherCtx, zeta.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound from another workspace, got %v", err)
+	}
+	if err := b.issuers.Update(otherCtx, zeta); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating from another workspace, got %v", err)
+	}
+
+	if err := b.issuers.Delete(ctx, zeta.ID); err != nil {
+		t.Fatalf("Failed to delete issuer: %v", err)
+	}
+	if _, err := b.issuers.GetByID(ctx, zeta.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a trashed issuer, got %v", err)
+	}
+	if err := b.issuers.Update(ctx, zeta); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a trashed issuer, got %v", err)
+	}
+	if err := b.issuers.Delete(ctx, zeta.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+	if all, _ := b.issuers.GetAll(ctx); len(all) != 1 {
+		t.Errorf("Expected the trashed issuer to be left out, got %d issuers", len(all))
+	}
+}
+
+func testReceiverConformance(t *testing.T, b *backend) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	otherCtx := tenant.WithWorkspace(context.Background(), b.other)
+
+	receiver := models.NewReceiver("Globex", "NL1", "Street", "City", "State", "1000", "Country")
+	if err := b.receivers.Create(ctx, receiver); err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+	foreign := models.NewReceiver("Initech", "US1", "Street", "City", "State", "1000", "Country")
+	if err := b.receivers.Create(otherCtx, foreign); err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+
+	all, err := b.receivers.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list receivers: %v", err)
+	}
+	if len(all) != 1 || all[0].ID != receiver.ID {
+		t.Fatalf("Expected only the receiver of the workspace, got %v", all)
+	}
+
+	receiver.Name = "Globex Corporation"
+	if err := b.receivers.Update(ctx, receiver); err != nil {
+		t.Fatalf("Failed to update receiver: %v", err)
+	}
+	stored, err := b.receivers.GetByID(ctx, receiver.ID)
+	if err != nil {
+		t.Fatalf("Failed to get receiver: %v", err)
+	}
+	if stored.Name != "Globex Corporation" {
+		t.Errorf("Expected the new name, got %q", stored.Name)
+	}
+
+	if err := b.receivers.Delete(otherCtx, receiver.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting from another workspace, got %v", err)
+	}
+	if err := b.receivers.Delete(ctx, receiver.ID); err != nil {
+		t.Fatalf("Failed to delete receiver: %v", err)
+	}
+	if _, err := b.receivers.GetByID(ctx, receiver.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a trashed receiver, got %v", err)
+	}
+}
+
+func testBillItemConformance(t *testing.T, b *backend) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	for _, item := range []*models.BillItem{
+		models.NewBillItem("Web hosting", 20.00, "EUR"),
+		models.NewBillItem("Logo design", 100.00, "EUR"),
+		models.NewBillItem("Web design", 80.00, "USD"),
+		models.NewBillItem("Support", 50.00, "EUR"),
+	} {
+		if err := b.items.Create(ctx, item); err != nil {
+			t.Fatalf("Failed to create bill item: %v", err)
+		}
+	}
+
+	names := func(items []*models.BillItem) []string {
+		var names []string
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	page, err := b.items.List(ctx, models.BillItemFilter{Text: "WEB", Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("Failed to list bill items: %v", err)
+	}
+	if got := fmt.Sprint(names(page.Items)); got != "[Web hosting]" {
+		t.Errorf("Expected the EUR items matching web in any case, got %s", got)
+	}
+
+	page, err = b.items.List(ctx, models.BillItemFilter{Sort: "-price", Limit: 3})
+	if err != nil {
+		t.Fatalf("Failed to list bill items: %v", err)
+	}
+	if got := fmt.Sprint(names(page.Items)); got != "[Logo design Web design Support]" {
+		t.Errorf("Expected the most expensive items first, got %s", got)
+	}
+	if page.Next != page.Items[2].ID {
+		t.Fatalf("Expected the next page to start after the last item, got %d", page.Next)
+	}
+
+	page, err = b.items.List(ctx, models.BillItemFilter{Sort: "-price", Limit: 3, After: page.Next})
+	if err != nil {
+		t.Fatalf("Failed to list bill items: %v", err)
+	}
+	if got := fmt.Sprint(names(page.Items)); got != "[Web hosting]" || page.Next != 0 {
+		t.Errorf("Expected the last page to hold the cheapest item, got %s next %d", got, page.Next)
+	}
+
+	all, err := b.items.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get bill items: %v", err)
+	}
+	if got := fmt.Sprint(names(all)); got != "[Logo design Support Web design Web hosting]" {
+		t.Errorf("Expected every item sorted by name, got %s", got)
+	}
+
+	// A page cannot start after an item of another workspace
+	otherCtx := tenant.WithWorkspace(context.Background(), b.other)
+	if err := b.items.Create(otherCtx, models.NewBillItem("Consulting", 10.00, "EUR")); err != nil {
+		t.Fatalf("Failed to create bill item: %v", err)
+	}
+	page, err = b.items.List(otherCtx, models.BillItemFilter{Sort: "-price", After: all[0].ID})
+	if err != nil {
+		t.Fatalf("Failed to list bill items: %v", err)
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("Expected nothing after an item of another workspace, got %s", names(page.Items))
+	}
+}
+
+func testBillConformance(t *testing.T, b *backend) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	otherCtx := tenant.WithWorkspace(context.Background(), b.other)
+	f := createConformanceFixture(t, ctx, b)
+
+	t.Run("Get with parties and lines", func(t *testing.T) {
+		bill, err := b.bills.GetByID(ctx, f.bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if bill.IssuerName != "Acme Studio" || bill.ReceiverName != "Globex" {
+			t.Errorf("Expected the names of the parties, got %q and %q", bill.IssuerName, bill.ReceiverName)
+		}
+		if bill.EURTotal != 220.00 || len(bill.Items) != 2 {
+			t.Fatalf("Expected 220.00 over 2 lines, got %.2f over %d", bill.EURTotal, len(bill.Items))
+		}
+		if bill.Items[0].BillItem.Name != "Logo design" || bill.Items[1].Quantity != 1 {
+			t.Errorf("Expected the lines in the order they were added, got %+v", bill.Items)
+		}
+		if _, err := b.bills.GetByID(otherCtx, f.bill.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound from another workspace, got %v", err)
+		}
+	})
+
+	t.Run("Create refuses records of another workspace", func(t *testing.T) {
+		bill := models.NewBill(time.Now(), f.issuer.ID, f.receiver.ID)
+		if err := b.bills.Create(otherCtx, bill); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace for foreign parties, got %v", err)
+		}
+
+		bill = models.NewBill(time.Now(), f.issuer.ID, f.receiver.ID)
+		bill.Items = []*models.BillItemAssignment{models.NewBillItemAssignment(0, 424242, 1, 10.00, "EUR", 1.0)}
+		if err := b.bills.Create(ctx, bill); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace for a missing item, got %v", err)
+		}
+		if all, _ := b.bills.GetAll(ctx); len(all) != 1 {
+			t.Errorf("Expected the failed bills not to be stored, got %d bills", len(all))
+		}
+	})
+
+	t.Run("List filters, sorts and pages", func(t *testing.T) {
+		overdue := models.NewBill(time.Now().AddDate(0, 0, -10), f.issuer.ID, f.receiver.ID)
+		overdue.Items = []*models.BillItemAssignment{models.NewBillItemAssignment(0, f.hosting.ID, 5, 20.00, "EUR", 1.0)}
+		overdue.CalculateTotals()
+		paid := models.NewBill(time.Now().AddDate(0, 0, 5), f.issuer.ID, f.receiver.ID)
+		paid.Paid = true
+		paid.EURTotal, paid.OriginalTotal = 500.00, 500.00
+		for _, bill := range []*models.Bill{overdue, paid} {
+			if err := b.bills.Create(ctx, bill); err != nil {
+				t.Fatalf("Failed to create bill: %v", err)
+			}
+		}
+
+		ids := func(filter models.BillFilter) string {
+			t.Helper()
+			page, err := b.bills.List(ctx, filter)
+			if err != nil {
+				t.Fatalf("Failed to list bills: %v", err)
+			}
+			var ids []int64
+			for _, bill := range page.Bills {
+				ids = append(ids, bill.ID)
+			}
+			return fmt.Sprint(ids, page.Next)
+		}
+
+		checks := []struct {
+			name   string
+			filter models.BillFilter
+			want   []int64
+			next   int64
+		}{
+			{"latest due first", models.BillFilter{}, []int64{f.bill.ID, paid.ID, overdue.ID}, 0},
+			{"overdue", models.BillFilter{Status: models.BillStatusOverdue}, []int64{overdue.ID}, 0},
+			{"paid", models.BillFilter{Status: models.BillStatusPaid}, []int64{paid.ID}, 0},
+			{"amount range", models.BillFilter{MinAmount: 100, MaxAmount: 300, Sort: "amount"}, []int64{overdue.ID, f.bill.ID}, 0},
+			{"item name", models.BillFilter{Text: "logo"}, []int64{f.bill.ID}, 0},
+			{"due range", models.BillFilter{DueFrom: time.Now(), DueTo: time.Now().AddDate(0, 0, 5)}, []int64{paid.ID}, 0},
+			{"first page", models.BillFilter{Sort: "due_date", Limit: 2}, []int64{overdue.ID, paid.ID}, paid.ID},
+			{"second page", models.BillFilter{Sort: "due_date", Limit: 2, After: paid.ID}, []int64{f.bill.ID}, 0},
+		}
+		for _, check := range checks {
+			if got, want := ids(check.filter), fmt.Sprint(check.want, check.next); got != want {
+				t.Errorf("%s: expected %s, got %s", check.name, want, got)
+			}
+		}
+
+		// A page cannot start after a bill of another workspace
+		createConformanceFixture(t, otherCtx, b)
+		page, err := b.bills.List(otherCtx, models.BillFilter{Sort: "due_date", After: paid.ID})
+		if err != nil {
+			t.Fatalf("Failed to list bills: %v", err)
+		}
+		if len(page.Bills) != 0 {
+			t.Errorf("Expected nothing after a bill of another workspace, got %d bills", len(page.Bills))
+		}
+
+		page, err = b.bills.List(ctx, models.BillFilter{SkipItems: true})
+		if err != nil {
+			t.Fatalf("Failed to list bills: %v", err)
+		}
+		for _, bill := range page.Bills {
+			if len(bill.Items) != 0 {
+				t.Errorf("Expected no lines with SkipItems, bill %d has %d", bill.ID, len(bill.Items))
+			}
+		}
+
+		totals, err := b.bills.SumByIssuer(ctx, f.issuer.ID)
+		if err != nil {
+			t.Fatalf("Failed to sum bills: %v", err)
+		}
+		if totals.Count != 3 || totals.Billed != 820.00 || totals.Paid != 500.00 || totals.Outstanding != 320.00 {
+			t.Errorf("Expected 3 bills, 820 billed and 500 paid, got %+v", totals)
+		}
+
+		for _, bill := range []*models.Bill{overdue, paid} {
+			if err := b.bills.Delete(ctx, bill.ID); err != nil {
+				t.Fatalf("Failed to delete bill: %v", err)
+			}
+		}
+	})
+
+	t.Run("UpdateWithItems adds, changes and removes lines", func(t *testing.T) {
+		bill, err := b.bills.GetByID(ctx, f.bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		bill.Items[0].Quantity = 3
+		bill.Items = []*models.BillItemAssignment{
+			bill.Items[0],
+			models.NewBillItemAssignment(0, f.hosting.ID, 2, 20.00, "EUR", 1.0),
+		}
+		if err := b.bills.UpdateWithItems(ctx, bill); err != nil {
+			t.Fatalf("Failed to update bill: %v", err)
+		}
+
+		stored, err := b.bills.GetByID(ctx, f.bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if stored.EURTotal != 340.00 || len(stored.Items) != 2 {
+			t.Fatalf("Expected 340.00 over 2 lines, got %.2f over %d", stored.EURTotal, len(stored.Items))
+		}
+		if stored.Items[0].EURAmount != 300.00 || stored.Items[1].ID != bill.Items[1].ID {
+			t.Errorf("Expected the changed line and the new one, got %+v", stored.Items)
+		}
+
+		bill.Items = append(bill.Items, &models.BillItemAssignment{ID: 424242, ItemID: f.design.ID, Quantity: 1})
+		if err := b.bills.UpdateWithItems(ctx, bill); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a line of no bill, got %v", err)
+		}
+		if stored, _ := b.bills.GetByID(ctx, f.bill.ID); stored.EURTotal != 340.00 {
+			t.Errorf("Expected the failed update to leave the bill as it was, got %.2f", stored.EURTotal)
+		}
+	})
+
+	t.Run("Update keeps trashed parties", func(t *testing.T) {
+		if err := b.issuers.Delete(ctx, f.issuer.ID); err != nil {
+			t.Fatalf("Failed to delete issuer: %v", err)
+		}
+		bill, err := b.bills.GetByID(ctx, f.bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		bill.Paid = true
+		if err := b.bills.Update(ctx, bill); err != nil {
+			t.Fatalf("Failed to update bill: %v", err)
+		}
+		if bill.IssuerName != "Acme Studio" {
+			t.Errorf("Expected the name of the trashed issuer, got %q", bill.IssuerName)
+		}
+
+		replacement := models.NewReceiver("Initech", "US1", "Street", "City", "State", "1000", "Country")
+		if err := b.receivers.Create(otherCtx, replacement); err != nil {
+			t.Fatalf("Failed to create receiver: %v", err)
+		}
+		bill.ReceiverID = replacement.ID
+		if err := b.bills.Update(ctx, bill); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace for a foreign receiver, got %v", err)
+		}
+	})
+
+	t.Run("Delete moves the bill to the trash", func(t *testing.T) {
+		if err := b.bills.Delete(ctx, f.bill.ID); err != nil {
+			t.Fatalf("Failed to delete bill: %v", err)
+		}
+		if _, err := b.bills.GetByID(ctx, f.bill.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a trashed bill, got %v", err)
+		}
+		if err := b.bills.Delete(ctx, f.bill.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+		}
+		if all, _ := b.bills.GetAll(ctx); len(all) != 0 {
+			t.Errorf("Expected no bills outside the trash, got %d", len(all))
+		}
+	})
+}
+
+func testAssignmentConformance(t *testing.T, b *backend) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	otherCtx := tenant.WithWorkspace(context.Background(), b.other)
+	f := createConformanceFixture(t, ctx, b)
+
+	line := models.NewBillItemAssignment(f.bill.ID, f.hosting.ID, 4, 20.00, "EUR", 1.0)
+	if err := b.assignments.Create(ctx, line); err != nil {
+		t.Fatalf("Failed to create assignment: %v", err)
+	}
+
+	stored, err := b.assignments.GetByID(ctx, line.ID)
+	if err != nil {
+		t.Fatalf("Failed to get assignment: %v", err)
+	}
+	if stored.BillID != f.bill.ID || stored.EURAmount != 80.00 || stored.BillItem.Name != "Web hosting" {
+		t.Errorf("Expected the line with its item, got %+v", stored)
+	}
+	if _, err := b.assignments.GetByID(otherCtx, line.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound from another workspace, got %v", err)
+	}
+
+	stored.Quantity = 1
+	stored.CalculateAmounts()
+	if err := b.assignments.Update(ctx, stored); err != nil {
+		t.Fatalf("Failed to update assignment: %v", err)
+	}
+	lines, err := b.assignments.GetByBillID(ctx, f.bill.ID)
+	if err != nil {
+		t.Fatalf("Failed to get assignments: %v", err)
+	}
+	if len(lines) != 3 || lines[2].ID != line.ID || lines[2].EURAmount != 20.00 {
+		t.Fatalf("Expected the updated line last of 3, got %+v", lines)
+	}
+
+	if err := b.assignments.Delete(ctx, line.ID); err != nil {
+		t.Fatalf("Failed to delete assignment: %v", err)
+	}
+	if _, err := b.assignments.GetByID(ctx, line.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a deleted line, got %v", err)
+	}
+	if err := b.assignments.Delete(ctx, line.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	if err := b.items.Delete(ctx, f.design.ID); err != nil {
+		t.Fatalf("Failed to delete bill item: %v", err)
+	}
+	trashed := models.NewBillItemAssignment(f.bill.ID, f.design.ID, 1, 100.00, "EUR", 1.0)
+	if err := b.assignments.Create(ctx, trashed); !errors.Is(err, repository.ErrNotInWorkspace) {
+		t.Errorf("Expected ErrNotInWorkspace for a trashed item, got %v", err)
+	}
+
+	if err := b.assignments.DeleteByBillID(ctx, f.bill.ID); err != nil {
+		t.Fatalf("Failed to delete the lines of the bill: %v", err)
+	}
+	if lines, _ := b.assignments.GetByBillID(ctx, f.bill.ID); len(lines) != 0 {
+		t.Errorf("Expected no lines left, got %d", len(lines))
+	}
+}
+
+func testUnitOfWorkConformance(t *testing.T, b *backend) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	f := createConformanceFixture(t, ctx, b)
+
+	committed := false
+	err := b.uow.Do(ctx, func(tx *repository.Tx) error {
+		line := models.NewBillItemAssignment(f.bill.ID, f.hosting.ID, 1, 20.00, "EUR", 1.0)
+		if err := tx.BillItemAssignments.Create(ctx, line); err != nil {
+			return err
+		}
+		bill, err := tx.Bills.GetByID(ctx, f.bill.ID)
+		if err != nil {
+			return err
+		}
+		bill.CalculateTotals()
+		tx.AfterCommit(func() { committed = true })
+		return tx.Bills.Update(ctx, bill)
+	})
+	if err != nil {
+		t.Fatalf("Failed to run the unit of work: %v", err)
+	}
+	if !committed {
+		t.Error("Expected the after commit function to run")
+	}
+	if bill, _ := b.bills.GetByID(ctx, f.bill.ID); bill.EURTotal != 240.00 || len(bill.Items) != 3 {
+		t.Errorf("Expected the line and the totals to be committed, got %.2f over %d lines", bill.EURTotal, len(bill.Items))
+	}
+
+	failure := errors.New("payment provider is down")
+	err = b.uow.Do(ctx, func(tx *repository.Tx) error {
+		if err := tx.BillItemAssignments.DeleteByBillID(ctx, f.bill.ID); err != nil {
+			return err
+		}
+		tx.AfterCommit(func() { t.Error("Expected the after commit function not to run") })
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the error of the work, got %v", err)
+	}
+	if lines, _ := b.assignments.GetByBillID(ctx, f.bill.ID); len(lines) != 3 {
+		t.Errorf("Expected the rollback to keep the 3 lines, got %d", len(lines))
+	}
+}
+
+// TestMemoryStoreConcurrency writes to one store from many goroutines, run it
+// with -race
+func TestMemoryStoreConcurrency(t *testing.T) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	b := backends["Memory"](t)
+	f := createConformanceFixture(t, ctx, b)
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bill := models.NewBill(time.Now(), f.issuer.ID, f.receiver.ID)
+			bill.Items = []*models.BillItemAssignment{models.NewBillItemAssignment(0, f.hosting.ID, 1, 20.00, "EUR", 1.0)}
+			bill.CalculateTotals()
+			if err := b.bills.Create(ctx, bill); err != nil {
+				t.Errorf("Failed to create bill: %v", err)
+			}
+			if _, err := b.bills.List(ctx, models.BillFilter{}); err != nil {
+				t.Errorf("Failed to list bills: %v", err)
+			}
+			err := b.uow.Do(ctx, func(tx *repository.Tx) error {
+				bill.Paid = true
+				return tx.Bills.Update(ctx, bill)
+			})
+			if err != nil {
+				t.Errorf("Failed to run the unit of work: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	totals, err := b.bills.SumByIssuer(ctx, f.issuer.ID)
+	if err != nil {
+		t.Fatalf("Failed to sum bills: %v", err)
+	}
+	if totals.Count != workers+1 || totals.Paid != workers*20.00 {
+		t.Errorf("Expected %d bills with %d paid, got %+v", workers+1, workers, totals)
+	}
+}
@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSQLiteUnitOfWork(t *testing.T) {
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	db := setupBillTestDB(t)
+	defer db.Close()
+
+	issuerID, receiverID, itemID := createTestData(t, db)
+	uow := repository.NewUnitOfWork(db)
+	bills := repository.NewRepositories(db).Bills
+
+	newBill := func(itemID int64) *models.Bill {
+		bill := models.NewBill(time.Now(), issuerID, receiverID)
+		bill.Items = append(bill.Items, models.NewBillItemAssignment(0, itemID, 1, 100.00, models.DefaultCurrency(), 1.0))
+		bill.CalculateTotals()
+		return bill
+	}
+	countBills := func(t *testing.T) int {
+		t.Helper()
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM bills").Scan(&count); err != nil {
+			t.Fatalf("Failed to count bills: %v", err)
+		}
+		return count
+	}
+
+	t.Run("Commits the work of every repository", func(t *testing.T) {
+		bill := newBill(itemID)
+		committed := false
+		err := uow.Do(ctx, func(tx *repository.Tx) error {
+			if err := tx.Bills.Create(ctx, bill); err != nil {
+				return err
+			}
+			tx.AfterCommit(func() { committed = true })
+
+			bill.Items[0].Quantity = 3
+			bill.Items[0].CalculateAmounts()
+			if err := tx.BillItemAssignments.Update(ctx, bill.Items[0]); err != nil {
+				return err
+			}
+			bill.CalculateTotals()
+			return tx.Bills.Update(ctx, bill)
+		})
+		if err != nil {
+			t.Fatalf("Failed to run the unit of work: %v", err)
+		}
+		if !committed {
+			t.Error("Expected the after commit function to run")
+		}
+
+		stored, err := bills.GetByID(ctx, bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if stored.OriginalTotal != 300.00 || stored.Items[0].Quantity != 3 {
+			t.Errorf("Expected the line and the totals to be updated, got %.2f with %d", stored.OriginalTotal, stored.Items[0].Quantity)
+		}
+
+		var events int
+		if err := db.QueryRow(testdb.Rebind("SELECT COUNT(*) FROM audit_events WHERE entity = 'bills' AND entity_id = ?"), bill.ID).Scan(&events); err != nil {
+			t.Fatalf("Failed to count audit events: %v", err)
+		}
+		if events != 2 {
+			t.Errorf("Expected the create and the update to be audited, got %d events", events)
+		}
+	})
+
+	t.Run("Rolls back everything when the work fails", func(t *testing.T) {
+		before := countBills(t)
+		committed := false
+		failure := errors.New("payment provider is down")
+		err := uow.Do(ctx, func(tx *repository.Tx) error {
+			if err := tx.Bills.Create(ctx, newBill(itemID)); err != nil {
+				return err
+			}
+			tx.AfterCommit(func() { committed = true })
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("Expected the error of the work, got %v", err)
+		}
+		if committed {
+			t.Error("Expected the after commit function not to run")
+		}
+		if got := countBills(t); got != before {
+			t.Errorf("Expected %d bills after the rollback, got %d", before, got)
+		}
+	})
+
+	t.Run("Rolls back when the work panics", func(t *testing.T) {
+		before := countBills(t)
+		func() {
+			defer func() { recover() }()
+			uow.Do(ctx, func(tx *repository.Tx) error {
+				if err := tx.Bills.Create(ctx, newBill(itemID)); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		}()
+		if got := countBills(t); got != before {
+			t.Errorf("Expected %d bills after the panic, got %d", before, got)
+		}
+	})
+
+	t.Run("A failed call leaves nothing behind when the work goes on", func(t *testing.T) {
+		before := countBills(t)
+		err := uow.Do(ctx, func(tx *repository.Tx) error {
+			// The bill row is written before the line is found to refer to
+			// a missing catalog item
+			if err := tx.Bills.Create(ctx, newBill(424242)); !errors.Is(err, repository.ErrNotInWorkspace) {
+				t.Errorf("Expected ErrNotInWorkspace, got %v", err)
+			}
+			return tx.Bills.Create(ctx, newBill(itemID))
+		})
+		if err != nil {
+			t.Fatalf("Failed to run the unit of work: %v", err)
+		}
+		if got := countBills(t); got != before+1 {
+			t.Errorf("Expected only the valid bill to be stored, got %d bills instead of %d", got, before+1)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+// Package routes lists the routes registered in main.go so tests can check
+// that every one of them is documented and guarded
+package routes
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+var routeMethods = map[string]bool{
+	"GET":    true,
+	"POST":   true,
+	"PUT":    true,
+	"PATCH":  true,
+	"DELETE": true,
+}
+
+// Registered parses main.go and returns the "METHOD path" key of every route
+// it registers, following e.Group prefixes. Test packages live three
+// directories below main.go
+func Registered(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "../../../main.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse main.go: %v", err)
+	}
+
+	prefixes := map[string]string{"e": ""}
+	var routes []string
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			// v1 := e.Group("/api/v1", ...)
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			name, ok := node.Lhs[0].(*ast.Ident)
+			if !ok {
+				return true
+			}
+			receiver, method, path, ok := routeCall(node.Rhs[0])
+			if ok && method == "Group" {
+				if prefix, known := prefixes[receiver]; known {
+					prefixes[name.Name] = prefix + path
+				}
+			}
+		case *ast.CallExpr:
+			receiver, method, path, ok := routeCall(node)
+			if !ok || !routeMethods[method] {
+				return true
+			}
+			if prefix, known := prefixes[receiver]; known {
+				routes = append(routes, method+" "+prefix+path)
+			}
+		}
+		return true
+	})
+
+	sort.Strings(routes)
+	return routes
+}
+
+// routeCall matches calls of the form receiver.Method("literal", ...)
+func routeCall(expr ast.Expr) (receiver, method, path string, ok bool) {
+	call, isCall := expr.(*ast.CallExpr)
+	if !isCall || len(call.Args) == 0 {
+		return "", "", "", false
+	}
+	sel, isSel := call.Fun.(*ast.SelectorExpr)
+	if !isSel {
+		return "", "", "", false
+	}
+	ident, isIdent := sel.X.(*ast.Ident)
+	if !isIdent {
+		return "", "", "", false
+	}
+	lit, isLit := call.Args[0].(*ast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return "", "", "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", "", false
+	}
+	return ident.Name, sel.Sel.Name, value, true
+}
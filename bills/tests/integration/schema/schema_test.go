@@ -0,0 +1,73 @@
+package schema_test
+
+import (
+	"bills/db"
+	"bills/tests/integration/testdb"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckSchemaAfterMigrations(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	if err := db.CheckSchema(sqlDB); err != nil {
+		t.Fatalf("Expected the migrated database to match the models, got %v", err)
+	}
+
+	// Running the migrations again leaves the schema as it is
+	if err := db.MigrateDB(sqlDB, ""); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	if err := db.CheckSchema(sqlDB); err != nil {
+		t.Fatalf("Expected the schema to still match, got %v", err)
+	}
+}
+
+func TestCheckSchemaDrift(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	for _, query := range []string{
+		"ALTER TABLE bills ADD COLUMN amount REAL",
+		"ALTER TABLE exchange_rates DROP COLUMN rate",
+		// SQLite cannot change the type of a column, it is replaced instead
+		"ALTER TABLE exchange_rates RENAME COLUMN currency_to TO old_currency_to",
+		"ALTER TABLE exchange_rates ADD COLUMN currency_to INTEGER",
+		"ALTER TABLE exchange_rates DROP COLUMN old_currency_to",
+		// A column of a model the migrations do not create
+		"ALTER TABLE users DROP COLUMN instance_admin",
+		"DROP TABLE workspace_members",
+		"CREATE TABLE legacy_bills (id INTEGER PRIMARY KEY)",
+	} {
+		if _, err := sqlDB.Exec(query); err != nil {
+			t.Fatalf("Failed to run %q: %v", query, err)
+		}
+	}
+
+	err := db.CheckSchema(sqlDB)
+	var schemaErr *db.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected a SchemaError, got %v", err)
+	}
+
+	want := strings.Join([]string{
+		"  table bills",
+		"    + amount",
+		"  table exchange_rates",
+		"    ~ currency_to INTEGER, expected TEXT",
+		"    - rate",
+		"  table users",
+		"    - instance_admin",
+		"- table workspace_members",
+		"+ table legacy_bills",
+		"",
+	}, "\n")
+	if schemaErr.Diff != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", schemaErr.Diff, want)
+	}
+	if !strings.Contains(err.Error(), "+ amount") {
+		t.Errorf("Expected the error to show the diff, got %q", err.Error())
+	}
+}
@@ -0,0 +1,265 @@
+package search_test
+
+import (
+	"bills/db"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/fixture"
+	"bills/tests/integration/testdb"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// search returns the "entity:title" of every result, in order
+func search(t *testing.T, repos *repository.Repositories, ctx context.Context, query string) []string {
+	t.Helper()
+	groups, err := repos.Search.Search(ctx, query, 10)
+	if err != nil {
+		t.Fatalf("Failed to search %q: %v", query, err)
+	}
+	var found []string
+	for _, group := range groups {
+		for _, result := range group.Results {
+			if result.Entity != group.Entity {
+				t.Errorf("Expected %s results only in the %s group", result.Entity, group.Entity)
+			}
+			found = append(found, result.Entity+":"+result.Title)
+		}
+	}
+	return found
+}
+
+func contains(found []string, want string) bool {
+	for _, f := range found {
+		if f == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSearch(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	// The index is only built when SQLite has FTS5, see the sqlite_fts5 build
+	// tag. Without it the same searches run with LIKE
+	fts5, err := db.HasFTS5(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("FTS5 available: %v", fts5)
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	repos := repository.NewRepositories(sqlDB)
+	bill := fixture.CreateBill(t, ctx, repos, "Acme Consulting", "Globex Trading", "Website design")
+	fixture.CreateBill(t, ctx, repos, "Initech", "Consulting Partners", "Server hosting")
+
+	t.Run("Groups results by entity", func(t *testing.T) {
+		groups, err := repos.Search.Search(ctx, "consulting", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entities []string
+		for _, group := range groups {
+			entities = append(entities, group.Entity)
+		}
+		want := []string{models.EntityBills, models.EntityIssuers, models.EntityReceivers}
+		if len(entities) != len(want) {
+			t.Fatalf("Expected groups %v, got %v", want, entities)
+		}
+		for i := range want {
+			if entities[i] != want[i] {
+				t.Errorf("Expected groups %v, got %v", want, entities)
+			}
+		}
+		if len(groups[0].Results) != 2 {
+			t.Errorf("Expected both bills to match their parties, got %d", len(groups[0].Results))
+		}
+	})
+
+	t.Run("Every word has to match as a prefix", func(t *testing.T) {
+		found := search(t, repos, ctx, "web desi")
+		if !contains(found, "bill_items:Website design") || len(found) != 1 {
+			t.Errorf("Expected only the website item, got %v", found)
+		}
+		if found := search(t, repos, ctx, "website hosting"); len(found) != 0 {
+			t.Errorf("Expected nothing to match both words, got %v", found)
+		}
+	})
+
+	t.Run("Finds parties by their address", func(t *testing.T) {
+		found := search(t, repos, ctx, "rotterdam")
+		if !contains(found, "receivers:Globex Trading") || !contains(found, "receivers:Consulting Partners") {
+			t.Errorf("Expected both receivers, got %v", found)
+		}
+	})
+
+	t.Run("Title matches come first", func(t *testing.T) {
+		groups, err := repos.Search.Search(ctx, "consulting", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, group := range groups {
+			if group.Entity == models.EntityReceivers && len(group.Results) > 0 {
+				if got := group.Results[0].Title; got != "Consulting Partners" {
+					t.Errorf("Expected the receiver named after the query first, got %s", got)
+				}
+			}
+		}
+	})
+
+	t.Run("Ignores diacritics", func(t *testing.T) {
+		if !fts5 {
+			t.Skip("LIKE compares accented letters as they are")
+		}
+		if found := search(t, repos, ctx, "zurich"); !contains(found, "issuers:Acme Consulting") {
+			t.Errorf("Expected Zürich to match zurich, got %v", found)
+		}
+	})
+
+	t.Run("Follows renames and the trash", func(t *testing.T) {
+		issuer, err := repos.Issuers.GetByID(ctx, bill.IssuerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issuer.Name = "Umbrella Corporation"
+		if err := repos.Issuers.Update(ctx, issuer); err != nil {
+			t.Fatal(err)
+		}
+
+		found := search(t, repos, ctx, "umbrella")
+		if !contains(found, "issuers:Umbrella Corporation") || !contains(found, "bills:#"+strconv.FormatInt(bill.ID, 10)) {
+			t.Errorf("Expected the renamed issuer and its bill, got %v", found)
+		}
+		if found := search(t, repos, ctx, "acme"); len(found) != 0 {
+			t.Errorf("Expected the old name to be gone, got %v", found)
+		}
+
+		if err := repos.Bills.Delete(ctx, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+		if found := search(t, repos, ctx, "umbrella"); contains(found, "bills:#"+strconv.FormatInt(bill.ID, 10)) {
+			t.Errorf("Expected the trashed bill to be gone, got %v", found)
+		}
+	})
+
+	t.Run("Stays in the workspace", func(t *testing.T) {
+		workspace := models.NewWorkspace("Other")
+		if err := repository.NewRepositories(sqlDB).Workspaces.Create(context.Background(), workspace); err != nil {
+			t.Fatal(err)
+		}
+		other := tenant.WithWorkspace(context.Background(), workspace.ID)
+		fixture.CreateBill(t, other, repos, "Hooli", "Pied Piper", "Compression")
+
+		if found := search(t, repos, ctx, "hooli"); len(found) != 0 {
+			t.Errorf("Expected nothing from another workspace, got %v", found)
+		}
+		if found := search(t, repos, other, "hooli"); !contains(found, "issuers:Hooli") {
+			t.Errorf("Expected the issuer of the workspace, got %v", found)
+		}
+	})
+
+	t.Run("Nothing to look for", func(t *testing.T) {
+		if found := search(t, repos, ctx, " -- "); len(found) != 0 {
+			t.Errorf("Expected no results for punctuation, got %v", found)
+		}
+	})
+}
+
+func TestSyncSearchIndex(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	fts5, err := db.HasFTS5(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fts5 {
+		t.Skip("SQLite was built without FTS5, run with -tags sqlite_fts5")
+	}
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	repos := repository.NewRepositories(sqlDB)
+	fixture.CreateBill(t, ctx, repos, "Stark Industries", "Wayne Enterprises", "Armor plating")
+
+	// A build without FTS5 drops the triggers and writes go on unindexed
+	for _, name := range []string{"search_issuers_insert", "search_issuers_update"} {
+		if _, err := sqlDB.Exec("DROP TRIGGER " + name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sqlDB.Exec("DELETE FROM search_index"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.SyncSearchIndex(sqlDB); err != nil {
+		t.Fatalf("Failed to sync the search index: %v", err)
+	}
+	if found := search(t, repos, ctx, "stark"); !contains(found, "issuers:Stark Industries") {
+		t.Errorf("Expected the index to be rebuilt, got %v", found)
+	}
+
+	// The triggers are back too
+	fixture.CreateBill(t, ctx, repos, "Oscorp", "Daily Bugle", "Ink")
+	if found := search(t, repos, ctx, "oscorp"); !contains(found, "issuers:Oscorp") {
+		t.Errorf("Expected new rows to be indexed, got %v", found)
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	repos := repository.NewRepositories(sqlDB)
+	fixture.CreateBill(t, ctx, repos, "Cyberdyne", "Tyrell", "Robotics")
+
+	renderer := &fixture.Renderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	h := handlers.NewSearchHandler(repos.Search)
+	e.GET("/search", h.RenderSearch)
+	e.GET("/search/results", h.GetSearchResults)
+
+	do := func(target, hxTarget string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(ctx)
+		if hxTarget != "" {
+			req.Header.Set("HX-Request", "true")
+			req.Header.Set("HX-Target", hxTarget)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("/search?q=robot", "")
+	if rec.Code != http.StatusOK || renderer.Name != "search.html" || renderer.Data["Query"] != "robot" {
+		t.Fatalf("Expected the search page for robot, got %d %s %v", rec.Code, renderer.Name, renderer.Data["Query"])
+	}
+	groups := renderer.Data["Groups"].([]*models.SearchGroup)
+	if len(groups) != 1 || groups[0].Results[0].Path() != "/bill-items?q=Robotics" {
+		t.Errorf("Expected the bill item linking to the filtered bill items list, got %v", groups)
+	}
+
+	rec = do("/search/results?q=tyrell", "search-suggestions")
+	if rec.Code != http.StatusOK || renderer.Name != "search-results" {
+		t.Fatalf("Expected the results partial, got %d %s", rec.Code, renderer.Name)
+	}
+	if url := rec.Header().Get("HX-Push-Url"); url != "" {
+		t.Errorf("Expected the type-ahead to leave the address alone, got %s", url)
+	}
+
+	rec = do("/search/results?q=tyrell", "search-page-results")
+	if url := rec.Header().Get("HX-Push-Url"); url != "/search?q=tyrell" {
+		t.Errorf("Expected the search page address to follow the query, got %q", url)
+	}
+}
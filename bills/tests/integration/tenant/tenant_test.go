@@ -0,0 +1,387 @@
+package tenant_test
+
+import (
+	"bills/internal/api"
+	"bills/internal/auth"
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// fixture holds the records created in one workspace
+type fixture struct {
+	ctx        context.Context
+	issuer     *models.Issuer
+	receiver   *models.Receiver
+	item       *models.BillItem
+	bill       *models.Bill
+	assignment *models.BillItemAssignment
+}
+
+type repositories struct {
+	bills       repository.BillRepository
+	issuers     repository.IssuerRepository
+	receivers   repository.ReceiverRepository
+	items       repository.BillItemRepository
+	assignments repository.BillItemAssignmentRepository
+}
+
+func newRepositories(sqlDB *sql.DB) repositories {
+	repos := repository.NewRepositories(sqlDB)
+	return repositories{
+		bills:       repos.Bills,
+		issuers:     repos.Issuers,
+		receivers:   repos.Receivers,
+		items:       repos.BillItems,
+		assignments: repos.BillItemAssignments,
+	}
+}
+
+func createWorkspace(t *testing.T, sqlDB *sql.DB, name string) *models.Workspace {
+	workspace := models.NewWorkspace(name)
+	if err := repository.NewRepositories(sqlDB).Workspaces.Create(context.Background(), workspace); err != nil {
+		t.Fatalf("Failed to create workspace: %v", err)
+	}
+	return workspace
+}
+
+// createFixture fills a workspace with one record of every kind
+func createFixture(t *testing.T, repos repositories, workspaceID int64, prefix string) *fixture {
+	f := &fixture{ctx: tenant.WithWorkspace(context.Background(), workspaceID)}
+
+	f.issuer = models.NewIssuer(prefix+" Issuer", "123456", "123 Street", "City", "State", "12345", "Country")
+	if err := repos.issuers.Create(f.ctx, f.issuer); err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+	f.receiver = models.NewReceiver(prefix+" Receiver", "654321", "321 Street", "City", "State", "54321", "Country")
+	if err := repos.receivers.Create(f.ctx, f.receiver); err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+	f.item = models.NewBillItem(prefix+" Item", 100.00, models.DefaultCurrency())
+	if err := repos.items.Create(f.ctx, f.item); err != nil {
+		t.Fatalf("Failed to create bill item: %v", err)
+	}
+	f.bill = models.NewBill(time.Now(), f.issuer.ID, f.receiver.ID)
+	if err := repos.bills.Create(f.ctx, f.bill); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+	f.assignment = models.NewBillItemAssignment(f.bill.ID, f.item.ID, 1, 100.00, models.DefaultCurrency(), 1.0)
+	if err := repos.assignments.Create(f.ctx, f.assignment); err != nil {
+		t.Fatalf("Failed to create assignment: %v", err)
+	}
+	return f
+}
+
+func TestRepositoriesAreScoped(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	repos := newRepositories(sqlDB)
+	acme := createWorkspace(t, sqlDB, "Acme")
+	globex := createWorkspace(t, sqlDB, "Globex")
+	a := createFixture(t, repos, acme.ID, "Acme")
+	b := createFixture(t, repos, globex.ID, "Globex")
+
+	t.Run("GetAll only returns the own workspace", func(t *testing.T) {
+		bills, err := repos.bills.GetAll(a.ctx)
+		if err != nil {
+			t.Fatalf("Failed to get bills: %v", err)
+		}
+		if len(bills) != 1 || bills[0].ID != a.bill.ID {
+			t.Errorf("Expected only bill %d, got %v", a.bill.ID, bills)
+		}
+		if len(bills) == 1 && (len(bills[0].Items) != 1 || bills[0].Items[0].ID != a.assignment.ID) {
+			t.Errorf("Expected only assignment %d, got %v", a.assignment.ID, bills[0].Items)
+		}
+
+		issuers, err := repos.issuers.GetAll(a.ctx)
+		if err != nil {
+			t.Fatalf("Failed to get issuers: %v", err)
+		}
+		if len(issuers) != 1 || issuers[0].ID != a.issuer.ID {
+			t.Errorf("Expected only issuer %d, got %v", a.issuer.ID, issuers)
+		}
+
+		receivers, err := repos.receivers.GetAll(a.ctx)
+		if err != nil {
+			t.Fatalf("Failed to get receivers: %v", err)
+		}
+		if len(receivers) != 1 || receivers[0].ID != a.receiver.ID {
+			t.Errorf("Expected only receiver %d, got %v", a.receiver.ID, receivers)
+		}
+
+		items, err := repos.items.GetAll(a.ctx)
+		if err != nil {
+			t.Fatalf("Failed to get bill items: %v", err)
+		}
+		if len(items) != 1 || items[0].ID != a.item.ID {
+			t.Errorf("Expected only bill item %d, got %v", a.item.ID, items)
+		}
+	})
+
+	t.Run("GetByID does not see other workspaces", func(t *testing.T) {
+		reads := []struct {
+			name string
+			find func() (bool, error)
+		}{
+			{"bill", func() (bool, error) { v, err := repos.bills.GetByID(a.ctx, b.bill.ID); return v != nil, err }},
+			{"issuer", func() (bool, error) { v, err := repos.issuers.GetByID(a.ctx, b.issuer.ID); return v != nil, err }},
+			{"receiver", func() (bool, error) { v, err := repos.receivers.GetByID(a.ctx, b.receiver.ID); return v != nil, err }},
+			{"bill item", func() (bool, error) { v, err := repos.items.GetByID(a.ctx, b.item.ID); return v != nil, err }},
+			{"assignment", func() (bool, error) {
+				v, err := repos.assignments.GetByID(a.ctx, b.assignment.ID)
+				return v != nil, err
+			}},
+		}
+		for _, read := range reads {
+			found, err := read.find()
+			if found || !errors.Is(err, models.ErrNotFound) {
+				t.Errorf("Expected the %s of another workspace to be invisible, got %v", read.name, err)
+			}
+		}
+
+		assignments, err := repos.assignments.GetByBillID(a.ctx, b.bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get assignments: %v", err)
+		}
+		if len(assignments) != 0 {
+			t.Errorf("Expected no assignments of another workspace, got %d", len(assignments))
+		}
+	})
+
+	t.Run("Writes do not reach other workspaces", func(t *testing.T) {
+		renamed := *b.issuer
+		renamed.Name = "Hijacked"
+		if err := repos.issuers.Update(a.ctx, &renamed); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound updating the issuer, got %v", err)
+		}
+
+		paid := *b.bill
+		paid.Paid = true
+		paid.IssuerID = a.issuer.ID
+		paid.ReceiverID = a.receiver.ID
+		if err := repos.bills.Update(a.ctx, &paid); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound updating the bill, got %v", err)
+		}
+
+		if err := repos.bills.Delete(a.ctx, b.bill.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound deleting the bill, got %v", err)
+		}
+		if err := repos.receivers.Delete(a.ctx, b.receiver.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound deleting the receiver, got %v", err)
+		}
+		if err := repos.items.Delete(a.ctx, b.item.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound deleting the bill item, got %v", err)
+		}
+		if err := repos.assignments.DeleteByBillID(a.ctx, b.bill.ID); err != nil {
+			t.Fatalf("Failed to delete assignments: %v", err)
+		}
+
+		issuer, err := repos.issuers.GetByID(b.ctx, b.issuer.ID)
+		if err != nil || issuer == nil {
+			t.Fatalf("Failed to get issuer: %v", err)
+		}
+		if issuer.Name != b.issuer.Name {
+			t.Errorf("Expected issuer name %q, got %q", b.issuer.Name, issuer.Name)
+		}
+
+		bill, err := repos.bills.GetByID(b.ctx, b.bill.ID)
+		if err != nil {
+			t.Fatalf("Failed to get bill: %v", err)
+		}
+		if bill == nil {
+			t.Fatal("Expected the bill of another workspace to survive the delete")
+		}
+		if bill.Paid || bill.IssuerID != b.issuer.ID {
+			t.Errorf("Expected the bill of another workspace to be unchanged, got %+v", bill)
+		}
+		if len(bill.Items) != 1 {
+			t.Errorf("Expected the bill of another workspace to keep its item, got %d", len(bill.Items))
+		}
+
+		if receiver, _ := repos.receivers.GetByID(b.ctx, b.receiver.ID); receiver == nil {
+			t.Error("Expected the receiver of another workspace to survive the delete")
+		}
+		if item, _ := repos.items.GetByID(b.ctx, b.item.ID); item == nil {
+			t.Error("Expected the bill item of another workspace to survive the delete")
+		}
+	})
+
+	t.Run("References to other workspaces are rejected", func(t *testing.T) {
+		bill := models.NewBill(time.Now(), b.issuer.ID, a.receiver.ID)
+		if err := repos.bills.Create(a.ctx, bill); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace for a foreign issuer, got %v", err)
+		}
+
+		bill = models.NewBill(time.Now(), a.issuer.ID, a.receiver.ID)
+		bill.Items = append(bill.Items, models.NewBillItemAssignment(0, b.item.ID, 1, 10, models.DefaultCurrency(), 1.0))
+		if err := repos.bills.Create(a.ctx, bill); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace for a foreign bill item, got %v", err)
+		}
+
+		assignment := models.NewBillItemAssignment(b.bill.ID, a.item.ID, 1, 10, models.DefaultCurrency(), 1.0)
+		if err := repos.assignments.Create(a.ctx, assignment); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace for a foreign bill, got %v", err)
+		}
+
+		moved := *a.bill
+		moved.ReceiverID = b.receiver.ID
+		if err := repos.bills.Update(a.ctx, &moved); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace for a foreign receiver, got %v", err)
+		}
+	})
+
+	t.Run("No workspace, no data", func(t *testing.T) {
+		if _, err := repos.bills.GetAll(context.Background()); !errors.Is(err, tenant.ErrNoWorkspace) {
+			t.Errorf("Expected ErrNoWorkspace, got %v", err)
+		}
+		if _, err := repos.issuers.GetByID(context.Background(), a.issuer.ID); !errors.Is(err, tenant.ErrNoWorkspace) {
+			t.Errorf("Expected ErrNoWorkspace, got %v", err)
+		}
+		if err := repos.items.Delete(context.Background(), a.item.ID); !errors.Is(err, tenant.ErrNoWorkspace) {
+			t.Errorf("Expected ErrNoWorkspace, got %v", err)
+		}
+	})
+}
+
+func TestSwitchWorkspace(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	repos := newRepositories(sqlDB)
+	shared := repository.NewRepositories(sqlDB)
+	workspaceRepo := shared.Workspaces
+	userRepo := shared.Users
+	sessions := auth.NewSessions(userRepo, shared.Sessions, workspaceRepo)
+	authHandler := handlers.NewAuthHandler(sessions)
+	issuerAPI := api.NewIssuerHandler(repos.issuers)
+
+	e := echo.New()
+	e.Use(sessions.Middleware)
+	e.Use(auth.Authorize)
+	e.POST("/login", authHandler.Login)
+	e.POST("/workspaces/switch", authHandler.SwitchWorkspace)
+	v1 := e.Group("/api/v1", api.ErrorMiddleware)
+	v1.GET("/issuers", issuerAPI.List)
+	v1.GET("/issuers/:id", issuerAPI.Get)
+
+	initech := createWorkspace(t, sqlDB, "Initech")
+	umbrella := createWorkspace(t, sqlDB, "Umbrella")
+	hooli := createWorkspace(t, sqlDB, "Hooli")
+	i := createFixture(t, repos, initech.ID, "Initech")
+	u := createFixture(t, repos, umbrella.ID, "Umbrella")
+	createFixture(t, repos, hooli.ID, "Hooli")
+
+	user, err := models.NewUser("milton", "stapler-123")
+	if err != nil {
+		t.Fatalf("Failed to build user: %v", err)
+	}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	for _, id := range []int64{initech.ID, umbrella.ID} {
+		if err := workspaceRepo.AddMember(context.Background(), id, user.ID, models.RoleViewer); err != nil {
+			t.Fatalf("Failed to add member: %v", err)
+		}
+	}
+
+	do := func(method, path string, form url.Values, cookie *http.Cookie) *httptest.ResponseRecorder {
+		var body string
+		if form != nil {
+			body = form.Encode()
+		}
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if form != nil {
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		}
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	issuerNames := func(cookie *http.Cookie) []string {
+		t.Helper()
+		rec := do(http.MethodGet, "/api/v1/issuers", nil, cookie)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200 listing issuers, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var issuers []models.Issuer
+		if err := json.Unmarshal(rec.Body.Bytes(), &issuers); err != nil {
+			t.Fatalf("Failed to decode issuers: %v", err)
+		}
+		var names []string
+		for _, issuer := range issuers {
+			names = append(names, issuer.Name)
+		}
+		return names
+	}
+
+	rec := do(http.MethodPost, "/login", url.Values{"username": {"milton"}, "password": {"stapler-123"}}, nil)
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == auth.CookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		t.Fatalf("Expected a session cookie, got %d", rec.Code)
+	}
+
+	// Workspaces are listed by name, the first one is used until another is
+	// picked
+	if got := issuerNames(cookie); len(got) != 1 || got[0] != i.issuer.Name {
+		t.Fatalf("Expected only %q, got %v", i.issuer.Name, got)
+	}
+	if rec := do(http.MethodGet, fmt.Sprintf("/api/v1/issuers/%d", u.issuer.ID), nil, cookie); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an issuer of another workspace, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodPost, "/workspaces/switch", url.Values{"workspace_id": {fmt.Sprint(umbrella.ID)}}, cookie)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("Expected 303 switching workspace, got %d", rec.Code)
+	}
+	if got := issuerNames(cookie); len(got) != 1 || got[0] != u.issuer.Name {
+		t.Fatalf("Expected only %q after switching, got %v", u.issuer.Name, got)
+	}
+
+	rec = do(http.MethodPost, "/workspaces/switch", url.Values{"workspace_id": {fmt.Sprint(hooli.ID)}}, cookie)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 switching to a workspace the user is not in, got %d", rec.Code)
+	}
+	if got := issuerNames(cookie); len(got) != 1 || got[0] != u.issuer.Name {
+		t.Errorf("Expected to stay in Umbrella, got %v", got)
+	}
+
+	// Users removed from the workspace they work in fall back to another one
+	if err := workspaceRepo.RemoveMember(context.Background(), umbrella.ID, user.ID); err != nil {
+		t.Fatalf("Failed to remove member: %v", err)
+	}
+	if got := issuerNames(cookie); len(got) != 1 || got[0] != i.issuer.Name {
+		t.Errorf("Expected to fall back to Initech, got %v", got)
+	}
+
+	// and are refused once they belong to none
+	if err := workspaceRepo.RemoveMember(context.Background(), initech.ID, user.ID); err != nil {
+		t.Fatalf("Failed to remove member: %v", err)
+	}
+	if rec := do(http.MethodGet, "/api/v1/issuers", nil, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without workspaces, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,238 @@
+package trash_test
+
+import (
+	"bills/internal/handlers"
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/fixture"
+	"bills/tests/integration/testdb"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func trashed(t *testing.T, repos *repository.Repositories, ctx context.Context, entity string, id int64) bool {
+	items, err := repos.Trash.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.Entity == entity && item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSoftDelete(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	repos := repository.NewRepositories(sqlDB)
+
+	t.Run("Deleted bills go to the trash with their lines", func(t *testing.T) {
+		bill := fixture.CreateBill(t, ctx, repos, "restore issuer", "restore receiver", "restore item")
+		if err := repos.Bills.Delete(ctx, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := repos.Bills.GetByID(ctx, bill.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected a trashed bill to be hidden from GetByID, got %v", err)
+		}
+		all, err := repos.Bills.GetAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range all {
+			if b.ID == bill.ID {
+				t.Error("Expected a trashed bill to be hidden from GetAll")
+			}
+		}
+		if !trashed(t, repos, ctx, models.EntityBills, bill.ID) {
+			t.Fatal("Expected the bill in the trash")
+		}
+
+		if err := repos.Trash.Restore(ctx, models.EntityBills, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repos.Bills.GetByID(ctx, bill.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || len(got.Items) != 1 {
+			t.Fatalf("Expected the bill back with its line, got %+v", got)
+		}
+	})
+
+	t.Run("Trashed parties", func(t *testing.T) {
+		bill := fixture.CreateBill(t, ctx, repos, "party issuer", "party receiver", "party item")
+		if err := repos.Issuers.Delete(ctx, bill.IssuerID); err != nil {
+			t.Fatal(err)
+		}
+
+		// Bills keep working with a party trashed since
+		bill.Paid = true
+		if err := repos.Bills.Update(ctx, bill); err != nil {
+			t.Errorf("Expected to update a bill of a trashed issuer, got %v", err)
+		}
+
+		// but new bills cannot use it
+		fresh := models.NewBill(time.Now(), bill.IssuerID, bill.ReceiverID)
+		if err := repos.Bills.Create(ctx, fresh); !errors.Is(err, repository.ErrNotInWorkspace) {
+			t.Errorf("Expected ErrNotInWorkspace, got %v", err)
+		}
+
+		// and it cannot be purged while a bill uses it, even a trashed one
+		if err := repos.Bills.Delete(ctx, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Trash.Purge(ctx, models.EntityIssuers, bill.IssuerID); !errors.Is(err, repository.ErrInUse) {
+			t.Errorf("Expected ErrInUse, got %v", err)
+		}
+
+		if err := repos.Trash.Purge(ctx, models.EntityBills, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Trash.Purge(ctx, models.EntityIssuers, bill.IssuerID); err != nil {
+			t.Fatal(err)
+		}
+		if trashed(t, repos, ctx, models.EntityIssuers, bill.IssuerID) || trashed(t, repos, ctx, models.EntityBills, bill.ID) {
+			t.Error("Expected purged rows to leave the trash")
+		}
+		var lines int
+		if err := sqlDB.QueryRow(testdb.Rebind("SELECT COUNT(*) FROM bill_item_assignments WHERE bill_id = ?"), bill.ID).Scan(&lines); err != nil {
+			t.Fatal(err)
+		}
+		if lines != 0 {
+			t.Errorf("Expected the lines to be purged with the bill, got %d", lines)
+		}
+	})
+
+	t.Run("Live rows cannot be purged", func(t *testing.T) {
+		bill := fixture.CreateBill(t, ctx, repos, "live issuer", "live receiver", "live item")
+		if err := repos.Trash.Purge(ctx, models.EntityBills, bill.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if got, _ := repos.Bills.GetByID(ctx, bill.ID); got == nil {
+			t.Error("Expected a live bill to survive a purge")
+		}
+	})
+
+	t.Run("Trash of other workspaces", func(t *testing.T) {
+		workspace := models.NewWorkspace("Other")
+		if err := repository.NewRepositories(sqlDB).Workspaces.Create(context.Background(), workspace); err != nil {
+			t.Fatal(err)
+		}
+		other := tenant.WithWorkspace(context.Background(), workspace.ID)
+
+		bill := fixture.CreateBill(t, ctx, repos, "scoped issuer", "scoped receiver", "scoped item")
+		if err := repos.Bills.Delete(ctx, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+		if trashed(t, repos, other, models.EntityBills, bill.ID) {
+			t.Error("Expected the trash to be scoped to the workspace")
+		}
+		if err := repos.Trash.Restore(other, models.EntityBills, bill.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if !trashed(t, repos, ctx, models.EntityBills, bill.ID) {
+			t.Error("Expected a restore from another workspace to do nothing")
+		}
+	})
+}
+
+func TestRetention(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	repos := repository.NewRepositories(sqlDB)
+
+	old := fixture.CreateBill(t, ctx, repos, "old issuer", "old receiver", "old item")
+	recent := fixture.CreateBill(t, ctx, repos, "recent issuer", "recent receiver", "recent item")
+	for _, bill := range []*models.Bill{old, recent} {
+		if err := repos.Bills.Delete(ctx, bill.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Issuers.Delete(ctx, bill.IssuerID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	longAgo := time.Now().Add(-60 * 24 * time.Hour)
+	if _, err := sqlDB.Exec(testdb.Rebind("UPDATE bills SET deleted_at = ? WHERE id = ?"), longAgo, old.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(testdb.Rebind("UPDATE issuers SET deleted_at = ? WHERE id IN (?, ?)"), longAgo, old.IssuerID, recent.IssuerID); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := repos.Trash.PurgeDeletedBefore(context.Background(), time.Now().Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 purged rows, got %d", purged)
+	}
+
+	if trashed(t, repos, ctx, models.EntityBills, old.ID) || trashed(t, repos, ctx, models.EntityIssuers, old.IssuerID) {
+		t.Error("Expected rows past the retention to be purged")
+	}
+	if !trashed(t, repos, ctx, models.EntityBills, recent.ID) {
+		t.Error("Expected a recently trashed bill to stay")
+	}
+	if !trashed(t, repos, ctx, models.EntityIssuers, recent.IssuerID) {
+		t.Error("Expected an issuer still used by a bill to stay")
+	}
+}
+
+func TestTrashHandler(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	repos := repository.NewRepositories(sqlDB)
+	bill := fixture.CreateBill(t, ctx, repos, "handler issuer", "handler receiver", "handler item")
+	if err := repos.Issuers.Delete(ctx, bill.IssuerID); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer := &fixture.Renderer{}
+	e := echo.New()
+	e.Renderer = renderer
+	h := handlers.NewTrashHandler(repos.Trash)
+	e.DELETE("/trash/:entity/:id", h.PurgeItem)
+	e.POST("/trash/:entity/:id/restore", h.RestoreItem)
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req = req.WithContext(ctx)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodDelete, fmt.Sprintf("/trash/issuers/%d", bill.IssuerID))
+	if rec.Code != http.StatusOK || renderer.Data["Error"] == "" {
+		t.Errorf("Expected the in use message, got %d %v", rec.Code, renderer.Data["Error"])
+	}
+
+	rec = do(http.MethodPost, fmt.Sprintf("/trash/issuers/%d/restore", bill.IssuerID))
+	if rec.Code != http.StatusOK || renderer.Name != "trash-list" {
+		t.Fatalf("Expected the trash list, got %d %s", rec.Code, renderer.Name)
+	}
+	if items := renderer.Data["Items"].([]*models.TrashedItem); len(items) != 0 {
+		t.Errorf("Expected an empty trash, got %d rows", len(items))
+	}
+
+	if rec := do(http.MethodDelete, "/trash/users/1"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown entity, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,310 @@
+package verify_test
+
+import (
+	"bills/internal/models"
+	"bills/internal/repository"
+	"bills/internal/tenant"
+	"bills/tests/integration/testdb"
+	"context"
+	"database/sql"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixture is a workspace with a bill of two lines, one in USD, and a second
+// workspace with an issuer and an item of its own
+type fixture struct {
+	repos       *repository.Repositories
+	bill        *models.Bill
+	other       *models.Workspace
+	otherIssuer *models.Issuer
+	otherItem   *models.BillItem
+}
+
+func createFixture(t *testing.T, sqlDB *sql.DB) *fixture {
+	t.Helper()
+
+	f := &fixture{repos: repository.NewRepositories(sqlDB)}
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+
+	issuer := models.NewIssuer("Acme Studio", "CHE-123.456.789", "1 Main St", "Zurich", "ZH", "8000", "Switzerland")
+	receiver := models.NewReceiver("Globex", "NL123456789B01", "2 Canal St", "Amsterdam", "NH", "1012", "Netherlands")
+	design := models.NewBillItem("Logo design", 100.00, "EUR")
+	hosting := models.NewBillItem("Web hosting", 20.00, "USD")
+	if err := f.repos.Issuers.Create(ctx, issuer); err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+	if err := f.repos.Receivers.Create(ctx, receiver); err != nil {
+		t.Fatalf("Failed to create receiver: %v", err)
+	}
+	for _, item := range []*models.BillItem{design, hosting} {
+		if err := f.repos.BillItems.Create(ctx, item); err != nil {
+			t.Fatalf("Failed to create bill item: %v", err)
+		}
+	}
+	f.bill = models.NewBill(time.Now().AddDate(0, 0, 30), issuer.ID, receiver.ID)
+	f.bill.Items = []*models.BillItemAssignment{
+		models.NewBillItemAssignment(0, design.ID, 2, 100.00, "EUR", 1.0),
+		models.NewBillItemAssignment(0, hosting.ID, 3, 20.00, "USD", 0.9),
+	}
+	f.bill.ResolveCurrency()
+	f.bill.CalculateTotals()
+	if err := f.repos.Bills.Create(ctx, f.bill); err != nil {
+		t.Fatalf("Failed to create bill: %v", err)
+	}
+
+	f.other = models.NewWorkspace("Other")
+	if err := f.repos.Workspaces.Create(context.Background(), f.other); err != nil {
+		t.Fatalf("Failed to create workspace: %v", err)
+	}
+	otherCtx := tenant.WithWorkspace(context.Background(), f.other.ID)
+	f.otherIssuer = models.NewIssuer("Other Studio", "CHE-987.654.321", "9 Side St", "Basel", "BS", "4000", "Switzerland")
+	if err := f.repos.Issuers.Create(otherCtx, f.otherIssuer); err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+	f.otherItem = models.NewBillItem("Consulting", 150.00, "EUR")
+	if err := f.repos.BillItems.Create(otherCtx, f.otherItem); err != nil {
+		t.Fatalf("Failed to create bill item: %v", err)
+	}
+	return f
+}
+
+func exec(t *testing.T, sqlDB *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+
+	if _, err := sqlDB.Exec(testdb.Rebind(query), args...); err != nil {
+		t.Fatalf("Failed to run %q: %v", query, err)
+	}
+}
+
+// kinds returns the kinds of the problems, with whether they can be fixed
+func kinds(problems []*models.IntegrityProblem) []string {
+	var kinds []string
+	for _, problem := range problems {
+		kind := problem.Kind
+		if !problem.Fixable {
+			kind += " by hand"
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// fixAll repairs every fixable problem in one unit of work, the way verify
+// -fix does
+func fixAll(t *testing.T, sqlDB *sql.DB) {
+	t.Helper()
+
+	ctx := context.Background()
+	err := repository.NewUnitOfWork(sqlDB).Do(ctx, func(tx *repository.Tx) error {
+		problems, err := tx.Integrity.Check(ctx)
+		if err != nil {
+			return err
+		}
+		for _, problem := range problems {
+			if problem.Fixable {
+				if err := tx.Integrity.Fix(ctx, problem); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to fix: %v", err)
+	}
+}
+
+func TestCheckCleanDatabase(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	f := createFixture(t, sqlDB)
+
+	// Trashed bills are checked too
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	if err := f.repos.Bills.Delete(ctx, f.bill.ID); err != nil {
+		t.Fatalf("Failed to delete bill: %v", err)
+	}
+
+	problems, err := f.repos.Integrity.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems in data the repositories wrote, got %v", kinds(problems))
+	}
+}
+
+func TestCheckAndFix(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	f := createFixture(t, sqlDB)
+	design, hosting := f.bill.Items[0], f.bill.Items[1]
+
+	// Drifted amounts and totals
+	exec(t, sqlDB, "UPDATE bill_item_assignments SET original_amount = ?, eur_amount = ? WHERE id = ?", 150.0, 150.0, design.ID)
+	exec(t, sqlDB, "UPDATE bills SET eur_total = ? WHERE id = ?", 1.0, f.bill.ID)
+	// A currency spelled differently on a line and an unknown one on the bill
+	exec(t, sqlDB, "UPDATE bill_item_assignments SET currency = ? WHERE id = ?", " usd", hosting.ID)
+	exec(t, sqlDB, "UPDATE bills SET currency = ? WHERE id = ?", "EURO", f.bill.ID)
+	// References to the rows of another workspace
+	exec(t, sqlDB, "UPDATE bills SET issuer_id = ? WHERE id = ?", f.otherIssuer.ID, f.bill.ID)
+	exec(t, sqlDB, "UPDATE bill_item_assignments SET item_id = ? WHERE id = ?", f.otherItem.ID, design.ID)
+
+	problems, err := f.repos.Integrity.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	want := []string{
+		models.ProblemMissingIssuer,
+		models.ProblemMissingItem,
+		models.ProblemLineCurrency,
+		models.ProblemBillCurrency,
+		models.ProblemLineAmounts,
+		models.ProblemBillTotals,
+	}
+	if got := kinds(problems); !slices.Equal(got, want) {
+		t.Fatalf("Expected problems %v, got %v", want, got)
+	}
+	for _, problem := range problems {
+		if problem.WorkspaceID != models.DefaultWorkspaceID || problem.Detail == "" {
+			t.Errorf("Unexpected problem %+v", problem)
+		}
+	}
+
+	fixAll(t, sqlDB)
+	problems, err = f.repos.Integrity.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected every problem fixed, got %v", kinds(problems))
+	}
+
+	ctx := tenant.WithWorkspace(context.Background(), models.DefaultWorkspaceID)
+	bill, err := f.repos.Bills.GetByID(ctx, f.bill.ID)
+	if err != nil {
+		t.Fatalf("Failed to get bill: %v", err)
+	}
+	if bill.Currency != "EUR" || math.Abs(bill.EURTotal-f.bill.EURTotal) > 0.005 {
+		t.Errorf("Expected the bill back at %.2f EUR, got %.2f %s", f.bill.EURTotal, bill.EURTotal, bill.Currency)
+	}
+	if bill.IssuerName != "Missing issuer "+itoa(f.otherIssuer.ID) {
+		t.Errorf("Expected a stand-in issuer, got %q", bill.IssuerName)
+	}
+
+	// The stand-ins wait in the trash of the workspace
+	trashed, err := f.repos.Trash.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list the trash: %v", err)
+	}
+	var labels []string
+	for _, item := range trashed {
+		labels = append(labels, item.Entity+" "+item.Label)
+	}
+	slices.Sort(labels)
+	wantLabels := []string{
+		"bill_items Missing bill item " + itoa(f.otherItem.ID),
+		"issuers Missing issuer " + itoa(f.otherIssuer.ID),
+	}
+	if !slices.Equal(labels, wantLabels) {
+		t.Errorf("Expected stand-ins %v in the trash, got %v", wantLabels, labels)
+	}
+}
+
+func TestUnknownCurrencyNeedsAHand(t *testing.T) {
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	f := createFixture(t, sqlDB)
+
+	exec(t, sqlDB, "UPDATE bill_item_assignments SET currency = ? WHERE id = ?", "DOGE", f.bill.Items[1].ID)
+	exec(t, sqlDB, "UPDATE bills SET currency = ? WHERE id = ?", "DOGE", f.bill.ID)
+
+	problems, err := f.repos.Integrity.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	want := []string{models.ProblemLineCurrency + " by hand", models.ProblemBillCurrency + " by hand"}
+	if got := kinds(problems); !slices.Equal(got, want) {
+		t.Fatalf("Expected problems %v, got %v", want, got)
+	}
+	if err := f.repos.Integrity.Fix(context.Background(), problems[0]); err == nil {
+		t.Error("Expected a problem that needs a hand to be refused")
+	}
+
+	fixAll(t, sqlDB)
+	problems, err = f.repos.Integrity.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	if got := kinds(problems); !slices.Equal(got, want) {
+		t.Errorf("Expected problems %v to be left, got %v", want, got)
+	}
+}
+
+func TestFixOrphansAndMissingRows(t *testing.T) {
+	if testdb.IsPostgres() {
+		t.Skip("PostgreSQL does not let foreign keys be turned off")
+	}
+	sqlDB := testdb.Open(t)
+	defer sqlDB.Close()
+	f := createFixture(t, sqlDB)
+	ctx := context.Background()
+
+	// Rows a build without foreign keys left behind
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"PRAGMA foreign_keys = OFF",
+		"UPDATE bills SET receiver_id = 4242",
+		"UPDATE bill_item_assignments SET item_id = 4343 WHERE id = " + itoa(f.bill.Items[0].ID),
+		`INSERT INTO bill_item_assignments (bill_id, item_id, quantity, price, currency, exchange_rate,
+			original_amount, eur_amount, created_at, updated_at)
+		VALUES (9999, 1, 1, 10, 'EUR', 1, 10, 10, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		"PRAGMA foreign_keys = ON",
+	} {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			t.Fatalf("Failed to run %q: %v", query, err)
+		}
+	}
+	conn.Close()
+
+	problems, err := f.repos.Integrity.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	want := []string{models.ProblemOrphanLine, models.ProblemMissingReceiver, models.ProblemMissingItem}
+	if got := kinds(problems); !slices.Equal(got, want) {
+		t.Fatalf("Expected problems %v, got %v", want, got)
+	}
+	if !strings.Contains(problems[1].Detail, "receiver 4242") {
+		t.Errorf("Expected the detail to name the receiver, got %q", problems[1].Detail)
+	}
+
+	fixAll(t, sqlDB)
+	problems, err = f.repos.Integrity.Check(ctx)
+	if err != nil {
+		t.Fatalf("Failed to check: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected every problem fixed, got %v", kinds(problems))
+	}
+	rows, err := sqlDB.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		t.Error("Expected no foreign key left dangling")
+	}
+}
+
+func itoa(id int64) string {
+	return strconv.FormatInt(id, 10)
+}